@@ -2,11 +2,15 @@ package deduper
 
 import (
 	"context"
+	"io"
 	"sync"
 )
 
 type Deduper interface {
 	AddIfNotExists(context.Context, string) bool
+	// Duplicates returns how many keys were rejected because they had
+	// already been added.
+	Duplicates() int
 }
 
 func New() Deduper {
@@ -15,3 +19,12 @@ func New() Deduper {
 		mux:  &sync.RWMutex{},
 	}
 }
+
+// NewWithReport is like New but also writes every rejected key to w,
+// one per line. Write errors are ignored.
+func NewWithReport(w io.Writer) Deduper {
+	d := New().(*hashmap)
+	d.report = w
+
+	return d
+}
@@ -3,20 +3,28 @@ package deduper
 import (
 	"context"
 	"hash/fnv"
+	"io"
 	"sync"
+	"sync/atomic"
 )
 
 var _ Deduper = (*hashmap)(nil)
 
 type hashmap struct {
-	mux  *sync.RWMutex
-	seen map[uint64]struct{}
+	mux        *sync.RWMutex
+	seen       map[uint64]struct{}
+	duplicates atomic.Int64
+
+	reportMux sync.Mutex
+	report    io.Writer
 }
 
 func (d *hashmap) AddIfNotExists(_ context.Context, key string) bool {
 	d.mux.RLock()
 	if _, ok := d.seen[d.hash(key)]; ok {
 		d.mux.RUnlock()
+		d.duplicate(key)
+
 		return false
 	}
 
@@ -26,6 +34,8 @@ func (d *hashmap) AddIfNotExists(_ context.Context, key string) bool {
 	defer d.mux.Unlock()
 
 	if _, ok := d.seen[d.hash(key)]; ok {
+		d.duplicate(key)
+
 		return false
 	}
 
@@ -34,6 +44,23 @@ func (d *hashmap) AddIfNotExists(_ context.Context, key string) bool {
 	return true
 }
 
+func (d *hashmap) Duplicates() int {
+	return int(d.duplicates.Load())
+}
+
+func (d *hashmap) duplicate(key string) {
+	d.duplicates.Add(1)
+
+	if d.report == nil {
+		return
+	}
+
+	d.reportMux.Lock()
+	defer d.reportMux.Unlock()
+
+	_, _ = io.WriteString(d.report, key+"\n")
+}
+
 func (d *hashmap) hash(key string) uint64 {
 	h := fnv.New64()
 	h.Write([]byte(key))
@@ -0,0 +1,49 @@
+package deduper_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+)
+
+func Test_DeduperDuplicates(t *testing.T) {
+	ctx := context.Background()
+	d := deduper.New()
+
+	require.True(t, d.AddIfNotExists(ctx, "a"))
+	require.True(t, d.AddIfNotExists(ctx, "b"))
+	require.False(t, d.AddIfNotExists(ctx, "a"))
+	require.False(t, d.AddIfNotExists(ctx, "a"))
+	require.Equal(t, 2, d.Duplicates())
+}
+
+func Test_DeduperReport(t *testing.T) {
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+
+	d := deduper.NewWithReport(&buf)
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			d.AddIfNotExists(ctx, "https://www.google.com/maps/place/a")
+		}()
+	}
+
+	wg.Wait()
+
+	require.False(t, d.AddIfNotExists(ctx, "https://www.google.com/maps/place/a"))
+	require.Equal(t, 10, d.Duplicates())
+	require.Equal(t, 10, bytes.Count(buf.Bytes(), []byte("https://www.google.com/maps/place/a\n")))
+}
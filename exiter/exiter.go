@@ -8,19 +8,40 @@ import (
 
 type Exiter interface {
 	SetSeedCount(int)
+	IncrSeedCount(int)
 	SetCancelFunc(context.CancelFunc)
 	IncrSeedCompleted(int)
 	IncrPlacesFound(int)
 	IncrPlacesCompleted(int)
+	SetRequestBudget(int)
+	AllowRequest() bool
+	Progress() Progress
 	Run(context.Context)
 }
 
+// Progress is a snapshot of the counters of an Exiter.
+type Progress struct {
+	SeedCount       int
+	SeedCompleted   int
+	PlacesFound     int
+	PlacesCompleted int
+	// Requests is the number of page navigations allowed so far.
+	Requests int
+}
+
+// checkInterval is how often Run checks whether the work is done.
+var checkInterval = 5 * time.Second
+
 type exiter struct {
 	seedCount       int
 	seedCompleted   int
 	placesFound     int
 	placesCompleted int
 
+	requests        int
+	requestBudget   int
+	budgetExhausted bool
+
 	mu         *sync.Mutex
 	cancelFunc context.CancelFunc
 }
@@ -38,6 +59,14 @@ func (e *exiter) SetSeedCount(val int) {
 	e.seedCount = val
 }
 
+// IncrSeedCount adds seeds created while running, e.g. follow-up searches.
+func (e *exiter) IncrSeedCount(val int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.seedCount += val
+}
+
 func (e *exiter) SetCancelFunc(fn context.CancelFunc) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -66,8 +95,48 @@ func (e *exiter) IncrPlacesCompleted(val int) {
 	e.placesCompleted += val
 }
 
+// SetRequestBudget limits the number of page navigations of the run,
+// 0 means no limit. Once the budget is used up Run stops the run.
+func (e *exiter) SetRequestBudget(val int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.requestBudget = val
+}
+
+// AllowRequest counts a page navigation and reports whether it fits in
+// the request budget. Jobs call it before navigating and skip the
+// navigation when it returns false.
+func (e *exiter) AllowRequest() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.requestBudget > 0 && e.requests >= e.requestBudget {
+		e.budgetExhausted = true
+
+		return false
+	}
+
+	e.requests++
+
+	return true
+}
+
+func (e *exiter) Progress() Progress {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return Progress{
+		SeedCount:       e.seedCount,
+		SeedCompleted:   e.seedCompleted,
+		PlacesFound:     e.placesFound,
+		PlacesCompleted: e.placesCompleted,
+		Requests:        e.requests,
+	}
+}
+
 func (e *exiter) Run(ctx context.Context) {
-	ticker := time.NewTicker(time.Second * 5)
+	ticker := time.NewTicker(checkInterval)
 	defer ticker.Stop()
 
 	for {
@@ -88,6 +157,11 @@ func (e *exiter) isDone() bool {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	// the navigations in progress had a tick to finish
+	if e.budgetExhausted {
+		return true
+	}
+
 	if e.seedCompleted != e.seedCount {
 		return false
 	}
@@ -0,0 +1,54 @@
+package exiter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+)
+
+func Test_RequestBudget(t *testing.T) {
+	exiter.SetCheckInterval(10 * time.Millisecond)
+
+	e := exiter.New()
+	e.SetSeedCount(10)
+	e.SetRequestBudget(3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e.SetCancelFunc(cancel)
+
+	go e.Run(ctx)
+
+	allowed := 0
+
+	for range 5 {
+		if e.AllowRequest() {
+			allowed++
+		}
+	}
+
+	require.Equal(t, 3, allowed)
+	require.Equal(t, 3, e.Progress().Requests)
+
+	// the seeds are not done, the exhausted budget stops the run
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the run was not stopped")
+	}
+}
+
+func Test_NoRequestBudget(t *testing.T) {
+	e := exiter.New()
+
+	for range 100 {
+		require.True(t, e.AllowRequest())
+	}
+
+	require.Equal(t, 100, e.Progress().Requests)
+}
@@ -0,0 +1,8 @@
+package exiter
+
+import "time"
+
+// SetCheckInterval changes how often Run checks whether the work is done.
+func SetCheckInterval(d time.Duration) {
+	checkInterval = d
+}
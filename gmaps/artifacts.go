@@ -0,0 +1,44 @@
+package gmaps
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var dataIDRe = regexp.MustCompile(`!1s(0x[0-9a-fA-F]+:0x[0-9a-fA-F]+)`)
+
+// ArtifactCounter counts the debug files (screenshots, raw JSON) the jobs
+// of a run write per kind and directory, so that their limit applies to
+// all the jobs writing there, see Settings.Artifacts. The zero value is
+// ready to use.
+type ArtifactCounter struct {
+	counts sync.Map
+}
+
+// allowArtifact reserves a slot for one more file of kind in dir.
+// It returns false when maxFiles were already written, 0 means no limit.
+// Without a counter in s every file is allowed.
+func (s *Settings) allowArtifact(kind, dir string, maxFiles int) bool {
+	if maxFiles <= 0 || s == nil || s.Artifacts == nil {
+		return true
+	}
+
+	counter, _ := s.Artifacts.counts.LoadOrStore(kind+"|"+dir, new(atomic.Int64))
+
+	return counter.(*atomic.Int64).Add(1) <= int64(maxFiles)
+}
+
+// placeFileName returns a file name for the place at placeURL, based on
+// its data ID, e.g. 0x14e732fd76f0d90d_0xe5415928d6702b47.jpg.
+// fallbackID is used when the URL has no data ID.
+func placeFileName(placeURL, fallbackID, ext string) string {
+	id := fallbackID
+
+	if dataID := dataIDFromURL(placeURL); dataID != "" {
+		id = strings.ReplaceAll(dataID, ":", "_")
+	}
+
+	return id + ext
+}
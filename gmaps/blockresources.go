@@ -0,0 +1,145 @@
+package gmaps
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Resources of Settings.BlockedResources.
+const (
+	ResourceImages      = "images"
+	ResourceFonts       = "fonts"
+	ResourceStylesheets = "stylesheets"
+	ResourceMedia       = "media"
+	ResourceAnalytics   = "analytics"
+)
+
+// resourceTypes maps the resources to the Playwright resource types of
+// their requests. The analytics are matched by domain instead.
+var resourceTypes = map[string]string{
+	ResourceImages:      "image",
+	ResourceFonts:       "font",
+	ResourceStylesheets: "stylesheet",
+	ResourceMedia:       "media",
+}
+
+// analyticsDomains are the domains of the requests blocked as analytics,
+// including their subdomains.
+var analyticsDomains = []string{
+	"google-analytics.com",
+	"googletagmanager.com",
+	"googleadservices.com",
+	"googlesyndication.com",
+	"doubleclick.net",
+}
+
+// routedContexts are the browser contexts the blocking route was added to.
+var routedContexts sync.Map
+
+// resourceBlock is the set of resources blocked by the route of
+// applyBlockedResources.
+type resourceBlock struct {
+	types     map[string]bool
+	analytics bool
+}
+
+// ValidateResources checks that names are resources of
+// Settings.BlockedResources.
+func ValidateResources(names []string) error {
+	for _, name := range names {
+		if _, ok := resourceTypes[name]; !ok && name != ResourceAnalytics {
+			return fmt.Errorf("unknown resource %q, must be one of: %s, %s, %s, %s, %s", name,
+				ResourceImages, ResourceFonts, ResourceStylesheets, ResourceMedia, ResourceAnalytics)
+		}
+	}
+
+	return nil
+}
+
+// newResourceBlock returns the set of the resources names. Unknown names
+// are ignored.
+func newResourceBlock(names []string) *resourceBlock {
+	block := resourceBlock{types: make(map[string]bool)}
+
+	for _, name := range names {
+		if name == ResourceAnalytics {
+			block.analytics = true
+		} else if typ, ok := resourceTypes[name]; ok {
+			block.types[typ] = true
+		}
+	}
+
+	return &block
+}
+
+// applyBlockedResources adds the route that blocks s.BlockedResources to
+// the browser context of page, once per context. Like the storage state,
+// the route is added to the contexts scrapemate creates instead of being an
+// option of their creation.
+func (s *Settings) applyBlockedResources(page storagePage) error {
+	if s == nil || len(s.BlockedResources) == 0 {
+		return nil
+	}
+
+	bctx := page.Context()
+
+	if _, loaded := routedContexts.LoadOrStore(bctx, struct{}{}); loaded {
+		return nil
+	}
+
+	bctx.OnClose(func(bctx playwright.BrowserContext) {
+		routedContexts.Delete(bctx)
+	})
+
+	if err := bctx.Route("**/*", newResourceBlock(s.BlockedResources).route); err != nil {
+		routedContexts.Delete(bctx)
+
+		return fmt.Errorf("blocking resources: %w", err)
+	}
+
+	return nil
+}
+
+// route aborts the requests of the blocked resources and lets the others
+// through.
+func (b *resourceBlock) route(route playwright.Route) {
+	req := route.Request()
+
+	if b.blocks(req.ResourceType(), req.URL()) {
+		_ = route.Abort("blockedbyclient")
+
+		return
+	}
+
+	_ = route.Continue()
+}
+
+// blocks reports whether a request of resourceType to rawURL is blocked.
+func (b *resourceBlock) blocks(resourceType, rawURL string) bool {
+	if b.types[resourceType] {
+		return true
+	}
+
+	if !b.analytics {
+		return false
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := u.Hostname()
+
+	for _, domain := range analyticsDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package gmaps_test
+
+import (
+	"testing"
+
+	"github.com/playwright-community/playwright-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+type routeContext struct {
+	playwright.BrowserContext
+
+	handlers []func(playwright.Route)
+	onClose  func(playwright.BrowserContext)
+}
+
+func (c *routeContext) Route(_ any, handler func(playwright.Route), _ ...int) error {
+	c.handlers = append(c.handlers, handler)
+
+	return nil
+}
+
+func (c *routeContext) OnClose(fn func(playwright.BrowserContext)) {
+	c.onClose = fn
+}
+
+type routePage struct {
+	bctx *routeContext
+}
+
+func (p *routePage) Context() playwright.BrowserContext {
+	return p.bctx
+}
+
+type routeRequest struct {
+	playwright.Request
+
+	resourceType string
+	url          string
+}
+
+func (r *routeRequest) ResourceType() string {
+	return r.resourceType
+}
+
+func (r *routeRequest) URL() string {
+	return r.url
+}
+
+// fakeRoute records whether the route handler aborted or continued it.
+type fakeRoute struct {
+	playwright.Route
+
+	req       *routeRequest
+	aborted   bool
+	continued bool
+}
+
+func (r *fakeRoute) Request() playwright.Request {
+	return r.req
+}
+
+func (r *fakeRoute) Abort(_ ...string) error {
+	r.aborted = true
+
+	return nil
+}
+
+func (r *fakeRoute) Continue(_ ...playwright.RouteContinueOptions) error {
+	r.continued = true
+
+	return nil
+}
+
+func Test_ApplyBlockedResources(t *testing.T) {
+	settings := &gmaps.Settings{
+		BlockedResources: []string{gmaps.ResourceFonts, gmaps.ResourceMedia, gmaps.ResourceAnalytics},
+	}
+
+	bctx := &routeContext{}
+	page := &routePage{bctx: bctx}
+
+	require.NoError(t, gmaps.ApplyBlockedResources(settings, page))
+	require.Len(t, bctx.handlers, 1)
+
+	tests := []struct {
+		name         string
+		resourceType string
+		url          string
+		blocked      bool
+	}{
+		{name: "font", resourceType: "font", url: "https://fonts.gstatic.com/s/roboto.woff2", blocked: true},
+		{name: "media", resourceType: "media", url: "https://www.google.com/maps/video.mp4", blocked: true},
+		{name: "analytics", resourceType: "script", url: "https://www.google-analytics.com/analytics.js", blocked: true},
+		{name: "analytics subdomain", resourceType: "xhr", url: "https://stats.g.doubleclick.net/collect", blocked: true},
+		{name: "document", resourceType: "document", url: "https://www.google.com/maps/search/cafe"},
+		{name: "search results", resourceType: "xhr", url: "https://www.google.com/search?tbm=map&q=cafe"},
+		{name: "image not configured", resourceType: "image", url: "https://lh5.googleusercontent.com/p/photo.jpg"},
+		{name: "stylesheet not configured", resourceType: "stylesheet", url: "https://www.gstatic.com/maps.css"},
+		{name: "lookalike domain", resourceType: "script", url: "https://notdoubleclick.net/tag.js"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			route := &fakeRoute{req: &routeRequest{resourceType: tc.resourceType, url: tc.url}}
+
+			bctx.handlers[0](route)
+
+			require.Equal(t, tc.blocked, route.aborted)
+			require.Equal(t, !tc.blocked, route.continued)
+		})
+	}
+
+	// the route is added once per context
+	require.NoError(t, gmaps.ApplyBlockedResources(settings, page))
+	require.Len(t, bctx.handlers, 1)
+
+	// and again once the context is closed
+	bctx.onClose(bctx)
+	require.NoError(t, gmaps.ApplyBlockedResources(settings, page))
+	require.Len(t, bctx.handlers, 2)
+}
+
+func Test_ApplyBlockedResourcesUnset(t *testing.T) {
+	bctx := &routeContext{}
+	require.NoError(t, gmaps.ApplyBlockedResources(nil, &routePage{bctx: bctx}))
+	require.NoError(t, gmaps.ApplyBlockedResources(&gmaps.Settings{}, &routePage{bctx: bctx}))
+	require.Empty(t, bctx.handlers)
+}
+
+func Test_ValidateResources(t *testing.T) {
+	require.NoError(t, gmaps.ValidateResources([]string{"images", "fonts", "stylesheets", "media", "analytics"}))
+	require.NoError(t, gmaps.ValidateResources(nil))
+	require.ErrorContains(t, gmaps.ValidateResources([]string{"fonts", "image"}), `"image"`)
+}
@@ -0,0 +1,84 @@
+package gmaps
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheKey prefixes key with the start of the current window of
+// s.CacheTTL, so that entries written in an earlier window are not found
+// anymore.
+//
+// Entries are grouped in windows of the TTL starting at the Unix epoch and
+// a window is dropped as a whole, so an entry is served for at most the
+// TTL.
+func (s *Settings) cacheKey(key string) string {
+	if s == nil || s.CacheTTL <= 0 {
+		return key
+	}
+
+	window := time.Now().UTC().Truncate(s.CacheTTL).Unix()
+
+	return strconv.FormatInt(window, 10) + ":" + key
+}
+
+// CacheKeyExpired reports whether the cache entry stored under key can no
+// longer be served at now with the cache TTL ttl, see Settings.CacheTTL.
+// Keys written without a TTL are always expired once a TTL is set.
+func CacheKeyExpired(key string, ttl time.Duration, now time.Time) bool {
+	if ttl <= 0 {
+		return false
+	}
+
+	prefix, _, ok := strings.Cut(key, ":")
+	if !ok {
+		return true
+	}
+
+	window, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return true
+	}
+
+	return window < now.UTC().Truncate(ttl).Unix()
+}
+
+// metaBytes returns v as bytes. Responses served from the cache went
+// through a JSON round trip, which turns []byte into base64 strings.
+func metaBytes(v any) ([]byte, bool) {
+	switch b := v.(type) {
+	case []byte:
+		return b, true
+	case string:
+		raw, err := base64.StdEncoding.DecodeString(b)
+		if err != nil {
+			return nil, false
+		}
+
+		return raw, true
+	default:
+		return nil, false
+	}
+}
+
+// metaPages is like metaBytes for a list of pages.
+func metaPages(v any) [][]byte {
+	switch p := v.(type) {
+	case [][]byte:
+		return p
+	case []any:
+		pages := make([][]byte, 0, len(p))
+
+		for _, item := range p {
+			if raw, ok := metaBytes(item); ok {
+				pages = append(pages, raw)
+			}
+		}
+
+		return pages
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+package gmaps_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_CacheKeyExpired(t *testing.T) {
+	require.Equal(t, "abc", gmaps.CacheKey(nil, "abc"))
+	require.Equal(t, "abc", gmaps.CacheKey(&gmaps.Settings{}, "abc"))
+	require.False(t, gmaps.CacheKeyExpired("abc", 0, time.Now()))
+
+	settings := &gmaps.Settings{CacheTTL: time.Hour}
+
+	key := gmaps.CacheKey(settings, "abc")
+	require.NotEqual(t, "abc", key)
+	require.Equal(t, key, gmaps.CacheKey(settings, "abc"))
+
+	require.False(t, gmaps.CacheKeyExpired(key, time.Hour, time.Now()))
+	require.True(t, gmaps.CacheKeyExpired(key, time.Hour, time.Now().Add(time.Hour)))
+	require.True(t, gmaps.CacheKeyExpired("abc", time.Hour, time.Now()))
+	require.True(t, gmaps.CacheKeyExpired("x:abc", time.Hour, time.Now()))
+}
+
+func Test_MetaAfterCacheRoundTrip(t *testing.T) {
+	resp := scrapemate.Response{
+		Meta: map[string]any{
+			"json":        []byte(`[1,2,3]`),
+			"reviews_raw": [][]byte{[]byte("page1"), []byte("page2")},
+		},
+	}
+
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+
+	var cached scrapemate.Response
+
+	require.NoError(t, json.Unmarshal(data, &cached))
+
+	for _, r := range []scrapemate.Response{resp, cached} {
+		raw, ok := gmaps.MetaBytes(r.Meta["json"])
+		require.True(t, ok)
+		require.Equal(t, []byte(`[1,2,3]`), raw)
+
+		require.Equal(t, [][]byte{[]byte("page1"), []byte("page2")}, gmaps.MetaPages(r.Meta["reviews_raw"]))
+	}
+
+	_, ok := gmaps.MetaBytes(nil)
+	require.False(t, ok)
+	require.Nil(t, gmaps.MetaPages(nil))
+}
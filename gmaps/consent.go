@@ -0,0 +1,85 @@
+package gmaps
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+const (
+	// consentRejectSelector matches the "Reject all" button of Google's
+	// consent form, both in the cookie banner of the maps page and on the
+	// consent.google.com interstitial. It does not depend on the language.
+	consentRejectSelector = `form[action^="https://consent.google."][action$="/save"]:first-of-type button:first-of-type`
+
+	consentTimeout  = 5 * time.Second
+	consentAttempts = 3
+)
+
+type consentPage interface {
+	URL() string
+	Click(selector string, options ...playwright.PageClickOptions) error
+	WaitForURL(url any, options ...playwright.PageWaitForURLOptions) error
+	WaitForSelector(selector string, options ...playwright.PageWaitForSelectorOptions) (playwright.ElementHandle, error)
+}
+
+// handleConsent gets the page past Google's consent form, if any.
+//
+// Usually the form is a cookie banner on top of the maps page, which is
+// dismissed when it shows up. In some regions Google redirects to a
+// consent.google.com interstitial instead, which redirects back to the
+// requested page once the form is answered. Answering it is tried up to
+// consentAttempts times; a page that stays on it returns ErrConsentWall.
+func (s *Settings) handleConsent(page consentPage) error {
+	ms := playwright.Float(float64(consentTimeout.Milliseconds()))
+
+	if !isConsentURL(page.URL()) {
+		//nolint:staticcheck // TODO replace with the new playwright API
+		el, err := page.WaitForSelector(consentRejectSelector, playwright.PageWaitForSelectorOptions{
+			Timeout: ms,
+		})
+		if err != nil || el == nil {
+			return nil
+		}
+
+		//nolint:staticcheck // TODO replace with the new playwright API
+		return el.Click()
+	}
+
+	left := func(u string) bool {
+		return !isConsentURL(u)
+	}
+
+	for range consentAttempts {
+		//nolint:staticcheck // TODO replace with the new playwright API
+		if err := page.Click(consentRejectSelector, playwright.PageClickOptions{Timeout: ms}); err != nil {
+			continue
+		}
+
+		err := page.WaitForURL(left, playwright.PageWaitForURLOptions{
+			WaitUntil: s.waitUntilState(playwright.WaitUntilStateDomcontentloaded),
+			Timeout:   ms,
+		})
+		if err == nil {
+			return nil
+		}
+	}
+
+	if !isConsentURL(page.URL()) {
+		return nil
+	}
+
+	return ErrConsentWall
+}
+
+// isConsentURL reports whether u is on a consent.google.* host.
+func isConsentURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(parsed.Hostname(), "consent.google.")
+}
@@ -0,0 +1,115 @@
+package gmaps_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/playwright-community/playwright-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const mapsURL = "https://www.google.com/maps/search/dentist+in+berlin?hl=en"
+
+// fakeConsentPage answers the consent interstitial by going back to
+// mapsURL, after failing the first failClicks clicks.
+type fakeConsentPage struct {
+	url        string
+	failClicks int
+	clicks     int
+	selector   string
+}
+
+func (p *fakeConsentPage) URL() string {
+	return p.url
+}
+
+func (p *fakeConsentPage) Click(selector string, _ ...playwright.PageClickOptions) error {
+	p.clicks++
+	p.selector = selector
+
+	if p.failClicks > 0 {
+		p.failClicks--
+
+		return errors.New("timeout: Timeout 5000ms exceeded")
+	}
+
+	p.url = mapsURL
+
+	return nil
+}
+
+func (p *fakeConsentPage) WaitForURL(u any, _ ...playwright.PageWaitForURLOptions) error {
+	if u.(func(string) bool)(p.url) {
+		return nil
+	}
+
+	return errors.New("timeout")
+}
+
+func (p *fakeConsentPage) WaitForSelector(string, ...playwright.PageWaitForSelectorOptions) (playwright.ElementHandle, error) {
+	// no cookie banner on the maps page
+	return nil, errors.New("timeout")
+}
+
+func Test_HandleConsent(t *testing.T) {
+	t.Run("no consent form", func(t *testing.T) {
+		page := &fakeConsentPage{url: mapsURL}
+
+		require.NoError(t, gmaps.HandleConsent(nil, page))
+		require.Zero(t, page.clicks)
+	})
+
+	for _, host := range []string{"consent.google.com", "consent.google.de", "consent.google.co.uk"} {
+		t.Run("redirect to "+host, func(t *testing.T) {
+			page := &fakeConsentPage{url: "https://" + host + "/ml?continue=https://www.google.com/maps/search/dentist"}
+
+			require.NoError(t, gmaps.HandleConsent(nil, page))
+			require.Equal(t, 1, page.clicks)
+			require.Equal(t, gmaps.ConsentRejectSelector, page.selector)
+			require.Equal(t, mapsURL, page.URL())
+		})
+	}
+
+	t.Run("retried after a failed click", func(t *testing.T) {
+		page := &fakeConsentPage{url: "https://consent.google.com/ml", failClicks: 2}
+
+		require.NoError(t, gmaps.HandleConsent(nil, page))
+		require.Equal(t, 3, page.clicks)
+	})
+
+	t.Run("stuck on the consent page", func(t *testing.T) {
+		page := &fakeConsentPage{url: "https://consent.google.com/ml", failClicks: 10}
+
+		err := gmaps.HandleConsent(nil, page)
+		require.ErrorIs(t, err, gmaps.ErrConsentWall)
+		require.True(t, gmaps.IsPermanentError(err))
+		require.Equal(t, 3, page.clicks)
+	})
+}
+
+func Test_ConsentRejectSelector(t *testing.T) {
+	tests := map[string]string{
+		"en": "Reject all",
+		"de": "Alle ablehnen",
+	}
+
+	for lang, label := range tests {
+		t.Run(lang, func(t *testing.T) {
+			f, err := os.Open("../testdata/consent/" + lang + ".html")
+			require.NoError(t, err)
+
+			defer f.Close()
+
+			doc, err := goquery.NewDocumentFromReader(f)
+			require.NoError(t, err)
+
+			buttons := doc.Find(gmaps.ConsentRejectSelector)
+			require.Equal(t, 1, buttons.Length())
+			require.Equal(t, label, buttons.AttrOr("aria-label", ""))
+		})
+	}
+}
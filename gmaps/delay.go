@@ -0,0 +1,45 @@
+package gmaps
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// randomDelay returns a random duration between minDelay and maxDelay,
+// both inclusive.
+func randomDelay(minDelay, maxDelay time.Duration) time.Duration {
+	if maxDelay <= minDelay {
+		return minDelay
+	}
+
+	return minDelay + rand.N(maxDelay-minDelay+1)
+}
+
+// sleepContext waits for d. It returns the error of ctx if ctx is done
+// first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// waitPlaceDelay pauses for a random delay between s.PlaceDelayMin and
+// s.PlaceDelayMax.
+func (s *Settings) waitPlaceDelay(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+
+	return sleepContext(ctx, randomDelay(s.PlaceDelayMin, s.PlaceDelayMax))
+}
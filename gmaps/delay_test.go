@@ -0,0 +1,57 @@
+package gmaps_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_RandomDelay(t *testing.T) {
+	const (
+		minDelay = 200 * time.Millisecond
+		maxDelay = 900 * time.Millisecond
+	)
+
+	seen := make(map[time.Duration]bool)
+
+	for range 1000 {
+		d := gmaps.RandomDelay(minDelay, maxDelay)
+		require.GreaterOrEqual(t, d, minDelay)
+		require.LessOrEqual(t, d, maxDelay)
+
+		seen[d] = true
+	}
+
+	require.Greater(t, len(seen), 1, "the delay must be random")
+
+	require.Equal(t, minDelay, gmaps.RandomDelay(minDelay, minDelay))
+	require.Equal(t, minDelay, gmaps.RandomDelay(minDelay, 0))
+	require.Zero(t, gmaps.RandomDelay(0, 0))
+}
+
+func Test_SleepContext(t *testing.T) {
+	start := time.Now()
+
+	require.NoError(t, gmaps.SleepContext(context.Background(), 20*time.Millisecond))
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	// cancellation interrupts a pending delay
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start = time.Now()
+
+	err := gmaps.SleepContext(ctx, time.Hour)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	require.NoError(t, gmaps.SleepContext(ctx, 0))
+}
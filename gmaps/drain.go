@@ -0,0 +1,40 @@
+package gmaps
+
+import (
+	"context"
+	"time"
+)
+
+// drainContext returns the context of a job in flight with ctx: a context
+// with the deadline and the values of ctx that is canceled s.DrainTimeout
+// after ctx is, and its cancel function. Without a drain timeout it returns
+// ctx.
+func (s *Settings) drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s == nil || s.DrainTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	base, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	dctx, cancelDeadline := base, context.CancelFunc(func() {})
+	if deadline, ok := ctx.Deadline(); ok {
+		dctx, cancelDeadline = context.WithDeadline(base, deadline)
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.NewTimer(s.DrainTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			cancel()
+		case <-dctx.Done():
+		}
+	})
+
+	return dctx, func() {
+		stop()
+		cancelDeadline()
+		cancel()
+	}
+}
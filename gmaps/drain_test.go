@@ -0,0 +1,184 @@
+package gmaps_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const drainPlaceURL = "https://www.google.com/maps/place/The+Red+Lion/data=!4m2!3m1!1s0x487604c5a1b50c85:0x4444444444444444"
+
+// drainPage is a place page whose title shows up at the third check. The
+// run is stopped by Goto, while the page loads.
+type drainPage struct {
+	playwright.Page
+
+	stop   func()
+	raw    string
+	gotos  int
+	checks int
+}
+
+func (p *drainPage) URL() string {
+	return drainPlaceURL
+}
+
+func (p *drainPage) Goto(string, ...playwright.PageGotoOptions) (playwright.Response, error) {
+	p.gotos++
+	p.stop()
+
+	return &drainResponse{}, nil
+}
+
+func (p *drainPage) Locator(string, ...playwright.PageLocatorOptions) playwright.Locator {
+	return &drainLocator{}
+}
+
+func (p *drainPage) WaitForSelector(string, ...playwright.PageWaitForSelectorOptions) (playwright.ElementHandle, error) {
+	// no cookie banner
+	return nil, errors.New("timeout")
+}
+
+func (p *drainPage) WaitForURL(any, ...playwright.PageWaitForURLOptions) error {
+	return nil
+}
+
+func (p *drainPage) Evaluate(_ string, arg ...any) (any, error) {
+	// the place data
+	if len(arg) == 0 {
+		return p.raw, nil
+	}
+
+	p.checks++
+
+	if p.checks < 3 {
+		return -1, nil
+	}
+
+	return 0, nil
+}
+
+type drainResponse struct {
+	playwright.Response
+}
+
+func (r *drainResponse) URL() string {
+	return drainPlaceURL
+}
+
+func (r *drainResponse) Status() int {
+	return 200
+}
+
+func (r *drainResponse) Headers() map[string]string {
+	return map[string]string{"content-type": "text/html"}
+}
+
+// locator lets drainLocator embed playwright.Locator, whose Locator method
+// would be shadowed by the field of the same name.
+type locator = playwright.Locator
+
+// drainLocator finds no CAPTCHA.
+type drainLocator struct {
+	locator
+}
+
+func (l *drainLocator) Count() (int, error) {
+	return 0, nil
+}
+
+func Test_PlaceJobDrain(t *testing.T) {
+	raw := string(loadPlaceFixture(t, "pub"))
+
+	// job returns a place job with the drain timeout
+	job := func(drainTimeout time.Duration) *gmaps.PlaceJob {
+		return gmaps.NewPlaceJob("seed", "en", drainPlaceURL, false, false,
+			gmaps.WithPlaceJobSettings(&gmaps.Settings{PlaceMarker: "h1.DUwDvf", DrainTimeout: drainTimeout}))
+	}
+
+	// run scrapes the place of a page that is loading when the run is stopped
+	run := func(t *testing.T, drainTimeout time.Duration) *gmaps.Entry {
+		t.Helper()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		page := &drainPage{stop: cancel, raw: raw}
+		job := job(drainTimeout)
+
+		resp := job.BrowserActions(ctx, page)
+		require.Equal(t, 1, page.gotos)
+
+		data, _, err := job.Process(ctx, &resp)
+		require.NoError(t, err)
+
+		entry, ok := data.(*gmaps.Entry)
+		require.True(t, ok)
+
+		return entry
+	}
+
+	t.Run("without drain timeout", func(t *testing.T) {
+		entry := run(t, 0)
+		require.Empty(t, entry.Title)
+		require.Contains(t, entry.Error, "not a place page")
+	})
+
+	t.Run("within the drain timeout", func(t *testing.T) {
+		entry := run(t, 5*time.Second)
+		require.Empty(t, entry.Error)
+		require.Equal(t, "The Red Lion", entry.Title)
+	})
+
+	t.Run("past the drain timeout", func(t *testing.T) {
+		entry := run(t, 100*time.Millisecond)
+		require.Empty(t, entry.Title)
+		require.Contains(t, entry.Error, "not a place page")
+	})
+
+	t.Run("not started", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		page := &drainPage{stop: cancel, raw: raw}
+
+		resp := job(5*time.Second).BrowserActions(ctx, page)
+		require.ErrorIs(t, resp.Error, context.Canceled)
+		require.Zero(t, page.gotos)
+	})
+}
+
+func Test_DrainContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	dctx, dcancel := gmaps.DrainContext(nil, ctx)
+	require.Equal(t, ctx, dctx)
+
+	dcancel()
+	cancel()
+
+	deadline := time.Now().Add(time.Hour)
+
+	ctx, cancel = context.WithDeadline(context.Background(), deadline)
+
+	dctx, dcancel = gmaps.DrainContext(&gmaps.Settings{DrainTimeout: 200 * time.Millisecond}, ctx)
+	defer dcancel()
+
+	got, ok := dctx.Deadline()
+	require.True(t, ok)
+	require.Equal(t, deadline, got)
+
+	cancel()
+
+	// the job runs on for the drain timeout
+	require.NoError(t, dctx.Err())
+	require.Eventually(t, func() bool {
+		return dctx.Err() != nil
+	}, 2*time.Second, 10*time.Millisecond)
+}
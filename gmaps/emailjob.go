@@ -9,6 +9,7 @@ import (
 	"github.com/gosom/google-maps-scraper/exiter"
 	"github.com/gosom/scrapemate"
 	"github.com/mcnijman/go-emailaddress"
+	"github.com/playwright-community/playwright-go"
 )
 
 type EmailExtractJobOptions func(*EmailExtractJob)
@@ -18,6 +19,16 @@ type EmailExtractJob struct {
 
 	Entry       *Entry
 	ExitMonitor exiter.Exiter
+	// Release, when set, is called once the website was fetched, and again
+	// when the job is processed: scrapemate does not fetch the responses of
+	// its cache, and does not process the ones it fails to parse. It is used
+	// to limit how many email jobs run concurrently, so only its first call
+	// must count.
+	Release func()
+
+	// settings are the settings of the run of the job, see
+	// WithEmailJobSettings.
+	settings *Settings
 }
 
 func NewEmailJob(parentID string, entry *Entry, opts ...EmailExtractJobOptions) *EmailExtractJob {
@@ -52,6 +63,14 @@ func WithEmailJobExitMonitor(exitMonitor exiter.Exiter) EmailExtractJobOptions {
 	}
 }
 
+// WithEmailJobSettings sets the settings of the run of the job, see
+// Settings.
+func WithEmailJobSettings(s *Settings) EmailExtractJobOptions {
+	return func(j *EmailExtractJob) {
+		j.settings = s
+	}
+}
+
 func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
@@ -64,6 +83,9 @@ func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response
 		}
 	}()
 
+	defer j.release()
+
+	ctx = withCorrelationID(ctx, j.Entry.CorrelationID)
 	log := scrapemate.GetLoggerFromContext(ctx)
 
 	log.Info("Processing email job", "url", j.URL)
@@ -88,6 +110,31 @@ func (j *EmailExtractJob) Process(ctx context.Context, resp *scrapemate.Response
 	return j.Entry, nil, nil
 }
 
+func (j *EmailExtractJob) GetCacheKey() string {
+	return j.settings.cacheKey(j.Job.GetCacheKey())
+}
+
+// BrowserActions visits the website unless the request budget is used up,
+// in which case the place is written without emails.
+func (j *EmailExtractJob) BrowserActions(_ context.Context, page playwright.Page) scrapemate.Response {
+	// scrapemate does not process the job when parsing the response fails
+	// or the fetch panics
+	defer j.release()
+
+	if !allowRequest(j.ExitMonitor) {
+		return scrapemate.Response{Error: ErrRequestBudget}
+	}
+
+	return j.settings.browse(page, j.GetFullURL())
+}
+
+// release calls Release, if set.
+func (j *EmailExtractJob) release() {
+	if j.Release != nil {
+		j.Release()
+	}
+}
+
 func (j *EmailExtractJob) ProcessOnFetchError() bool {
 	return true
 }
@@ -0,0 +1,34 @@
+package gmaps_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/playwright-community/playwright-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// panicPage panics on every call, like a page whose browser crashed
+// badly enough for scrapemate to recover the job.
+type panicPage struct {
+	playwright.Page
+}
+
+func Test_EmailJobRelease(t *testing.T) {
+	var released int
+
+	job := gmaps.NewEmailJob("place", &gmaps.Entry{WebSite: "https://example.com"})
+	job.Release = func() { released++ }
+
+	// scrapemate skips Process when the fetch panics
+	require.Panics(t, func() { job.BrowserActions(context.Background(), panicPage{}) })
+	require.Equal(t, 1, released)
+
+	// the responses of the cache are processed without a fetch
+	_, _, err := job.Process(context.Background(), &scrapemate.Response{})
+	require.NoError(t, err)
+	require.Equal(t, 2, released)
+}
@@ -9,6 +9,8 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 type Image struct {
@@ -27,6 +29,9 @@ type Owner struct {
 	Link string `json:"link"`
 }
 
+// Address is the address of a place split in its components. Components
+// that the address format of the country does not use are empty, e.g. the
+// state of most UK and Japanese addresses.
 type Address struct {
 	Borough    string `json:"borough"`
 	Street     string `json:"street"`
@@ -54,12 +59,26 @@ type Review struct {
 	Description    string
 	Images         []string
 	When           string
+	// AuthorReviewCount is the number of reviews the author has written.
+	AuthorReviewCount  int
+	AuthorIsLocalGuide bool
+	// OwnerResponse is the reply of the owner to the review and
+	// OwnerResponseWhen its date, both empty when the owner did not reply.
+	OwnerResponse     string
+	OwnerResponseWhen string
 }
 
 type Entry struct {
-	ID         string              `json:"input_id"`
+	// ID is the ID of the input line that produced the entry, or the
+	// random ID of its search when the line has none. It is not stable:
+	// without IDs in the input it changes on every run.
+	ID string `json:"input_id"`
+	// PlaceID identifies the place itself and does not change between
+	// runs, see placeID. It is the key to join the results of runs on.
+	PlaceID    string              `json:"place_id"`
 	Link       string              `json:"link"`
 	Cid        string              `json:"cid"`
+	CIDURL     string              `json:"cid_url"`
 	Title      string              `json:"title"`
 	Categories []string            `json:"categories"`
 	Category   string              `json:"category"`
@@ -74,25 +93,86 @@ type Entry struct {
 	ReviewCount         int                    `json:"review_count"`
 	ReviewRating        float64                `json:"review_rating"`
 	ReviewsPerRating    map[int]int            `json:"reviews_per_rating"`
+	RatingDistribution  [5]int                 `json:"rating_distribution"`
 	Latitude            float64                `json:"latitude"`
 	Longtitude          float64                `json:"longtitude"`
 	Status              string                 `json:"status"`
+	BusinessStatus      string                 `json:"business_status"`
 	Description         string                 `json:"description"`
+	DescriptionSource   string                 `json:"description_source"`
 	ReviewsLink         string                 `json:"reviews_link"`
 	Thumbnail           string                 `json:"thumbnail"`
 	Timezone            string                 `json:"timezone"`
+	UTCOffset           string                 `json:"utc_offset"`
+	Country             string                 `json:"country_code"`
 	PriceRange          string                 `json:"price_range"`
+	TypicalSpend        *TypicalSpend          `json:"typical_spend"`
 	DataID              string                 `json:"data_id"`
 	Images              []Image                `json:"images"`
+	PhotoCount          int                    `json:"photo_count"`
+	VideoCount          int                    `json:"video_count"`
 	Reservations        []LinkSource           `json:"reservations"`
 	OrderOnline         []LinkSource           `json:"order_online"`
 	Menu                LinkSource             `json:"menu"`
+	MenuURL             string                 `json:"menu_url"`
+	OrderURL            string                 `json:"order_url"`
+	ReservationURL      string                 `json:"reservation_url"`
+	ShareURL            string                 `json:"share_url"`
+	DirectionsURL       string                 `json:"directions_url"`
 	Owner               Owner                  `json:"owner"`
+	Claimed             *bool                  `json:"claimed"`
 	CompleteAddress     Address                `json:"complete_address"`
 	About               []About                `json:"about"`
 	UserReviews         []Review               `json:"user_reviews"`
 	UserReviewsExtended []Review               `json:"user_reviews_extended"`
 	Emails              []string               `json:"emails"`
+
+	// HotelClass (the stars), CheckIn, CheckOut and HotelAmenities are
+	// only set for lodging places, see isLodging.
+	HotelClass     int      `json:"hotel_class"`
+	CheckIn        string   `json:"check_in"`
+	CheckOut       string   `json:"check_out"`
+	HotelAmenities []string `json:"hotel_amenities"`
+
+	// The wheelchair accessibility of the place, from the accessibility
+	// attributes of its about section. They are nil when Google does not
+	// show the attribute, which does not mean the place is not accessible.
+	WheelchairAccessibleEntrance *bool `json:"wheelchair_accessible_entrance"`
+	WheelchairAccessibleParking  *bool `json:"wheelchair_accessible_parking"`
+	WheelchairAccessibleRestroom *bool `json:"wheelchair_accessible_restroom"`
+
+	// RelatedPlaces are the links of the places of the "People also
+	// search for" section of the place page and RelatedPlaceNames their
+	// names, in the same order.
+	RelatedPlaces     []string `json:"related_places"`
+	RelatedPlaceNames []string `json:"related_place_names"`
+
+	// provenance of the entry: the search that produced it and when
+	// it was scraped (UTC, RFC3339)
+	SourceKeyword string  `json:"source_keyword"`
+	SearchLat     float64 `json:"search_lat"`
+	SearchLon     float64 `json:"search_lon"`
+	SearchZoom    int     `json:"search_zoom"`
+	ScrapedAt     string  `json:"scraped_at"`
+	// CorrelationID is the ID of the run and the seed job of the entry,
+	// in the log lines of its jobs too, see Trace.
+	CorrelationID string `json:"correlation_id"`
+	// Proxy is the label of the proxy the place page was fetched through,
+	// or its URL without the credentials, see MetaProxy.
+	Proxy string `json:"proxy"`
+
+	// WebSiteGuessed is set with -enrich-website for places without a
+	// website on Google Maps. It comes from a web search and may be wrong.
+	WebSiteGuessed string `json:"web_site_guessed"`
+
+	// Change is set with -since: new for places not emitted by a
+	// previous run, changed for places whose rating or reviews changed
+	Change string `json:"change"`
+
+	// Error is set on the rows of places that could not be scraped because
+	// of a permanent failure, see IsPermanentError. Only the link, the IDs
+	// and the provenance of such rows are filled.
+	Error string `json:"error"`
 }
 
 func (e *Entry) haversineDistance(lat, lon float64) float64 {
@@ -154,6 +234,20 @@ func (e *Entry) Validate() error {
 	return nil
 }
 
+// RatingDistributionMatches reports whether the counts of the rating
+// distribution add up to the review count. Places whose distribution is
+// not shown by Google, all zeros, match. Google updates both separately,
+// so recently reviewed places may be off by a few reviews.
+func (e *Entry) RatingDistributionMatches() bool {
+	var total int
+
+	for _, n := range e.RatingDistribution {
+		total += n
+	}
+
+	return total == 0 || total == e.ReviewCount
+}
+
 func (e *Entry) CsvHeaders() []string {
 	return []string{
 		"input_id",
@@ -189,11 +283,58 @@ func (e *Entry) CsvHeaders() []string {
 		"user_reviews",
 		"user_reviews_extended",
 		"emails",
+		"source_keyword",
+		"search_lat",
+		"search_lon",
+		"search_zoom",
+		"scraped_at",
+		"change",
+		"error",
+		"address_borough",
+		"address_street",
+		"address_city",
+		"address_postal_code",
+		"address_state",
+		"address_country",
+		"place_id",
+		"web_site_guessed",
+		"menu_url",
+		"order_url",
+		"reservation_url",
+		"description_source",
+		"rating_1",
+		"rating_2",
+		"rating_3",
+		"rating_4",
+		"rating_5",
+		"share_url",
+		"directions_url",
+		"cid_url",
+		"claimed",
+		"photo_count",
+		"video_count",
+		"hotel_class",
+		"hotel_check_in",
+		"hotel_check_out",
+		"hotel_amenities",
+		"correlation_id",
+		"proxy",
+		"related_places",
+		"related_place_names",
+		"wheelchair_accessible_entrance",
+		"wheelchair_accessible_parking",
+		"wheelchair_accessible_restroom",
+		"typical_spend_min",
+		"typical_spend_max",
+		"typical_spend_currency",
+		"utc_offset",
+		"country_code",
+		"business_status",
 	}
 }
 
 func (e *Entry) CsvRow() []string {
-	return []string{
+	row := []string{
 		e.ID,
 		e.Link,
 		e.Title,
@@ -227,7 +368,66 @@ func (e *Entry) CsvRow() []string {
 		stringify(e.UserReviews),
 		stringify(e.UserReviewsExtended),
 		stringSliceToString(e.Emails),
+		e.SourceKeyword,
+		stringify(e.SearchLat),
+		stringify(e.SearchLon),
+		stringify(e.SearchZoom),
+		e.ScrapedAt,
+		e.Change,
+		e.Error,
+		e.CompleteAddress.Borough,
+		e.CompleteAddress.Street,
+		e.CompleteAddress.City,
+		e.CompleteAddress.PostalCode,
+		e.CompleteAddress.State,
+		e.CompleteAddress.Country,
+		e.PlaceID,
+		e.WebSiteGuessed,
+		e.MenuURL,
+		e.OrderURL,
+		e.ReservationURL,
+		e.DescriptionSource,
+		stringify(e.RatingDistribution[0]),
+		stringify(e.RatingDistribution[1]),
+		stringify(e.RatingDistribution[2]),
+		stringify(e.RatingDistribution[3]),
+		stringify(e.RatingDistribution[4]),
+		e.ShareURL,
+		e.DirectionsURL,
+		e.CIDURL,
+		optionalBool(e.Claimed),
+		strconv.Itoa(e.PhotoCount),
+		strconv.Itoa(e.VideoCount),
+		strconv.Itoa(e.HotelClass),
+		e.CheckIn,
+		e.CheckOut,
+		stringSliceToString(e.HotelAmenities),
+		e.CorrelationID,
+		e.Proxy,
+		stringSliceToString(e.RelatedPlaces),
+		stringSliceToString(e.RelatedPlaceNames),
+		optionalBool(e.WheelchairAccessibleEntrance),
+		optionalBool(e.WheelchairAccessibleParking),
+		optionalBool(e.WheelchairAccessibleRestroom),
 	}
+
+	if e.TypicalSpend != nil {
+		row = append(row,
+			strconv.Itoa(e.TypicalSpend.Min),
+			strconv.Itoa(e.TypicalSpend.Max),
+			e.TypicalSpend.Currency,
+		)
+	} else {
+		row = append(row, "", "", "")
+	}
+
+	row = append(row,
+		e.UTCOffset,
+		e.Country,
+		e.BusinessStatus,
+	)
+
+	return row
 }
 
 func (e *Entry) AddExtraReviews(pages [][]byte) {
@@ -314,7 +514,7 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 	)
 	entry.OpenHours = getHours(darray)
 	entry.PopularTimes = getPopularTimes(darray)
-	entry.WebSite = getNthElementAndCast[string](darray, 7, 0)
+	entry.WebSite = normalizeWebsite(getNthElementAndCast[string](darray, 7, 0))
 	entry.Phone = getNthElementAndCast[string](darray, 178, 0, 0)
 	entry.PlusCode = getNthElementAndCast[string](darray, 183, 2, 2, 0)
 	entry.ReviewRating = getNthElementAndCast[float64](darray, 4, 7)
@@ -322,26 +522,25 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 	entry.Longtitude = getNthElementAndCast[float64](darray, 9, 3)
 	entry.Cid = getNthElementAndCast[string](jd, 25, 3, 0, 13, 0, 0, 1)
 	entry.Status = getNthElementAndCast[string](darray, 34, 4, 4)
-	entry.Description = getNthElementAndCast[string](darray, 32, 1, 1)
+	entry.BusinessStatus = businessStatus(darray)
+	entry.Description, entry.DescriptionSource = getDescription(darray)
 	entry.ReviewsLink = getNthElementAndCast[string](darray, 4, 3, 0)
 	entry.Thumbnail = getNthElementAndCast[string](darray, 72, 0, 1, 6, 0)
 	entry.Timezone = getNthElementAndCast[string](darray, 30)
 	entry.PriceRange = getNthElementAndCast[string](darray, 4, 2)
 	entry.DataID = getNthElementAndCast[string](darray, 10)
 
-	items := getLinkSource(getLinkSourceParams{
-		arr:    getNthElementAndCast[[]any](darray, 171, 0),
-		link:   []int{3, 0, 6, 0},
-		source: []int{2},
-	})
+	if entry.Cid == "" {
+		entry.Cid = cidFromDataID(entry.DataID)
+	}
 
-	entry.Images = make([]Image, len(items))
+	setMapLinks(&entry)
 
-	for i := range items {
-		entry.Images[i] = Image{
-			Title: items[i].Source,
-			Image: items[i].Link,
-		}
+	entry.Images = getImages(darray)
+	entry.PhotoCount, entry.VideoCount = getMediaCounts(darray)
+
+	if isLodging(entry.Categories) {
+		setHotel(&entry, darray)
 	}
 
 	entry.Reservations = getLinkSource(getLinkSourceParams{
@@ -367,6 +566,11 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 		Source: getNthElementAndCast[string](darray, 38, 1),
 	}
 
+	// the first link of each action, for datasets that only need one URL
+	entry.MenuURL = entry.Menu.Link
+	entry.OrderURL = firstLink(entry.OrderOnline)
+	entry.ReservationURL = firstLink(entry.Reservations)
+
 	entry.Owner = Owner{
 		ID:   getNthElementAndCast[string](darray, 57, 2),
 		Name: getNthElementAndCast[string](darray, 57, 1),
@@ -376,46 +580,34 @@ func EntryFromJSON(raw []byte, reviewCountOnly ...bool) (entry Entry, err error)
 		entry.Owner.Link = fmt.Sprintf("https://www.google.com/maps/contrib/%s", entry.Owner.ID)
 	}
 
-	entry.CompleteAddress = Address{
-		Borough:    getNthElementAndCast[string](darray, 183, 1, 0),
-		Street:     getNthElementAndCast[string](darray, 183, 1, 1),
-		City:       getNthElementAndCast[string](darray, 183, 1, 3),
-		PostalCode: getNthElementAndCast[string](darray, 183, 1, 4),
-		State:      getNthElementAndCast[string](darray, 183, 1, 5),
-		Country:    getNthElementAndCast[string](darray, 183, 1, 6),
-	}
+	entry.Claimed = isClaimed(darray)
 
-	aboutI := getNthElementAndCast[[]any](darray, 100, 1)
+	entry.CompleteAddress = getAddress(darray)
 
-	for i := range aboutI {
-		el := getNthElementAndCast[[]any](aboutI, i)
-		about := About{
-			ID:   getNthElementAndCast[string](el, 0),
-			Name: getNthElementAndCast[string](el, 1),
-		}
+	entry.Country = getNthElementAndCast[string](darray, 243)
+	if entry.Country == "" {
+		entry.Country = entry.CompleteAddress.Country
+	}
 
-		optsI := getNthElementAndCast[[]any](el, 2)
+	entry.TypicalSpend = parseTypicalSpend(entry.PriceRange, entry.Country)
 
-		for j := range optsI {
-			opt := Option{
-				Enabled: (getNthElementAndCast[float64](optsI, j, 2, 1, 0, 0)) == 1,
-				Name:    getNthElementAndCast[string](optsI, j, 1),
-			}
+	entry.UTCOffset = utcOffset(entry.Timezone, time.Now())
 
-			if opt.Name != "" {
-				about.Options = append(about.Options, opt)
-			}
-		}
+	entry.About = getAbout(darray)
+	setWheelchairAccessibility(&entry, darray)
+
+	entry.RelatedPlaceNames, entry.RelatedPlaces = getRelatedPlaces(darray)
 
-		entry.About = append(entry.About, about)
+	for i := range entry.RatingDistribution {
+		entry.RatingDistribution[i] = int(getNthElementAndCast[float64](darray, 175, 3, i))
 	}
 
 	entry.ReviewsPerRating = map[int]int{
-		1: int(getNthElementAndCast[float64](darray, 175, 3, 0)),
-		2: int(getNthElementAndCast[float64](darray, 175, 3, 1)),
-		3: int(getNthElementAndCast[float64](darray, 175, 3, 2)),
-		4: int(getNthElementAndCast[float64](darray, 175, 3, 3)),
-		5: int(getNthElementAndCast[float64](darray, 175, 3, 4)),
+		1: entry.RatingDistribution[0],
+		2: entry.RatingDistribution[1],
+		3: entry.RatingDistribution[2],
+		4: entry.RatingDistribution[3],
+		5: entry.RatingDistribution[4],
 	}
 
 	reviewsI := getNthElementAndCast[[]any](darray, 175, 9, 0, 0)
@@ -447,14 +639,18 @@ func parseReviews(reviewsI []any) []Review {
 
 				return fmt.Sprintf("%v-%v-%v", time[0], time[1], time[2])
 			}(),
-			Rating:      int(getNthElementAndCast[float64](el, 2, 0, 0)),
-			Description: getNthElementAndCast[string](el, 2, 15, 0, 0),
+			Rating:             int(getNthElementAndCast[float64](el, 2, 0, 0)),
+			Description:        getNthElementAndCast[string](el, 2, 15, 0, 0),
+			AuthorReviewCount:  int(getNthElementAndCast[float64](el, 1, 4, 5, 5)),
+			AuthorIsLocalGuide: getNthElementAndCast[float64](el, 1, 4, 5, 8, 0) == 1,
 		}
 
 		if review.Name == "" {
 			continue
 		}
 
+		review.OwnerResponse, review.OwnerResponseWhen = getOwnerResponse(el)
+
 		optsI := getNthElementAndCast[[]any](el, 2, 2, 0, 1, 21, 7)
 
 		for j := range optsI {
@@ -470,6 +666,25 @@ func parseReviews(reviewsI []any) []Review {
 	return ans
 }
 
+// getOwnerResponse returns the reply of the owner to the review el and its
+// date, formatted like the one of the review. The reply follows the review
+// with the same layout: its text, then its timestamp in microseconds.
+//
+//nolint:gomnd // it's ok, I need the indexes
+func getOwnerResponse(el []any) (text, when string) {
+	text = getNthElementAndCast[string](el, 3, 14, 0, 0)
+	if text == "" {
+		return "", ""
+	}
+
+	if usec := getNthElementAndCast[float64](el, 3, 1); usec > 0 {
+		t := time.UnixMicro(int64(usec)).UTC()
+		when = fmt.Sprintf("%d-%d-%d", t.Year(), t.Month(), t.Day())
+	}
+
+	return text, when
+}
+
 type getLinkSourceParams struct {
 	arr    []any
 	source []int
@@ -494,7 +709,332 @@ func getLinkSource(params getLinkSourceParams) []LinkSource {
 	return result
 }
 
+// firstLink returns the link of the first source, or an empty string when
+// there is none.
+func firstLink(sources []LinkSource) string {
+	if len(sources) == 0 {
+		return ""
+	}
+
+	return sources[0].Link
+}
+
+// Values of Entry.DescriptionSource.
+const (
+	DescriptionSourceOwner  = "owner"
+	DescriptionSourceGoogle = "google"
+)
+
+// isClaimed reports whether the business claimed the place, nil when the
+// JSON does not tell. The JSON has no flag for it, so it is a guess: Google
+// shows the "Claim this business" prompt, with a link to Google Business
+// Profile (darray[49] or darray[226]), on the places nobody claimed, and
+// the profile of the owner (darray[57]) on the places that are claimed.
+// Places with neither are unknown rather than taken as claimed.
+//
+//nolint:gomnd // it's ok, I need the indexes
+func isClaimed(darray []any) *bool {
+	claimed := false
+
+	for _, i := range []int{49, 226} {
+		if strings.HasPrefix(getNthElementAndCast[string](darray, i, 0), "https://business.google.com/") {
+			return &claimed
+		}
+	}
+
+	if getNthElementAndCast[string](darray, 57, 2) == "" {
+		return nil
+	}
+
+	claimed = true
+
+	return &claimed
+}
+
+// getDescription returns the description of the place and where it comes
+// from. The "From the business" text written by the owner is preferred to
+// the summary generated by Google. Both are empty for places without one.
+//
 //nolint:gomnd // it's ok, I need the indexes
+func getDescription(darray []any) (description, source string) {
+	if owner := joinParagraphs(getNthElementAndCast[[]any](darray, 154, 0)); owner != "" {
+		return owner, DescriptionSourceOwner
+	}
+
+	if google := cleanParagraphs(getNthElementAndCast[string](darray, 32, 1, 1)); google != "" {
+		return google, DescriptionSourceGoogle
+	}
+
+	return "", ""
+}
+
+// joinParagraphs joins the text paragraphs of items with a blank line.
+func joinParagraphs(items []any) string {
+	paragraphs := make([]string, 0, len(items))
+
+	for _, item := range items {
+		if text, ok := item.(string); ok {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+
+	return cleanParagraphs(strings.Join(paragraphs, "\n\n"))
+}
+
+// cleanParagraphs trims the paragraphs of text and separates them with a
+// single blank line. Line breaks within a paragraph are kept.
+func cleanParagraphs(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var paragraphs []string
+
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// Values of Entry.BusinessStatus.
+const (
+	BusinessStatusOperational       = "operational"
+	BusinessStatusTemporarilyClosed = "temporarily_closed"
+	BusinessStatusPermanentlyClosed = "permanently_closed"
+)
+
+// Values of the closed flag of the place (darray[88][0]).
+const (
+	closedTemporarily = 1
+	closedPermanently = 2
+)
+
+// businessStatus returns the business status of the place from its closed
+// flag (darray[88][0]), which open places do not have. The status line
+// (darray[34][4][4]) says the same, but in the language of the page.
+func businessStatus(darray []any) string {
+	switch int(getNthElementAndCast[float64](darray, 88, 0)) {
+	case closedPermanently:
+		return BusinessStatusPermanentlyClosed
+	case closedTemporarily:
+		return BusinessStatusTemporarilyClosed
+	default:
+		return BusinessStatusOperational
+	}
+}
+
+// IsClosed reports whether the business is permanently or temporarily closed.
+func (e *Entry) IsClosed() bool {
+	return e.BusinessStatus == BusinessStatusPermanentlyClosed || e.BusinessStatus == BusinessStatusTemporarilyClosed
+}
+
+//nolint:gomnd // it's ok, I need the indexes
+func getImages(darray []any) []Image {
+	items := getLinkSource(getLinkSourceParams{
+		arr:    getNthElementAndCast[[]any](darray, 171, 0),
+		link:   []int{3, 0, 6, 0},
+		source: []int{2},
+	})
+
+	images := make([]Image, len(items))
+
+	for i := range items {
+		images[i] = Image{
+			Title: items[i].Source,
+			Image: items[i].Link,
+		}
+	}
+
+	return images
+}
+
+// mediaKindVideo is the kind of the media items that are videos. Photos
+// are 12 and Street View panoramas 11.
+const mediaKindVideo = 10
+
+// getMediaCounts returns the number of photos of the place and the number
+// of videos in its data. Google publishes the total of the photos, shown
+// as "411+ Photos" on the page, but not the one of the videos: only the
+// few videos of the media previews are counted, so the video count is a
+// lower bound.
+//
+//nolint:gomnd // it's ok, I need the indexes
+func getMediaCounts(darray []any) (photos, videos int) {
+	photos = int(getNthElementAndCast[float64](darray, 37, 1))
+
+	seen := map[string]bool{}
+
+	count := func(items []any) {
+		for i := range items {
+			id := getNthElementAndCast[string](items, i, 0)
+			kind := int(getNthElementAndCast[float64](items, i, 2))
+
+			if id != "" && kind == mediaKindVideo && !seen[id] {
+				seen[id] = true
+				videos++
+			}
+		}
+	}
+
+	count(getNthElementAndCast[[]any](darray, 37, 0))
+
+	categories := getNthElementAndCast[[]any](darray, 171, 0)
+
+	for i := range categories {
+		count(getNthElementAndCast[[]any](categories, i, 3))
+	}
+
+	return photos, videos
+}
+
+// lodgingWords are the words of the categories of lodging places, e.g.
+// "Resort hotel" or "Inn".
+var lodgingWords = map[string]bool{
+	"hotel":      true,
+	"motel":      true,
+	"hostel":     true,
+	"inn":        true,
+	"resort":     true,
+	"lodging":    true,
+	"guesthouse": true,
+	"aparthotel": true,
+}
+
+// isLodging reports whether one of the categories is a lodging one, so the
+// hotel data of the place is parsed.
+func isLodging(categories []string) bool {
+	for _, category := range categories {
+		category = strings.ToLower(category)
+
+		if strings.Contains(category, "bed & breakfast") || strings.Contains(category, "bed and breakfast") {
+			return true
+		}
+
+		for _, word := range strings.FieldsFunc(category, func(r rune) bool {
+			return !unicode.IsLetter(r)
+		}) {
+			if lodgingWords[word] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// setHotel sets the hotel fields of the entry from the hotel data of the
+// place: its class label, e.g. "4-star hotel", the check-in and check-out
+// times and the amenities, each with a flag telling whether the hotel has
+// it.
+//
+//nolint:gomnd // it's ok, I need the indexes
+func setHotel(entry *Entry, darray []any) {
+	class := getNthElementAndCast[string](darray, 64, 3)
+	if stars, _, ok := strings.Cut(class, "-star"); ok {
+		entry.HotelClass, _ = strconv.Atoi(strings.TrimSpace(stars))
+	}
+
+	entry.CheckIn = getNthElementAndCast[string](darray, 64, 7, 0)
+	entry.CheckOut = getNthElementAndCast[string](darray, 64, 7, 1)
+
+	amenities := getNthElementAndCast[[]any](darray, 64, 10)
+
+	for i := range amenities {
+		name := getNthElementAndCast[string](amenities, i, 1)
+
+		if name != "" && getNthElementAndCast[float64](amenities, i, 2) == 1 {
+			entry.HotelAmenities = append(entry.HotelAmenities, name)
+		}
+	}
+}
+
+// getAddress returns the components of the address of the place. The
+// components are positional, so the ones the country does not use are
+// null and end up empty.
+//
+//nolint:gomnd // it's ok, I need the indexes
+func getAddress(darray []any) Address {
+	component := func(i int) string {
+		return strings.TrimSpace(getNthElementAndCast[string](darray, 183, 1, i))
+	}
+
+	ans := Address{
+		Borough:    component(0),
+		Street:     component(1),
+		City:       component(3),
+		PostalCode: component(4),
+		State:      component(5),
+		Country:    component(6),
+	}
+
+	if ans.Country == "" && ans != (Address{}) {
+		ans.Country = getNthElementAndCast[string](darray, 243)
+	}
+
+	return ans
+}
+
+func getAbout(darray []any) []About {
+	var ans []About
+
+	aboutI := getNthElementAndCast[[]any](darray, 100, 1)
+
+	for i := range aboutI {
+		el := getNthElementAndCast[[]any](aboutI, i)
+		about := About{
+			ID:   getNthElementAndCast[string](el, 0),
+			Name: getNthElementAndCast[string](el, 1),
+		}
+
+		optsI := getNthElementAndCast[[]any](el, 2)
+
+		for j := range optsI {
+			opt := Option{
+				Enabled: (getNthElementAndCast[float64](optsI, j, 2, 1, 0, 0)) == 1,
+				Name:    getNthElementAndCast[string](optsI, j, 1),
+			}
+
+			if opt.Name != "" {
+				about.Options = append(about.Options, opt)
+			}
+		}
+
+		ans = append(ans, about)
+	}
+
+	return ans
+}
+
+// setWheelchairAccessibility sets the wheelchair accessibility fields of
+// entry from the attributes of the about section, which are identified by
+// their ID rather than their name, as the name is translated.
+//
+//nolint:gomnd // it's ok, I need the indexes
+func setWheelchairAccessibility(entry *Entry, darray []any) {
+	fields := map[string]**bool{
+		"/geo/type/establishment_poi/has_wheelchair_accessible_entrance": &entry.WheelchairAccessibleEntrance,
+		"/geo/type/establishment_poi/has_wheelchair_accessible_parking":  &entry.WheelchairAccessibleParking,
+		"/geo/type/establishment_poi/has_wheelchair_accessible_restroom": &entry.WheelchairAccessibleRestroom,
+	}
+
+	aboutI := getNthElementAndCast[[]any](darray, 100, 1)
+
+	for i := range aboutI {
+		optsI := getNthElementAndCast[[]any](aboutI, i, 2)
+
+		for j := range optsI {
+			field, ok := fields[getNthElementAndCast[string](optsI, j, 0)]
+			if !ok {
+				continue
+			}
+
+			enabled := getNthElementAndCast[float64](optsI, j, 2, 1, 0, 0) == 1
+			*field = &enabled
+		}
+	}
+}
+
 func getHours(darray []any) map[string][]string {
 	items := getNthElementAndCast[[]any](darray, 34, 1)
 	hours := make(map[string][]string, len(items))
@@ -580,7 +1120,7 @@ func getNthElementAndCast[T any](arr []any, indexes ...int) T {
 	for len(indexes) > 1 {
 		idx, indexes = indexes[0], indexes[1:]
 
-		if idx >= len(arr) {
+		if idx < 0 || idx >= len(arr) {
 			return defaultVal
 		}
 
@@ -598,7 +1138,7 @@ func getNthElementAndCast[T any](arr []any, indexes ...int) T {
 		}
 	}
 
-	if len(indexes) == 0 || len(arr) == 0 {
+	if indexes[0] < 0 || indexes[0] >= len(arr) {
 		return defaultVal
 	}
 
@@ -614,6 +1154,15 @@ func stringSliceToString(s []string) string {
 	return strings.Join(s, ", ")
 }
 
+// optionalBool formats b for CSV, empty when it is unknown.
+func optionalBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+
+	return strconv.FormatBool(*b)
+}
+
 func stringify(v any) string {
 	switch val := v.(type) {
 	case string:
@@ -639,6 +1188,21 @@ func decodeURL(url string) (string, error) {
 	return unquoted, nil
 }
 
+// utcOffset returns the offset of the IANA timezone tz at t, formatted
+// like +02:00. It returns an empty string when tz is empty or unknown.
+func utcOffset(tz string, t time.Time) string {
+	if tz == "" {
+		return ""
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return ""
+	}
+
+	return t.In(loc).Format("-07:00")
+}
+
 type EntryWithDistance struct {
 	Entry    *Entry
 	Distance float64
@@ -1,9 +1,14 @@
 package gmaps_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/stretchr/testify/require"
@@ -26,6 +31,8 @@ func createGoQueryFromFile(t *testing.T, path string) *goquery.Document {
 }
 
 func Test_EntryFromJSON(t *testing.T) {
+	claimed := true
+
 	expected := gmaps.Entry{
 		Link:       "https://www.google.com/maps/place/Kipriakon/data=!4m2!3m1!1s0x14e732fd76f0d90d:0xe5415928d6702b47!10m1!1e1",
 		Title:      "Kipriakon",
@@ -41,20 +48,22 @@ func Test_EntryFromJSON(t *testing.T) {
 			"Saturday":  {"12:30–10 pm"},
 			"Sunday":    {"12:30–10 pm"},
 		},
-		WebSite:      "",
-		Phone:        "25 101555",
-		PlusCode:     "M2CR+6X Limassol",
-		ReviewCount:  396,
-		ReviewRating: 4.2,
-		Latitude:     34.670595399999996,
-		Longtitude:   33.042456699999995,
-		Cid:          "16519582940102929223",
-		Status:       "Closed ⋅ Opens 12:30\u202fpm Tue",
-		ReviewsLink:  "https://search.google.com/local/reviews?placeid=ChIJDdnwdv0y5xQRRytw1ihZQeU&q=Kipriakon&authuser=0&hl=en&gl=CY",
-		Thumbnail:    "https://lh5.googleusercontent.com/p/AF1QipP4Y7A8nYL3KKXznSl69pXSq9p2IXCYUjVvOh0F=w408-h408-k-no",
-		Timezone:     "Asia/Nicosia",
-		PriceRange:   "€€",
-		DataID:       "0x14e732fd76f0d90d:0xe5415928d6702b47",
+		WebSite:        "",
+		Phone:          "25 101555",
+		PlusCode:       "M2CR+6X Limassol",
+		ReviewCount:    396,
+		ReviewRating:   4.2,
+		Latitude:       34.670595399999996,
+		Longtitude:     33.042456699999995,
+		Cid:            "16519582940102929223",
+		Status:         "Closed ⋅ Opens 12:30\u202fpm Tue",
+		BusinessStatus: gmaps.BusinessStatusOperational,
+		ReviewsLink:    "https://search.google.com/local/reviews?placeid=ChIJDdnwdv0y5xQRRytw1ihZQeU&q=Kipriakon&authuser=0&hl=en&gl=CY",
+		Thumbnail:      "https://lh5.googleusercontent.com/p/AF1QipP4Y7A8nYL3KKXznSl69pXSq9p2IXCYUjVvOh0F=w408-h408-k-no",
+		Timezone:       "Asia/Nicosia",
+		Country:        "CY",
+		PriceRange:     "€€",
+		DataID:         "0x14e732fd76f0d90d:0xe5415928d6702b47",
 		Images: []gmaps.Image{
 			{
 				Title: "All",
@@ -97,6 +106,8 @@ func Test_EntryFromJSON(t *testing.T) {
 				Image: "https://lh5.googleusercontent.com/p/AF1QipMwkHP8GmDCSuwnWS7pYVQvtDWdsdk-CUwxtsXL=w224-h298-k-no-pi-23.425545-ya289.20517-ro-8.658787-fo100",
 			},
 		},
+		PhotoCount: 411,
+		VideoCount: 1,
 		OrderOnline: []gmaps.LinkSource{
 			{
 				Link:   "https://foody.com.cy/delivery/lemesos/to-kypriakon?utm_source=google&utm_medium=organic&utm_campaign=google_reserve_place_order_action",
@@ -107,11 +118,13 @@ func Test_EntryFromJSON(t *testing.T) {
 				Source: "wolt.com",
 			},
 		},
+		OrderURL: "https://foody.com.cy/delivery/lemesos/to-kypriakon?utm_source=google&utm_medium=organic&utm_campaign=google_reserve_place_order_action",
 		Owner: gmaps.Owner{
 			ID:   "102769814432182832009",
 			Name: "Kipriakon (Owner)",
 			Link: "https://www.google.com/maps/contrib/102769814432182832009",
 		},
+		Claimed: &claimed,
 		CompleteAddress: gmaps.Address{
 			Borough:    "",
 			Street:     "Old port",
@@ -127,6 +140,10 @@ func Test_EntryFromJSON(t *testing.T) {
 			4: 60,
 			5: 256,
 		},
+		RatingDistribution: [5]int{37, 16, 27, 60, 256},
+		ShareURL:           "https://maps.google.com/?cid=16519582940102929223",
+		CIDURL:             "https://maps.google.com/?cid=16519582940102929223",
+		DirectionsURL:      "https://www.google.com/maps/dir/?api=1&destination=34.670595399999996,33.042456699999995",
 	}
 
 	raw, err := os.ReadFile("../testdata/raw.json")
@@ -169,8 +186,26 @@ func Test_EntryFromJSON(t *testing.T) {
 	monday := entry.PopularTimes["Monday"]
 	require.Equal(t, 100, monday[20])
 
+	// the offset depends on the current date because of daylight saving
+	require.Contains(t, []string{"+02:00", "+03:00"}, entry.UTCOffset)
+
+	// the page has the details of the first 5 of its 20 related places only
+	require.Len(t, entry.RelatedPlaces, 5)
+	require.Len(t, entry.RelatedPlaceNames, 5)
+	require.Equal(t, "Aktéon", entry.RelatedPlaceNames[0])
+	require.Equal(t, "https://www.google.com/maps/place/Akt%C3%A9on/data=!4m2!3m1!1s0x0:0x5278272a6a8cc765", entry.RelatedPlaces[0])
+
+	// the page shows the wheelchair-accessible entrance only
+	require.NotNil(t, entry.WheelchairAccessibleEntrance)
+	require.True(t, *entry.WheelchairAccessibleEntrance)
+	require.Nil(t, entry.WheelchairAccessibleParking)
+
 	entry.PopularTimes = nil
 	entry.UserReviews = nil
+	entry.UTCOffset = ""
+	entry.RelatedPlaces = nil
+	entry.RelatedPlaceNames = nil
+	entry.WheelchairAccessibleEntrance = nil
 
 	require.Equal(t, expected, entry)
 }
@@ -216,3 +251,870 @@ func Test_EntryFromJsonC(t *testing.T) {
 		fmt.Printf("%+v\n", entry)
 	}
 }
+
+func Test_ParseSearchResultsFastMode(t *testing.T) {
+	raw, err := os.ReadFile("../testdata/output.json")
+	require.NoError(t, err)
+
+	entries, err := gmaps.ParseSearchResults(raw)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	for _, entry := range entries {
+		require.NotEmpty(t, entry.Title)
+		require.NotEmpty(t, entry.Category, entry.Title)
+		require.NotEmpty(t, entry.Categories, entry.Title)
+		require.NotEmpty(t, entry.Address, entry.Title)
+		require.Greater(t, entry.ReviewRating, 0.0, entry.Title)
+		require.Greater(t, entry.ReviewCount, 0, entry.Title)
+
+		require.Empty(t, entry.OpenHours, entry.Title)
+		require.Empty(t, entry.About, entry.Title)
+		require.Empty(t, entry.Images, entry.Title)
+	}
+
+	detailed, err := gmaps.ParseSearchResults(raw, true)
+	require.NoError(t, err)
+	require.Len(t, detailed, len(entries))
+
+	var withHours, withAbout int
+
+	for _, entry := range detailed {
+		if len(entry.OpenHours) > 0 {
+			withHours++
+		}
+
+		if len(entry.About) > 0 {
+			withAbout++
+		}
+	}
+
+	require.Positive(t, withHours)
+	require.Positive(t, withAbout)
+}
+
+func loadPlaceFixture(t *testing.T, name string) []byte {
+	t.Helper()
+
+	raw, err := os.ReadFile("../testdata/places/" + name + ".json")
+	require.NoError(t, err)
+	require.NotEmpty(t, raw)
+
+	return raw
+}
+
+func Test_EntryFromJSONFixtures(t *testing.T) {
+	tests := []struct {
+		fixture      string
+		title        string
+		category     string
+		categories   []string
+		reviewRating float64
+		reviewCount  int
+		latitude     float64
+		longitude    float64
+		cid          string
+		dataID       string
+		timezone     string
+		country      string
+	}{
+		{
+			fixture:      "restaurant",
+			title:        "Kipriakon",
+			category:     "Restaurant",
+			categories:   []string{"Restaurant"},
+			reviewRating: 4.2,
+			reviewCount:  396,
+			latitude:     34.670595399999996,
+			longitude:    33.042456699999995,
+			cid:          "16519582940102929223",
+			dataID:       "0x14e732fd76f0d90d:0xe5415928d6702b47",
+			timezone:     "Asia/Nicosia",
+			country:      "CY",
+		},
+		{
+			fixture:      "hotel",
+			title:        "Seaview Hotel",
+			category:     "Hotel",
+			categories:   []string{"Hotel", "Resort hotel"},
+			reviewRating: 4.5,
+			reviewCount:  1234,
+			latitude:     35.1689,
+			longitude:    33.3614,
+			cid:          "1229782938247303441",
+			dataID:       "0x14de1767ca494d55:0x1111111111111111",
+			timezone:     "Asia/Nicosia",
+			country:      "CY",
+		},
+		{
+			fixture:      "doctor",
+			title:        "Dr. Jane Example, MD",
+			category:     "Doctor",
+			categories:   []string{"Doctor", "Family practice physician"},
+			reviewRating: 4.8,
+			reviewCount:  57,
+			latitude:     40.7411,
+			longitude:    -73.9897,
+			cid:          "2459565876494606882", // from the data ID
+			dataID:       "0x89c259a3f81d3b0d:0x2222222222222222",
+			timezone:     "America/New_York",
+			country:      "US",
+		},
+		{
+			fixture:      "pub",
+			title:        "The Red Lion",
+			category:     "Pub",
+			categories:   []string{"Pub", "Bar"},
+			reviewRating: 4.4,
+			reviewCount:  2048,
+			latitude:     51.5027,
+			longitude:    -0.1262,
+			cid:          "4919131752989213764",
+			dataID:       "0x487604c5a1b50c85:0x4444444444444444",
+			timezone:     "Europe/London",
+			country:      "GB",
+		},
+		{
+			fixture:      "bakery",
+			title:        "Shibuya Bakery",
+			category:     "Bakery",
+			categories:   []string{"Bakery"},
+			reviewRating: 4.3,
+			reviewCount:  310,
+			latitude:     35.6595,
+			longitude:    139.7005,
+			cid:          "3689348814741910323", // from the data ID
+			dataID:       "0x60188b563b00109f:0x3333333333333333",
+			timezone:     "Asia/Tokyo",
+			country:      "JP",
+		},
+		{
+			fixture:    "pharmacy",
+			title:      "Farmácia Paulista",
+			category:   "Pharmacy",
+			categories: []string{"Pharmacy"},
+			latitude:   -23.5614,
+			longitude:  -46.6559,
+			timezone:   "America/Sao_Paulo",
+			country:    "BR",
+		},
+		{
+			fixture:    "minimal",
+			title:      "Unnamed Kiosk",
+			category:   "Kiosk",
+			categories: []string{"Kiosk"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.fixture, func(t *testing.T) {
+			entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, tc.fixture))
+			require.NoError(t, err)
+
+			require.Equal(t, tc.title, entry.Title)
+			require.Equal(t, tc.category, entry.Category)
+			require.Equal(t, tc.categories, entry.Categories)
+			require.InDelta(t, tc.reviewRating, entry.ReviewRating, 0.0001)
+			require.Equal(t, tc.reviewCount, entry.ReviewCount)
+			require.InDelta(t, tc.latitude, entry.Latitude, 0.000001)
+			require.InDelta(t, tc.longitude, entry.Longtitude, 0.000001)
+			require.Equal(t, tc.cid, entry.Cid)
+			require.Equal(t, tc.dataID, entry.DataID)
+			require.Equal(t, tc.timezone, entry.Timezone)
+			require.Equal(t, tc.country, entry.Country)
+			require.NoError(t, entry.Validate())
+		})
+	}
+}
+
+func Test_EntryFromJSONFixturesDetails(t *testing.T) {
+	entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, "doctor"))
+	require.NoError(t, err)
+
+	require.Equal(t, "100 Example St, New York, NY 10010", entry.Address)
+	require.Equal(t, []string{"9 am–5 pm"}, entry.OpenHours["Monday"])
+	require.Equal(t, []string{"Closed"}, entry.OpenHours["Sunday"])
+	require.Equal(t, "(212) 555-0100", entry.Phone)
+	require.Equal(t, "PXRJ+F4 New York", entry.PlusCode)
+	require.Equal(t, "US", entry.CompleteAddress.Country)
+	require.Equal(t, map[int]int{1: 1, 2: 0, 3: 1, 4: 5, 5: 50}, entry.ReviewsPerRating)
+
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "minimal"))
+	require.NoError(t, err)
+
+	require.Empty(t, entry.Address)
+	require.Empty(t, entry.WebSite)
+	require.Empty(t, entry.Phone)
+	require.Empty(t, entry.OpenHours)
+	require.Empty(t, entry.Images)
+}
+
+func Test_EntryFromJSONActionLinks(t *testing.T) {
+	entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, "trattoria"))
+	require.NoError(t, err)
+
+	require.Equal(t, "Trattoria da Mario", entry.Title)
+	require.Equal(t, "https://www.trattoriadamario.example/menu", entry.MenuURL)
+	require.Equal(t, "https://deliveroo.it/menu/roma/centro-storico/trattoria-da-mario", entry.OrderURL)
+	require.Equal(t, "https://www.thefork.it/ristorante/trattoria-da-mario-r12345", entry.ReservationURL)
+	require.Len(t, entry.OrderOnline, 2)
+	require.Len(t, entry.Reservations, 2)
+
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "hardware_store"))
+	require.NoError(t, err)
+
+	require.Equal(t, "Ferramenta Rossi", entry.Title)
+	require.Empty(t, entry.MenuURL)
+	require.Empty(t, entry.OrderURL)
+	require.Empty(t, entry.ReservationURL)
+	require.NoError(t, entry.Validate())
+}
+
+func Test_EntryFromJSONDescription(t *testing.T) {
+	tests := []struct {
+		fixture     string
+		description string
+		source      string
+	}{
+		{
+			fixture: "bookshop",
+			description: "Family-run bookshop since 1962, specialised in travel writing and old maps.\n\n" +
+				"We also buy collections and ship worldwide.\nOpen for readings on Thursday evenings.",
+			source: gmaps.DescriptionSourceOwner,
+		},
+		{
+			fixture:     "museum",
+			description: "Museum of papermaking in a former convent, with live demonstrations of making paper by hand.",
+			source:      gmaps.DescriptionSourceGoogle,
+		},
+		{fixture: "hardware_store"},
+		{fixture: "minimal"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.fixture, func(t *testing.T) {
+			entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, tc.fixture))
+			require.NoError(t, err)
+
+			require.Equal(t, tc.description, entry.Description)
+			require.Equal(t, tc.source, entry.DescriptionSource)
+		})
+	}
+}
+
+func Test_EntryFromJSONRatingDistribution(t *testing.T) {
+	tests := []struct {
+		fixture      string
+		distribution [5]int
+		matches      bool
+	}{
+		{fixture: "car_dealer", distribution: [5]int{210, 15, 10, 20, 245}, matches: true},
+		{fixture: "hotel", distribution: [5]int{40, 25, 99, 300, 770}, matches: true},
+		{fixture: "trattoria", distribution: [5]int{12, 8, 30, 150, 612}, matches: true},
+		// the review count moved on since the histogram was computed
+		{fixture: "pub", distribution: [5]int{40, 25, 99, 300, 770}, matches: false},
+		{fixture: "minimal", matches: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.fixture, func(t *testing.T) {
+			entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, tc.fixture))
+			require.NoError(t, err)
+
+			require.Equal(t, tc.distribution, entry.RatingDistribution)
+			require.Equal(t, tc.matches, entry.RatingDistributionMatches())
+
+			for stars := 1; stars <= 5; stars++ {
+				require.Equal(t, tc.distribution[stars-1], entry.ReviewsPerRating[stars])
+			}
+
+			headers := entry.CsvHeaders()
+			row := entry.CsvRow()
+			require.Len(t, row, len(headers))
+
+			col := slices.Index(headers, "rating_1")
+			require.Equal(t, []string{"rating_1", "rating_2", "rating_3", "rating_4", "rating_5"}, headers[col:col+5])
+			require.Equal(t, strconv.Itoa(tc.distribution[0]), row[col])
+			require.Equal(t, strconv.Itoa(tc.distribution[4]), row[col+4])
+		})
+	}
+}
+
+func Test_EntryFromJSONMapLinks(t *testing.T) {
+	entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, "trattoria"))
+	require.NoError(t, err)
+
+	require.Equal(t, "4919131752989213764", entry.Cid)
+	require.Equal(t, "https://maps.google.com/?cid=4919131752989213764", entry.ShareURL)
+	require.Equal(t, "https://maps.google.com/?cid=4919131752989213764", entry.CIDURL)
+	require.Equal(t, "https://www.google.com/maps/dir/?api=1&destination=41.8986,12.4769", entry.DirectionsURL)
+
+	// no CID nor coordinates
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "minimal"))
+	require.NoError(t, err)
+
+	require.Empty(t, entry.Cid)
+	require.Empty(t, entry.CIDURL)
+	require.Empty(t, entry.ShareURL)
+	require.Empty(t, entry.DirectionsURL)
+}
+
+func Test_EntryFromJSONClaimed(t *testing.T) {
+	headers := (&gmaps.Entry{}).CsvHeaders()
+	idx := slices.Index(headers, "claimed")
+	require.NotEqual(t, -1, idx)
+
+	entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, "claimed"))
+	require.NoError(t, err)
+
+	require.NotNil(t, entry.Claimed)
+	require.True(t, *entry.Claimed)
+	require.Equal(t, "true", entry.CsvRow()[idx])
+
+	// the JSON has the "Claim this business" link
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "unclaimed"))
+	require.NoError(t, err)
+
+	require.NotNil(t, entry.Claimed)
+	require.False(t, *entry.Claimed)
+	require.Equal(t, "false", entry.CsvRow()[idx])
+
+	// neither the link nor the owner
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "minimal"))
+	require.NoError(t, err)
+
+	require.Nil(t, entry.Claimed)
+	require.Empty(t, entry.CsvRow()[idx])
+}
+
+func Test_EntryFromJSONMediaCounts(t *testing.T) {
+	headers := (&gmaps.Entry{}).CsvHeaders()
+	photosIdx := slices.Index(headers, "photo_count")
+	videosIdx := slices.Index(headers, "video_count")
+
+	require.NotEqual(t, -1, photosIdx)
+	require.NotEqual(t, -1, videosIdx)
+
+	tests := []struct {
+		name   string
+		photos int
+		videos int
+	}{
+		// the video of the Videos tab is repeated in the Latest one
+		{name: "media_rich", photos: 1284, videos: 2},
+		{name: "media_less", photos: 0, videos: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, tc.name))
+			require.NoError(t, err)
+
+			require.Equal(t, tc.photos, entry.PhotoCount)
+			require.Equal(t, tc.videos, entry.VideoCount)
+
+			row := entry.CsvRow()
+			require.Equal(t, strconv.Itoa(tc.photos), row[photosIdx])
+			require.Equal(t, strconv.Itoa(tc.videos), row[videosIdx])
+		})
+	}
+}
+
+func Test_EntryFromJSONHotel(t *testing.T) {
+	headers := (&gmaps.Entry{}).CsvHeaders()
+	classIdx := slices.Index(headers, "hotel_class")
+	amenitiesIdx := slices.Index(headers, "hotel_amenities")
+
+	require.NotEqual(t, -1, classIdx)
+	require.NotEqual(t, -1, amenitiesIdx)
+
+	entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, "hotel"))
+	require.NoError(t, err)
+
+	require.Equal(t, 4, entry.HotelClass)
+	require.Equal(t, "3:00 PM", entry.CheckIn)
+	require.Equal(t, "11:00 AM", entry.CheckOut)
+	// the amenities the hotel does not have are left out
+	require.Equal(t, []string{"Free Wi-Fi", "Outdoor pool", "Free parking", "Restaurant"}, entry.HotelAmenities)
+
+	row := entry.CsvRow()
+	require.Equal(t, "4", row[classIdx])
+	require.Equal(t, "Free Wi-Fi, Outdoor pool, Free parking, Restaurant", row[amenitiesIdx])
+
+	// a bar with the same hotel data, e.g. the bar of a hotel, is not a lodging
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "rooftop_bar"))
+	require.NoError(t, err)
+
+	require.Equal(t, "Skyline Rooftop Bar", entry.Title)
+	require.Zero(t, entry.HotelClass)
+	require.Empty(t, entry.CheckIn)
+	require.Empty(t, entry.CheckOut)
+	require.Empty(t, entry.HotelAmenities)
+	require.Equal(t, "0", entry.CsvRow()[classIdx])
+}
+
+func Test_EntryFromJSONRelatedPlaces(t *testing.T) {
+	headers := (&gmaps.Entry{}).CsvHeaders()
+	placesIdx := slices.Index(headers, "related_places")
+	namesIdx := slices.Index(headers, "related_place_names")
+
+	require.NotEqual(t, -1, placesIdx)
+	require.NotEqual(t, -1, namesIdx)
+
+	entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, "related"))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"The Old Shades", "St Stephen's Tavern", "The Clarence"}, entry.RelatedPlaceNames)
+	require.Equal(t, []string{
+		"https://www.google.com/maps/place/The+Old+Shades/data=!4m2!3m1!1s0x0:0x5555555555555555",
+		"https://www.google.com/maps/place/St+Stephen%27s+Tavern/data=!4m2!3m1!1s0x0:0x6666666666666666",
+		"https://www.google.com/maps/place/The+Clarence/data=!4m2!3m1!1s0x0:0x7777777777777777",
+	}, entry.RelatedPlaces)
+
+	// the links identify the places like the ones of the searches
+	require.Equal(t, "cid:6148914691236517205", gmaps.PlaceKey(entry.RelatedPlaces[0]))
+
+	row := entry.CsvRow()
+	require.Equal(t, "The Old Shades, St Stephen's Tavern, The Clarence", row[namesIdx])
+
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "pub"))
+	require.NoError(t, err)
+
+	require.Empty(t, entry.RelatedPlaces)
+	require.Empty(t, entry.RelatedPlaceNames)
+	require.Empty(t, entry.CsvRow()[placesIdx])
+}
+
+func Test_EntryFromJSONWheelchairAccessibility(t *testing.T) {
+	headers := (&gmaps.Entry{}).CsvHeaders()
+	entranceIdx := slices.Index(headers, "wheelchair_accessible_entrance")
+	restroomIdx := slices.Index(headers, "wheelchair_accessible_restroom")
+
+	require.NotEqual(t, -1, entranceIdx)
+	require.NotEqual(t, -1, restroomIdx)
+
+	entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, "accessible"))
+	require.NoError(t, err)
+
+	require.NotNil(t, entry.WheelchairAccessibleEntrance)
+	require.True(t, *entry.WheelchairAccessibleEntrance)
+	require.NotNil(t, entry.WheelchairAccessibleParking)
+	require.True(t, *entry.WheelchairAccessibleParking)
+	require.NotNil(t, entry.WheelchairAccessibleRestroom)
+	require.True(t, *entry.WheelchairAccessibleRestroom)
+	require.Equal(t, "true", entry.CsvRow()[restroomIdx])
+
+	// the attributes Google does not show are unknown, not false
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "no_accessibility"))
+	require.NoError(t, err)
+
+	require.NotEmpty(t, entry.About)
+	require.Nil(t, entry.WheelchairAccessibleEntrance)
+	require.Nil(t, entry.WheelchairAccessibleParking)
+	require.Nil(t, entry.WheelchairAccessibleRestroom)
+	require.Empty(t, entry.CsvRow()[entranceIdx])
+
+	encoded, err := json.Marshal(&entry)
+	require.NoError(t, err)
+	require.Contains(t, string(encoded), `"wheelchair_accessible_entrance":null`)
+
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "restaurant"))
+	require.NoError(t, err)
+
+	require.NotNil(t, entry.WheelchairAccessibleEntrance)
+	require.True(t, *entry.WheelchairAccessibleEntrance)
+	require.Nil(t, entry.WheelchairAccessibleRestroom)
+}
+
+func Test_EntryFromJSONTypicalSpend(t *testing.T) {
+	headers := (&gmaps.Entry{}).CsvHeaders()
+	minIdx := slices.Index(headers, "typical_spend_min")
+	maxIdx := slices.Index(headers, "typical_spend_max")
+	currencyIdx := slices.Index(headers, "typical_spend_currency")
+
+	require.NotEqual(t, -1, minIdx)
+	require.NotEqual(t, -1, maxIdx)
+	require.NotEqual(t, -1, currencyIdx)
+
+	entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, "typical_spend"))
+	require.NoError(t, err)
+
+	require.Equal(t, "$20–40", entry.PriceRange)
+	require.Equal(t, &gmaps.TypicalSpend{Min: 20, Max: 40, Currency: "USD"}, entry.TypicalSpend)
+
+	row := entry.CsvRow()
+	require.Equal(t, "20", row[minIdx])
+	require.Equal(t, "40", row[maxIdx])
+	require.Equal(t, "USD", row[currencyIdx])
+
+	encoded, err := json.Marshal(&entry)
+	require.NoError(t, err)
+	require.Contains(t, string(encoded), `"typical_spend":{"min":20,"max":40,"currency":"USD"}`)
+
+	// German pages put the symbol after the amounts
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "typical_spend_de"))
+	require.NoError(t, err)
+
+	require.Equal(t, &gmaps.TypicalSpend{Min: 20, Max: 30, Currency: "EUR"}, entry.TypicalSpend)
+
+	// the coarse price level has no amounts
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "restaurant"))
+	require.NoError(t, err)
+
+	require.Equal(t, "€€", entry.PriceRange)
+	require.Nil(t, entry.TypicalSpend)
+
+	row = entry.CsvRow()
+	require.Empty(t, row[minIdx])
+	require.Empty(t, row[currencyIdx])
+
+	encoded, err = json.Marshal(&entry)
+	require.NoError(t, err)
+	require.Contains(t, string(encoded), `"typical_spend":null`)
+
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "pub"))
+	require.NoError(t, err)
+	require.Nil(t, entry.TypicalSpend)
+}
+
+func Test_ParseTypicalSpend(t *testing.T) {
+	tests := []struct {
+		priceRange string
+		country    string
+		want       *gmaps.TypicalSpend
+	}{
+		{priceRange: "$20–40", country: "US", want: &gmaps.TypicalSpend{Min: 20, Max: 40, Currency: "USD"}},
+		{priceRange: "$20–$40", country: "", want: &gmaps.TypicalSpend{Min: 20, Max: 40, Currency: "USD"}},
+		{priceRange: "$15–25", country: "CA", want: &gmaps.TypicalSpend{Min: 15, Max: 25, Currency: "CAD"}},
+		{priceRange: "R$ 40–60", country: "BR", want: &gmaps.TypicalSpend{Min: 40, Max: 60, Currency: "BRL"}},
+		{priceRange: "20–30 €", country: "DE", want: &gmaps.TypicalSpend{Min: 20, Max: 30, Currency: "EUR"}},
+		{priceRange: "£10-20", country: "GB", want: &gmaps.TypicalSpend{Min: 10, Max: 20, Currency: "GBP"}},
+		{priceRange: "¥1,000–2,000", country: "JP", want: &gmaps.TypicalSpend{Min: 1000, Max: 2000, Currency: "JPY"}},
+		{priceRange: "₩10.000–20.000", country: "KR", want: &gmaps.TypicalSpend{Min: 10000, Max: 20000, Currency: "KRW"}},
+		{priceRange: "CHF 30–50", country: "CH", want: &gmaps.TypicalSpend{Min: 30, Max: 50, Currency: "CHF"}},
+		{priceRange: "200–300 kr", country: "SE", want: &gmaps.TypicalSpend{Min: 200, Max: 300, Currency: "kr"}},
+		{priceRange: "€100+", country: "FR", want: &gmaps.TypicalSpend{Min: 100, Currency: "EUR"}},
+		{priceRange: "€€", country: "CY"},
+		{priceRange: "$$$", country: "US"},
+		{priceRange: "Moderately expensive", country: "US"},
+		{priceRange: "", country: "US"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.priceRange, func(t *testing.T) {
+			require.Equal(t, tc.want, gmaps.ParseTypicalSpend(tc.priceRange, tc.country))
+		})
+	}
+}
+
+func Test_IsLodging(t *testing.T) {
+	for _, categories := range [][]string{
+		{"Hotel"},
+		{"Restaurant", "Resort hotel"},
+		{"Bed & breakfast"},
+		{"Inn"},
+		{"Youth hostel"},
+	} {
+		require.True(t, gmaps.IsLodging(categories), categories)
+	}
+
+	for _, categories := range [][]string{
+		nil,
+		{"Bar", "Cocktail bar"},
+		{"Breakfast restaurant"},
+		{"Dinner theater"},
+	} {
+		require.False(t, gmaps.IsLodging(categories), categories)
+	}
+}
+
+func Test_MapLinks(t *testing.T) {
+	tests := []struct {
+		name       string
+		cid        string
+		lat, lon   float64
+		share      string
+		directions string
+	}{
+		{
+			name:       "cid and coordinates",
+			cid:        "16519582940102929223",
+			lat:        34.6705954,
+			lon:        33.0424567,
+			share:      "https://maps.google.com/?cid=16519582940102929223",
+			directions: "https://www.google.com/maps/dir/?api=1&destination=34.6705954,33.0424567",
+		},
+		{
+			name:       "coordinates only",
+			lat:        -33.8567844,
+			lon:        151.213108,
+			share:      "https://www.google.com/maps/search/?api=1&query=-33.8567844,151.213108",
+			directions: "https://www.google.com/maps/dir/?api=1&destination=-33.8567844,151.213108",
+		},
+		{
+			name:  "cid only",
+			cid:   "123",
+			share: "https://maps.google.com/?cid=123",
+		},
+		{name: "neither"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			share, directions := gmaps.MapLinks(tc.cid, tc.lat, tc.lon)
+			require.Equal(t, tc.share, share)
+			require.Equal(t, tc.directions, directions)
+		})
+	}
+}
+
+func Test_EntryFromJSONAddress(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    gmaps.Address
+	}{
+		{
+			fixture: "doctor",
+			want: gmaps.Address{
+				Street:     "100 Example St",
+				City:       "New York",
+				PostalCode: "10010",
+				State:      "NY",
+				Country:    "US",
+			},
+		},
+		{
+			fixture: "pub",
+			want: gmaps.Address{
+				Street:     "48 Parliament St",
+				City:       "London",
+				PostalCode: "SW1A 2NH",
+				Country:    "GB",
+			},
+		},
+		{
+			fixture: "bakery",
+			want: gmaps.Address{
+				Street:     "1-2-3 Dogenzaka",
+				City:       "Shibuya City",
+				PostalCode: "150-0043",
+				State:      "Tokyo",
+				Country:    "JP",
+			},
+		},
+		{
+			fixture: "minimal",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.fixture, func(t *testing.T) {
+			entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, tc.fixture))
+			require.NoError(t, err)
+
+			require.Equal(t, tc.want, entry.CompleteAddress)
+
+			row := entry.CsvRow()
+			headers := entry.CsvHeaders()
+			require.Len(t, row, len(headers))
+
+			columns := make(map[string]string, len(headers))
+			for i := range headers {
+				columns[headers[i]] = row[i]
+			}
+
+			require.Equal(t, tc.want.Street, columns["address_street"])
+			require.Equal(t, tc.want.City, columns["address_city"])
+			require.Equal(t, tc.want.PostalCode, columns["address_postal_code"])
+			require.Equal(t, tc.want.State, columns["address_state"])
+			require.Equal(t, tc.want.Country, columns["address_country"])
+		})
+	}
+}
+
+func Test_EntryUTCOffset(t *testing.T) {
+	winter := time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC)
+	summer := time.Date(2024, time.July, 15, 12, 0, 0, 0, time.UTC)
+
+	require.Equal(t, "-05:00", gmaps.UTCOffset("America/New_York", winter))
+	require.Equal(t, "-04:00", gmaps.UTCOffset("America/New_York", summer))
+	require.Equal(t, "+09:00", gmaps.UTCOffset("Asia/Tokyo", summer))
+	require.Equal(t, "+05:30", gmaps.UTCOffset("Asia/Kolkata", winter))
+	require.Empty(t, gmaps.UTCOffset("", winter))
+	require.Empty(t, gmaps.UTCOffset("Not/AZone", winter))
+
+	entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, "bakery"))
+	require.NoError(t, err)
+	require.Equal(t, "+09:00", entry.UTCOffset)
+
+	entry, err = gmaps.EntryFromJSON(loadPlaceFixture(t, "minimal"))
+	require.NoError(t, err)
+	require.Empty(t, entry.UTCOffset)
+	require.Empty(t, entry.Country)
+}
+
+func Test_CidFromDataID(t *testing.T) {
+	tests := []struct {
+		name   string
+		dataID string
+		cid    string
+	}{
+		{
+			name:   "above the max int64",
+			dataID: "0x14e732fd76f0d90d:0xe5415928d6702b47",
+			cid:    "16519582940102929223",
+		},
+		{
+			name:   "max uint64",
+			dataID: "0x0:0xffffffffffffffff",
+			cid:    "18446744073709551615",
+		},
+		{
+			name:   "below the max int64",
+			dataID: "0x14a1a3347017fa47:0x575361ebcc78036d",
+			cid:    "6292480769742340973",
+		},
+		{
+			name:   "leading zeros",
+			dataID: "0x0:0x00000000000000ff",
+			cid:    "255",
+		},
+		{
+			name:   "without 0x",
+			dataID: "14a1a3347017fa47:575361ebcc78036d",
+			cid:    "6292480769742340973",
+		},
+		{name: "above the max uint64", dataID: "0x0:0x10000000000000000"},
+		{name: "zero", dataID: "0x0:0x0"},
+		{name: "not hex", dataID: "0x0:0xnothex"},
+		{name: "no listing", dataID: "0x14a1a3347017fa47"},
+		{name: "empty"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.cid, gmaps.CidFromDataID(tc.dataID))
+		})
+	}
+}
+
+func Test_PlaceKey(t *testing.T) {
+	const placeURL = "https://www.google.com/maps/place/Kipriakon/data=!4m7!3m6!1s0x14e732fd76f0d90d:0xe5415928d6702b47!8m2"
+
+	require.Equal(t, "cid:16519582940102929223", gmaps.PlaceKey(placeURL))
+	require.Equal(t, gmaps.PlaceKey(placeURL), gmaps.PlaceKey(strings.Replace(placeURL, "Kipriakon", "Kipriakon+Tavern", 1)))
+	require.Equal(t, "https://www.google.com/maps/place/Foo", gmaps.PlaceKey("https://www.google.com/maps/place/Foo"))
+}
+
+func Test_GetNthElementAndCast(t *testing.T) {
+	arr := []any{1.0, []any{2.0, []any{3.0}}, nil, "a"}
+
+	require.Equal(t, 1.0, gmaps.GetNthFloat(arr, 0))
+	require.Equal(t, 3.0, gmaps.GetNthFloat(arr, 1, 1, 0))
+
+	// out of range, as the last index and on the way to it
+	require.Zero(t, gmaps.GetNthFloat(arr, 4))
+	require.Zero(t, gmaps.GetNthFloat(arr, 1, 1, 1))
+	require.Zero(t, gmaps.GetNthFloat(arr, 4, 0))
+	require.Zero(t, gmaps.GetNthFloat(arr, -1))
+	require.Zero(t, gmaps.GetNthFloat(arr, 1, -1))
+	require.Zero(t, gmaps.GetNthFloat(nil, 0))
+
+	// nil, not an array, another type, no index
+	require.Zero(t, gmaps.GetNthFloat(arr, 2, 0))
+	require.Zero(t, gmaps.GetNthFloat(arr, 0, 0))
+	require.Zero(t, gmaps.GetNthFloat(arr, 3))
+	require.Zero(t, gmaps.GetNthFloat(arr))
+}
+
+func Test_EntryBusinessStatus(t *testing.T) {
+	tests := []struct {
+		fixture string
+		status  string
+		closed  bool
+	}{
+		// "Closed ⋅ Opens 12:30 pm Tue" is only closed for the day
+		{fixture: "restaurant", status: gmaps.BusinessStatusOperational},
+		{fixture: "minimal", status: gmaps.BusinessStatusOperational},
+		{fixture: "temporarily_closed", status: gmaps.BusinessStatusTemporarilyClosed, closed: true},
+		{fixture: "permanently_closed", status: gmaps.BusinessStatusPermanentlyClosed, closed: true},
+		// the status line is in the language of the page, the closed flag is not
+		{fixture: "operational_de", status: gmaps.BusinessStatusOperational},
+		{fixture: "temporarily_closed_de", status: gmaps.BusinessStatusTemporarilyClosed, closed: true},
+		{fixture: "permanently_closed_de", status: gmaps.BusinessStatusPermanentlyClosed, closed: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.fixture, func(t *testing.T) {
+			entry, err := gmaps.EntryFromJSON(loadPlaceFixture(t, tc.fixture))
+			require.NoError(t, err)
+
+			require.Equal(t, tc.status, entry.BusinessStatus)
+			require.Equal(t, tc.closed, entry.IsClosed())
+		})
+	}
+}
+
+func Test_EntryReviewAuthors(t *testing.T) {
+	page, err := os.ReadFile("../testdata/reviews/page.json")
+	require.NoError(t, err)
+
+	entry := gmaps.Entry{}
+	entry.AddExtraReviews([][]byte{page})
+
+	guide := entry.UserReviewsExtended[0]
+	require.Equal(t, "Vaios Gaintatzis", guide.Name)
+	require.Equal(t, 11, guide.AuthorReviewCount)
+	require.True(t, guide.AuthorIsLocalGuide)
+
+	regular := entry.UserReviewsExtended[1]
+	require.Equal(t, "MARIJANA MILOJEVIC", regular.Name)
+	require.Equal(t, 3, regular.AuthorReviewCount)
+	require.False(t, regular.AuthorIsLocalGuide)
+
+	// the author data is missing for some reviews
+	require.Len(t, entry.UserReviewsExtended, 3)
+	require.Equal(t, "Gabriel Georgiou", entry.UserReviewsExtended[2].Name)
+	require.Zero(t, entry.UserReviewsExtended[2].AuthorReviewCount)
+	require.False(t, entry.UserReviewsExtended[2].AuthorIsLocalGuide)
+}
+
+func Test_EntryReviewOwnerResponse(t *testing.T) {
+	page, err := os.ReadFile("../testdata/reviews/page_owner_response.json")
+	require.NoError(t, err)
+
+	entry := gmaps.Entry{}
+	entry.AddExtraReviews([][]byte{page})
+
+	require.Len(t, entry.UserReviewsExtended, 3)
+
+	replied := entry.UserReviewsExtended[0]
+	require.Equal(t, "Thank you for your kind words, we hope to see you again soon!", replied.OwnerResponse)
+	require.Equal(t, "2024-8-12", replied.OwnerResponseWhen)
+
+	// the owner did not reply to the other reviews
+	for _, review := range entry.UserReviewsExtended[1:] {
+		require.Empty(t, review.OwnerResponse)
+		require.Empty(t, review.OwnerResponseWhen)
+	}
+
+	rows := entry.ReviewRows()
+	require.Equal(t, replied.OwnerResponse, rows[0].OwnerResponse)
+
+	headers := rows[0].CsvHeaders()
+	idx := slices.Index(headers, "owner_response_when")
+	require.NotEqual(t, -1, idx)
+	require.Equal(t, "2024-8-12", rows[0].CsvRow()[idx])
+	require.Empty(t, rows[1].CsvRow()[idx])
+
+	// no review of the page without replies has one
+	page, err = os.ReadFile("../testdata/reviews/page.json")
+	require.NoError(t, err)
+
+	entry = gmaps.Entry{}
+	entry.AddExtraReviews([][]byte{page})
+
+	for _, review := range entry.UserReviewsExtended {
+		require.Empty(t, review.OwnerResponse)
+	}
+}
@@ -0,0 +1,103 @@
+package gmaps
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+)
+
+var (
+	// ErrPlaceNotFound is returned when the place page has no place data,
+	// e.g. because the place was removed.
+	ErrPlaceNotFound = errors.New("place not found")
+	// ErrNotPlacePage is returned when the page the place URL led to is not
+	// a place page, e.g. the generic Google Maps page, see Settings.PlaceMarker.
+	ErrNotPlacePage = errors.New("not a place page")
+	// ErrConsentWall is returned when Google keeps the page on its consent
+	// form instead of showing the place.
+	ErrConsentWall = errors.New("redirected to the consent page")
+	// ErrRequestBudget is returned by the jobs that did not navigate
+	// because the request budget of the run is used up.
+	ErrRequestBudget = errors.New("request budget exhausted")
+)
+
+// allowRequest reports whether a job with exitMonitor may navigate, see
+// exiter.Exiter.AllowRequest.
+func allowRequest(exitMonitor exiter.Exiter) bool {
+	return exitMonitor == nil || exitMonitor.AllowRequest()
+}
+
+// transientMessages and permanentMessages are parts of the error messages
+// of playwright and scrapemate, in lower case. Transient ones are checked
+// first, so a timeout while loading a consent page is still retried.
+var (
+	transientMessages = []string{
+		"timeout",
+		"net::err_",
+		"connection reset",
+		"connection refused",
+		"target closed",
+		"status code 429",
+		"status code 5",
+	}
+
+	permanentMessages = []string{
+		"place not found",
+		"not a place page",
+		"consent.google.com",
+		"status code 404",
+		"status code 410",
+	}
+)
+
+// IsPermanentError reports whether err is a failure that fetching the page
+// again would not fix, e.g. a place that does not exist. Timeouts, network
+// errors and unknown errors are transient and worth a retry.
+func IsPermanentError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrPlaceNotFound) || errors.Is(err, ErrNotPlacePage) || errors.Is(err, ErrConsentWall) {
+		return true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+
+	for _, s := range transientMessages {
+		if strings.Contains(msg, s) {
+			return false
+		}
+	}
+
+	for _, s := range permanentMessages {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FailureReporter is told about the jobs that failed for good, i.e. after
+// their retries: the input that runs the job again, the keyword of a
+// search or the URL of a place, the ID of its input line and the error.
+type FailureReporter interface {
+	JobFailed(input, id string, err error)
+}
+
+// reportFailure tells r, when set, about the failure of the job with input
+// and id. The jobs interrupted by the end of the run are not failures.
+func reportFailure(ctx context.Context, r FailureReporter, input, id string, err error) {
+	if r == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	r.JobFailed(input, id, err)
+}
@@ -0,0 +1,44 @@
+package gmaps_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_IsPermanentError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		permanent bool
+	}{
+		{name: "nil", err: nil, permanent: false},
+		{name: "place not found", err: gmaps.ErrPlaceNotFound, permanent: true},
+		{name: "wrapped place not found", err: fmt.Errorf("place x: %w", gmaps.ErrPlaceNotFound), permanent: true},
+		{name: "not a place page", err: fmt.Errorf("%w: https://www.google.com/maps/@37.97,23.73,17z", gmaps.ErrNotPlacePage), permanent: true},
+		{name: "not a place page message", err: errors.New("not a place page: https://www.google.com/maps/@37.97,23.73,17z"), permanent: true},
+		{name: "consent wall", err: gmaps.ErrConsentWall, permanent: true},
+		{name: "consent redirect message", err: errors.New("navigated to https://consent.google.com/ml?continue=https://www.google.com/maps"), permanent: true},
+		{name: "not found status", err: errors.New("status code 404"), permanent: true},
+		{name: "gone status", err: errors.New("status code 410"), permanent: true},
+		{name: "deadline", err: context.DeadlineExceeded, permanent: false},
+		{name: "canceled", err: fmt.Errorf("fetch: %w", context.Canceled), permanent: false},
+		{name: "playwright timeout", err: errors.New("playwright: timeout: Timeout 30000ms exceeded."), permanent: false},
+		{name: "timeout on consent page", err: errors.New("Timeout 5000ms exceeded waiting for consent.google.com"), permanent: false},
+		{name: "network", err: errors.New("page.goto: net::ERR_CONNECTION_RESET at https://www.google.com/maps"), permanent: false},
+		{name: "rate limited", err: errors.New("status code 429"), permanent: false},
+		{name: "server error", err: errors.New("status code 503"), permanent: false},
+		{name: "unknown", err: errors.New("could not convert to []byte"), permanent: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.permanent, gmaps.IsPermanentError(tc.err))
+		})
+	}
+}
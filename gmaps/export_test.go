@@ -0,0 +1,41 @@
+package gmaps
+
+import "time"
+
+// exported for testing
+var (
+	Scroll                = scroll
+	ScrollUntilNoNewItems = scrollUntilNoNewItems
+	UTCOffset             = utcOffset
+	WaitForPage           = (*Settings).waitForPage
+	TakeScreenshot        = (*Settings).takeScreenshot
+	CacheKey              = (*Settings).cacheKey
+	MetaBytes             = metaBytes
+	MetaPages             = metaPages
+	HandleConsent         = (*Settings).handleConsent
+	ConsentRejectSelector = consentRejectSelector
+	GuessWebsite          = guessWebsite
+	RandomDelay           = randomDelay
+	SleepContext          = sleepContext
+	CleanWebsite          = cleanWebsite
+	SettingsWebsite       = (*Settings).website
+	IsBlockedResponse     = isBlockedResponse
+	CidFromDataID         = cidFromDataID
+	MatchSelector         = matchSelector
+	ApplyStorageState     = (*Settings).applyStorageState
+	ApplyBlockedResources = (*Settings).applyBlockedResources
+	IsLodging             = isLodging
+	VerifyPlacePage       = verifyPlacePage
+	DrainContext          = (*Settings).drainContext
+	ParseTypicalSpend     = parseTypicalSpend
+	GetNthFloat           = getNthElementAndCast[float64]
+)
+
+func MapLinks(cid string, lat, lon float64) (share, directions string) {
+	return shareURL(cid, lat, lon), directionsURL(lat, lon)
+}
+
+// SetKeywordTimeoutClock replaces the clock of k.
+func SetKeywordTimeoutClock(k *KeywordTimeout, now func() time.Time) {
+	k.now = now
+}
@@ -2,9 +2,11 @@ package gmaps
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/gosom/google-maps-scraper/deduper"
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/limiter"
 	"github.com/gosom/scrapemate"
 	"github.com/playwright-community/playwright-go"
 )
@@ -23,11 +26,79 @@ type GmapJob struct {
 
 	MaxDepth     int
 	LangCode     string
+	Country      string
+	Keyword      string
+	Lat          float64
+	Lon          float64
+	Zoom         int
 	ExtractEmail bool
 
 	Deduper             deduper.Deduper
 	ExitMonitor         exiter.Exiter
 	ExtractExtraReviews bool
+
+	// SeedReporter is told how many places the search found.
+	SeedReporter SeedReporter
+
+	// KeywordTracker is told when the search and its places are done,
+	// see WithKeywordTracker.
+	KeywordTracker KeywordTracker
+
+	// Trace identifies the run and the seed of the job, see WithTrace.
+	Trace Trace
+
+	// FailureReporter is told when the search, or one of its places,
+	// fails, see WithFailureReporter.
+	FailureReporter FailureReporter
+
+	// ExpandNearby is the number of hops of nearby searches its places
+	// trigger, see WithPlaceJobExpandNearby. NearbyZoom is their zoom level.
+	ExpandNearby int
+	NearbyZoom   int
+
+	// ExpandRelated is the number of hops of related places its places
+	// scrape, see WithPlaceJobExpandRelated.
+	ExpandRelated int
+
+	// KeywordLimiter caps the number of places emitted per Keyword.
+	KeywordLimiter limiter.Limiter
+
+	// KeywordTimeout caps the time spent on the Keyword, see
+	// WithKeywordTimeout.
+	KeywordTimeout *KeywordTimeout
+
+	// WaitTimeout is how long to wait for the search page after navigation.
+	// WaitSelector is passed on to the place jobs, see WithPlaceJobWait.
+	WaitSelector string
+	WaitTimeout  time.Duration
+
+	// ScreenshotsDir and ScreenshotsMax are passed on to the place jobs,
+	// see WithPlaceJobScreenshots.
+	ScreenshotsDir string
+	ScreenshotsMax int
+
+	// RawJSONDir and RawJSONMax are passed on to the place jobs,
+	// see WithPlaceJobRawJSON.
+	RawJSONDir string
+	RawJSONMax int
+
+	// EnrichWebsite is passed on to the place jobs,
+	// see WithPlaceJobWebsiteEnrichment.
+	EnrichWebsite bool
+
+	// AutoDepthPatience enables auto depth when greater than 0.
+	// Scrolling stops after that many consecutive scrolls without new
+	// feed items, or when MaxDepth scrolls have been made.
+	AutoDepthPatience int
+
+	// MaxEmptyScrolls is the number of consecutive scrolls that must leave
+	// the height of the results feed unchanged before scrolling stops,
+	// see WithMaxEmptyScrolls. Values below 1 mean 1.
+	MaxEmptyScrolls int
+
+	// settings are passed on to the jobs it creates, see WithSettings.
+	// They are not exported, so they are not encoded with the job.
+	settings *Settings
 }
 
 func NewGmapJob(
@@ -38,6 +109,7 @@ func NewGmapJob(
 	zoom int,
 	opts ...GmapJobOptions,
 ) *GmapJob {
+	keyword := query
 	query = url.QueryEscape(query)
 
 	const (
@@ -49,8 +121,12 @@ func NewGmapJob(
 		id = uuid.New().String()
 	}
 
+	var lat, lon float64
+
 	mapURL := ""
 	if geoCoordinates != "" && zoom > 0 {
+		lat, lon = parseGeoCoordinates(geoCoordinates)
+
 		mapURL = fmt.Sprintf("https://www.google.com/maps/search/%s/@%s,%dz", query, strings.ReplaceAll(geoCoordinates, " ", ""), zoom)
 	} else {
 		//Warning: geo and zoom MUST be both set or not
@@ -68,16 +144,148 @@ func NewGmapJob(
 		},
 		MaxDepth:     maxDepth,
 		LangCode:     langCode,
+		Keyword:      keyword,
 		ExtractEmail: extractEmail,
 	}
 
+	if lat != 0 || lon != 0 {
+		job.Lat = lat
+		job.Lon = lon
+		job.Zoom = zoom
+	}
+
 	for _, opt := range opts {
 		opt(&job)
 	}
 
+	if job.Country != "" {
+		job.URLParams["gl"] = job.Country
+	}
+
 	return &job
 }
 
+// WithCountry restricts the search to a country using Google's gl parameter.
+// The code is expected to be a lowercase ISO 3166-1 alpha-2 code.
+func WithCountry(code string) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.Country = code
+	}
+}
+
+// WithAutoDepth makes the job scroll until no new places appear
+// for patience consecutive scrolls instead of scrolling a fixed number of times.
+// MaxDepth is still respected as a safety cap.
+func WithAutoDepth(patience int) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.AutoDepthPatience = patience
+	}
+}
+
+// WithMaxEmptyScrolls makes the job keep scrolling until n consecutive
+// scrolls leave the results feed unchanged, so that a brief pause while
+// Google loads more places does not end the search early.
+func WithMaxEmptyScrolls(n int) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.MaxEmptyScrolls = n
+	}
+}
+
+// SeedReporter receives the number of places a search job found,
+// including places that were already scraped by another search.
+type SeedReporter interface {
+	SeedDone(keyword string, places int)
+}
+
+func WithSeedReporter(r SeedReporter) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.SeedReporter = r
+	}
+}
+
+// WithFailureReporter makes the job and its place jobs report their
+// failures to r.
+func WithFailureReporter(r FailureReporter) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.FailureReporter = r
+	}
+}
+
+// WithExpandNearby makes the places found search for places of the same
+// category around them, for up to hops levels.
+func WithExpandNearby(hops, zoom int) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.ExpandNearby = hops
+		j.NearbyZoom = zoom
+	}
+}
+
+// WithExpandRelated makes the places found scrape the places of their
+// "People also search for" section too, for up to hops levels.
+func WithExpandRelated(hops int) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.ExpandRelated = hops
+	}
+}
+
+// WithKeywordLimiter limits how many places are emitted for the job's keyword.
+func WithKeywordLimiter(l limiter.Limiter) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.KeywordLimiter = l
+	}
+}
+
+// WithKeywordTimeout stops the search, and skips the places it found,
+// once the job's keyword ran out of the time of k.
+func WithKeywordTimeout(k *KeywordTimeout) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.KeywordTimeout = k
+	}
+}
+
+// WithWait sets how long the search waits for its page after navigation
+// and how its places wait for theirs, see WithPlaceJobWait.
+func WithWait(selector string, timeout time.Duration) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.WaitSelector = selector
+		j.WaitTimeout = timeout
+	}
+}
+
+// WithScreenshots makes the places found save a screenshot of their page,
+// see WithPlaceJobScreenshots.
+func WithScreenshots(dir string, maxShots int) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.ScreenshotsDir = dir
+		j.ScreenshotsMax = maxShots
+	}
+}
+
+// WithRawJSON makes the places found save their raw JSON,
+// see WithPlaceJobRawJSON.
+func WithRawJSON(dir string, maxFiles int) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.RawJSONDir = dir
+		j.RawJSONMax = maxFiles
+	}
+}
+
+// WithWebsiteEnrichment makes the places found without a website guess it
+// from a web search, see WithPlaceJobWebsiteEnrichment.
+func WithWebsiteEnrichment() GmapJobOptions {
+	return func(j *GmapJob) {
+		j.EnrichWebsite = true
+	}
+}
+
+// WithSettings sets the settings of the run of the job, passed on to the
+// place jobs, see Settings.
+func WithSettings(s *Settings) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.settings = s
+	}
+}
+
 func WithDeduper(d deduper.Deduper) GmapJobOptions {
 	return func(j *GmapJob) {
 		j.Deduper = d
@@ -96,6 +304,12 @@ func WithExtraReviews() GmapJobOptions {
 	}
 }
 
+// GetCacheKey returns the key of the search page in the cache. The scroll
+// depth changes the content, so it is part of the key.
+func (j *GmapJob) GetCacheKey() string {
+	return j.settings.cacheKey(j.Job.GetCacheKey() + ":" + strconv.Itoa(j.MaxDepth))
+}
+
 func (j *GmapJob) UseInResults() bool {
 	return false
 }
@@ -106,77 +320,292 @@ func (j *GmapJob) Process(ctx context.Context, resp *scrapemate.Response) (any,
 		resp.Body = nil
 	}()
 
+	ctx = withCorrelationID(ctx, j.Trace.ID())
 	log := scrapemate.GetLoggerFromContext(ctx)
 
+	if errors.Is(resp.Error, ErrKeywordTimeout) {
+		log.Info(fmt.Sprintf("keyword %q ran out of time", j.Keyword))
+
+		j.seedDone(0, 0)
+
+		return nil, nil, nil
+	}
+
+	if resp.Error != nil {
+		if !errors.Is(resp.Error, ErrRequestBudget) {
+			reportFailure(ctx, j.FailureReporter, j.Keyword, j.ID, resp.Error)
+		}
+
+		return nil, nil, resp.Error
+	}
+
 	doc, ok := resp.Document.(*goquery.Document)
 	if !ok {
-		return nil, nil, fmt.Errorf("could not convert to goquery document")
+		err := fmt.Errorf("could not convert to goquery document")
+		reportFailure(ctx, j.FailureReporter, j.Keyword, j.ID, err)
+
+		return nil, nil, err
 	}
 
-	var next []scrapemate.IJob
+	var (
+		next  []scrapemate.IJob
+		found int
+	)
 
 	if strings.Contains(resp.URL, "/maps/place/") {
-		jopts := []PlaceJobOptions{}
-		if j.ExitMonitor != nil {
-			jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
-		}
-
-		placeJob := NewPlaceJob(j.ID, j.LangCode, resp.URL, j.ExtractEmail, j.ExtractExtraReviews, jopts...)
+		placeJob := NewPlaceJob(j.ID, j.LangCode, resp.URL, j.ExtractEmail, j.ExtractExtraReviews, j.placeJobOptions()...)
 
 		next = append(next, placeJob)
+		found++
 	} else {
-		doc.Find(`div[role=feed] div[jsaction]>a`).Each(func(_ int, s *goquery.Selection) {
+		feedLinks(doc, j.settings.feedSelectors()).Each(func(_ int, s *goquery.Selection) {
 			if href := s.AttrOr("href", ""); href != "" {
-				jopts := []PlaceJobOptions{}
-				if j.ExitMonitor != nil {
-					jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
-				}
+				found++
 
-				nextJob := NewPlaceJob(j.ID, j.LangCode, href, j.ExtractEmail, j.ExtractExtraReviews, jopts...)
+				nextJob := NewPlaceJob(j.ID, j.LangCode, href, j.ExtractEmail, j.ExtractExtraReviews, j.placeJobOptions()...)
 
-				if j.Deduper == nil || j.Deduper.AddIfNotExists(ctx, href) {
+				if j.Deduper == nil || j.Deduper.AddIfNotExists(ctx, PlaceKey(href)) {
 					next = append(next, nextJob)
 				}
 			}
 		})
 	}
 
-	if j.ExitMonitor != nil {
-		j.ExitMonitor.IncrPlacesFound(len(next))
-		j.ExitMonitor.IncrSeedCompleted(1)
+	if len(next) > 0 && j.KeywordTimeout.Expired(j.Keyword) {
+		log.Info(fmt.Sprintf("keyword %q ran out of time, skipping its %d places", j.Keyword, len(next)))
+
+		next = nil
 	}
 
+	j.seedDone(len(next), found)
+
 	log.Info(fmt.Sprintf("%d places found", len(next)))
 
 	return nil, next, nil
 }
 
+// seedDone reports the end of the search, which emitted places jobs out of
+// the found places.
+func (j *GmapJob) seedDone(places, found int) {
+	if j.ExitMonitor != nil {
+		j.ExitMonitor.IncrPlacesFound(places)
+		j.ExitMonitor.IncrSeedCompleted(1)
+	}
+
+	if j.SeedReporter != nil {
+		j.SeedReporter.SeedDone(j.Keyword, found)
+	}
+
+	if j.KeywordTracker != nil {
+		j.KeywordTracker.SearchDone(j.keywordSearch(found), places)
+	}
+}
+
+// feedLinks returns the links to the places of the results feed of doc,
+// found with the first of the feed selectors that matches.
+func feedLinks(doc *goquery.Document, selectors []string) *goquery.Selection {
+	var links *goquery.Selection
+
+	for _, sel := range selectors {
+		links = doc.Find(sel + ` div[jsaction]>a`)
+		if links.Length() > 0 {
+			break
+		}
+	}
+
+	return links
+}
+
+// parseGeoCoordinates parses "lat,lon". It returns zeros when s is invalid.
+func parseGeoCoordinates(s string) (lat, lon float64) {
+	latStr, lonStr, ok := strings.Cut(strings.ReplaceAll(s, " ", ""), ",")
+	if !ok {
+		return 0, 0
+	}
+
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil {
+		return 0, 0
+	}
+
+	lon, err = strconv.ParseFloat(lonStr, 64)
+	if err != nil {
+		return 0, 0
+	}
+
+	return lat, lon
+}
+
+func (j *GmapJob) placeJobOptions() []PlaceJobOptions {
+	jopts := []PlaceJobOptions{}
+
+	if j.ExitMonitor != nil {
+		jopts = append(jopts, WithPlaceJobExitMonitor(j.ExitMonitor))
+	}
+
+	if j.Country != "" {
+		jopts = append(jopts, WithPlaceJobCountry(j.Country))
+	}
+
+	jopts = append(jopts, WithPlaceJobSource(j.Keyword, j.Lat, j.Lon, j.Zoom))
+
+	if j.KeywordLimiter != nil {
+		jopts = append(jopts, WithPlaceJobKeywordLimiter(j.KeywordLimiter))
+	}
+
+	if j.KeywordTimeout != nil {
+		jopts = append(jopts, WithPlaceJobKeywordTimeout(j.KeywordTimeout))
+	}
+
+	if j.ExpandNearby > 0 {
+		jopts = append(jopts, WithPlaceJobExpandNearby(j.ExpandNearby, j.MaxDepth, j.NearbyZoom, j.Deduper))
+	}
+
+	if j.ExpandRelated > 0 {
+		jopts = append(jopts, WithPlaceJobExpandRelated(j.ExpandRelated, j.Deduper))
+	}
+
+	if j.WaitSelector != "" || j.WaitTimeout > 0 {
+		jopts = append(jopts, WithPlaceJobWait(j.WaitSelector, j.WaitTimeout))
+	}
+
+	if j.ScreenshotsDir != "" {
+		jopts = append(jopts, WithPlaceJobScreenshots(j.ScreenshotsDir, j.ScreenshotsMax))
+	}
+
+	if j.RawJSONDir != "" {
+		jopts = append(jopts, WithPlaceJobRawJSON(j.RawJSONDir, j.RawJSONMax))
+	}
+
+	if j.EnrichWebsite {
+		jopts = append(jopts, WithPlaceJobWebsiteEnrichment())
+	}
+
+	if j.FailureReporter != nil {
+		jopts = append(jopts, WithPlaceJobFailureReporter(j.FailureReporter))
+	}
+
+	if j.KeywordTracker != nil {
+		jopts = append(jopts, WithPlaceJobKeywordTracker(j.KeywordTracker))
+	}
+
+	if j.Trace != (Trace{}) {
+		jopts = append(jopts, WithPlaceJobTrace(j.Trace))
+	}
+
+	if j.settings != nil {
+		jopts = append(jopts, WithPlaceJobSettings(j.settings))
+	}
+
+	return jopts
+}
+
+// ProcessOnFetchError is true so that Process can report the searches that
+// failed. The failures are still returned as errors.
+func (j *GmapJob) ProcessOnFetchError() bool {
+	return true
+}
+
+// DoCheckResponse does not retry the job when the request budget is used up
+// or its keyword ran out of time.
+func (j *GmapJob) DoCheckResponse(resp *scrapemate.Response) bool {
+	if errors.Is(resp.Error, ErrRequestBudget) || errors.Is(resp.Error, ErrKeywordTimeout) {
+		return true
+	}
+
+	return j.Job.DoCheckResponse(resp)
+}
+
+// BrowserActions searches the keyword within the time it has left, see
+// WithKeywordTimeout.
 func (j *GmapJob) BrowserActions(ctx context.Context, page playwright.Page) scrapemate.Response {
+	ctx = withCorrelationID(ctx, j.Trace.ID())
+
+	ctx, cancel, err := j.KeywordTimeout.withDeadline(ctx, j.Keyword)
+	if err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	defer cancel()
+
+	resp := j.search(ctx, page)
+
+	// the search was cut short
+	if resp.Error != nil && j.KeywordTimeout.Expired(j.Keyword) {
+		resp.Error = ErrKeywordTimeout
+	}
+
+	return resp
+}
+
+func (j *GmapJob) search(ctx context.Context, page playwright.Page) scrapemate.Response {
 	var resp scrapemate.Response
 
-	pageResponse, err := page.Goto(j.GetFullURL(), playwright.PageGotoOptions{
-		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
-	})
+	if !allowRequest(j.ExitMonitor) {
+		resp.Error = ErrRequestBudget
 
+		return resp
+	}
+
+	t := j.settings.throttle()
+
+	release, err := t.Acquire(ctx)
 	if err != nil {
 		resp.Error = err
 
 		return resp
 	}
 
-	if err = clickRejectCookiesIfRequired(page); err != nil {
+	defer release()
+
+	if err := j.settings.applyStorageState(page); err != nil {
 		resp.Error = err
 
 		return resp
 	}
 
-	const defaultTimeout = 5000
+	if err := j.settings.applyBlockedResources(page); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	if err := j.settings.warmUp(page, j.LangCode); err != nil {
+		resp.Error = err
+
+		return resp
+	}
 
-	err = page.WaitForURL(page.URL(), playwright.PageWaitForURLOptions{
-		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
-		Timeout:   playwright.Float(defaultTimeout),
+	pageResponse, err := page.Goto(j.GetFullURL(), playwright.PageGotoOptions{
+		WaitUntil: j.settings.waitUntilState(playwright.WaitUntilStateDomcontentloaded),
 	})
 
+	if err != nil {
+		if ctx.Err() == nil {
+			t.ObserveFailure()
+		}
+
+		resp.Error = err
+
+		return resp
+	}
+
+	if isBlockedPage(page, pageResponse.Status()) {
+		t.Observe(true)
+
+		resp.Error = ErrBlocked
+
+		return resp
+	}
+
+	if err = j.settings.handleConsent(page); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	// the feed is waited for below, with a short timeout to detect single places
+	err = j.settings.waitForPage(page, "", j.WaitTimeout)
 	if err != nil {
 		resp.Error = err
 
@@ -193,16 +622,18 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page playwright.Page) scra
 
 	// When Google Maps finds only 1 place, it slowly redirects to that place's URL
 	// check element scroll
-	sel := `div[role='feed']`
 
-	//nolint:staticcheck // TODO replace with the new playwright API
-	_, err = page.WaitForSelector(sel, playwright.PageWaitForSelectorOptions{
-		Timeout: playwright.Float(700),
-	})
+	// jobs with an explicit timeout (e.g. retries) give the feed more time to appear
+	feedTimeout := 700 * time.Millisecond
+	if j.Timeout > 0 {
+		feedTimeout = 5 * time.Second
+	}
+
+	scrollSelector, found := matchSelector(ctx, page, "feed", j.settings.feedSelectors(), feedTimeout)
 
 	var singlePlace bool
 
-	if err != nil {
+	if !found {
 		waitCtx, waitCancel := context.WithTimeout(ctx, time.Second*5)
 		defer waitCancel()
 
@@ -222,14 +653,19 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page playwright.Page) scra
 			return resp
 		}
 
+		t.Observe(false)
+
 		resp.Body = []byte(body)
 
 		return resp
 	}
 
-	scrollSelector := `div[role='feed']`
+	if j.AutoDepthPatience > 0 {
+		_, err = scrollUntilNoNewItems(ctx, page, j.MaxDepth, j.AutoDepthPatience, scrollSelector)
+	} else {
+		_, err = scroll(ctx, page, j.MaxDepth, j.MaxEmptyScrolls, scrollSelector)
+	}
 
-	_, err = scroll(ctx, page, j.MaxDepth, scrollSelector)
 	if err != nil {
 		resp.Error = err
 
@@ -242,6 +678,8 @@ func (j *GmapJob) BrowserActions(ctx context.Context, page playwright.Page) scra
 		return resp
 	}
 
+	t.Observe(false)
+
 	resp.Body = []byte(body)
 
 	return resp
@@ -263,36 +701,22 @@ func waitUntilURLContains(ctx context.Context, page playwright.Page, s string) b
 	}
 }
 
-func clickRejectCookiesIfRequired(page playwright.Page) error {
-	// click the cookie reject button if exists
-	sel := `form[action="https://consent.google.com/save"]:first-of-type button:first-of-type`
-
-	const timeout = 5000
-
-	//nolint:staticcheck // TODO replace with the new playwright API
-	el, err := page.WaitForSelector(sel, playwright.PageWaitForSelectorOptions{
-		Timeout: playwright.Float(timeout),
-	})
-
-	if err != nil {
-		return nil
-	}
-
-	if el == nil {
-		return nil
-	}
-
-	//nolint:staticcheck // TODO replace with the new playwright API
-	return el.Click()
+// scrollPage is the subset of playwright.Page used for scrolling the results feed.
+type scrollPage interface {
+	Evaluate(expression string, arg ...any) (any, error)
+	WaitForTimeout(timeout float64)
 }
 
+// scroll scrolls the results feed up to maxDepth times. It stops earlier
+// when maxEmptyScrolls consecutive scrolls leave its height unchanged.
 func scroll(ctx context.Context,
-	page playwright.Page,
+	page scrollPage,
 	maxDepth int,
+	maxEmptyScrolls int,
 	scrollSelector string,
 ) (int, error) {
 	expr := `async () => {
-		const el = document.querySelector("` + scrollSelector + `");
+		const el = document.querySelector(%s);
 		el.scrollTop = el.scrollHeight;
 
 		return new Promise((resolve, reject) => {
@@ -306,6 +730,7 @@ func scroll(ctx context.Context,
 	// Scroll to the bottom of the page.
 	waitTime := 100.
 	cnt := 0
+	empty := 0
 
 	const (
 		timeout  = 500
@@ -321,7 +746,7 @@ func scroll(ctx context.Context,
 		}
 
 		// Scroll to the bottom of the page.
-		scrollHeight, err := page.Evaluate(fmt.Sprintf(expr, waitTime2))
+		scrollHeight, err := page.Evaluate(fmt.Sprintf(expr, strconv.Quote(scrollSelector), waitTime2))
 		if err != nil {
 			return cnt, err
 		}
@@ -332,7 +757,13 @@ func scroll(ctx context.Context,
 		}
 
 		if height == currentScrollHeight {
-			break
+			empty++
+
+			if empty >= maxEmptyScrolls {
+				break
+			}
+		} else {
+			empty = 0
 		}
 
 		currentScrollHeight = height
@@ -354,4 +785,82 @@ func scroll(ctx context.Context,
 	}
 
 	return cnt, nil
-}
\ No newline at end of file
+}
+
+// feedGrowth keeps track of the number of items in the results feed
+// and decides when scrolling should stop in auto depth mode.
+type feedGrowth struct {
+	patience   int
+	maxScrolls int
+
+	scrolls   int
+	lastCount int
+	stale     int
+}
+
+// observe records the item count after a scroll and reports whether
+// scrolling should stop.
+func (f *feedGrowth) observe(count int) bool {
+	f.scrolls++
+
+	if count > f.lastCount {
+		f.lastCount = count
+		f.stale = 0
+	} else {
+		f.stale++
+	}
+
+	return f.stale >= f.patience || f.scrolls >= f.maxScrolls
+}
+
+func scrollUntilNoNewItems(ctx context.Context,
+	page scrollPage,
+	maxScrolls int,
+	patience int,
+	scrollSelector string,
+) (int, error) {
+	expr := `async () => {
+		const el = document.querySelector(%s);
+		el.scrollTop = el.scrollHeight;
+
+		return new Promise((resolve, reject) => {
+  			setTimeout(() => {
+    		resolve(el.querySelectorAll("div[jsaction]>a").length);
+  			}, %d);
+		});
+	}`
+
+	const (
+		timeout  = 500
+		maxWait2 = 2000
+	)
+
+	growth := feedGrowth{
+		patience:   patience,
+		maxScrolls: maxScrolls,
+	}
+
+	for {
+		waitTime := min(timeout*(growth.stale+1), maxWait2)
+
+		countI, err := page.Evaluate(fmt.Sprintf(expr, strconv.Quote(scrollSelector), waitTime))
+		if err != nil {
+			return growth.scrolls, err
+		}
+
+		count, ok := countI.(int)
+		if !ok {
+			return growth.scrolls, fmt.Errorf("feed item count is not an int")
+		}
+
+		if growth.observe(count) {
+			return growth.scrolls, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return growth.scrolls, nil
+		default:
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package gmaps_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// fakeFeedPage simulates a results feed that loads a batch of items
+// on every scroll until it is exhausted.
+type fakeFeedPage struct {
+	counts []int
+	calls  int
+}
+
+func (p *fakeFeedPage) Evaluate(string, ...any) (any, error) {
+	idx := min(p.calls, len(p.counts)-1)
+	p.calls++
+
+	return p.counts[idx], nil
+}
+
+func (p *fakeFeedPage) WaitForTimeout(float64) {}
+
+func Test_ScrollUntilNoNewItems(t *testing.T) {
+	tests := []struct {
+		name       string
+		counts     []int
+		patience   int
+		maxScrolls int
+		expected   int
+	}{
+		{
+			name:       "stops after patience scrolls without new items",
+			counts:     []int{7, 14, 21, 28, 28},
+			patience:   2,
+			maxScrolls: 100,
+			expected:   6,
+		},
+		{
+			name:       "patience of one stops on first stale scroll",
+			counts:     []int{7, 14, 14},
+			patience:   1,
+			maxScrolls: 100,
+			expected:   3,
+		},
+		{
+			name:       "a temporary pause does not stop scrolling",
+			counts:     []int{7, 7, 14, 21, 21, 21, 21},
+			patience:   3,
+			maxScrolls: 100,
+			expected:   7,
+		},
+		{
+			name:       "safety cap",
+			counts:     []int{7, 14, 21, 28, 35, 42, 49, 56},
+			patience:   3,
+			maxScrolls: 5,
+			expected:   5,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			page := &fakeFeedPage{counts: tc.counts}
+
+			scrolls, err := gmaps.ScrollUntilNoNewItems(context.Background(), page, tc.maxScrolls, tc.patience, "div[role='feed']")
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, scrolls)
+			require.Equal(t, tc.expected, page.calls)
+		})
+	}
+}
+
+func Test_ScrollMaxEmptyScrolls(t *testing.T) {
+	// the feed stops growing for one scroll before loading more places
+	heights := []int{1000, 1000, 2000, 3000, 3000, 3000, 3000}
+
+	tests := []struct {
+		name            string
+		maxEmptyScrolls int
+		expected        int
+	}{
+		{name: "default stops at the pause", maxEmptyScrolls: 0, expected: 2},
+		{name: "one", maxEmptyScrolls: 1, expected: 2},
+		{name: "two survives the pause", maxEmptyScrolls: 2, expected: 6},
+		{name: "three", maxEmptyScrolls: 3, expected: 7},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			page := &fakeFeedPage{counts: heights}
+
+			scrolls, err := gmaps.Scroll(context.Background(), page, 100, tc.maxEmptyScrolls, "div[role='feed']")
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, scrolls)
+		})
+	}
+
+	// maxDepth still caps the scrolls
+	page := &fakeFeedPage{counts: heights}
+
+	scrolls, err := gmaps.Scroll(context.Background(), page, 4, 3, "div[role='feed']")
+	require.NoError(t, err)
+	require.Equal(t, 4, scrolls)
+}
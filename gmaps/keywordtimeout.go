@@ -0,0 +1,124 @@
+package gmaps
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrKeywordTimeout is the error of the jobs of a keyword that ran out of
+// time, see KeywordTimeout. The jobs are skipped, not retried.
+var ErrKeywordTimeout = errors.New("keyword timeout exceeded")
+
+// KeywordTimeout caps the time spent on every keyword: its search and the
+// places it found must be done within the timeout from the start of the
+// search. Once the time is up, the search stops scrolling and the places
+// not visited yet are skipped, so a single slow keyword cannot hold the
+// run up.
+type KeywordTimeout struct {
+	timeout time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	deadlines map[string]time.Time
+	limited   map[string]bool
+}
+
+// NewKeywordTimeout returns a KeywordTimeout of timeout per keyword.
+func NewKeywordTimeout(timeout time.Duration) *KeywordTimeout {
+	return &KeywordTimeout{
+		timeout:   timeout,
+		now:       time.Now,
+		deadlines: make(map[string]time.Time),
+		limited:   make(map[string]bool),
+	}
+}
+
+// Start starts the clock of keyword and returns its deadline. A keyword
+// keeps the deadline of its first start, e.g. when its search is retried.
+func (k *KeywordTimeout) Start(keyword string) time.Time {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	deadline, ok := k.deadlines[keyword]
+	if !ok {
+		deadline = k.now().Add(k.timeout)
+		k.deadlines[keyword] = deadline
+	}
+
+	return deadline
+}
+
+// Expired reports whether the time of keyword is up, and records keyword
+// as time limited when it is. Keywords not started never expire.
+func (k *KeywordTimeout) Expired(keyword string) bool {
+	if k == nil {
+		return false
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	deadline, ok := k.deadlines[keyword]
+	if !ok || k.now().Before(deadline) {
+		return false
+	}
+
+	k.limited[keyword] = true
+
+	return true
+}
+
+// Limited returns the sorted keywords that ran out of time.
+func (k *KeywordTimeout) Limited() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	keywords := make([]string, 0, len(k.limited))
+
+	for keyword := range k.limited {
+		keywords = append(keywords, keyword)
+	}
+
+	sort.Strings(keywords)
+
+	return keywords
+}
+
+// GobEncode makes the jobs with a KeywordTimeout field encodable by the
+// database provider, which gob-encodes them. The clocks of the keywords are
+// in memory and cannot be shared through the database, so only jobs without
+// a KeywordTimeout can be encoded.
+func (k *KeywordTimeout) GobEncode() ([]byte, error) {
+	return nil, errors.New("a keyword timeout cannot be encoded")
+}
+
+// GobDecode is the counterpart of GobEncode.
+func (k *KeywordTimeout) GobDecode([]byte) error {
+	return errors.New("a keyword timeout cannot be decoded")
+}
+
+// withDeadline starts the clock of keyword and returns ctx with its
+// deadline. It returns ErrKeywordTimeout when the time is already up. A
+// nil KeywordTimeout returns ctx as is.
+func (k *KeywordTimeout) withDeadline(ctx context.Context, keyword string) (context.Context, context.CancelFunc, error) {
+	if k == nil {
+		return ctx, func() {}, nil
+	}
+
+	k.Start(keyword)
+
+	if k.Expired(keyword) {
+		return ctx, func() {}, ErrKeywordTimeout
+	}
+
+	k.mu.Lock()
+	deadline := k.deadlines[keyword]
+	k.mu.Unlock()
+
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+
+	return ctx, cancel, nil
+}
@@ -0,0 +1,107 @@
+package gmaps_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+type seedRecorder struct {
+	found map[string]int
+}
+
+func (r *seedRecorder) SeedDone(keyword string, places int) {
+	r.found[keyword] += places
+}
+
+func Test_KeywordTimeout(t *testing.T) {
+	now := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	k := gmaps.NewKeywordTimeout(time.Minute)
+	gmaps.SetKeywordTimeoutClock(k, func() time.Time { return now })
+
+	require.False(t, k.Expired("cafe"), "a keyword not started does not expire")
+
+	require.Equal(t, now.Add(time.Minute), k.Start("cafe"))
+
+	now = now.Add(30 * time.Second)
+
+	// a retried search keeps its deadline
+	require.Equal(t, now.Add(30*time.Second), k.Start("cafe"))
+	require.False(t, k.Expired("cafe"))
+
+	now = now.Add(30 * time.Second)
+
+	require.True(t, k.Expired("cafe"))
+	require.False(t, k.Expired("bakery"))
+	require.Equal(t, []string{"cafe"}, k.Limited())
+}
+
+func Test_KeywordTimeoutSkipsPlaces(t *testing.T) {
+	const (
+		u1 = "https://www.google.com/maps/place/a/data=!4m2!3m1!1s0x14e732fd76f0d90d:0xe5415928d6702b47"
+		u2 = "https://www.google.com/maps/place/b/data=!4m2!3m1!1s0x14e732fd76f0d90d:0xe5415928d6702b48"
+	)
+
+	now := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	k := gmaps.NewKeywordTimeout(time.Minute)
+	gmaps.SetKeywordTimeoutClock(k, func() time.Time { return now })
+
+	seeds := &seedRecorder{found: map[string]int{}}
+	job := gmaps.NewGmapJob("line-1", "en", "cafe in athens", 1, false, "", 0,
+		gmaps.WithKeywordTimeout(k), gmaps.WithSeedReporter(seeds))
+
+	k.Start(job.Keyword)
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(
+		`<div role="feed"><div jsaction><a href="` + u1 + `"></a></div><div jsaction><a href="` + u2 + `"></a></div></div>`))
+	require.NoError(t, err)
+
+	_, next, err := job.Process(context.Background(), &scrapemate.Response{Document: doc})
+	require.NoError(t, err)
+	require.Len(t, next, 2)
+
+	// the places take longer than the keyword is allowed
+	now = now.Add(2 * time.Minute)
+
+	for _, n := range next {
+		place, ok := n.(*gmaps.PlaceJob)
+		require.True(t, ok)
+
+		resp := place.BrowserActions(context.Background(), nil)
+		require.ErrorIs(t, resp.Error, gmaps.ErrKeywordTimeout)
+		require.True(t, place.DoCheckResponse(&resp), "places out of time are not retried")
+
+		result, more, err := place.Process(context.Background(), &resp)
+		require.NoError(t, err)
+		require.Nil(t, result)
+		require.Empty(t, more)
+		require.False(t, place.UseInResults())
+	}
+
+	require.Equal(t, []string{"cafe in athens"}, k.Limited())
+
+	// a search of the keyword out of time finds nothing and is not retried
+	resp := job.BrowserActions(context.Background(), nil)
+	require.ErrorIs(t, resp.Error, gmaps.ErrKeywordTimeout)
+	require.True(t, job.DoCheckResponse(&resp))
+
+	_, next, err = job.Process(context.Background(), &resp)
+	require.NoError(t, err)
+	require.Empty(t, next)
+
+	// a search cut short by the deadline counts the places it found but
+	// skips them
+	_, next, err = job.Process(context.Background(), &scrapemate.Response{Document: doc})
+	require.NoError(t, err)
+	require.Empty(t, next)
+	require.Equal(t, 4, seeds.found["cafe in athens"])
+}
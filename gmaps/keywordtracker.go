@@ -0,0 +1,56 @@
+package gmaps
+
+// KeywordSearch describes a search that is done: its keyword and the
+// parameters it was run with.
+type KeywordSearch struct {
+	Keyword  string  `json:"-"`
+	InputID  string  `json:"input_id"`
+	LangCode string  `json:"lang"`
+	Country  string  `json:"country,omitempty"`
+	Lat      float64 `json:"search_lat,omitempty"`
+	Lon      float64 `json:"search_lon,omitempty"`
+	Zoom     int     `json:"search_zoom,omitempty"`
+	Depth    int     `json:"depth"`
+	Found    int     `json:"found"`
+}
+
+// KeywordTracker follows the places of every keyword, so it knows when all
+// of them are done. SearchDone is called when the search of a keyword is
+// done with the number of place jobs it emitted, and PlaceSkipped for
+// every one of them that ends without a result. The others end with an
+// Entry of the keyword.
+type KeywordTracker interface {
+	SearchDone(search KeywordSearch, places int)
+	PlaceSkipped(keyword string)
+}
+
+// WithKeywordTracker makes the job and its place jobs report their
+// progress to t.
+func WithKeywordTracker(t KeywordTracker) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.KeywordTracker = t
+	}
+}
+
+// WithPlaceJobKeywordTracker makes the job tell t when the place is
+// skipped, see KeywordTracker.
+func WithPlaceJobKeywordTracker(t KeywordTracker) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.KeywordTracker = t
+	}
+}
+
+// keywordSearch returns the search of the job once it found found places.
+func (j *GmapJob) keywordSearch(found int) KeywordSearch {
+	return KeywordSearch{
+		Keyword:  j.Keyword,
+		InputID:  j.ID,
+		LangCode: j.LangCode,
+		Country:  j.Country,
+		Lat:      j.Lat,
+		Lon:      j.Lon,
+		Zoom:     j.Zoom,
+		Depth:    j.MaxDepth,
+		Found:    found,
+	}
+}
@@ -0,0 +1,56 @@
+package gmaps
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// cidURL returns the canonical link of the place with the given decimal
+// CID. Unlike the /maps/place/ links it does not change when the name or
+// the address of the place do. It is empty when the CID is unknown.
+func cidURL(cid string) string {
+	if cid == "" {
+		return ""
+	}
+
+	return "https://maps.google.com/?cid=" + url.QueryEscape(cid)
+}
+
+// shareURL returns the link that opens the place in Google Maps: the CID
+// link, see cidURL, or else a search of its coordinates. It is empty when
+// neither is known.
+func shareURL(cid string, lat, lon float64) string {
+	if cid != "" {
+		return cidURL(cid)
+	}
+
+	if lat == 0 && lon == 0 {
+		return ""
+	}
+
+	return "https://www.google.com/maps/search/?api=1&query=" + coordinates(lat, lon)
+}
+
+// directionsURL returns the link that opens the directions to the
+// coordinates in Google Maps, or an empty string when they are unknown.
+func directionsURL(lat, lon float64) string {
+	if lat == 0 && lon == 0 {
+		return ""
+	}
+
+	return "https://www.google.com/maps/dir/?api=1&destination=" + coordinates(lat, lon)
+}
+
+// coordinates formats lat and lon for the query of a link. Digits, dots,
+// minus signs and the comma need no escaping.
+func coordinates(lat, lon float64) string {
+	return strconv.FormatFloat(lat, 'f', -1, 64) + "," + strconv.FormatFloat(lon, 'f', -1, 64)
+}
+
+// setMapLinks sets the CID, share and directions links of entry from its
+// CID and coordinates.
+func setMapLinks(entry *Entry) {
+	entry.CIDURL = cidURL(entry.Cid)
+	entry.ShareURL = shareURL(entry.Cid, entry.Latitude, entry.Longtitude)
+	entry.DirectionsURL = directionsURL(entry.Latitude, entry.Longtitude)
+}
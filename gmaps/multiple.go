@@ -8,7 +8,17 @@ import (
 	olc "github.com/google/open-location-code/go"
 )
 
-func ParseSearchResults(raw []byte) ([]*Entry, error) {
+// ParseSearchResults parses the places of a fast mode search response.
+//
+// The search response carries less than a place page, so fast mode has a
+// fixed set of fields. Title, categories, address, rating and review count
+// are always set, and items without a title are skipped. Website, phone,
+// coordinates, plus code, status, timezone and IDs are set when present.
+// Opening hours, about and images make the output much larger and are only
+// parsed with details.
+func ParseSearchResults(raw []byte, details ...bool) ([]*Entry, error) {
+	withDetails := len(details) == 1 && details[0]
+
 	var data []any
 	if err := json.Unmarshal(raw, &data); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
@@ -40,10 +50,18 @@ func ParseSearchResults(raw []byte) ([]*Entry, error) {
 
 		var entry Entry
 
-		entry.ID = getNthElementAndCast[string](business, 0)
 		entry.Title = getNthElementAndCast[string](business, 11)
+		if entry.Title == "" {
+			continue
+		}
+
+		entry.ID = getNthElementAndCast[string](business, 0)
 		entry.Categories = toStringSlice(getNthElementAndCast[[]any](business, 13))
-		entry.WebSite = getNthElementAndCast[string](business, 7, 0)
+
+		if len(entry.Categories) > 0 {
+			entry.Category = entry.Categories[0]
+		}
+		entry.WebSite = normalizeWebsite(getNthElementAndCast[string](business, 7, 0))
 
 		entry.ReviewRating = getNthElementAndCast[float64](business, 4, 7)
 		entry.ReviewCount = int(getNthElementAndCast[float64](business, 4, 8))
@@ -64,16 +82,33 @@ func ParseSearchResults(raw []byte) ([]*Entry, error) {
 			return sb.String()
 		}()
 
+		if entry.Address == "" {
+			entry.Address = strings.TrimSpace(
+				strings.TrimPrefix(getNthElementAndCast[string](business, 18), entry.Title+","),
+			)
+		}
+
 		entry.Latitude = getNthElementAndCast[float64](business, 9, 2)
 		entry.Longtitude = getNthElementAndCast[float64](business, 9, 3)
 		entry.Phone = strings.ReplaceAll(getNthElementAndCast[string](business, 178, 0, 0), " ", "")
-		entry.OpenHours = getHours(business)
 		entry.Status = getNthElementAndCast[string](business, 34, 4, 4)
+		entry.BusinessStatus = businessStatus(business)
 		entry.Timezone = getNthElementAndCast[string](business, 30)
 		entry.DataID = getNthElementAndCast[string](business, 10)
+		entry.Cid = cidFromDataID(entry.DataID)
+		entry.PlaceID = placeID(&entry)
+		setMapLinks(&entry)
+		entry.Claimed = isClaimed(business)
 
 		entry.PlusCode = olc.Encode(entry.Latitude, entry.Longtitude, 10)
 
+		if withDetails {
+			entry.OpenHours = getHours(business)
+			entry.About = getAbout(business)
+			entry.Images = getImages(business)
+			entry.PhotoCount, entry.VideoCount = getMediaCounts(business)
+		}
+
 		entries = append(entries, &entry)
 	}
 
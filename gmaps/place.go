@@ -2,6 +2,7 @@ package gmaps
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -11,9 +12,16 @@ import (
 	"github.com/gosom/scrapemate"
 	"github.com/playwright-community/playwright-go"
 
+	"github.com/gosom/google-maps-scraper/deduper"
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/limiter"
 )
 
+// MetaProxy is the key of scrapemate.Response.Meta a fetcher sets to the
+// proxy the page was fetched through, copied to Entry.Proxy. The fetchers
+// of scrapemate do not tell which of their proxies they used.
+const MetaProxy = "proxy"
+
 type PlaceJobOptions func(*PlaceJob)
 
 type PlaceJob struct {
@@ -23,6 +31,60 @@ type PlaceJob struct {
 	ExtractEmail        bool
 	ExitMonitor         exiter.Exiter
 	ExtractExtraReviews bool
+	KeywordLimiter      limiter.Limiter
+	KeywordTimeout      *KeywordTimeout
+
+	// WaitSelector and WaitTimeout control how long the job waits for the
+	// place page after navigating to it, see WithPlaceJobWait.
+	WaitSelector string
+	WaitTimeout  time.Duration
+
+	// ScreenshotsDir enables a screenshot of the place page, see
+	// WithPlaceJobScreenshots.
+	ScreenshotsDir string
+	ScreenshotsMax int
+
+	// RawJSONDir enables saving the raw place JSON, see WithPlaceJobRawJSON.
+	RawJSONDir string
+	RawJSONMax int
+
+	// EnrichWebsite searches the web for places without a website,
+	// see WithPlaceJobWebsiteEnrichment.
+	EnrichWebsite bool
+
+	// ExpandNearby is the number of hops of nearby searches left:
+	// when > 0 a search for the place's category around its location follows.
+	ExpandNearby   int
+	NearbyMaxDepth int
+	NearbyZoom     int
+	Deduper        deduper.Deduper
+
+	// ExpandRelated is the number of hops of related places left: when > 0
+	// the places of the "People also search for" section are scraped too.
+	ExpandRelated int
+
+	// FailureReporter is told when the place fails, see
+	// WithPlaceJobFailureReporter.
+	FailureReporter FailureReporter
+
+	// KeywordTracker is told when the place is skipped, see
+	// WithPlaceJobKeywordTracker.
+	KeywordTracker KeywordTracker
+
+	// Trace identifies the run and the seed of the job, see
+	// WithPlaceJobTrace.
+	Trace Trace
+
+	// settings are passed on to the jobs it creates, see
+	// WithPlaceJobSettings. They are not exported, so they are not encoded
+	// with the job.
+	settings *Settings
+
+	// Keyword and the Search* fields describe the search that found the place.
+	Keyword    string
+	SearchLat  float64
+	SearchLon  float64
+	SearchZoom int
 }
 
 func NewPlaceJob(parentID, langCode, u string, extractEmail, extraExtraReviews bool, opts ...PlaceJobOptions) *PlaceJob {
@@ -54,38 +116,232 @@ func NewPlaceJob(parentID, langCode, u string, extractEmail, extraExtraReviews b
 	return &job
 }
 
+// WithPlaceJobSettings sets the settings of the run of the job, passed on
+// to the jobs it creates, see Settings.
+func WithPlaceJobSettings(s *Settings) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.settings = s
+	}
+}
+
+// WithPlaceJobCountry sets Google's gl parameter on the place URL.
+func WithPlaceJobCountry(code string) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		if code != "" {
+			j.URLParams["gl"] = code
+		}
+	}
+}
+
+// WithPlaceJobFailureReporter makes the job report its failures to r,
+// including the places that failed permanently and get an error row.
+func WithPlaceJobFailureReporter(r FailureReporter) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.FailureReporter = r
+	}
+}
+
+// WithPlaceJobSource records the keyword, coordinates and zoom of the search
+// that found the place. They are copied to the resulting Entry.
+func WithPlaceJobSource(keyword string, lat, lon float64, zoom int) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Keyword = keyword
+		j.SearchLat = lat
+		j.SearchLon = lon
+		j.SearchZoom = zoom
+	}
+}
+
+// WithPlaceJobKeywordLimiter skips the place when l has no slots left
+// for the job's keyword.
+func WithPlaceJobKeywordLimiter(l limiter.Limiter) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.KeywordLimiter = l
+	}
+}
+
+// WithPlaceJobKeywordTimeout skips the place when the job's keyword ran
+// out of the time of k, see KeywordTimeout.
+func WithPlaceJobKeywordTimeout(k *KeywordTimeout) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.KeywordTimeout = k
+	}
+}
+
+// WithPlaceJobExpandNearby searches for places of the same category around
+// the scraped place, for up to hops levels of follow-up searches.
+// dedup prevents searching the same category in the same area twice.
+func WithPlaceJobExpandNearby(hops, maxDepth, zoom int, dedup deduper.Deduper) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.ExpandNearby = hops
+		j.NearbyMaxDepth = maxDepth
+		j.NearbyZoom = zoom
+		j.Deduper = dedup
+	}
+}
+
+// WithPlaceJobExpandRelated scrapes the places of the "People also search
+// for" section of the place page too, for up to hops levels of related
+// places. dedup prevents scraping the same place twice.
+func WithPlaceJobExpandRelated(hops int, dedup deduper.Deduper) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.ExpandRelated = hops
+		j.Deduper = dedup
+	}
+}
+
+// WithPlaceJobWait makes the job wait up to timeout for an element matching
+// selector (e.g. the place title) after navigating to the place, instead of
+// only waiting for the DOM to load. An empty selector keeps the DOM wait and
+// a zero timeout means DefaultWaitTimeout.
+func WithPlaceJobWait(selector string, timeout time.Duration) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.WaitSelector = selector
+		j.WaitTimeout = timeout
+	}
+}
+
+// WithPlaceJobScreenshots saves a screenshot of the place page to dir
+// before extracting its data. At most maxShots screenshots are saved to dir
+// by the jobs of the settings of the job, see Settings.Artifacts, 0 means no
+// limit.
+func WithPlaceJobScreenshots(dir string, maxShots int) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.ScreenshotsDir = dir
+		j.ScreenshotsMax = maxShots
+	}
+}
+
+// WithPlaceJobRawJSON saves the raw JSON of the place to dir before parsing
+// it. At most maxFiles files are saved to dir by the jobs of the settings of
+// the job, see Settings.Artifacts, 0 means no limit.
+func WithPlaceJobRawJSON(dir string, maxFiles int) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.RawJSONDir = dir
+		j.RawJSONMax = maxFiles
+	}
+}
+
+// WithPlaceJobWebsiteEnrichment makes a place without a website on Google
+// Maps followed by a WebsiteSearchJob, which guesses it from a web search.
+func WithPlaceJobWebsiteEnrichment() PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.EnrichWebsite = true
+	}
+}
+
 func WithPlaceJobExitMonitor(exitMonitor exiter.Exiter) PlaceJobOptions {
 	return func(j *PlaceJob) {
 		j.ExitMonitor = exitMonitor
 	}
 }
 
-func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+func (j *PlaceJob) Process(ctx context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
 		resp.Body = nil
 		resp.Meta = nil
 	}()
 
-	raw, ok := resp.Meta["json"].([]byte)
+	ctx = withCorrelationID(ctx, j.Trace.ID())
+
+	if j.KeywordLimiter != nil && j.KeywordLimiter.Reached(j.Keyword) {
+		return j.skip()
+	}
+
+	if errors.Is(resp.Error, ErrRequestBudget) || errors.Is(resp.Error, ErrKeywordTimeout) {
+		return j.skip()
+	}
+
+	if resp.Error != nil {
+		reportFailure(ctx, j.FailureReporter, j.GetURL(), j.ParentID, resp.Error)
+
+		if !IsPermanentError(resp.Error) {
+			return nil, nil, resp.Error
+		}
+
+		return j.errorEntry(resp.Error), nil, nil
+	}
+
+	raw, ok := metaBytes(resp.Meta["json"])
 	if !ok {
-		return nil, nil, fmt.Errorf("could not convert to []byte")
+		err := fmt.Errorf("could not convert to []byte")
+		reportFailure(ctx, j.FailureReporter, j.GetURL(), j.ParentID, err)
+
+		return nil, nil, err
+	}
+
+	if j.RawJSONDir != "" {
+		if _, err := j.settings.saveRawJSON(raw, j.RawJSONDir, j.RawJSONMax, j.GetURL(), j.ID); err != nil {
+			log := scrapemate.GetLoggerFromContext(ctx)
+			log.Error("saving raw json failed", "url", j.GetURL(), "error", err)
+		}
 	}
 
 	entry, err := EntryFromJSON(raw)
 	if err != nil {
+		reportFailure(ctx, j.FailureReporter, j.GetURL(), j.ParentID, err)
+
 		return nil, nil, err
 	}
 
+	if j.KeywordLimiter != nil && !j.KeywordLimiter.Allow(j.Keyword) {
+		return j.skip()
+	}
+
 	entry.ID = j.ParentID
+	entry.SourceKeyword = j.Keyword
+	entry.SearchLat = j.SearchLat
+	entry.SearchLon = j.SearchLon
+	entry.SearchZoom = j.SearchZoom
+	entry.ScrapedAt = time.Now().UTC().Format(time.RFC3339)
+	entry.CorrelationID = j.Trace.ID()
+	entry.Proxy, _ = resp.Meta[MetaProxy].(string)
 
 	if entry.Link == "" {
 		entry.Link = j.GetURL()
 	}
 
-	allReviewsRaw, ok := resp.Meta["reviews_raw"].(fetchReviewsResponse)
-	if ok && len(allReviewsRaw.pages) > 0 {
-		entry.AddExtraReviews(allReviewsRaw.pages)
+	if entry.DataID == "" {
+		entry.DataID = dataIDFromURL(j.GetURL())
+	}
+
+	if entry.Cid == "" {
+		entry.Cid = cidFromDataID(entry.DataID)
+	}
+
+	entry.WebSite = j.settings.website(entry.WebSite)
+	entry.PlaceID = placeID(&entry)
+	setMapLinks(&entry)
+
+	if pages := metaPages(resp.Meta["reviews_raw"]); len(pages) > 0 {
+		entry.AddExtraReviews(pages)
+	}
+
+	var next []scrapemate.IJob
+
+	// the nearby search and the related places must be counted before the
+	// place is completed, otherwise the exit monitor may stop the run in
+	// between
+	if nearby := j.nearbyJob(ctx, &entry); nearby != nil {
+		next = append(next, nearby)
+	}
+
+	next = append(next, j.relatedJobs(ctx, &entry)...)
+
+	if j.EnrichWebsite && entry.WebSite == "" {
+		opts := []WebsiteSearchJobOptions{}
+		if j.ExitMonitor != nil {
+			opts = append(opts, WithWebsiteSearchJobExitMonitor(j.ExitMonitor))
+		}
+
+		if j.settings != nil {
+			opts = append(opts, WithWebsiteSearchJobSettings(j.settings))
+		}
+
+		j.UsageInResultststs = false
+
+		return nil, append(next, NewWebsiteSearchJob(j.ID, &entry, opts...)), nil
 	}
 
 	if j.ExtractEmail && entry.IsWebsiteValidForEmail() {
@@ -94,48 +350,246 @@ func (j *PlaceJob) Process(_ context.Context, resp *scrapemate.Response) (any, [
 			opts = append(opts, WithEmailJobExitMonitor(j.ExitMonitor))
 		}
 
+		if j.settings != nil {
+			opts = append(opts, WithEmailJobSettings(j.settings))
+		}
+
 		emailJob := NewEmailJob(j.ID, &entry, opts...)
 
 		j.UsageInResultststs = false
 
-		return nil, []scrapemate.IJob{emailJob}, nil
+		return nil, append(next, emailJob), nil
 	} else if j.ExitMonitor != nil {
 		j.ExitMonitor.IncrPlacesCompleted(1)
 	}
 
-	return &entry, nil, err
+	return &entry, next, err
+}
+
+// nearbyJob returns a search for the entry's category around its location,
+// or nil when there are no hops left or the area was already searched.
+func (j *PlaceJob) nearbyJob(ctx context.Context, entry *Entry) *GmapJob {
+	if j.ExpandNearby <= 0 || entry.Category == "" || (entry.Latitude == 0 && entry.Longtitude == 0) {
+		return nil
+	}
+
+	// ~100m precision, so neighbours don't trigger the same search again
+	geo := fmt.Sprintf("%.3f,%.3f", entry.Latitude, entry.Longtitude)
+
+	if j.Deduper != nil && !j.Deduper.AddIfNotExists(ctx, "nearby|"+strings.ToLower(entry.Category)+"|"+geo) {
+		return nil
+	}
+
+	opts := []GmapJobOptions{
+		WithExpandNearby(j.ExpandNearby-1, j.NearbyZoom),
+	}
+
+	if j.Deduper != nil {
+		opts = append(opts, WithDeduper(j.Deduper))
+	}
+
+	if j.ExitMonitor != nil {
+		opts = append(opts, WithExitMonitor(j.ExitMonitor))
+
+		j.ExitMonitor.IncrSeedCount(1)
+	}
+
+	if j.ExtractExtraReviews {
+		opts = append(opts, WithExtraReviews())
+	}
+
+	if j.Trace != (Trace{}) {
+		opts = append(opts, WithTrace(j.Trace))
+	}
+
+	if country := j.URLParams["gl"]; country != "" {
+		opts = append(opts, WithCountry(country))
+	}
+
+	if j.WaitSelector != "" || j.WaitTimeout > 0 {
+		opts = append(opts, WithWait(j.WaitSelector, j.WaitTimeout))
+	}
+
+	if j.ScreenshotsDir != "" {
+		opts = append(opts, WithScreenshots(j.ScreenshotsDir, j.ScreenshotsMax))
+	}
+
+	if j.RawJSONDir != "" {
+		opts = append(opts, WithRawJSON(j.RawJSONDir, j.RawJSONMax))
+	}
+
+	if j.settings != nil {
+		opts = append(opts, WithSettings(j.settings))
+	}
+
+	return NewGmapJob("", j.URLParams["hl"], entry.Category, j.NearbyMaxDepth, j.ExtractEmail, geo, j.NearbyZoom, opts...)
+}
+
+// errorEntry returns the row written for a place that failed permanently.
+func (j *PlaceJob) errorEntry(err error) *Entry {
+	entry := Entry{
+		ID:            j.ParentID,
+		Link:          j.GetURL(),
+		DataID:        dataIDFromURL(j.GetURL()),
+		SourceKeyword: j.Keyword,
+		SearchLat:     j.SearchLat,
+		SearchLon:     j.SearchLon,
+		SearchZoom:    j.SearchZoom,
+		ScrapedAt:     time.Now().UTC().Format(time.RFC3339),
+		CorrelationID: j.Trace.ID(),
+		Error:         err.Error(),
+	}
+
+	entry.Cid = cidFromDataID(entry.DataID)
+	entry.PlaceID = placeID(&entry)
+
+	if j.ExitMonitor != nil {
+		j.ExitMonitor.IncrPlacesCompleted(1)
+	}
+
+	return &entry
+}
+
+// skip drops the place without producing a result.
+func (j *PlaceJob) skip() (any, []scrapemate.IJob, error) {
+	j.UsageInResultststs = false
+
+	if j.ExitMonitor != nil {
+		j.ExitMonitor.IncrPlacesCompleted(1)
+	}
+
+	if j.KeywordTracker != nil {
+		j.KeywordTracker.PlaceSkipped(j.Keyword)
+	}
+
+	return nil, nil, nil
+}
+
+// DoCheckResponse accepts the responses of permanent failures, of an
+// exhausted request budget and of keywords out of time as well, so they are
+// not retried.
+func (j *PlaceJob) DoCheckResponse(resp *scrapemate.Response) bool {
+	if IsPermanentError(resp.Error) || errors.Is(resp.Error, ErrRequestBudget) || errors.Is(resp.Error, ErrKeywordTimeout) {
+		return true
+	}
+
+	return j.Job.DoCheckResponse(resp)
+}
+
+// ProcessOnFetchError is true so that Process can write a row for the
+// places that failed permanently. Other failures are returned as errors.
+func (j *PlaceJob) ProcessOnFetchError() bool {
+	return true
 }
 
 func (j *PlaceJob) BrowserActions(ctx context.Context, page playwright.Page) scrapemate.Response {
 	var resp scrapemate.Response
 
-	pageResponse, err := page.Goto(j.GetURL(), playwright.PageGotoOptions{
-		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
-	})
+	// the run was stopped before the job started
+	if err := ctx.Err(); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	ctx, cancel := j.settings.drainContext(ctx)
+	defer cancel()
+
+	ctx = withCorrelationID(ctx, j.Trace.ID())
+
+	// no need to visit the page if the keyword already has enough places
+	if j.KeywordLimiter != nil && j.KeywordLimiter.Reached(j.Keyword) {
+		return resp
+	}
+
+	if j.KeywordTimeout.Expired(j.Keyword) {
+		resp.Error = ErrKeywordTimeout
+
+		return resp
+	}
+
+	if !allowRequest(j.ExitMonitor) {
+		resp.Error = ErrRequestBudget
+
+		return resp
+	}
+
+	if err := j.settings.waitPlaceDelay(ctx); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	t := j.settings.throttle()
+
+	release, err := t.Acquire(ctx)
 	if err != nil {
 		resp.Error = err
 
 		return resp
 	}
 
-	if err = clickRejectCookiesIfRequired(page); err != nil {
+	defer release()
+
+	if err := j.settings.applyStorageState(page); err != nil {
 		resp.Error = err
 
 		return resp
 	}
 
-	const defaultTimeout = 5000
+	if err := j.settings.applyBlockedResources(page); err != nil {
+		resp.Error = err
 
-	err = page.WaitForURL(page.URL(), playwright.PageWaitForURLOptions{
-		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
-		Timeout:   playwright.Float(defaultTimeout),
+		return resp
+	}
+
+	if err := j.settings.warmUp(page, j.URLParams["hl"]); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	pageResponse, err := page.Goto(j.GetURL(), playwright.PageGotoOptions{
+		WaitUntil: j.settings.waitUntilState(playwright.WaitUntilStateDomcontentloaded),
 	})
 	if err != nil {
+		if ctx.Err() == nil {
+			t.ObserveFailure()
+		}
+
+		resp.Error = err
+
+		return resp
+	}
+
+	if isBlockedPage(page, pageResponse.Status()) {
+		t.Observe(true)
+
+		resp.Error = ErrBlocked
+
+		return resp
+	}
+
+	if err = j.settings.handleConsent(page); err != nil {
 		resp.Error = err
 
 		return resp
 	}
 
+	if err = j.settings.waitForPage(page, j.WaitSelector, j.WaitTimeout); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	if j.ScreenshotsDir != "" {
+		// a failed screenshot should not lose the place
+		if _, err := j.settings.takeScreenshot(page, j.ScreenshotsDir, j.ScreenshotsMax, j.GetURL(), j.ID); err != nil {
+			log := scrapemate.GetLoggerFromContext(ctx)
+			log.Error("screenshot failed", "url", j.GetURL(), "error", err)
+		}
+	}
+
 	resp.URL = pageResponse.URL()
 	resp.StatusCode = pageResponse.Status()
 	resp.Headers = make(http.Header, len(pageResponse.Headers()))
@@ -144,7 +598,30 @@ func (j *PlaceJob) BrowserActions(ctx context.Context, page playwright.Page) scr
 		resp.Headers.Add(k, v)
 	}
 
-	raw, err := j.extractJSON(page)
+	err = verifyPlacePage(ctx, page, j.settings.placeMarker(), waitTimeout(j.WaitTimeout))
+
+	var raw []byte
+
+	if err == nil {
+		raw, err = j.extractJSON(page)
+	}
+
+	if errors.Is(err, ErrPlaceNotFound) {
+		// the data may come with the rest of the page: read it again once
+		// the title of the place is there
+		if _, ok := matchSelector(ctx, page, "place", j.settings.placeSelectors(), waitTimeout(j.WaitTimeout)); ok {
+			raw, err = j.extractJSON(page)
+		}
+	}
+
+	// a run of places without data is what a soft block looks like
+	switch {
+	case errors.Is(err, ErrPlaceNotFound), errors.Is(err, ErrNotPlacePage):
+		t.Observe(true)
+	case err == nil:
+		t.Observe(false)
+	}
+
 	if err != nil {
 		resp.Error = err
 
@@ -173,19 +650,50 @@ func (j *PlaceJob) BrowserActions(ctx context.Context, page playwright.Page) scr
 				return resp
 			}
 
-			resp.Meta["reviews_raw"] = reviewData
+			resp.Meta["reviews_raw"] = reviewData.pages
 		}
 	}
 
 	return resp
 }
 
+type placePage interface {
+	selectorPage
+	URL() string
+}
+
+// verifyPlacePage checks that page is a place page before its data is read:
+// that its URL is a /maps/place/ URL and that it has an element matching
+// marker, waited for up to timeout, when set. Otherwise it returns
+// ErrNotPlacePage, so that a page without place data fails at once instead
+// of being read again.
+func verifyPlacePage(ctx context.Context, page placePage, marker string, timeout time.Duration) error {
+	if u := page.URL(); !strings.Contains(u, "/maps/place/") {
+		return fmt.Errorf("%w: %s", ErrNotPlacePage, u)
+	}
+
+	if marker == "" {
+		return nil
+	}
+
+	if _, ok := matchSelector(ctx, page, "place marker", []string{marker}, timeout); !ok {
+		return fmt.Errorf("%w: %s has no %q", ErrNotPlacePage, page.URL(), marker)
+	}
+
+	return nil
+}
+
 func (j *PlaceJob) extractJSON(page playwright.Page) ([]byte, error) {
 	rawI, err := page.Evaluate(js)
 	if err != nil {
 		return nil, err
 	}
 
+	// the page loaded but has no place data
+	if rawI == nil {
+		return nil, ErrPlaceNotFound
+	}
+
 	raw, ok := rawI.(string)
 	if !ok {
 		return nil, fmt.Errorf("could not convert to string")
@@ -207,6 +715,17 @@ func (j *PlaceJob) getReviewCount(data []byte) int {
 	return tmpEntry.ReviewCount
 }
 
+// GetCacheKey returns the key of the place page in the cache. Pages fetched
+// with the extra reviews are cached separately.
+func (j *PlaceJob) GetCacheKey() string {
+	key := j.Job.GetCacheKey()
+	if j.ExtractExtraReviews {
+		key += ":reviews"
+	}
+
+	return j.settings.cacheKey(key)
+}
+
 func (j *PlaceJob) UseInResults() bool {
 	return j.UsageInResultststs
 }
@@ -0,0 +1,488 @@
+package gmaps_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/deduper"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/limiter"
+)
+
+func Test_PlaceJobKeywordLimit(t *testing.T) {
+	raw := loadPlaceFixture(t, "restaurant")
+	l := limiter.New(2)
+
+	process := func(keyword string) bool {
+		job := gmaps.NewPlaceJob("seed", "en", "https://www.google.com/maps/place/x", false, false,
+			gmaps.WithPlaceJobSource(keyword, 0, 0, 0),
+			gmaps.WithPlaceJobKeywordLimiter(l),
+		)
+
+		resp := scrapemate.Response{Meta: map[string]any{"json": raw}}
+
+		data, next, err := job.Process(context.Background(), &resp)
+		require.NoError(t, err)
+		require.Empty(t, next)
+
+		if data == nil {
+			require.False(t, job.UseInResults())
+
+			return false
+		}
+
+		require.True(t, job.UseInResults())
+
+		return true
+	}
+
+	require.True(t, process("cafe"))
+	require.True(t, process("cafe"))
+	require.False(t, process("cafe"))
+
+	// a different keyword is capped independently
+	require.True(t, process("dentist"))
+	require.True(t, process("dentist"))
+	require.False(t, process("dentist"))
+}
+
+func Test_PlaceJobProxy(t *testing.T) {
+	job := gmaps.NewPlaceJob("seed", "en", "https://www.google.com/maps/place/x", false, false)
+
+	resp := scrapemate.Response{Meta: map[string]any{
+		"json":          loadPlaceFixture(t, "restaurant"),
+		gmaps.MetaProxy: "us-east",
+	}}
+
+	data, _, err := job.Process(context.Background(), &resp)
+	require.NoError(t, err)
+
+	entry, ok := data.(*gmaps.Entry)
+	require.True(t, ok)
+	require.Equal(t, "us-east", entry.Proxy)
+
+	idx := slices.Index(entry.CsvHeaders(), "proxy")
+	require.NotEqual(t, -1, idx)
+	require.Equal(t, "us-east", entry.CsvRow()[idx])
+
+	// the fetchers of scrapemate do not tell the proxy
+	resp = scrapemate.Response{Meta: map[string]any{"json": loadPlaceFixture(t, "restaurant")}}
+
+	data, _, err = job.Process(context.Background(), &resp)
+	require.NoError(t, err)
+	require.Empty(t, data.(*gmaps.Entry).Proxy)
+}
+
+func Test_PlaceJobProvenance(t *testing.T) {
+	job := gmaps.NewPlaceJob("seed", "en", "https://www.google.com/maps/place/x", false, false,
+		gmaps.WithPlaceJobSource("cafe in athens", 37.9838, 23.7275, 15),
+	)
+
+	resp := scrapemate.Response{Meta: map[string]any{"json": loadPlaceFixture(t, "restaurant")}}
+
+	data, _, err := job.Process(context.Background(), &resp)
+	require.NoError(t, err)
+
+	entry, ok := data.(*gmaps.Entry)
+	require.True(t, ok)
+
+	require.Equal(t, "cafe in athens", entry.SourceKeyword)
+	require.InDelta(t, 37.9838, entry.SearchLat, 0.000001)
+	require.InDelta(t, 23.7275, entry.SearchLon, 0.000001)
+	require.Equal(t, 15, entry.SearchZoom)
+
+	scrapedAt, err := time.Parse(time.RFC3339, entry.ScrapedAt)
+	require.NoError(t, err)
+	require.Equal(t, time.UTC, scrapedAt.Location())
+	require.WithinDuration(t, time.Now(), scrapedAt, time.Minute)
+
+	require.Len(t, entry.CsvRow(), len(entry.CsvHeaders()))
+}
+
+func Test_PlaceJobExpandNearby(t *testing.T) {
+	raw := loadPlaceFixture(t, "restaurant")
+
+	entry, err := gmaps.EntryFromJSON(raw)
+	require.NoError(t, err)
+	require.NotEmpty(t, entry.Category)
+
+	dedup := deduper.New()
+
+	process := func(hops int) []scrapemate.IJob {
+		job := gmaps.NewPlaceJob("seed", "el", "https://www.google.com/maps/place/x", false, false,
+			gmaps.WithPlaceJobCountry("gr"),
+			gmaps.WithPlaceJobExpandNearby(hops, 5, 16, dedup),
+			gmaps.WithPlaceJobWait("h1", 10*time.Second),
+		)
+
+		resp := scrapemate.Response{Meta: map[string]any{"json": raw}}
+
+		data, next, err := job.Process(context.Background(), &resp)
+		require.NoError(t, err)
+		require.NotNil(t, data)
+
+		return next
+	}
+
+	require.Empty(t, process(0))
+
+	next := process(2)
+	require.Len(t, next, 1)
+
+	search, ok := next[0].(*gmaps.GmapJob)
+	require.True(t, ok)
+
+	require.Equal(t, entry.Category, search.Keyword)
+	require.Equal(t, 1, search.ExpandNearby)
+	require.Equal(t, 5, search.MaxDepth)
+	require.Equal(t, 16, search.Zoom)
+	require.InDelta(t, entry.Latitude, search.Lat, 0.001)
+	require.InDelta(t, entry.Longtitude, search.Lon, 0.001)
+	require.Equal(t, "el", search.URLParams["hl"])
+	require.Equal(t, "gr", search.URLParams["gl"])
+	require.Equal(t, "h1", search.WaitSelector)
+	require.Equal(t, 10*time.Second, search.WaitTimeout)
+
+	// the same category around the same location is searched only once
+	require.Empty(t, process(2))
+}
+
+func Test_PlaceJobExpandRelated(t *testing.T) {
+	related := loadPlaceFixture(t, "related")
+
+	process := func(raw []byte, opts ...gmaps.PlaceJobOptions) []scrapemate.IJob {
+		job := gmaps.NewPlaceJob("seed", "en", "https://www.google.com/maps/place/x", false, false, opts...)
+
+		resp := scrapemate.Response{Meta: map[string]any{"json": raw}}
+
+		data, next, err := job.Process(context.Background(), &resp)
+		require.NoError(t, err)
+
+		entry, ok := data.(*gmaps.Entry)
+		require.True(t, ok)
+		require.Len(t, entry.RelatedPlaces, len(entry.RelatedPlaceNames))
+
+		return next
+	}
+
+	// the related places are only listed by default
+	require.Empty(t, process(related))
+
+	dedup := deduper.New()
+
+	next := process(related,
+		gmaps.WithPlaceJobCountry("gb"),
+		gmaps.WithPlaceJobExpandRelated(2, dedup),
+		gmaps.WithPlaceJobWait("h1", 10*time.Second),
+	)
+	require.Len(t, next, 3)
+
+	place, ok := next[0].(*gmaps.PlaceJob)
+	require.True(t, ok)
+
+	require.Equal(t, "https://www.google.com/maps/place/The+Old+Shades/data=!4m2!3m1!1s0x0:0x5555555555555555", place.GetURL())
+	require.Equal(t, "seed", place.ParentID)
+	require.Equal(t, 1, place.ExpandRelated)
+	require.Equal(t, "en", place.URLParams["hl"])
+	require.Equal(t, "gb", place.URLParams["gl"])
+	require.Equal(t, "h1", place.WaitSelector)
+
+	// the places already scraped are not scraped again
+	require.Empty(t, process(related, gmaps.WithPlaceJobExpandRelated(2, dedup)))
+
+	// the last hop does not expand
+	require.Empty(t, process(related, gmaps.WithPlaceJobExpandRelated(0, deduper.New())))
+
+	// a place without related places
+	require.Empty(t, process(loadPlaceFixture(t, "pub"), gmaps.WithPlaceJobExpandRelated(2, deduper.New())))
+}
+
+func Test_PlaceJobSaveRawJSON(t *testing.T) {
+	raw := loadPlaceFixture(t, "restaurant")
+	dir := filepath.Join(t.TempDir(), "raw")
+	settings := &gmaps.Settings{Artifacts: &gmaps.ArtifactCounter{}}
+
+	const placeURL = "https://www.google.com/maps/place/Kipriakon/data=!4m7!3m6!1s0x14e732fd76f0d90d:0xe5415928d6702b47!8m2"
+
+	process := func(u string) {
+		job := gmaps.NewPlaceJob("seed", "en", u, false, false, gmaps.WithPlaceJobRawJSON(dir, 1), gmaps.WithPlaceJobSettings(settings))
+		resp := scrapemate.Response{Meta: map[string]any{"json": raw}}
+
+		_, _, err := job.Process(context.Background(), &resp)
+		require.NoError(t, err)
+	}
+
+	process(placeURL)
+	process("https://www.google.com/maps/place/other")
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1, "only one file is allowed")
+
+	saved, err := os.ReadFile(filepath.Join(dir, "0x14e732fd76f0d90d_0xe5415928d6702b47.json"))
+	require.NoError(t, err)
+
+	// the saved file can be replayed
+	entry, err := gmaps.EntryFromJSON(saved)
+	require.NoError(t, err)
+	require.Equal(t, "Kipriakon", entry.Title)
+}
+
+func Test_PlaceJobFetchErrors(t *testing.T) {
+	const u = "https://www.google.com/maps/place/x/data=!4m2!3m1!1s0x14e732fd76f0d90d:0xe5415928d6702b47"
+
+	t.Run("permanent", func(t *testing.T) {
+		job := gmaps.NewPlaceJob("seed", "en", u, false, false,
+			gmaps.WithPlaceJobSource("cafe", 0, 0, 0),
+		)
+
+		resp := scrapemate.Response{Error: gmaps.ErrPlaceNotFound}
+		require.True(t, job.DoCheckResponse(&resp))
+		require.True(t, job.ProcessOnFetchError())
+
+		data, next, err := job.Process(context.Background(), &resp)
+		require.NoError(t, err)
+		require.Empty(t, next)
+
+		entry, ok := data.(*gmaps.Entry)
+		require.True(t, ok)
+		require.Equal(t, gmaps.ErrPlaceNotFound.Error(), entry.Error)
+		require.Equal(t, u, entry.Link)
+		require.Equal(t, "0x14e732fd76f0d90d:0xe5415928d6702b47", entry.DataID)
+		require.NotEmpty(t, entry.Cid)
+		require.Equal(t, "cafe", entry.SourceKeyword)
+		require.Len(t, entry.CsvRow(), len(entry.CsvHeaders()))
+	})
+
+	t.Run("request budget", func(t *testing.T) {
+		job := gmaps.NewPlaceJob("seed", "en", u, false, false)
+
+		resp := scrapemate.Response{Error: gmaps.ErrRequestBudget}
+		require.True(t, job.DoCheckResponse(&resp))
+
+		data, _, err := job.Process(context.Background(), &resp)
+		require.NoError(t, err)
+		require.Nil(t, data)
+		require.False(t, job.UseInResults())
+	})
+
+	t.Run("transient", func(t *testing.T) {
+		job := gmaps.NewPlaceJob("seed", "en", u, false, false)
+
+		resp := scrapemate.Response{Error: errors.New("playwright: timeout: Timeout 30000ms exceeded.")}
+		require.False(t, job.DoCheckResponse(&resp))
+
+		data, _, err := job.Process(context.Background(), &resp)
+		require.Error(t, err)
+		require.Nil(t, data)
+	})
+}
+
+// fixturePage is a page at url with the HTML of a file of testdata/pages.
+type fixturePage struct {
+	url string
+	doc *goquery.Document
+}
+
+func newFixturePage(t *testing.T, url, name string) *fixturePage {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("..", "testdata", "pages", name))
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	require.NoError(t, err)
+
+	return &fixturePage{url: url, doc: doc}
+}
+
+func (p *fixturePage) URL() string {
+	return p.url
+}
+
+func (p *fixturePage) Evaluate(_ string, arg ...any) (any, error) {
+	selectors, _ := arg[0].([]string)
+
+	for i, sel := range selectors {
+		if p.doc.Find(sel).Length() > 0 {
+			return i, nil
+		}
+	}
+
+	return -1, nil
+}
+
+func Test_VerifyPlacePage(t *testing.T) {
+	const (
+		placeURL = "https://www.google.com/maps/place/Kafeneio+Athinon/@37.97,23.73,17z?hl=en"
+		shellURL = "https://www.google.com/maps/@37.97,23.73,17z?hl=en"
+		marker   = "div[role='main'][aria-label]"
+	)
+
+	ctx := context.Background()
+
+	t.Run("place page", func(t *testing.T) {
+		page := newFixturePage(t, placeURL, "place.html")
+
+		require.NoError(t, gmaps.VerifyPlacePage(ctx, page, "", time.Second))
+		require.NoError(t, gmaps.VerifyPlacePage(ctx, page, marker, time.Second))
+	})
+
+	t.Run("navigated to the maps shell", func(t *testing.T) {
+		page := newFixturePage(t, shellURL, "shell.html")
+
+		err := gmaps.VerifyPlacePage(ctx, page, "", time.Second)
+		require.ErrorIs(t, err, gmaps.ErrNotPlacePage)
+		require.ErrorContains(t, err, shellURL)
+		require.True(t, gmaps.IsPermanentError(err))
+	})
+
+	t.Run("place URL without the marker", func(t *testing.T) {
+		page := newFixturePage(t, placeURL, "shell.html")
+
+		start := time.Now()
+
+		err := gmaps.VerifyPlacePage(ctx, page, marker, 300*time.Millisecond)
+		require.ErrorIs(t, err, gmaps.ErrNotPlacePage)
+		require.ErrorContains(t, err, marker)
+		require.Less(t, time.Since(start), time.Second)
+
+		// the failure is not retried, the place gets a row with the error
+		job := gmaps.NewPlaceJob("seed", "en", placeURL, false, false)
+
+		resp := scrapemate.Response{Error: err}
+		require.True(t, job.DoCheckResponse(&resp))
+
+		data, _, err := job.Process(ctx, &resp)
+		require.NoError(t, err)
+		require.Contains(t, data.(*gmaps.Entry).Error, "not a place page")
+	})
+}
+
+type failure struct {
+	input, id, err string
+}
+
+type failureRecorder struct {
+	failures []failure
+}
+
+func (r *failureRecorder) JobFailed(input, id string, err error) {
+	r.failures = append(r.failures, failure{input: input, id: id, err: err.Error()})
+}
+
+func Test_JobFailureReporter(t *testing.T) {
+	const u = "https://www.google.com/maps/place/x/data=!4m2!3m1!1s0x14e732fd76f0d90d:0xe5415928d6702b47"
+
+	timeout := errors.New("playwright: timeout: Timeout 30000ms exceeded.")
+
+	t.Run("search", func(t *testing.T) {
+		rec := &failureRecorder{}
+		job := gmaps.NewGmapJob("line-1", "en", "cafe in athens", 1, false, "", 0, gmaps.WithFailureReporter(rec))
+
+		require.True(t, job.ProcessOnFetchError())
+
+		_, _, err := job.Process(context.Background(), &scrapemate.Response{Error: timeout})
+		require.ErrorIs(t, err, timeout)
+		require.Equal(t, []failure{{input: "cafe in athens", id: "line-1", err: timeout.Error()}}, rec.failures)
+
+		// the place jobs of the search report to the same reporter
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<div role="feed"><div jsaction><a href="` + u + `"></a></div></div>`))
+		require.NoError(t, err)
+
+		_, next, err := job.Process(context.Background(), &scrapemate.Response{Document: doc})
+		require.NoError(t, err)
+		require.Len(t, next, 1)
+
+		place, ok := next[0].(*gmaps.PlaceJob)
+		require.True(t, ok)
+		require.Same(t, rec, place.FailureReporter)
+	})
+
+	t.Run("request budget", func(t *testing.T) {
+		rec := &failureRecorder{}
+		job := gmaps.NewGmapJob("line-1", "en", "cafe in athens", 1, false, "", 0, gmaps.WithFailureReporter(rec))
+
+		_, _, err := job.Process(context.Background(), &scrapemate.Response{Error: gmaps.ErrRequestBudget})
+		require.ErrorIs(t, err, gmaps.ErrRequestBudget)
+		require.Empty(t, rec.failures)
+	})
+
+	t.Run("place", func(t *testing.T) {
+		rec := &failureRecorder{}
+
+		for _, fetchErr := range []error{timeout, gmaps.ErrPlaceNotFound} {
+			job := gmaps.NewPlaceJob("line-1", "en", u, false, false, gmaps.WithPlaceJobFailureReporter(rec))
+
+			_, _, _ = job.Process(context.Background(), &scrapemate.Response{Error: fetchErr})
+		}
+
+		require.Equal(t, []failure{
+			{input: u, id: "line-1", err: timeout.Error()},
+			{input: u, id: "line-1", err: gmaps.ErrPlaceNotFound.Error()},
+		}, rec.failures)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		rec := &failureRecorder{}
+		job := gmaps.NewPlaceJob("line-1", "en", u, false, false, gmaps.WithPlaceJobFailureReporter(rec))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, _, err := job.Process(ctx, &scrapemate.Response{Error: context.Canceled})
+		require.Error(t, err)
+		require.Empty(t, rec.failures)
+	})
+}
+
+func Test_PlaceJobPlaceID(t *testing.T) {
+	raw := loadPlaceFixture(t, "restaurant")
+
+	process := func(parentID, u string, fetchErr error) *gmaps.Entry {
+		job := gmaps.NewPlaceJob(parentID, "en", u, false, false)
+
+		resp := scrapemate.Response{Meta: map[string]any{"json": raw}, Error: fetchErr}
+
+		data, _, err := job.Process(context.Background(), &resp)
+		require.NoError(t, err)
+
+		entry, ok := data.(*gmaps.Entry)
+		require.True(t, ok)
+		require.Equal(t, parentID, entry.ID)
+
+		return entry
+	}
+
+	// the same place scraped by two runs gets the same place ID
+	first := process("3f1c2a9e-run-1", "https://www.google.com/maps/place/x", nil)
+	second := process("8b7d4e10-run-2", "https://www.google.com/maps/place/x?hl=en", nil)
+
+	require.NotEmpty(t, first.PlaceID)
+	require.Equal(t, first.Cid, first.PlaceID)
+	require.Equal(t, first.PlaceID, second.PlaceID)
+	require.Equal(t, first.PlaceID, first.CsvRow()[slices.Index(first.CsvHeaders(), "place_id")])
+
+	// and so does the error row of the place
+	failed := process("run-3", "https://www.google.com/maps/place/x/data=!4m2!3m1!1s0x14e732fd76f0d90d:0xe5415928d6702b47", gmaps.ErrPlaceNotFound)
+	require.Equal(t, first.PlaceID, failed.PlaceID)
+
+	// without a data ID the link is hashed, ignoring the query string
+	noID := process("run-4", "https://www.google.com/maps/place/Unknown?hl=en", gmaps.ErrPlaceNotFound)
+	again := process("run-5", "https://www.google.com/maps/place/Unknown?hl=de", gmaps.ErrPlaceNotFound)
+
+	require.True(t, strings.HasPrefix(noID.PlaceID, "url:"))
+	require.Equal(t, noID.PlaceID, again.PlaceID)
+}
@@ -0,0 +1,79 @@
+package gmaps
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// dataIDFromURL returns the data ID (feature ID) found in a place URL,
+// e.g. 0x14e732fd76f0d90d:0xe5415928d6702b47, or an empty string.
+func dataIDFromURL(u string) string {
+	if m := dataIDRe.FindStringSubmatch(u); m != nil {
+		return m[1]
+	}
+
+	return ""
+}
+
+// cidFromDataID returns the CID of a place from its data ID. The CID is
+// the second half of the data ID in decimal.
+func cidFromDataID(dataID string) string {
+	_, listing, ok := strings.Cut(dataID, ":")
+	if !ok {
+		return ""
+	}
+
+	cid, err := strconv.ParseUint(strings.TrimPrefix(listing, "0x"), 16, 64)
+	if err != nil || cid == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(cid, 10)
+}
+
+// PlaceKey returns the key used to deduplicate the place at u: its CID
+// when the URL has a data ID, u otherwise. The CID does not change when
+// the name or the coordinates in the URL do.
+func PlaceKey(u string) string {
+	if cid := cidFromDataID(dataIDFromURL(u)); cid != "" {
+		return "cid:" + cid
+	}
+
+	return u
+}
+
+// placeID returns the ID of the place of entry, which is the same in every
+// run: its CID, its data ID when the CID is unknown, or else a hash of its
+// link without the query string.
+func placeID(entry *Entry) string {
+	if entry.Cid != "" {
+		return entry.Cid
+	}
+
+	if cid := cidFromDataID(entry.DataID); cid != "" {
+		return cid
+	}
+
+	if entry.DataID != "" {
+		return entry.DataID
+	}
+
+	if entry.Link == "" {
+		return ""
+	}
+
+	link := entry.Link
+
+	if u, err := url.Parse(link); err == nil {
+		u.RawQuery = ""
+		u.Fragment = ""
+		link = u.String()
+	}
+
+	sum := sha256.Sum256([]byte(link))
+
+	return "url:" + hex.EncodeToString(sum[:8])
+}
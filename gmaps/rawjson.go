@@ -0,0 +1,28 @@
+package gmaps
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// saveRawJSON writes the raw place JSON to dir, named after the place's
+// data ID (or fallbackID when the URL has none), so it can be replayed
+// through EntryFromJSON. It returns an empty path without error when
+// maxFiles were already written to dir by the jobs of s.
+func (s *Settings) saveRawJSON(raw []byte, dir string, maxFiles int, placeURL, fallbackID string) (string, error) {
+	if !s.allowArtifact("rawjson", dir, maxFiles) {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, placeFileName(placeURL, fallbackID, ".json"))
+
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
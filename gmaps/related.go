@@ -0,0 +1,101 @@
+package gmaps
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/gosom/scrapemate"
+)
+
+// getRelatedPlaces returns the names and the links of the places of the
+// "People also search for" section of the place page, in the order of the
+// page. The page has the details of the first few places only, the others
+// are left out. The section has no links: they are built from the data
+// IDs, see relatedPlaceURL.
+func getRelatedPlaces(darray []any) (names, links []string) {
+	items := getNthElementAndCast[[]any](darray, 99, 0, 0, 1)
+
+	for i := range items {
+		dataID := getNthElementAndCast[string](items, i, 1, 10)
+		name := getNthElementAndCast[string](items, i, 1, 11)
+
+		if dataID == "" || name == "" {
+			continue
+		}
+
+		names = append(names, name)
+		links = append(links, relatedPlaceURL(name, dataID))
+	}
+
+	return names, links
+}
+
+// relatedPlaceURL returns the /maps/place/ link of the place name with the
+// data ID dataID. PlaceKey finds the CID of the place in it.
+func relatedPlaceURL(name, dataID string) string {
+	return "https://www.google.com/maps/place/" + url.QueryEscape(name) + "/data=!4m2!3m1!1s" + dataID
+}
+
+// relatedJobs returns the jobs of the related places of entry that were
+// not scraped yet, or nil when there are no hops left.
+func (j *PlaceJob) relatedJobs(ctx context.Context, entry *Entry) []scrapemate.IJob {
+	if j.ExpandRelated <= 0 {
+		return nil
+	}
+
+	var next []scrapemate.IJob
+
+	for _, link := range entry.RelatedPlaces {
+		if j.Deduper != nil && !j.Deduper.AddIfNotExists(ctx, PlaceKey(link)) {
+			continue
+		}
+
+		opts := []PlaceJobOptions{
+			WithPlaceJobExpandRelated(j.ExpandRelated-1, j.Deduper),
+		}
+
+		if j.ExitMonitor != nil {
+			opts = append(opts, WithPlaceJobExitMonitor(j.ExitMonitor))
+		}
+
+		if country := j.URLParams["gl"]; country != "" {
+			opts = append(opts, WithPlaceJobCountry(country))
+		}
+
+		if j.WaitSelector != "" || j.WaitTimeout > 0 {
+			opts = append(opts, WithPlaceJobWait(j.WaitSelector, j.WaitTimeout))
+		}
+
+		if j.ScreenshotsDir != "" {
+			opts = append(opts, WithPlaceJobScreenshots(j.ScreenshotsDir, j.ScreenshotsMax))
+		}
+
+		if j.RawJSONDir != "" {
+			opts = append(opts, WithPlaceJobRawJSON(j.RawJSONDir, j.RawJSONMax))
+		}
+
+		if j.EnrichWebsite {
+			opts = append(opts, WithPlaceJobWebsiteEnrichment())
+		}
+
+		if j.FailureReporter != nil {
+			opts = append(opts, WithPlaceJobFailureReporter(j.FailureReporter))
+		}
+
+		if j.Trace != (Trace{}) {
+			opts = append(opts, WithPlaceJobTrace(j.Trace))
+		}
+
+		if j.settings != nil {
+			opts = append(opts, WithPlaceJobSettings(j.settings))
+		}
+
+		next = append(next, NewPlaceJob(j.ParentID, j.URLParams["hl"], link, j.ExtractEmail, j.ExtractExtraReviews, opts...))
+	}
+
+	if j.ExitMonitor != nil {
+		j.ExitMonitor.IncrPlacesFound(len(next))
+	}
+
+	return next
+}
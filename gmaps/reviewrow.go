@@ -0,0 +1,88 @@
+package gmaps
+
+import (
+	"strconv"
+)
+
+// ReviewRow is a review of a place, written on its own when reviews are
+// exported separately from the places. PlaceID is the PlaceID of the
+// entry the review belongs to.
+type ReviewRow struct {
+	PlaceID              string   `json:"place_id"`
+	PlaceTitle           string   `json:"place_title"`
+	Author               string   `json:"author"`
+	AuthorProfilePicture string   `json:"author_profile_picture"`
+	AuthorReviewCount    int      `json:"author_review_count"`
+	AuthorIsLocalGuide   bool     `json:"author_is_local_guide"`
+	Rating               int      `json:"rating"`
+	Text                 string   `json:"text"`
+	When                 string   `json:"when"`
+	Images               []string `json:"images"`
+	OwnerResponse        string   `json:"owner_response"`
+	OwnerResponseWhen    string   `json:"owner_response_when"`
+}
+
+func (r *ReviewRow) CsvHeaders() []string {
+	return []string{
+		"place_id",
+		"place_title",
+		"author",
+		"author_profile_picture",
+		"author_review_count",
+		"author_is_local_guide",
+		"rating",
+		"text",
+		"when",
+		"images",
+		"owner_response",
+		"owner_response_when",
+	}
+}
+
+func (r *ReviewRow) CsvRow() []string {
+	return []string{
+		r.PlaceID,
+		r.PlaceTitle,
+		r.Author,
+		r.AuthorProfilePicture,
+		strconv.Itoa(r.AuthorReviewCount),
+		strconv.FormatBool(r.AuthorIsLocalGuide),
+		strconv.Itoa(r.Rating),
+		r.Text,
+		r.When,
+		stringSliceToString(r.Images),
+		r.OwnerResponse,
+		r.OwnerResponseWhen,
+	}
+}
+
+// ReviewRows returns the reviews of the entry as rows. The extended
+// reviews are used when they were fetched, since they include the ones
+// shown on the place page.
+func (e *Entry) ReviewRows() []*ReviewRow {
+	reviews := e.UserReviewsExtended
+	if len(reviews) == 0 {
+		reviews = e.UserReviews
+	}
+
+	rows := make([]*ReviewRow, 0, len(reviews))
+
+	for i := range reviews {
+		rows = append(rows, &ReviewRow{
+			PlaceID:              e.PlaceID,
+			PlaceTitle:           e.Title,
+			Author:               reviews[i].Name,
+			AuthorProfilePicture: reviews[i].ProfilePicture,
+			AuthorReviewCount:    reviews[i].AuthorReviewCount,
+			AuthorIsLocalGuide:   reviews[i].AuthorIsLocalGuide,
+			Rating:               reviews[i].Rating,
+			Text:                 reviews[i].Description,
+			When:                 reviews[i].When,
+			Images:               reviews[i].Images,
+			OwnerResponse:        reviews[i].OwnerResponse,
+			OwnerResponseWhen:    reviews[i].OwnerResponseWhen,
+		})
+	}
+
+	return rows
+}
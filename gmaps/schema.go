@@ -0,0 +1,147 @@
+package gmaps
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// entryDescriptions are the descriptions of the fields of Entry that are
+// easy to mistake for one another.
+var entryDescriptions = map[string]string{
+	"input_id": "ID of the input line that found the place, or a random ID per run when the line has none. " +
+		"It is not stable across runs: use place_id to identify the place.",
+	"place_id": "Stable ID of the place, the same in every run: the key for joining the results of different runs.",
+}
+
+// EntryJSONSchema returns the JSON Schema of Entry as it is encoded in the
+// JSON outputs. The schema is generated from the struct fields and their json
+// tags, so it stays in sync when fields are added.
+func EntryJSONSchema() ([]byte, error) {
+	defs := map[string]any{}
+
+	root := structSchema(reflect.TypeOf(Entry{}), defs)
+	root["$schema"] = jsonSchemaDraft
+	root["title"] = "Entry"
+
+	properties := root["properties"].(map[string]any)
+	for name, description := range entryDescriptions {
+		properties[name].(map[string]any)["description"] = description
+	}
+
+	if len(defs) > 0 {
+		root["$defs"] = defs
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func typeSchema(t reflect.Type, defs map[string]any) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(typeSchema(t.Elem(), defs))
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		// []byte is encoded as a base64 string
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nullable(map[string]any{"type": "string", "contentEncoding": "base64"})
+		}
+
+		return nullable(map[string]any{"type": "array", "items": typeSchema(t.Elem(), defs)})
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		// map keys are always encoded as strings, integer keys included
+		return nullable(map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), defs)})
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, defs)
+		}
+
+		if _, ok := defs[t.Name()]; !ok {
+			// placeholder first, so recursive types terminate
+			defs[t.Name()] = map[string]any{}
+			defs[t.Name()] = structSchema(t, defs)
+		}
+
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	default:
+		// interfaces and anything else can hold any value
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	var addFields func(t reflect.Type)
+
+	addFields = func(t reflect.Type) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(tag, ",")
+
+			// untagged embedded structs are flattened like encoding/json does
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				addFields(f.Type)
+
+				continue
+			}
+
+			if !f.IsExported() {
+				continue
+			}
+
+			if name == "" {
+				name = f.Name
+			}
+
+			properties[name] = typeSchema(f.Type, defs)
+
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+	}
+
+	addFields(t)
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func nullable(schema map[string]any) map[string]any {
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []string{typ, "null"}
+	}
+
+	return schema
+}
@@ -0,0 +1,57 @@
+package gmaps_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_EntryJSONSchema(t *testing.T) {
+	raw, err := gmaps.EntryJSONSchema()
+	require.NoError(t, err)
+
+	var schema struct {
+		Schema     string                     `json:"$schema"`
+		Type       string                     `json:"type"`
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+		Defs       map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+
+	require.NoError(t, json.Unmarshal(raw, &schema))
+	require.NotEmpty(t, schema.Schema)
+	require.Equal(t, "object", schema.Type)
+
+	// the schema must describe exactly the keys of an encoded entry
+	encoded, err := json.Marshal(gmaps.Entry{})
+	require.NoError(t, err)
+
+	var fields map[string]any
+
+	require.NoError(t, json.Unmarshal(encoded, &fields))
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+
+	propertyNames := make([]string, 0, len(schema.Properties))
+	for k := range schema.Properties {
+		propertyNames = append(propertyNames, k)
+	}
+
+	require.ElementsMatch(t, keys, propertyNames)
+	require.ElementsMatch(t, keys, schema.Required)
+
+	require.JSONEq(t, `{"type":["array","null"],"items":{"$ref":"#/$defs/Review"}}`, string(schema.Properties["user_reviews"]))
+	require.JSONEq(t, `{"type":"number"}`, string(schema.Properties["latitude"]))
+	require.Contains(t, string(schema.Properties["input_id"]), "not stable")
+	require.Contains(t, string(schema.Properties["place_id"]), "Stable ID")
+	require.Contains(t, schema.Defs["Review"].Properties, "Rating")
+	require.Contains(t, schema.Defs["About"].Properties, "options")
+}
@@ -0,0 +1,39 @@
+package gmaps
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+type screenshotter interface {
+	Screenshot(options ...playwright.PageScreenshotOptions) ([]byte, error)
+}
+
+// takeScreenshot saves a screenshot of the page to dir, named after the
+// place's data ID (or fallbackID when the URL has none). It returns an
+// empty path without error when max screenshots were already taken in dir
+// by the jobs of s.
+func (s *Settings) takeScreenshot(page screenshotter, dir string, maxShots int, placeURL, fallbackID string) (string, error) {
+	if !s.allowArtifact("screenshot", dir, maxShots) {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, placeFileName(placeURL, fallbackID, ".jpg"))
+
+	_, err := page.Screenshot(playwright.PageScreenshotOptions{
+		Path:    playwright.String(path),
+		Type:    playwright.ScreenshotTypeJpeg,
+		Quality: playwright.Int(70),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
@@ -0,0 +1,55 @@
+package gmaps_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/playwright-community/playwright-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+type fakeScreenshotPage struct{}
+
+func (fakeScreenshotPage) Screenshot(options ...playwright.PageScreenshotOptions) ([]byte, error) {
+	data := []byte("jpeg")
+
+	return data, os.WriteFile(*options[0].Path, data, 0o600)
+}
+
+func Test_TakeScreenshot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "shots")
+	settings := &gmaps.Settings{Artifacts: &gmaps.ArtifactCounter{}}
+
+	const placeURL = "https://www.google.com/maps/place/Kipriakon/data=!4m7!3m6!1s0x14e732fd76f0d90d:0xe5415928d6702b47!8m2"
+
+	path, err := gmaps.TakeScreenshot(settings, fakeScreenshotPage{}, dir, 2, placeURL, "job-1")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "0x14e732fd76f0d90d_0xe5415928d6702b47.jpg"), path)
+	require.FileExists(t, path)
+
+	// no data ID in the URL
+	path, err = gmaps.TakeScreenshot(settings, fakeScreenshotPage{}, dir, 2, "https://www.google.com/maps/place/x", "job-2")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "job-2.jpg"), path)
+
+	// the cap is reached
+	path, err = gmaps.TakeScreenshot(settings, fakeScreenshotPage{}, dir, 2, "https://www.google.com/maps/place/y", "job-3")
+	require.NoError(t, err)
+	require.Empty(t, path)
+	require.NoFileExists(t, filepath.Join(dir, "job-3.jpg"))
+
+	// the jobs of another run count their own
+	path, err = gmaps.TakeScreenshot(&gmaps.Settings{Artifacts: &gmaps.ArtifactCounter{}}, fakeScreenshotPage{}, dir, 2, "https://www.google.com/maps/place/y", "job-3")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "job-3.jpg"), path)
+
+	// without a counter nothing is counted
+	for _, id := range []string{"job-4", "job-5", "job-6"} {
+		path, err = gmaps.TakeScreenshot(nil, fakeScreenshotPage{}, dir, 2, "https://www.google.com/maps/place/z", id)
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, id+".jpg"), path)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gosom/google-maps-scraper/exiter"
@@ -26,12 +27,15 @@ type MapSearchParams struct {
 	ViewportW int
 	ViewportH int
 	Hl        string
+	Gl        string
 }
 
 type SearchJob struct {
 	scrapemate.Job
 
 	params      *MapSearchParams
+	settings    *Settings
+	Details     bool
 	ExitMonitor exiter.Exiter
 }
 
@@ -68,6 +72,22 @@ func WithSearchJobExitMonitor(exitMonitor exiter.Exiter) SearchJobOptions {
 	}
 }
 
+// WithSearchJobSettings sets the settings of the run of the job, see
+// Settings.
+func WithSearchJobSettings(s *Settings) SearchJobOptions {
+	return func(j *SearchJob) {
+		j.settings = s
+	}
+}
+
+// WithSearchJobDetails also parses the opening hours, about and images of
+// the places. See ParseSearchResults.
+func WithSearchJobDetails() SearchJobOptions {
+	return func(j *SearchJob) {
+		j.Details = true
+	}
+}
+
 func (j *SearchJob) Process(_ context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
 	defer func() {
 		resp.Document = nil
@@ -80,7 +100,7 @@ func (j *SearchJob) Process(_ context.Context, resp *scrapemate.Response) (any,
 		return nil, nil, fmt.Errorf("empty response body")
 	}
 
-	entries, err := ParseSearchResults(body)
+	entries, err := ParseSearchResults(body, j.Details)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse search results: %w", err)
 	}
@@ -91,6 +111,17 @@ func (j *SearchJob) Process(_ context.Context, resp *scrapemate.Response) (any,
 		j.params.Location.Radius,
 	)
 
+	scrapedAt := time.Now().UTC().Format(time.RFC3339)
+
+	for _, entry := range entries {
+		entry.WebSite = j.settings.website(entry.WebSite)
+		entry.SourceKeyword = j.params.Query
+		entry.SearchLat = j.params.Location.Lat
+		entry.SearchLon = j.params.Location.Lon
+		entry.SearchZoom = int(j.params.Location.ZoomLvl)
+		entry.ScrapedAt = scrapedAt
+	}
+
 	if j.ExitMonitor != nil {
 		j.ExitMonitor.IncrSeedCompleted(1)
 		j.ExitMonitor.IncrPlacesFound(len(entries))
@@ -100,6 +131,10 @@ func (j *SearchJob) Process(_ context.Context, resp *scrapemate.Response) (any,
 	return entries, nil, nil
 }
 
+func (j *SearchJob) GetCacheKey() string {
+	return j.settings.cacheKey(j.Job.GetCacheKey())
+}
+
 func removeFirstLine(data []byte) []byte {
 	if len(data) == 0 {
 		return data
@@ -124,6 +159,10 @@ func buildGoogleMapsParams(params *MapSearchParams) map[string]string {
 		"q":        params.Query,
 	}
 
+	if params.Gl != "" {
+		ans["gl"] = params.Gl
+	}
+
 	pb := fmt.Sprintf("!4m12!1m3!1d3826.902183192154!2d%.4f!3d%.4f!2m3!1f0!2f0!3f0!3m2!1i%d!2i%d!4f%.1f!7i20!8i0"+
 		"!10b1!12m22!1m3!18b1!30b1!34e1!2m3!5m1!6e2!20e3!4b0!10b1!12b1!13b1!16b1!17m1!3e1!20m3!5e2!6b1!14b1!46m1!1b0"+
 		"!96b1!19m4!2m3!1i360!2i120!4i8",
@@ -0,0 +1,106 @@
+package gmaps
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gosom/scrapemate"
+)
+
+// defaultFeedSelectors are the selectors of the results feed of a search,
+// tried in order. The second one is the class of the scrollable list, for
+// when Google drops the role of the feed.
+var defaultFeedSelectors = []string{
+	`div[role='feed']`,
+	`div.m6QErb[aria-label]`,
+}
+
+// defaultPlaceSelectors are the selectors of the title of a place page,
+// tried in order.
+var defaultPlaceSelectors = []string{
+	`h1.DUwDvf`,
+	`div[role='main'] h1`,
+}
+
+// selectorPollInterval is the pause between two checks of matchSelector.
+const selectorPollInterval = 150 * time.Millisecond
+
+// feedSelectors returns the selectors of the results feed, see
+// Settings.FeedSelectors.
+func (s *Settings) feedSelectors() []string {
+	if s == nil || len(s.FeedSelectors) == 0 {
+		return defaultFeedSelectors
+	}
+
+	return s.FeedSelectors
+}
+
+// placeSelectors returns the selectors of the title of the place pages,
+// see Settings.PlaceSelectors.
+func (s *Settings) placeSelectors() []string {
+	if s == nil || len(s.PlaceSelectors) == 0 {
+		return defaultPlaceSelectors
+	}
+
+	return s.PlaceSelectors
+}
+
+// placeMarker returns the selector of Settings.PlaceMarker.
+func (s *Settings) placeMarker() string {
+	if s == nil {
+		return ""
+	}
+
+	return s.PlaceMarker
+}
+
+type selectorPage interface {
+	Evaluate(expression string, arg ...any) (any, error)
+}
+
+// matchSelectorJS returns the index of the first selector matching an
+// element of the page, or -1. Invalid selectors do not match.
+const matchSelectorJS = `(selectors) => selectors.findIndex((s) => {
+	try {
+		return document.querySelector(s) !== null;
+	} catch (e) {
+		return false;
+	}
+})`
+
+// matchSelector waits up to timeout for an element matching one of the
+// selectors and returns the first selector, in order, that matches. It
+// logs the selector, as a warning when it is a fallback: the markup of
+// Google Maps changed and the first selectors should be updated.
+func matchSelector(ctx context.Context, page selectorPage, what string, selectors []string, timeout time.Duration) (string, bool) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		idxI, err := page.Evaluate(matchSelectorJS, selectors)
+		if err != nil {
+			return "", false
+		}
+
+		if idx, ok := idxI.(int); ok && idx >= 0 && idx < len(selectors) {
+			log := scrapemate.GetLoggerFromContext(ctx)
+
+			msg := fmt.Sprintf("%s selector %q matched", what, selectors[idx])
+			if idx > 0 {
+				log.Warn(msg + ", the previous ones did not")
+			} else {
+				log.Debug(msg)
+			}
+
+			return selectors[idx], true
+		}
+
+		if time.Now().Add(selectorPollInterval).After(deadline) {
+			return "", false
+		}
+
+		if err := sleepContext(ctx, selectorPollInterval); err != nil {
+			return "", false
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package gmaps_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// fakeSelectorPage is a page with the elements matching present.
+type fakeSelectorPage struct {
+	present map[string]bool
+	calls   int
+}
+
+func (p *fakeSelectorPage) Evaluate(_ string, arg ...any) (any, error) {
+	p.calls++
+
+	selectors, _ := arg[0].([]string)
+
+	for i, sel := range selectors {
+		if p.present[sel] {
+			return i, nil
+		}
+	}
+
+	return -1, nil
+}
+
+func Test_MatchSelector(t *testing.T) {
+	ctx := context.Background()
+	selectors := []string{"div[role='feed']", "div.m6QErb[aria-label]", "div.results"}
+
+	// the primary selector wins over the fallbacks
+	page := &fakeSelectorPage{present: map[string]bool{"div[role='feed']": true, "div.results": true}}
+
+	sel, ok := gmaps.MatchSelector(ctx, page, "feed", selectors, time.Second)
+	require.True(t, ok)
+	require.Equal(t, "div[role='feed']", sel)
+
+	// the markup changed, the first fallback present is used
+	page = &fakeSelectorPage{present: map[string]bool{"div.results": true}}
+
+	sel, ok = gmaps.MatchSelector(ctx, page, "feed", selectors, time.Second)
+	require.True(t, ok)
+	require.Equal(t, "div.results", sel)
+	require.Equal(t, 1, page.calls)
+
+	// nothing matches: the page is checked until the timeout
+	page = &fakeSelectorPage{}
+
+	_, ok = gmaps.MatchSelector(ctx, page, "feed", selectors, 400*time.Millisecond)
+	require.False(t, ok)
+	require.Greater(t, page.calls, 1)
+}
+
+func Test_FeedSelectorFallback(t *testing.T) {
+	const u = "https://www.google.com/maps/place/x/data=!4m2!3m1!1s0x14e732fd76f0d90d:0xe5415928d6702b47"
+
+	process := func(settings *gmaps.Settings, html string) int {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		require.NoError(t, err)
+
+		job := gmaps.NewGmapJob("line-1", "en", "cafe in athens", 1, false, "", 0, gmaps.WithSettings(settings))
+
+		_, next, err := job.Process(context.Background(), &scrapemate.Response{Document: doc})
+		require.NoError(t, err)
+
+		return len(next)
+	}
+
+	// no role on the feed anymore: the default fallback finds it
+	require.Equal(t, 1, process(nil, `<div class="m6QErb" aria-label="Results"><div jsaction><a href="`+u+`"></a></div></div>`))
+
+	settings := &gmaps.Settings{FeedSelectors: []string{"div[role='feed']", "section.results"}}
+
+	require.Equal(t, 1, process(settings, `<section class="results"><div jsaction><a href="`+u+`"></a></div></section>`))
+	require.Zero(t, process(settings, `<div class="m6QErb" aria-label="Results"><div jsaction><a href="`+u+`"></a></div></div>`))
+}
@@ -0,0 +1,86 @@
+package gmaps
+
+import (
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Settings are the settings shared by the jobs of a run. They are given to
+// the jobs with WithSettings, WithPlaceJobSettings, WithSearchJobSettings,
+// WithEmailJobSettings and WithWebsiteSearchJobSettings, and the jobs pass
+// them on to the jobs they create, so the runs of a process, e.g. the jobs
+// of the web UI, each have their own.
+//
+// A nil *Settings is valid: every setting has its default. The settings are
+// not encoded with the jobs, whoever decodes a job gives them to it again.
+type Settings struct {
+	// WaitUntil is the state that the navigations wait for:
+	// WaitUntilDOMContentLoaded, WaitUntilLoad or WaitUntilNetworkIdle.
+	// Empty keeps the default of every job: the DOM content for Google
+	// Maps pages and network idle for websites. Other values are ignored,
+	// the caller validates them.
+	WaitUntil string
+
+	// BlockedResources are the resources whose requests are aborted in the
+	// browser contexts, to save the time and bandwidth of what the
+	// extraction does not need: ResourceImages, ResourceFonts,
+	// ResourceStylesheets, ResourceMedia or ResourceAnalytics. Unknown
+	// names are ignored, the caller validates them with ValidateResources.
+	BlockedResources []string
+
+	// PlaceDelayMin and PlaceDelayMax are the range of the random pause
+	// before every place page is opened, so the pages of a worker are not
+	// opened back to back. Zero disables the pause.
+	PlaceDelayMin time.Duration
+	PlaceDelayMax time.Duration
+
+	// StripTrackingParams removes the tracking query parameters, e.g.
+	// utm_source, from the websites of the places, see cleanWebsite.
+	StripTrackingParams bool
+
+	// FeedSelectors replace the selectors of the results feed. They are
+	// tried in order, so a change of the markup of Google Maps can be
+	// worked around without a new release. The selectors are checked in
+	// the browser and on the fetched page, so they must be plain CSS.
+	// PlaceSelectors do the same for the title of the place pages: when a
+	// place page has no data yet, the jobs wait for its title before
+	// reading it again. No selectors keep the defaults.
+	FeedSelectors  []string
+	PlaceSelectors []string
+
+	// PlaceMarker is the selector of an element that every place page with
+	// data has. The place pages without it, e.g. the generic Google Maps
+	// page shown instead of the place, fail with ErrNotPlacePage instead
+	// of being read. Empty checks only the URL of the page.
+	PlaceMarker string
+
+	// StorageState is loaded into the browser contexts before their first
+	// navigation, so the pages start with its session, e.g. past Google's
+	// consent form. nil keeps the contexts empty.
+	StorageState *playwright.StorageState
+
+	// Warmup makes the jobs visit a few Google pages in every new browser
+	// context before their first page, see warmUp.
+	Warmup bool
+
+	// DrainTimeout lets the place jobs that are in flight when the run is
+	// stopped, e.g. by SIGTERM, finish for up to the timeout instead of
+	// failing at once, so that their places are still written. The jobs not
+	// started yet are not run. Zero stops the jobs in flight with the run.
+	DrainTimeout time.Duration
+
+	// Artifacts counts the screenshots and raw JSON files the jobs write,
+	// for the limits of WithPlaceJobScreenshots and WithPlaceJobRawJSON.
+	// nil leaves them unlimited.
+	Artifacts *ArtifactCounter
+
+	// Throttle slows the navigations down when Google starts blocking the
+	// run. nil leaves them unthrottled.
+	Throttle *Throttle
+
+	// CacheTTL is how long the responses stored in the scrapemate cache are
+	// served before the page is fetched again, see CacheKeyExpired. Zero
+	// serves them forever.
+	CacheTTL time.Duration
+}
@@ -0,0 +1,38 @@
+package gmaps_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_SettingsPassedOn(t *testing.T) {
+	const u = "https://www.google.com/maps/place/x/data=!4m2!3m1!1s0x14e732fd76f0d90d:0xe5415928d6702b47"
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<div role="feed"><div jsaction><a href="` + u + `"></a></div></div>`))
+	require.NoError(t, err)
+
+	process := func(settings *gmaps.Settings) scrapemate.IJob {
+		job := gmaps.NewGmapJob("line-1", "en", "cafe in athens", 1, false, "", 0, gmaps.WithSettings(settings))
+
+		_, next, err := job.Process(context.Background(), &scrapemate.Response{Document: doc})
+		require.NoError(t, err)
+		require.Len(t, next, 1)
+
+		return next[0]
+	}
+
+	key := process(nil).GetCacheKey()
+
+	// the cache key of the place has the window of the TTL of its search
+	withTTL := process(&gmaps.Settings{CacheTTL: time.Hour}).GetCacheKey()
+	require.NotEqual(t, key, withTTL)
+	require.True(t, strings.HasSuffix(withTTL, ":"+key))
+}
@@ -0,0 +1,205 @@
+package gmaps
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// TypicalSpend is what people typically spend per person at a place,
+// e.g. "$20–40", which Google shows for some restaurants instead of the
+// coarse "$$" of the price range.
+type TypicalSpend struct {
+	Min int `json:"min"`
+	// Max is 0 for open ranges, e.g. "€100+".
+	Max int `json:"max"`
+	// Currency is the ISO 4217 code of the currency, or its symbol as
+	// shown when the code cannot be told from it, e.g. "kr".
+	Currency string `json:"currency"`
+}
+
+// currencySymbols maps the symbols of the price ranges to their currency.
+// The longer symbols come first, so R$ is not read as $.
+var currencySymbols = []struct {
+	symbol   string
+	currency string
+}{
+	{"CN¥", "CNY"},
+	{"CA$", "CAD"},
+	{"HK$", "HKD"},
+	{"MX$", "MXN"},
+	{"NZ$", "NZD"},
+	{"US$", "USD"},
+	{"A$", "AUD"},
+	{"R$", "BRL"},
+	{"zł", "PLN"},
+	{"$", ""},
+	{"€", "EUR"},
+	{"£", "GBP"},
+	{"¥", "JPY"},
+	{"₹", "INR"},
+	{"₩", "KRW"},
+	{"₺", "TRY"},
+	{"₽", "RUB"},
+	{"₪", "ILS"},
+	{"฿", "THB"},
+	{"₱", "PHP"},
+	{"₫", "VND"},
+}
+
+// dollarCurrencies are the currencies of the countries whose prices are
+// shown with a bare $.
+var dollarCurrencies = map[string]string{
+	"AR": "ARS",
+	"AU": "AUD",
+	"CA": "CAD",
+	"CL": "CLP",
+	"CO": "COP",
+	"HK": "HKD",
+	"MX": "MXN",
+	"NZ": "NZD",
+	"SG": "SGD",
+	"TW": "TWD",
+}
+
+// parseTypicalSpend parses the price range of a place in the country with
+// the code country. It returns nil when the range has no amounts, e.g.
+// "€€". The symbol comes before or after the amounts depending on the
+// language of the page: "$20–40", "20–30 €", "R$ 40–60".
+func parseTypicalSpend(priceRange, country string) *TypicalSpend {
+	amounts, symbol := splitCurrency(strings.TrimSpace(priceRange))
+	if amounts == "" || symbol == "" {
+		return nil
+	}
+
+	// e.g. "$20–$40"
+	amounts = strings.ReplaceAll(amounts, symbol, "")
+
+	var ans TypicalSpend
+
+	low, high, isRange := strings.Cut(amounts, "–")
+	if !isRange {
+		low, high, isRange = strings.Cut(amounts, "-")
+	}
+
+	var ok bool
+
+	switch {
+	case isRange:
+		ans.Min, ok = parseAmount(low)
+		if !ok {
+			return nil
+		}
+
+		ans.Max, ok = parseAmount(high)
+	case strings.HasSuffix(amounts, "+"):
+		ans.Min, ok = parseAmount(strings.TrimSuffix(amounts, "+"))
+	default:
+		ans.Min, ok = parseAmount(amounts)
+		ans.Max = ans.Min
+	}
+
+	if !ok {
+		return nil
+	}
+
+	ans.Currency = currencyOf(symbol, country)
+
+	return &ans
+}
+
+// splitCurrency splits s into its amounts and its currency symbol, or a
+// three letter currency code, e.g. "CHF 20–30".
+func splitCurrency(s string) (amounts, symbol string) {
+	for _, c := range currencySymbols {
+		if rest, ok := strings.CutPrefix(s, c.symbol); ok {
+			return strings.TrimSpace(rest), c.symbol
+		}
+
+		if rest, ok := strings.CutSuffix(s, c.symbol); ok {
+			return strings.TrimSpace(rest), c.symbol
+		}
+	}
+
+	if code, rest, ok := strings.Cut(s, " "); ok && isCurrencyCode(code) {
+		return strings.TrimSpace(rest), code
+	}
+
+	if rest, code, ok := cutLast(s, " "); ok && isCurrencyCode(code) {
+		return strings.TrimSpace(rest), code
+	}
+
+	// e.g. kr, which several currencies share
+	if rest, symbol, ok := cutLast(s, " "); ok && symbol != "" && strings.IndexFunc(symbol, unicode.IsDigit) == -1 {
+		return strings.TrimSpace(rest), symbol
+	}
+
+	return "", ""
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
+
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// currencyOf returns the currency of symbol in country.
+func currencyOf(symbol, country string) string {
+	if isCurrencyCode(symbol) {
+		return symbol
+	}
+
+	for _, c := range currencySymbols {
+		if c.symbol != symbol {
+			continue
+		}
+
+		if symbol == "$" {
+			if currency, ok := dollarCurrencies[country]; ok {
+				return currency
+			}
+
+			return "USD"
+		}
+
+		return c.currency
+	}
+
+	return symbol
+}
+
+// parseAmount parses an amount with the thousands separators of any
+// language, e.g. "1,000", "1.000" or "1 000".
+func parseAmount(s string) (int, bool) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ',', '.', ' ', '\u00a0', '\u202f':
+			return -1
+		default:
+			return r
+		}
+	}, strings.TrimSpace(s))
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	return n, true
+}
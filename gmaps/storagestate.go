@@ -0,0 +1,142 @@
+package gmaps
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// storageContexts are the browser contexts the storage state was loaded
+// into.
+var storageContexts sync.Map
+
+// localStorageJS sets the local storage items of the origin of the page
+// that it does not have yet, so the changes of the page are kept.
+const localStorageJS = `(() => {
+	const items = (%s)[location.origin];
+	if (!items) {
+		return;
+	}
+	for (const {name, value} of items) {
+		try {
+			if (localStorage.getItem(name) === null) {
+				localStorage.setItem(name, value);
+			}
+		} catch (e) {}
+	}
+})()`
+
+// LoadStorageState reads a storage state file saved by Playwright, e.g.
+// with SaveStorageState: the cookies and local storage of a browser
+// context.
+func LoadStorageState(path string) (*playwright.StorageState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var state playwright.StorageState
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &state, nil
+}
+
+type storagePage interface {
+	Context() playwright.BrowserContext
+}
+
+// applyStorageState loads s.StorageState into the browser context of page,
+// once per context. The browser contexts are created by scrapemate, so the
+// state is added to them instead of being an option of their creation.
+func (s *Settings) applyStorageState(page storagePage) error {
+	if s == nil || s.StorageState == nil {
+		return nil
+	}
+
+	state := s.StorageState
+
+	bctx := page.Context()
+
+	if _, loaded := storageContexts.LoadOrStore(bctx, struct{}{}); loaded {
+		return nil
+	}
+
+	bctx.OnClose(func(bctx playwright.BrowserContext) {
+		storageContexts.Delete(bctx)
+	})
+
+	if len(state.Cookies) > 0 {
+		cookies := state.ToOptionalStorageState().Cookies
+
+		if err := bctx.AddCookies(cookies); err != nil {
+			storageContexts.Delete(bctx)
+
+			return fmt.Errorf("loading the storage state: %w", err)
+		}
+	}
+
+	script, err := localStorageScript(state.Origins)
+	if err != nil || script == "" {
+		return err
+	}
+
+	if err := bctx.AddInitScript(playwright.Script{Content: &script}); err != nil {
+		storageContexts.Delete(bctx)
+
+		return fmt.Errorf("loading the storage state: %w", err)
+	}
+
+	return nil
+}
+
+// localStorageScript returns the script that sets the local storage of
+// origins, or an empty string when there is nothing to set.
+func localStorageScript(origins []playwright.Origin) (string, error) {
+	items := make(map[string][]playwright.NameValue)
+
+	for _, o := range origins {
+		if len(o.LocalStorage) > 0 {
+			items[o.Origin] = append(items[o.Origin], o.LocalStorage...)
+		}
+	}
+
+	if len(items) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(localStorageJS, data), nil
+}
+
+// SaveStorageState opens Google Maps in page, gets it past the consent
+// form and saves the storage state of its browser context to path, to be
+// loaded with LoadStorageState. The navigation waits for the state of
+// s.WaitUntil.
+func (s *Settings) SaveStorageState(page playwright.Page, langCode, path string) error {
+	u := "https://www.google.com/maps?hl=" + url.QueryEscape(langCode)
+
+	if _, err := page.Goto(u, playwright.PageGotoOptions{
+		WaitUntil: s.waitUntilState(playwright.WaitUntilStateDomcontentloaded),
+	}); err != nil {
+		return err
+	}
+
+	if err := s.handleConsent(page); err != nil {
+		return err
+	}
+
+	_, err := page.Context().StorageState(path)
+
+	return err
+}
@@ -0,0 +1,112 @@
+package gmaps_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/playwright-community/playwright-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+const storageStateJSON = `{
+  "cookies": [
+    {"name": "SOCS", "value": "CAESHAgBEhJnd3NfMjAyNDA4MTItMF9SQzIaAmVuIAEaBgiA", "domain": ".google.com", "path": "/", "expires": 1760000000, "httpOnly": false, "secure": true, "sameSite": "Lax"}
+  ],
+  "origins": [
+    {"origin": "https://www.google.com", "localStorage": [{"name": "consent", "value": "rejected"}]}
+  ]
+}`
+
+type storageContext struct {
+	playwright.BrowserContext
+
+	cookies [][]playwright.OptionalCookie
+	scripts []string
+	onClose func(playwright.BrowserContext)
+}
+
+func (c *storageContext) AddCookies(cookies []playwright.OptionalCookie) error {
+	c.cookies = append(c.cookies, cookies)
+
+	return nil
+}
+
+func (c *storageContext) AddInitScript(script playwright.Script) error {
+	c.scripts = append(c.scripts, *script.Content)
+
+	return nil
+}
+
+func (c *storageContext) OnClose(fn func(playwright.BrowserContext)) {
+	c.onClose = fn
+}
+
+type storagePage struct {
+	bctx *storageContext
+}
+
+func (p *storagePage) Context() playwright.BrowserContext {
+	return p.bctx
+}
+
+func Test_ApplyStorageState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte(storageStateJSON), 0o600))
+
+	state, err := gmaps.LoadStorageState(path)
+	require.NoError(t, err)
+
+	settings := &gmaps.Settings{StorageState: state}
+
+	bctx := &storageContext{}
+	page := &storagePage{bctx: bctx}
+
+	require.NoError(t, gmaps.ApplyStorageState(settings, page))
+
+	require.Len(t, bctx.cookies, 1)
+	require.Len(t, bctx.cookies[0], 1)
+
+	cookie := bctx.cookies[0][0]
+	require.Equal(t, "SOCS", cookie.Name)
+	require.Equal(t, ".google.com", *cookie.Domain)
+	require.True(t, *cookie.Secure)
+
+	require.Len(t, bctx.scripts, 1)
+	require.Contains(t, bctx.scripts[0], `"https://www.google.com":[{"name":"consent","value":"rejected"}]`)
+
+	// the state is loaded once per context
+	require.NoError(t, gmaps.ApplyStorageState(settings, page))
+	require.Len(t, bctx.cookies, 1)
+
+	// and again into a new context
+	other := &storageContext{}
+	require.NoError(t, gmaps.ApplyStorageState(settings, &storagePage{bctx: other}))
+	require.Len(t, other.cookies, 1)
+
+	// a closed context is forgotten
+	bctx.onClose(bctx)
+	require.NoError(t, gmaps.ApplyStorageState(settings, page))
+	require.Len(t, bctx.cookies, 2)
+}
+
+func Test_ApplyStorageStateUnset(t *testing.T) {
+	bctx := &storageContext{}
+	require.NoError(t, gmaps.ApplyStorageState(nil, &storagePage{bctx: bctx}))
+	require.NoError(t, gmaps.ApplyStorageState(&gmaps.Settings{}, &storagePage{bctx: bctx}))
+	require.Empty(t, bctx.cookies)
+	require.Empty(t, bctx.scripts)
+}
+
+func Test_LoadStorageStateInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("cookies"), 0o600))
+
+	_, err := gmaps.LoadStorageState(path)
+	require.ErrorContains(t, err, path)
+
+	_, err = gmaps.LoadStorageState(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,337 @@
+package gmaps
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// ErrBlocked is returned when Google answers with its CAPTCHA page or a
+// 429 instead of the requested page. It is transient: the job is retried
+// once the throttle slowed the run down.
+var ErrBlocked = errors.New("blocked by google (captcha or too many requests)")
+
+const (
+	// throttleThreshold is the number of blocked responses in a row that
+	// engages the throttle, or raises its level.
+	throttleThreshold = 3
+	// throttleRecovery is the number of successful responses in a row that
+	// lowers the level of an engaged throttle.
+	throttleRecovery = 10
+	// throttleMaxLevel caps the level, i.e. the slowdown.
+	throttleMaxLevel = 5
+	// throttleBasePause is the pause before every navigation at level 1.
+	// It doubles with every level.
+	throttleBasePause = 2 * time.Second
+	// throttleMaxPause caps the pause.
+	throttleMaxPause = time.Minute
+	// rampUpAfter is the number of successful responses in a row after
+	// which an adaptive concurrency is raised by one.
+	rampUpAfter = 5
+)
+
+// captchaSelector matches the CAPTCHA forms Google shows instead of a page.
+const captchaSelector = `form#captcha-form, div#recaptcha, iframe[src*="recaptcha"]`
+
+// Throttle slows the navigations of the jobs down when Google starts
+// blocking the run, so the run does not waste its requests and proxies on
+// CAPTCHA pages. It engages after a few blocked responses in a row: every
+// level halves the number of navigations in flight and doubles the pause
+// before each of them. Successful responses bring the level back down.
+// A nil throttle lets every navigation through.
+//
+// With a minimum concurrency the throttle also adapts the number of
+// navigations in flight: it starts at the minimum, adds one after every few
+// successful responses up to the concurrency of the run, and halves it,
+// down to the minimum, on blocked responses and runs of failures.
+type Throttle struct {
+	mu             sync.Mutex
+	concurrency    int
+	minConcurrency int
+	current        int
+	level          int
+	blocked        int
+	failed         int
+	succeeded      int
+	streak         int
+	inFlight       int
+	released       chan struct{}
+
+	engaged atomic.Int64
+}
+
+// NewThrottle returns a throttle for a run with the given concurrency. A
+// minConcurrency greater than 0 enables the adaptive concurrency.
+func NewThrottle(concurrency, minConcurrency int) *Throttle {
+	return &Throttle{
+		concurrency:    concurrency,
+		minConcurrency: minConcurrency,
+		current:        min(minConcurrency, concurrency),
+		released:       make(chan struct{}),
+	}
+}
+
+// Level returns the current level, 0 when the throttle is not engaged.
+func (t *Throttle) Level() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.level
+}
+
+// Engaged returns the number of times the throttle was engaged or raised.
+func (t *Throttle) Engaged() int {
+	if t == nil {
+		return 0
+	}
+
+	return int(t.engaged.Load())
+}
+
+// Limit returns the number of navigations allowed in flight. It is 0, no
+// limit, when the throttle is not engaged and the concurrency is not
+// adaptive.
+func (t *Throttle) Limit() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.limit()
+}
+
+// Pause returns the pause before every navigation.
+func (t *Throttle) Pause() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.pause()
+}
+
+func (t *Throttle) limit() int {
+	var limit int
+
+	if t.level > 0 {
+		limit = max(t.concurrency>>t.level, 1)
+	}
+
+	if t.adaptive() && (limit == 0 || t.current < limit) {
+		limit = t.current
+	}
+
+	return limit
+}
+
+func (t *Throttle) adaptive() bool {
+	return t.minConcurrency > 0
+}
+
+// lower halves the adaptive concurrency, down to the minimum. It must be
+// called with mu held.
+func (t *Throttle) lower() {
+	t.streak = 0
+
+	if !t.adaptive() {
+		return
+	}
+
+	if n := max(t.current/2, t.minConcurrency); n < t.current {
+		t.current = n
+
+		log.Printf("concurrency lowered to %d", t.current)
+	}
+}
+
+// raise adds one to the adaptive concurrency after enough successes in a
+// row, up to the concurrency of the run. It must be called with mu held.
+func (t *Throttle) raise() {
+	if !t.adaptive() || t.current >= t.concurrency {
+		return
+	}
+
+	t.streak++
+
+	if t.streak < rampUpAfter {
+		return
+	}
+
+	t.streak = 0
+	t.current++
+
+	log.Printf("concurrency raised to %d of %d", t.current, t.concurrency)
+
+	t.wake()
+}
+
+func (t *Throttle) pause() time.Duration {
+	if t.level == 0 {
+		return 0
+	}
+
+	return min(throttleBasePause<<(t.level-1), throttleMaxPause)
+}
+
+// Observe records the outcome of a navigation.
+func (t *Throttle) Observe(blocked bool) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if blocked {
+		t.blocked++
+		t.failed = 0
+		t.succeeded = 0
+
+		t.lower()
+
+		if t.blocked < throttleThreshold {
+			return
+		}
+
+		t.blocked = 0
+
+		if t.level < throttleMaxLevel {
+			t.level++
+			t.engaged.Add(1)
+
+			log.Printf("google is blocking requests, throttling (level %d): at most %d pages at a time, %s pause",
+				t.level, t.limit(), t.pause())
+		}
+
+		return
+	}
+
+	t.blocked = 0
+	t.failed = 0
+
+	t.raise()
+
+	if t.level == 0 {
+		return
+	}
+
+	t.succeeded++
+
+	if t.succeeded < throttleRecovery {
+		return
+	}
+
+	t.succeeded = 0
+	t.level--
+
+	if t.level == 0 {
+		log.Printf("google stopped blocking requests, throttling disengaged")
+	} else {
+		log.Printf("throttling lowered to level %d", t.level)
+	}
+
+	// the limit was raised, wake up the waiting jobs
+	t.wake()
+}
+
+// ObserveFailure records a navigation that failed for another reason than
+// a block, e.g. a timeout. A run of failures lowers the adaptive
+// concurrency; it does not engage the throttle.
+func (t *Throttle) ObserveFailure() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.failed++
+	t.streak = 0
+
+	if t.failed < throttleThreshold {
+		return
+	}
+
+	t.failed = 0
+
+	t.lower()
+}
+
+// Acquire waits until a navigation is allowed, including the pause, and
+// returns the function that releases it.
+func (t *Throttle) Acquire(ctx context.Context) (func(), error) {
+	if t == nil {
+		return func() {}, nil
+	}
+
+	for {
+		t.mu.Lock()
+
+		limit := t.limit()
+		if limit == 0 || t.inFlight < limit {
+			t.inFlight++
+			pause := t.pause()
+			t.mu.Unlock()
+
+			if err := sleepContext(ctx, pause); err != nil {
+				t.release()
+
+				return nil, err
+			}
+
+			return t.release, nil
+		}
+
+		released := t.released
+		t.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+func (t *Throttle) release() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.inFlight--
+	t.wake()
+}
+
+// wake signals the jobs waiting in Acquire. It must be called with mu held.
+func (t *Throttle) wake() {
+	close(t.released)
+	t.released = make(chan struct{})
+}
+
+// throttle returns the throttle of s.Throttle, nil when there is none.
+func (s *Settings) throttle() *Throttle {
+	if s == nil {
+		return nil
+	}
+
+	return s.Throttle
+}
+
+// isBlockedResponse reports whether a response with the final url and
+// status is the CAPTCHA page of Google or a 429.
+func isBlockedResponse(u string, status int) bool {
+	return status == http.StatusTooManyRequests || strings.Contains(u, "google.com/sorry/")
+}
+
+// isBlockedPage reports whether page shows the CAPTCHA of Google.
+func isBlockedPage(page playwright.Page, status int) bool {
+	if isBlockedResponse(page.URL(), status) {
+		return true
+	}
+
+	n, err := page.Locator(captchaSelector).Count()
+
+	return err == nil && n > 0
+}
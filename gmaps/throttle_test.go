@@ -0,0 +1,207 @@
+package gmaps_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_ThrottleBacksOffOnCaptchas(t *testing.T) {
+	th := gmaps.NewThrottle(8, 0)
+
+	require.Zero(t, th.Level())
+	require.Zero(t, th.Limit())
+	require.Zero(t, th.Pause())
+
+	// a couple of blocked pages are not enough
+	th.Observe(true)
+	th.Observe(true)
+	require.Zero(t, th.Level())
+
+	th.Observe(true)
+	require.Equal(t, 1, th.Level())
+	require.Equal(t, 4, th.Limit())
+	require.Equal(t, 2*time.Second, th.Pause())
+
+	// a run of captchas keeps slowing down, up to one page at a time
+	for range 30 {
+		th.Observe(true)
+	}
+
+	require.Equal(t, 5, th.Level())
+	require.Equal(t, 1, th.Limit())
+	require.Equal(t, 32*time.Second, th.Pause())
+	require.Equal(t, 5, th.Engaged())
+
+	// a success resets the run of blocked pages
+	th.Observe(true)
+	th.Observe(true)
+	th.Observe(false)
+	th.Observe(true)
+	require.Equal(t, 5, th.Level())
+
+	// enough successes in a row recover
+	for range 50 {
+		th.Observe(false)
+	}
+
+	require.Zero(t, th.Level())
+	require.Zero(t, th.Limit())
+	require.Equal(t, 5, th.Engaged())
+}
+
+func Test_ThrottleAcquire(t *testing.T) {
+	th := gmaps.NewThrottle(2, 0)
+
+	// not engaged: no limit and no pause
+	var releases []func()
+
+	for range 10 {
+		release, err := th.Acquire(context.Background())
+		require.NoError(t, err)
+
+		releases = append(releases, release)
+	}
+
+	for _, release := range releases {
+		release()
+	}
+
+	for range 3 {
+		th.Observe(true)
+	}
+
+	require.Equal(t, 1, th.Limit())
+
+	// engaged: one page at a time, after the pause
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := th.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the cancelled acquire released its slot: recovering lets jobs through
+	for range 10 {
+		th.Observe(false)
+	}
+
+	require.Zero(t, th.Level())
+
+	release, err := th.Acquire(context.Background())
+	require.NoError(t, err)
+
+	release()
+}
+
+func Test_ThrottleAdaptiveConcurrency(t *testing.T) {
+	th := gmaps.NewThrottle(8, 2)
+
+	// starts at the minimum
+	require.Equal(t, 2, th.Limit())
+	require.Zero(t, th.Pause())
+
+	// ramps up by one every 5 successes, up to -c
+	for range 5 {
+		th.Observe(false)
+	}
+
+	require.Equal(t, 3, th.Limit())
+
+	for range 100 {
+		th.Observe(false)
+	}
+
+	require.Equal(t, 8, th.Limit())
+
+	// a run of failures halves it
+	th.ObserveFailure()
+	th.ObserveFailure()
+	require.Equal(t, 8, th.Limit())
+
+	th.ObserveFailure()
+	require.Equal(t, 4, th.Limit())
+
+	// a failure breaks the run of successes
+	for range 4 {
+		th.Observe(false)
+	}
+
+	th.ObserveFailure()
+
+	th.Observe(false)
+	require.Equal(t, 4, th.Limit())
+
+	// every captcha halves it, down to the minimum, and the throttle
+	// engages on top of it
+	th.Observe(true)
+	require.Equal(t, 2, th.Limit())
+
+	th.Observe(true)
+	th.Observe(true)
+	require.Equal(t, 2, th.Limit())
+	require.Equal(t, 1, th.Level())
+	require.Equal(t, 2*time.Second, th.Pause())
+
+	// the success rate recovers: the throttle disengages and the
+	// concurrency ramps up again
+	for range 10 {
+		th.Observe(false)
+	}
+
+	require.Zero(t, th.Level())
+	require.Equal(t, 4, th.Limit())
+
+	for range 50 {
+		th.Observe(false)
+	}
+
+	require.Equal(t, 8, th.Limit())
+}
+
+func Test_ThrottleAdaptiveAcquire(t *testing.T) {
+	th := gmaps.NewThrottle(4, 1)
+
+	release, err := th.Acquire(context.Background())
+	require.NoError(t, err)
+
+	// the second navigation waits for the first one
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = th.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	acquired := make(chan func())
+
+	go func() {
+		r, err := th.Acquire(context.Background())
+		if err == nil {
+			acquired <- r
+		}
+	}()
+
+	// raising the concurrency lets it through without a release
+	for range 5 {
+		th.Observe(false)
+	}
+
+	select {
+	case r := <-acquired:
+		r()
+	case <-time.After(5 * time.Second):
+		t.Fatal("the waiting navigation was not let through")
+	}
+
+	release()
+}
+
+func Test_IsBlockedResponse(t *testing.T) {
+	require.True(t, gmaps.IsBlockedResponse("https://www.google.com/maps/search/cafe", 429))
+	require.True(t, gmaps.IsBlockedResponse("https://www.google.com/sorry/index?continue=https://www.google.com/maps", 200))
+	require.True(t, gmaps.IsBlockedResponse("https://ipv4.google.com/sorry/index", 200))
+	require.False(t, gmaps.IsBlockedResponse("https://www.google.com/maps/place/Kipriakon", 200))
+}
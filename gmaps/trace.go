@@ -0,0 +1,54 @@
+package gmaps
+
+import (
+	"context"
+
+	"github.com/gosom/scrapemate"
+)
+
+// Trace identifies the run and the seed job a job comes from. Its ID, the
+// correlation ID, is added to the log lines of the job and of the jobs it
+// creates, and to the places they produce, so that a place can be traced
+// back through the logs, e.g. in runs spread over several workers.
+type Trace struct {
+	RunID  string
+	SeedID string
+}
+
+// ID returns the correlation ID of the trace, "<run ID>/<seed ID>", or an
+// empty string when the job is not traced.
+func (t Trace) ID() string {
+	if t.RunID == "" && t.SeedID == "" {
+		return ""
+	}
+
+	return t.RunID + "/" + t.SeedID
+}
+
+// WithTrace traces the job, its place jobs and the searches of
+// -expand-nearby they trigger with t.
+func WithTrace(t Trace) GmapJobOptions {
+	return func(j *GmapJob) {
+		j.Trace = t
+	}
+}
+
+// WithPlaceJobTrace traces the job with t. Its place gets the correlation
+// ID of t.
+func WithPlaceJobTrace(t Trace) PlaceJobOptions {
+	return func(j *PlaceJob) {
+		j.Trace = t
+	}
+}
+
+// withCorrelationID returns ctx with a logger that adds id to the log
+// lines as correlation_id. It returns ctx as is when id is empty.
+func withCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+
+	log := scrapemate.GetLoggerFromContext(ctx)
+
+	return scrapemate.ContextWithLogger(ctx, log.With("correlation_id", id))
+}
@@ -0,0 +1,73 @@
+package gmaps_test
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/kit/logging"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_TraceID(t *testing.T) {
+	require.Empty(t, gmaps.Trace{}.ID())
+	require.Equal(t, "3f9a1c/7", gmaps.Trace{RunID: "3f9a1c", SeedID: "7"}.ID())
+}
+
+func Test_TraceReachesPlaceJob(t *testing.T) {
+	const u = "https://www.google.com/maps/place/a/data=!4m2!3m1!1s0x14e732fd76f0d90d:0xe5415928d6702b47"
+
+	var logs bytes.Buffer
+
+	ctx := scrapemate.ContextWithLogger(context.Background(), logging.New("zerolog", logging.INFO, &logs))
+
+	job := gmaps.NewGmapJob("line-1", "en", "cafe in athens", 1, false, "", 0,
+		gmaps.WithTrace(gmaps.Trace{RunID: "3f9a1c", SeedID: "7"}))
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(
+		`<div role="feed"><div jsaction><a href="` + u + `"></a></div></div>`))
+	require.NoError(t, err)
+
+	_, next, err := job.Process(ctx, &scrapemate.Response{Document: doc})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+
+	// the log lines of the search carry the correlation ID
+	require.Contains(t, logs.String(), `"correlation_id":"3f9a1c/7"`)
+
+	place, ok := next[0].(*gmaps.PlaceJob)
+	require.True(t, ok)
+	require.Equal(t, gmaps.Trace{RunID: "3f9a1c", SeedID: "7"}, place.Trace)
+
+	resp := scrapemate.Response{Meta: map[string]any{"json": loadPlaceFixture(t, "restaurant")}}
+
+	data, _, err := place.Process(ctx, &resp)
+	require.NoError(t, err)
+
+	entry, ok := data.(*gmaps.Entry)
+	require.True(t, ok)
+	require.Equal(t, "3f9a1c/7", entry.CorrelationID)
+
+	idx := slices.Index(entry.CsvHeaders(), "correlation_id")
+	require.NotEqual(t, -1, idx)
+	require.Equal(t, "3f9a1c/7", entry.CsvRow()[idx])
+}
+
+func Test_TraceUntracedJob(t *testing.T) {
+	job := gmaps.NewPlaceJob("seed", "en", "https://www.google.com/maps/place/x", false, false)
+
+	resp := scrapemate.Response{Meta: map[string]any{"json": loadPlaceFixture(t, "restaurant")}}
+
+	data, _, err := job.Process(context.Background(), &resp)
+	require.NoError(t, err)
+
+	entry, ok := data.(*gmaps.Entry)
+	require.True(t, ok)
+	require.Empty(t, entry.CorrelationID)
+}
@@ -0,0 +1,113 @@
+package gmaps
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/playwright-community/playwright-go"
+)
+
+// DefaultWaitTimeout is how long jobs wait for a page to be ready after
+// navigating to it, when no timeout is configured.
+const DefaultWaitTimeout = 5 * time.Second
+
+// Navigation wait states of Settings.WaitUntil.
+const (
+	WaitUntilDOMContentLoaded = "domcontentloaded"
+	WaitUntilLoad             = "load"
+	WaitUntilNetworkIdle      = "networkidle"
+)
+
+// waitUntilState returns the state of s.WaitUntil, or def.
+func (s *Settings) waitUntilState(def *playwright.WaitUntilState) *playwright.WaitUntilState {
+	if s == nil {
+		return def
+	}
+
+	switch s.WaitUntil {
+	case WaitUntilDOMContentLoaded:
+		return playwright.WaitUntilStateDomcontentloaded
+	case WaitUntilLoad:
+		return playwright.WaitUntilStateLoad
+	case WaitUntilNetworkIdle:
+		return playwright.WaitUntilStateNetworkidle
+	default:
+		return def
+	}
+}
+
+type pageWaiter interface {
+	URL() string
+	WaitForURL(url any, options ...playwright.PageWaitForURLOptions) error
+	WaitForSelector(selector string, options ...playwright.PageWaitForSelectorOptions) (playwright.ElementHandle, error)
+}
+
+// waitTimeout returns timeout, or DefaultWaitTimeout when it is not set.
+func waitTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return DefaultWaitTimeout
+	}
+
+	return timeout
+}
+
+// waitForPage waits up to timeout for the page to be ready.
+//
+// With a selector it returns as soon as an element matching it is attached,
+// so fast pages don't wait longer than needed. A selector that never shows
+// up is not an error: the place data is read from the page state, which
+// is usually there anyway.
+// Without a selector it waits for the DOM of the current URL to be loaded.
+func (s *Settings) waitForPage(page pageWaiter, selector string, timeout time.Duration) error {
+	ms := playwright.Float(float64(waitTimeout(timeout).Milliseconds()))
+
+	if selector != "" {
+		//nolint:staticcheck // TODO replace with the new playwright API
+		_, _ = page.WaitForSelector(selector, playwright.PageWaitForSelectorOptions{
+			State:   playwright.WaitForSelectorStateAttached,
+			Timeout: ms,
+		})
+
+		return nil
+	}
+
+	return page.WaitForURL(page.URL(), playwright.PageWaitForURLOptions{
+		WaitUntil: s.waitUntilState(playwright.WaitUntilStateDomcontentloaded),
+		Timeout:   ms,
+	})
+}
+
+// browse is the navigation of the jobs that visit websites. It is
+// scrapemate.Job.BrowserActions with the wait state and the blocked
+// resources of s.
+func (s *Settings) browse(page playwright.Page, u string) scrapemate.Response {
+	var resp scrapemate.Response
+
+	if err := s.applyBlockedResources(page); err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	pageResponse, err := page.Goto(u, playwright.PageGotoOptions{
+		WaitUntil: s.waitUntilState(playwright.WaitUntilStateNetworkidle),
+	})
+	if err != nil {
+		resp.Error = err
+
+		return resp
+	}
+
+	resp.URL = pageResponse.URL()
+	resp.StatusCode = pageResponse.Status()
+	resp.Headers = make(http.Header, len(pageResponse.Headers()))
+
+	for k, v := range pageResponse.Headers() {
+		resp.Headers.Add(k, v)
+	}
+
+	resp.Body, resp.Error = pageResponse.Body()
+
+	return resp
+}
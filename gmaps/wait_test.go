@@ -0,0 +1,80 @@
+package gmaps_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+type fakeWaitPage struct {
+	urlTimeout      float64
+	waitUntil       playwright.WaitUntilState
+	selector        string
+	selectorTimeout float64
+	err             error
+}
+
+func (p *fakeWaitPage) URL() string {
+	return "https://www.google.com/maps/place/x"
+}
+
+func (p *fakeWaitPage) WaitForURL(_ any, options ...playwright.PageWaitForURLOptions) error {
+	p.urlTimeout = *options[0].Timeout
+	p.waitUntil = *options[0].WaitUntil
+
+	return p.err
+}
+
+func (p *fakeWaitPage) WaitForSelector(selector string, options ...playwright.PageWaitForSelectorOptions) (playwright.ElementHandle, error) {
+	p.selector = selector
+	p.selectorTimeout = *options[0].Timeout
+
+	return nil, p.err
+}
+
+func Test_WaitForPage(t *testing.T) {
+	page := &fakeWaitPage{}
+
+	require.NoError(t, gmaps.WaitForPage(nil, page, "", 0))
+	require.InDelta(t, 5000, page.urlTimeout, 0)
+	require.Empty(t, page.selector)
+
+	page = &fakeWaitPage{}
+
+	require.NoError(t, gmaps.WaitForPage(nil, page, "", 12*time.Second))
+	require.InDelta(t, 12000, page.urlTimeout, 0)
+
+	// a selector replaces the DOM wait and a timeout is not fatal
+	page = &fakeWaitPage{err: errors.New("timeout")}
+
+	require.NoError(t, gmaps.WaitForPage(nil, page, "h1", 2*time.Second))
+	require.Equal(t, "h1", page.selector)
+	require.InDelta(t, 2000, page.selectorTimeout, 0)
+	require.Zero(t, page.urlTimeout)
+
+	page = &fakeWaitPage{err: errors.New("timeout")}
+
+	require.Error(t, gmaps.WaitForPage(nil, page, "", time.Second))
+}
+
+func Test_WaitUntil(t *testing.T) {
+	page := &fakeWaitPage{}
+
+	require.NoError(t, gmaps.WaitForPage(nil, page, "", time.Second))
+	require.Equal(t, *playwright.WaitUntilStateDomcontentloaded, page.waitUntil)
+
+	require.NoError(t, gmaps.WaitForPage(&gmaps.Settings{WaitUntil: gmaps.WaitUntilNetworkIdle}, page, "", time.Second))
+	require.Equal(t, *playwright.WaitUntilStateNetworkidle, page.waitUntil)
+
+	require.NoError(t, gmaps.WaitForPage(&gmaps.Settings{WaitUntil: gmaps.WaitUntilLoad}, page, "", time.Second))
+	require.Equal(t, *playwright.WaitUntilStateLoad, page.waitUntil)
+
+	// an empty state keeps the default
+	require.NoError(t, gmaps.WaitForPage(&gmaps.Settings{}, page, "", time.Second))
+	require.Equal(t, *playwright.WaitUntilStateDomcontentloaded, page.waitUntil)
+}
@@ -0,0 +1,68 @@
+package gmaps
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// warmContexts are the browser contexts that were warmed up.
+var warmContexts sync.Map
+
+// warmupURLs are the pages visited by the warmup, in order, with the
+// language of the job: the home pages of Google and of Google Maps.
+var warmupURLs = []string{
+	"https://www.google.com/?hl=%s",
+	"https://www.google.com/maps?hl=%s",
+}
+
+type warmupPage interface {
+	consentPage
+	Context() playwright.BrowserContext
+	Goto(u string, options ...playwright.PageGotoOptions) (playwright.Response, error)
+}
+
+// warmUp warms up the browser context of page for s.Warmup, once per
+// context: it visits a few Google pages, getting past the consent form, so
+// the context has the cookies of a browser that was used before. Google
+// tends to block the first requests of a fresh browser, more so behind a
+// fresh proxy. Contexts with a storage state are not warmed up: the state
+// has the cookies already. A failed warmup fails the job, and the retry
+// warms the context up again.
+func (s *Settings) warmUp(page warmupPage, langCode string) error {
+	if s == nil || !s.Warmup || s.StorageState != nil {
+		return nil
+	}
+
+	bctx := page.Context()
+
+	if _, loaded := warmContexts.LoadOrStore(bctx, struct{}{}); loaded {
+		return nil
+	}
+
+	bctx.OnClose(func(bctx playwright.BrowserContext) {
+		warmContexts.Delete(bctx)
+	})
+
+	for _, u := range warmupURLs {
+		u = fmt.Sprintf(u, url.QueryEscape(langCode))
+
+		if _, err := page.Goto(u, playwright.PageGotoOptions{
+			WaitUntil: s.waitUntilState(playwright.WaitUntilStateDomcontentloaded),
+		}); err != nil {
+			warmContexts.Delete(bctx)
+
+			return fmt.Errorf("warming up with %s: %w", u, err)
+		}
+
+		if err := s.handleConsent(page); err != nil {
+			warmContexts.Delete(bctx)
+
+			return fmt.Errorf("warming up with %s: %w", u, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package gmaps_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/playwright-community/playwright-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// warmupPage is a place page that records its navigations.
+type warmupPage struct {
+	drainPage
+
+	bctx  *storageContext
+	gotos []string
+}
+
+func (p *warmupPage) Context() playwright.BrowserContext {
+	return p.bctx
+}
+
+func (p *warmupPage) Goto(u string, _ ...playwright.PageGotoOptions) (playwright.Response, error) {
+	p.gotos = append(p.gotos, u)
+
+	return &drainResponse{}, nil
+}
+
+func Test_PlaceJobWarmup(t *testing.T) {
+	raw := string(loadPlaceFixture(t, "pub"))
+
+	newPage := func() *warmupPage {
+		return &warmupPage{drainPage: drainPage{raw: raw, checks: 2}, bctx: &storageContext{}}
+	}
+
+	run := func(settings *gmaps.Settings, page *warmupPage) {
+		job := gmaps.NewPlaceJob("seed", "de", drainPlaceURL, false, false, gmaps.WithPlaceJobSettings(settings))
+
+		resp := job.BrowserActions(context.Background(), page)
+		require.NoError(t, resp.Error)
+	}
+
+	settings := &gmaps.Settings{PlaceMarker: "h1.DUwDvf", Warmup: true}
+
+	// the warmup pages are visited before the first place only
+	page := newPage()
+
+	run(settings, page)
+	run(settings, page)
+
+	require.Equal(t, []string{
+		"https://www.google.com/?hl=de",
+		"https://www.google.com/maps?hl=de",
+		drainPlaceURL,
+		drainPlaceURL,
+	}, page.gotos)
+
+	// a new context is warmed up again
+	page.bctx.onClose(page.bctx)
+
+	run(settings, page)
+	require.Len(t, page.gotos, 7)
+
+	// the storage state has the cookies of the warmup already
+	page = newPage()
+	run(&gmaps.Settings{PlaceMarker: "h1.DUwDvf", Warmup: true, StorageState: &playwright.StorageState{}}, page)
+	require.Equal(t, []string{drainPlaceURL}, page.gotos)
+
+	page = newPage()
+	run(&gmaps.Settings{PlaceMarker: "h1.DUwDvf"}, page)
+	require.Equal(t, []string{drainPlaceURL}, page.gotos)
+}
@@ -0,0 +1,256 @@
+package gmaps
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/google/uuid"
+	"github.com/gosom/scrapemate"
+	"github.com/playwright-community/playwright-go"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+)
+
+const websiteSearchURL = "https://html.duckduckgo.com/html/"
+
+// maxWebsiteResults is the number of search results considered by
+// guessWebsite. Lower results rarely belong to the place.
+const maxWebsiteResults = 5
+
+type WebsiteSearchJobOptions func(*WebsiteSearchJob)
+
+// WebsiteSearchJob searches the web for the name and address of a place
+// without a website on Google Maps and stores the most likely website in
+// Entry.WebSiteGuessed, see guessWebsite.
+type WebsiteSearchJob struct {
+	scrapemate.Job
+
+	Entry       *Entry
+	ExitMonitor exiter.Exiter
+
+	// settings are the settings of the run of the job, see
+	// WithWebsiteSearchJobSettings.
+	settings *Settings
+}
+
+func NewWebsiteSearchJob(parentID string, entry *Entry, opts ...WebsiteSearchJobOptions) *WebsiteSearchJob {
+	const (
+		defaultPrio       = scrapemate.PriorityHigh
+		defaultMaxRetries = 0
+	)
+
+	job := WebsiteSearchJob{
+		Job: scrapemate.Job{
+			ID:         uuid.New().String(),
+			ParentID:   parentID,
+			Method:     "GET",
+			URL:        websiteSearchURL,
+			URLParams:  map[string]string{"q": strings.TrimSpace(entry.Title + " " + entry.Address)},
+			MaxRetries: defaultMaxRetries,
+			Priority:   defaultPrio,
+		},
+	}
+
+	job.Entry = entry
+
+	for _, opt := range opts {
+		opt(&job)
+	}
+
+	return &job
+}
+
+func WithWebsiteSearchJobExitMonitor(exitMonitor exiter.Exiter) WebsiteSearchJobOptions {
+	return func(j *WebsiteSearchJob) {
+		j.ExitMonitor = exitMonitor
+	}
+}
+
+// WithWebsiteSearchJobSettings sets the settings of the run of the job, see
+// Settings.
+func WithWebsiteSearchJobSettings(s *Settings) WebsiteSearchJobOptions {
+	return func(j *WebsiteSearchJob) {
+		j.settings = s
+	}
+}
+
+func (j *WebsiteSearchJob) Process(_ context.Context, resp *scrapemate.Response) (any, []scrapemate.IJob, error) {
+	defer func() {
+		resp.Document = nil
+		resp.Body = nil
+	}()
+
+	defer func() {
+		if j.ExitMonitor != nil {
+			j.ExitMonitor.IncrPlacesCompleted(1)
+		}
+	}()
+
+	// the place is written without a guess when the search failed
+	if resp.Error != nil {
+		return j.Entry, nil, nil
+	}
+
+	doc, ok := resp.Document.(*goquery.Document)
+	if !ok {
+		return j.Entry, nil, nil
+	}
+
+	j.Entry.WebSiteGuessed = guessWebsite(j.Entry.Title, searchResultLinks(doc))
+
+	return j.Entry, nil, nil
+}
+
+func (j *WebsiteSearchJob) GetCacheKey() string {
+	return j.settings.cacheKey(j.Job.GetCacheKey())
+}
+
+// BrowserActions runs the search unless the request budget is used up,
+// in which case the place is written without a guess.
+func (j *WebsiteSearchJob) BrowserActions(_ context.Context, page playwright.Page) scrapemate.Response {
+	if !allowRequest(j.ExitMonitor) {
+		return scrapemate.Response{Error: ErrRequestBudget}
+	}
+
+	return j.settings.browse(page, j.GetFullURL())
+}
+
+func (j *WebsiteSearchJob) ProcessOnFetchError() bool {
+	return true
+}
+
+// searchResultLinks returns the target URLs of the results of a search
+// page, in rank order.
+func searchResultLinks(doc *goquery.Document) []string {
+	var links []string
+
+	doc.Find("a.result__a").Each(func(_ int, s *goquery.Selection) {
+		href, ok := s.Attr("href")
+		if !ok {
+			return
+		}
+
+		// results link to a redirect with the target in the uddg parameter
+		if u, err := url.Parse(href); err == nil && strings.HasSuffix(u.Host, "duckduckgo.com") {
+			href = u.Query().Get("uddg")
+		}
+
+		if href != "" {
+			links = append(links, href)
+		}
+	})
+
+	return links
+}
+
+// directorySites are domain labels of social networks, review sites,
+// delivery services and other sites that list businesses without being
+// their website.
+var directorySites = map[string]bool{
+	"apple": true, "bing": true, "booking": true, "deliveroo": true,
+	"doordash": true, "duckduckgo": true, "expedia": true, "facebook": true,
+	"foursquare": true, "google": true, "grubhub": true, "instagram": true,
+	"just-eat": true, "justeat": true, "linkedin": true, "mapquest": true,
+	"opentable": true, "pinterest": true, "restaurantguru": true, "thefork": true,
+	"tiktok": true, "tripadvisor": true, "twitter": true, "ubereats": true,
+	"wanderlog": true, "wikipedia": true, "x": true, "yellowpages": true,
+	"yelp": true, "youtube": true, "zomato": true,
+}
+
+// nameStopWords are left out when the name of a place is matched against
+// a domain.
+var nameStopWords = map[string]bool{
+	"and": true, "the": true, "und": true, "der": true, "die": true, "das": true,
+	"les": true, "des": true, "del": true, "los": true, "las": true,
+}
+
+// guessWebsite picks the website of the place called name among the
+// search results. Only the first maxWebsiteResults results are looked at
+// and directory sites are skipped. A result is a candidate when its host,
+// without dots and dashes, contains the words of the name joined together
+// (redlion for The Red Lion) or at least one word of four letters or more.
+// The first result with the joined name wins, else the first candidate.
+// When nothing matches, the guess is left empty: a wrong website is worse
+// than none.
+//
+// The guess is the scheme and host of the result, e.g. https://redlion.co.uk.
+func guessWebsite(name string, results []string) string {
+	words := nameWords(name)
+	if len(words) == 0 {
+		return ""
+	}
+
+	joined := strings.Join(words, "")
+
+	var candidate string
+
+	for i, result := range results {
+		if i == maxWebsiteResults {
+			break
+		}
+
+		u, err := url.Parse(result)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+
+		host := strings.ToLower(u.Hostname())
+		if isDirectorySite(host) {
+			continue
+		}
+
+		compact := strings.NewReplacer(".", "", "-", "").Replace(strings.TrimPrefix(host, "www."))
+		site := u.Scheme + "://" + host
+
+		if strings.Contains(compact, joined) {
+			return site
+		}
+
+		if candidate != "" {
+			continue
+		}
+
+		for _, w := range words {
+			if len([]rune(w)) >= 4 && strings.Contains(compact, w) {
+				candidate = site
+
+				break
+			}
+		}
+	}
+
+	return candidate
+}
+
+// nameWords returns the lower case words of name without stop words.
+func nameWords(name string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := fields[:0]
+
+	for _, f := range fields {
+		if !nameStopWords[f] {
+			words = append(words, f)
+		}
+	}
+
+	return words
+}
+
+func isDirectorySite(host string) bool {
+	labels := strings.Split(host, ".")
+
+	// the last label is the top level domain
+	for _, label := range labels[:len(labels)-1] {
+		if directorySites[label] {
+			return true
+		}
+	}
+
+	return false
+}
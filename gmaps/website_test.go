@@ -0,0 +1,162 @@
+package gmaps_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_GuessWebsite(t *testing.T) {
+	tests := []struct {
+		name     string
+		place    string
+		results  []string
+		expected string
+	}{
+		{
+			name:  "directories are skipped",
+			place: "The Red Lion",
+			results: []string{
+				"https://www.tripadvisor.co.uk/Restaurant_Review-The_Red_Lion.html",
+				"https://www.facebook.com/theredlionpub",
+				"https://www.redlion-westminster.co.uk/menu",
+			},
+			expected: "https://www.redlion-westminster.co.uk",
+		},
+		{
+			name:  "joined name beats a single word",
+			place: "Kipriakon Taverna",
+			results: []string{
+				"https://kipriakon-athens.gr/",
+				"https://www.kipriakontaverna.gr/about",
+			},
+			expected: "https://www.kipriakontaverna.gr",
+		},
+		{
+			name:  "first single word match",
+			place: "Kipriakon Taverna",
+			results: []string{
+				"https://www.athens-food-blog.com/best-tavernas",
+				"https://kipriakon.gr/",
+				"https://kipriakon-athens.gr/",
+			},
+			expected: "https://kipriakon.gr",
+		},
+		{
+			name:  "short words do not match",
+			place: "Bar 21",
+			results: []string{
+				"https://www.bar-equipment.com/",
+			},
+			expected: "",
+		},
+		{
+			name:  "results after the fifth are ignored",
+			place: "Bäckerei Müller",
+			results: []string{
+				"https://www.yelp.de/biz/mueller",
+				"https://de.wikipedia.org/wiki/M%C3%BCller",
+				"https://www.google.com/maps",
+				"https://www.instagram.com/baeckerei",
+				"https://www.tiktok.com/@baeckerei",
+				"https://bäckereimüller.de/",
+			},
+			expected: "",
+		},
+		{
+			name:     "no results",
+			place:    "The Red Lion",
+			results:  nil,
+			expected: "",
+		},
+		{
+			name:  "invalid results",
+			place: "The Red Lion",
+			results: []string{
+				"mailto:info@redlion.co.uk",
+				"%%",
+				"http://redlion.co.uk",
+			},
+			expected: "http://redlion.co.uk",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, gmaps.GuessWebsite(tc.place, tc.results))
+		})
+	}
+}
+
+func Test_WebsiteSearchJob(t *testing.T) {
+	result := func(target string) string {
+		return `<div class="result"><a class="result__a" href="//duckduckgo.com/l/?uddg=` +
+			url.QueryEscape(target) + `&amp;rut=abc">title</a></div>`
+	}
+
+	page := "<html><body>" +
+		result("https://www.yelp.com/biz/kipriakon-athens") +
+		result("https://www.kipriakon.gr/") +
+		"</body></html>"
+
+	t.Run("place without website", func(t *testing.T) {
+		job := gmaps.NewPlaceJob("seed", "en", "https://www.google.com/maps/place/x", false, false,
+			gmaps.WithPlaceJobWebsiteEnrichment(),
+		)
+
+		resp := scrapemate.Response{Meta: map[string]any{"json": loadPlaceFixture(t, "restaurant")}}
+
+		data, next, err := job.Process(context.Background(), &resp)
+		require.NoError(t, err)
+		require.Nil(t, data)
+		require.False(t, job.UseInResults())
+		require.Len(t, next, 1)
+
+		search, ok := next[0].(*gmaps.WebsiteSearchJob)
+		require.True(t, ok)
+		require.True(t, strings.HasPrefix(search.URLParams["q"], "Kipriakon"))
+
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+		require.NoError(t, err)
+
+		data, next, err = search.Process(context.Background(), &scrapemate.Response{Document: doc})
+		require.NoError(t, err)
+		require.Empty(t, next)
+
+		entry, ok := data.(*gmaps.Entry)
+		require.True(t, ok)
+		require.Equal(t, "https://www.kipriakon.gr", entry.WebSiteGuessed)
+		require.Empty(t, entry.WebSite)
+	})
+
+	t.Run("failed search", func(t *testing.T) {
+		entry := gmaps.Entry{Title: "Kipriakon"}
+		search := gmaps.NewWebsiteSearchJob("seed", &entry)
+
+		data, _, err := search.Process(context.Background(), &scrapemate.Response{Error: errors.New("timeout")})
+		require.NoError(t, err)
+		require.Equal(t, &entry, data)
+		require.Empty(t, entry.WebSiteGuessed)
+	})
+
+	t.Run("place with website", func(t *testing.T) {
+		job := gmaps.NewPlaceJob("seed", "en", "https://www.google.com/maps/place/x", false, false,
+			gmaps.WithPlaceJobWebsiteEnrichment(),
+		)
+
+		resp := scrapemate.Response{Meta: map[string]any{"json": loadPlaceFixture(t, "pub")}}
+
+		data, next, err := job.Process(context.Background(), &resp)
+		require.NoError(t, err)
+		require.Empty(t, next)
+		require.NotNil(t, data)
+	})
+}
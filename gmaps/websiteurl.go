@@ -0,0 +1,101 @@
+package gmaps
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters added for analytics only. Parameters
+// starting with utm_ are tracking parameters too.
+var trackingParams = map[string]bool{
+	"gclid": true, "gbraid": true, "wbraid": true, "dclid": true,
+	"fbclid": true, "msclkid": true, "yclid": true, "igshid": true,
+	"mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true,
+	"srsltid": true,
+}
+
+// normalizeWebsite cleans the website of a place as extracted from Google
+// Maps, see cleanWebsite. The tracking parameters are removed by the jobs,
+// with their settings, see Settings.website.
+func normalizeWebsite(raw string) string {
+	return cleanWebsite(raw, false)
+}
+
+// website returns the website of a place with its tracking parameters
+// removed, with s.StripTrackingParams.
+func (s *Settings) website(raw string) string {
+	if s == nil || !s.StripTrackingParams {
+		return raw
+	}
+
+	return cleanWebsite(raw, true)
+}
+
+// cleanWebsite unwraps Google redirect URLs (/url?q=target) to their
+// target and, with strip, removes the tracking parameters from the query.
+// Other parameters are kept since some sites need them. Clean URLs and
+// URLs that cannot be parsed are returned as they are.
+func cleanWebsite(raw string, strip bool) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	changed := false
+
+	if isGoogleRedirect(u) {
+		q := u.Query()
+
+		target := q.Get("q")
+		if target == "" {
+			target = q.Get("url")
+		}
+
+		if t, err := url.Parse(target); err == nil && (t.Scheme == "http" || t.Scheme == "https") {
+			u, raw = t, target
+		}
+	}
+
+	if strip && u.RawQuery != "" {
+		q := u.Query()
+
+		for key := range q {
+			lower := strings.ToLower(key)
+			if trackingParams[lower] || strings.HasPrefix(lower, "utm_") {
+				q.Del(key)
+
+				changed = true
+			}
+		}
+
+		u.RawQuery = q.Encode()
+	}
+
+	// the URL is only rebuilt when needed, so clean URLs keep their form
+	if !changed {
+		return raw
+	}
+
+	return u.String()
+}
+
+// isGoogleRedirect reports whether u is a Google /url redirect, absolute
+// or relative to a Google page.
+func isGoogleRedirect(u *url.URL) bool {
+	if u.Path != "/url" {
+		return false
+	}
+
+	if u.Host == "" {
+		return true
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+	return strings.HasPrefix(host, "google.")
+}
@@ -0,0 +1,83 @@
+package gmaps_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+func Test_CleanWebsite(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		strip    bool
+		expected string
+	}{
+		{
+			name:     "google redirect",
+			raw:      "/url?q=https://www.kipriakon.gr/menu%3Flang%3Den&opi=79508299&sa=U&ved=0ahUKEwi",
+			expected: "https://www.kipriakon.gr/menu?lang=en",
+		},
+		{
+			name:     "absolute google redirect",
+			raw:      "https://www.google.com/url?q=http://redlion.co.uk/&sa=D",
+			expected: "http://redlion.co.uk/",
+		},
+		{
+			name:     "redirect with tracking params",
+			raw:      "https://www.google.com/url?q=https://www.coffeeisland.gr/%3Futm_source%3Dgoogle&sa=D",
+			strip:    true,
+			expected: "https://www.coffeeisland.gr/",
+		},
+		{
+			name:     "tracking params",
+			raw:      "https://www.coffeeisland.gr/?utm_campaign=website&utm_medium=organic&utm_source=google&gclid=abc",
+			strip:    true,
+			expected: "https://www.coffeeisland.gr/",
+		},
+		{
+			name:     "other params are kept",
+			raw:      "https://www.facebook.com/profile.php?id=100089941206221&UTM_SOURCE=google",
+			strip:    true,
+			expected: "https://www.facebook.com/profile.php?id=100089941206221",
+		},
+		{
+			name:     "tracking params without strip",
+			raw:      "https://www.coffeeisland.gr/?utm_source=google",
+			expected: "https://www.coffeeisland.gr/?utm_source=google",
+		},
+		{
+			name:     "clean url",
+			raw:      "https://nookofficial.gr/?b=2&a=1",
+			strip:    true,
+			expected: "https://nookofficial.gr/?b=2&a=1",
+		},
+		{
+			name:     "not a google redirect",
+			raw:      "https://example.com/url?q=https://other.example",
+			expected: "https://example.com/url?q=https://other.example",
+		},
+		{
+			name:     "empty",
+			raw:      "",
+			strip:    true,
+			expected: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, gmaps.CleanWebsite(tc.raw, tc.strip))
+		})
+	}
+}
+
+func Test_SettingsWebsite(t *testing.T) {
+	const raw = "https://example.com/?utm_source=gmb&id=1"
+
+	require.Equal(t, raw, gmaps.SettingsWebsite(nil, raw))
+	require.Equal(t, raw, gmaps.SettingsWebsite(&gmaps.Settings{}, raw))
+	require.Equal(t, "https://example.com/?id=1", gmaps.SettingsWebsite(&gmaps.Settings{StripTrackingParams: true}, raw))
+}
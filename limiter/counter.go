@@ -0,0 +1,41 @@
+package limiter
+
+import (
+	"sync"
+)
+
+var _ Limiter = (*counter)(nil)
+
+type counter struct {
+	mux    *sync.Mutex
+	limit  int
+	counts map[string]int
+}
+
+func (c *counter) Allow(key string) bool {
+	if key == "" {
+		return true
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.counts[key] >= c.limit {
+		return false
+	}
+
+	c.counts[key]++
+
+	return true
+}
+
+func (c *counter) Reached(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.counts[key] >= c.limit
+}
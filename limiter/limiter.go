@@ -0,0 +1,23 @@
+package limiter
+
+import (
+	"sync"
+)
+
+// Limiter caps how many items are allowed per key.
+type Limiter interface {
+	// Allow takes one slot for key and reports whether one was available.
+	Allow(key string) bool
+	// Reached reports whether all the slots for key are taken.
+	Reached(key string) bool
+}
+
+// New returns a Limiter that allows at most limit items per key.
+// The empty key is never limited.
+func New(limit int) Limiter {
+	return &counter{
+		limit:  limit,
+		counts: make(map[string]int),
+		mux:    &sync.Mutex{},
+	}
+}
@@ -0,0 +1,31 @@
+package limiter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/limiter"
+)
+
+func Test_LimiterPerKey(t *testing.T) {
+	l := limiter.New(2)
+
+	require.True(t, l.Allow("cafe"))
+	require.True(t, l.Allow("cafe"))
+	require.True(t, l.Reached("cafe"))
+	require.False(t, l.Allow("cafe"))
+
+	// other keys have their own budget
+	require.False(t, l.Reached("dentist"))
+	require.True(t, l.Allow("dentist"))
+	require.True(t, l.Allow("dentist"))
+	require.False(t, l.Allow("dentist"))
+
+	// the empty key is never limited
+	for range 5 {
+		require.True(t, l.Allow(""))
+	}
+
+	require.False(t, l.Reached(""))
+}
@@ -8,20 +8,21 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // utc_offset must work in images without zoneinfo
 
 	"github.com/gosom/google-maps-scraper/runner"
 	"github.com/gosom/google-maps-scraper/runner/databaserunner"
 	"github.com/gosom/google-maps-scraper/runner/filerunner"
 	"github.com/gosom/google-maps-scraper/runner/installplaywright"
 	"github.com/gosom/google-maps-scraper/runner/lambdaaws"
+	"github.com/gosom/google-maps-scraper/runner/printschema"
+	"github.com/gosom/google-maps-scraper/runner/validate"
 	"github.com/gosom/google-maps-scraper/runner/webrunner"
 )
 
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	runner.Banner()
-
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
@@ -31,9 +32,26 @@ func main() {
 		log.Println("Received signal, shutting down...")
 
 		cancel()
+
+		// the place pages in progress may still be finishing, see -drain-timeout
+		<-sigChan
+
+		log.Println("Received a second signal, exiting without waiting for the jobs in progress")
+
+		os.Exit(runner.ExitError)
 	}()
 
-	cfg := runner.ParseConfig()
+	cfg, err := runner.ParseConfig()
+	if err != nil {
+		cancel()
+		os.Stderr.WriteString(err.Error() + "\n")
+
+		os.Exit(runner.ExitCode(err))
+	}
+
+	if runner.BannerEnabled(cfg) {
+		runner.Banner()
+	}
 
 	runnerInstance, err := runnerFactory(cfg)
 	if err != nil {
@@ -42,7 +60,7 @@ func main() {
 
 		runner.Telemetry().Close()
 
-		os.Exit(1)
+		os.Exit(runner.ExitCode(err))
 	}
 
 	if err := runnerInstance.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
@@ -53,7 +71,7 @@ func main() {
 
 		cancel()
 
-		os.Exit(1)
+		os.Exit(runner.ExitCode(err))
 	}
 
 	_ = runnerInstance.Close(ctx)
@@ -65,6 +83,10 @@ func main() {
 }
 
 func runnerFactory(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.ValidateOnly {
+		return validate.New(cfg)
+	}
+
 	switch cfg.RunMode {
 	case runner.RunModeFile:
 		return filerunner.New(cfg)
@@ -78,6 +100,8 @@ func runnerFactory(cfg *runner.Config) (runner.Runner, error) {
 		return lambdaaws.New(cfg)
 	case runner.RunModeAwsLambdaInvoker:
 		return lambdaaws.NewInvoker(cfg)
+	case runner.RunModePrintSchema:
+		return printschema.New(cfg)
 	default:
 		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
 	}
@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+var MigrationsFS = migrationsFS
+
+// LoadMigrations returns the versions and names of the migrations of fsys,
+// e.g. 0005_create_reviews.
+func LoadMigrations(fsys fs.FS) ([]string, error) {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	ans := make([]string, 0, len(migrations))
+
+	for _, m := range migrations {
+		ans = append(ans, fmt.Sprintf("%04d_%s", m.version, m.name))
+	}
+
+	return ans, nil
+}
+
+// EncodeJob and DecodeJob encode and decode the jobs of the queue.
+var (
+	EncodeJob = encodeJob
+	DecodeJob = decodeJob
+)
@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// migrationsLockKey is the key of the advisory lock held while migrating,
+// so workers starting together apply every migration once.
+const migrationsLockKey int64 = 0x676d617073 // "gmaps"
+
+//go:embed migrations/*.up.sql
+var migrationsFS embed.FS
+
+// migrationName matches the files of the migrations, e.g.
+// 0005_create_reviews.up.sql.
+var migrationName = regexp.MustCompile(`^(\d+)_(.+)\.up\.sql$`)
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// Migrate brings the schema of db up to date: it applies, in order, the
+// migrations not recorded in the gmaps_migrations table yet. Every migration
+// runs in a transaction with its record. The migrations are idempotent, so
+// databases set up with the scripts of older versions are migrated too.
+//
+// An advisory lock serializes the workers calling Migrate at startup.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	migrations, err := loadMigrations(migrationsFS)
+	if err != nil {
+		return err
+	}
+
+	return migrate(ctx, db, migrations)
+}
+
+func migrate(ctx context.Context, db *sql.DB, migrations []migration) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	// the lock belongs to the session, so the migrations and the unlock
+	// must use the same connection
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationsLockKey); err != nil {
+		return fmt.Errorf("locking migrations: %w", err)
+	}
+
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationsLockKey)
+	}()
+
+	q := `CREATE TABLE IF NOT EXISTS gmaps_migrations(
+		version INT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL
+	)`
+
+	if _, err := conn.ExecContext(ctx, q); err != nil {
+		return fmt.Errorf("creating migrations table: %w", err)
+	}
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", m.version, m.name, err)
+		}
+
+		log.Printf("applied migration %04d_%s", m.version, m.name)
+	}
+
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[int]bool, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM gmaps_migrations`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	applied := map[int]bool{}
+
+	for rows.Next() {
+		var version int
+
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, m migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return err
+	}
+
+	q := `INSERT INTO gmaps_migrations (version, name, applied_at) VALUES ($1, $2, $3)`
+
+	if _, err := tx.ExecContext(ctx, q, m.version, m.name, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// loadMigrations reads the up migrations of fsys, sorted by version.
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	files, err := fs.Glob(fsys, "migrations/*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(files))
+	versions := map[int]string{}
+
+	for _, file := range files {
+		base := path.Base(file)
+
+		match := migrationName.FindStringSubmatch(base)
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", base)
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %q: %w", base, err)
+		}
+
+		if other, ok := versions[version]; ok {
+			return nil, fmt.Errorf("migrations %q and %q have the same version", other, base)
+		}
+
+		versions[version] = base
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration{
+			version: version,
+			name:    match[2],
+			sql:     string(data),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
@@ -0,0 +1,181 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/postgres"
+)
+
+// testDSNEnv names the variable with the DSN of a database the migration
+// tests may use. They create and drop a schema of their own in it.
+const testDSNEnv = "POSTGRES_TEST_DSN"
+
+func Test_LoadMigrations(t *testing.T) {
+	names, err := postgres.LoadMigrations(postgres.MigrationsFS)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"0001_create_tables",
+		"0002_add_lat_lon_results",
+		"0003_results_jsonb",
+		"0004_add-index-gmaps_jobs",
+		"0005_create_reviews",
+		"0006_add_seed_hash_gmaps_jobs",
+		"0007_add_lease_gmaps_jobs",
+	}, names)
+}
+
+func Test_LoadMigrationsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		fsys  fstest.MapFS
+		error string
+	}{
+		{
+			name: "no version",
+			fsys: fstest.MapFS{
+				"migrations/create_tables.up.sql": {Data: []byte("SELECT 1")},
+			},
+			error: `invalid migration file name "create_tables.up.sql"`,
+		},
+		{
+			name: "same version",
+			fsys: fstest.MapFS{
+				"migrations/0001_create_tables.up.sql": {Data: []byte("SELECT 1")},
+				"migrations/1_create_reviews.up.sql":   {Data: []byte("SELECT 1")},
+			},
+			error: `migrations "0001_create_tables.up.sql" and "1_create_reviews.up.sql" have the same version`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := postgres.LoadMigrations(tc.fsys)
+			require.EqualError(t, err, tc.error)
+		})
+	}
+}
+
+func Test_Migrate(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	// workers starting together
+	var wg sync.WaitGroup
+
+	errs := make([]error, 4)
+
+	for i := range errs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs[i] = postgres.Migrate(ctx, db)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	var applied int
+
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM gmaps_migrations`).Scan(&applied))
+	require.Equal(t, 7, applied)
+
+	_, err := db.ExecContext(ctx, `INSERT INTO results (data) VALUES ('{}')`)
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, `INSERT INTO reviews (result_id, place_id, data) SELECT id, '1', '{}' FROM results`)
+	require.NoError(t, err)
+
+	// nothing left to apply
+	require.NoError(t, postgres.Migrate(ctx, db))
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM gmaps_migrations`).Scan(&applied))
+	require.Equal(t, 7, applied)
+}
+
+func Test_MigrateExistingSchema(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	// the schema of the migration scripts of older versions, without the
+	// migrations table
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE gmaps_jobs(
+			id UUID PRIMARY KEY,
+			priority SMALLINT NOT NULL,
+			payload_type TEXT NOT NULL,
+			payload BYTEA NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			status TEXT NOT NULL
+		);
+		CREATE TABLE results(
+			id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			data JSONB NOT NULL
+		);
+		CREATE INDEX idx_gmaps_jobs_status_priority_created ON gmaps_jobs(status, priority ASC, created_at ASC);
+		INSERT INTO results (data) VALUES ('{"title": "kept"}');
+	`)
+	require.NoError(t, err)
+
+	require.NoError(t, postgres.Migrate(ctx, db))
+
+	var title string
+
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT data->>'title' FROM results`).Scan(&title))
+	require.Equal(t, "kept", title)
+
+	var reviews int
+
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM reviews`).Scan(&reviews))
+	require.Zero(t, reviews)
+}
+
+// openTestDB opens the database of POSTGRES_TEST_DSN with a new schema as
+// the search path, dropped at the end of the test. The test is skipped
+// without the variable.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	cfg, err := pgx.ParseConfig(dsn)
+	require.NoError(t, err)
+
+	admin := stdlib.OpenDB(*cfg)
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("gmaps_test_%d", time.Now().UnixNano())
+
+	_, err = admin.Exec(`CREATE SCHEMA ` + schema)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+	})
+
+	cfg.RuntimeParams["search_path"] = schema
+
+	db := stdlib.OpenDB(*cfg)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
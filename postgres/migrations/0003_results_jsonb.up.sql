@@ -0,0 +1,15 @@
+ALTER TABLE results
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS category,
+    DROP COLUMN IF EXISTS address,
+    DROP COLUMN IF EXISTS openhours,
+    DROP COLUMN IF EXISTS website,
+    DROP COLUMN IF EXISTS phone,
+    DROP COLUMN IF EXISTS pluscode,
+    DROP COLUMN IF EXISTS review_count,
+    DROP COLUMN IF EXISTS rating,
+    DROP COLUMN IF EXISTS latitude,
+    DROP COLUMN IF EXISTS longitude;
+
+ALTER TABLE results
+    ADD COLUMN IF NOT EXISTS data JSONB NOT NULL;
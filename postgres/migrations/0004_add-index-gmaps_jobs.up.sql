@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_gmaps_jobs_status_priority_created ON gmaps_jobs(status, priority ASC, created_at ASC);
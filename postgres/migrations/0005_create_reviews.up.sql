@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS reviews(
+    id INT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    result_id INT NOT NULL REFERENCES results(id) ON DELETE CASCADE,
+    place_id TEXT NOT NULL,
+    data JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_place_id ON reviews(place_id);
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_gmaps_jobs_seed_hash;
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS seed_hash;
+
+COMMIT;
@@ -0,0 +1,3 @@
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS seed_hash TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gmaps_jobs_seed_hash ON gmaps_jobs(seed_hash);
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS queued_at;
+ALTER TABLE gmaps_jobs DROP COLUMN IF EXISTS worker_id;
+
+COMMIT;
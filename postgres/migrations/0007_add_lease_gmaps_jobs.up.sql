@@ -0,0 +1,2 @@
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS worker_id TEXT;
+ALTER TABLE gmaps_jobs ADD COLUMN IF NOT EXISTS queued_at TIMESTAMP WITH TIME ZONE;
@@ -17,6 +17,7 @@ import (
 const (
 	statusNew    = "new"
 	statusQueued = "queued"
+	statusDone   = "done"
 	batchSize    = 10
 )
 
@@ -29,6 +30,8 @@ type provider struct {
 	errc      chan error
 	started   bool
 	batchSize int
+	settings  *gmaps.Settings
+	workerID  string
 }
 
 func NewProvider(db *sql.DB, opts ...ProviderOption) scrapemate.JobProvider {
@@ -60,6 +63,22 @@ func WithBatchSize(size int) ProviderOption {
 	}
 }
 
+// WithSettings gives the settings of the jobs of the run to the jobs read
+// from the queue. They are not encoded with the jobs.
+func WithSettings(s *gmaps.Settings) ProviderOption {
+	return func(p *provider) {
+		p.settings = s
+	}
+}
+
+// WithWorkerID records id as the worker of the jobs the provider takes from
+// the queue, see TrackDoneJobs.
+func WithWorkerID(id string) ProviderOption {
+	return func(p *provider) {
+		p.workerID = id
+	}
+}
+
 //nolint:gocritic // it contains about unnamed results
 func (p *provider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
 	outc := make(chan scrapemate.IJob)
@@ -105,11 +124,40 @@ func (p *provider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan err
 
 // Push pushes a job to the job provider
 func (p *provider) Push(ctx context.Context, job scrapemate.IJob) error {
+	_, err := pushJob(ctx, p.db, job, "")
+
+	return err
+}
+
+// pushJob inserts job in the queue, with seedHash unless it is empty. It
+// reports whether job was inserted, i.e. no job with its ID or seed hash
+// is in the queue.
+func pushJob(ctx context.Context, db *sql.DB, job scrapemate.IJob, seedHash string) (bool, error) {
 	q := `INSERT INTO gmaps_jobs
-		(id, priority, payload_type, payload, created_at, status)
+		(id, priority, payload_type, payload, created_at, status, seed_hash)
 		VALUES
-		($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING`
+		($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING`
+
+	payloadType, payload, err := encodeJob(job)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := db.ExecContext(ctx, q,
+		job.GetID(), job.GetPriority(), payloadType, payload, time.Now().UTC(), statusNew,
+		sql.NullString{String: seedHash, Valid: seedHash != ""},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return n > 0, err
+}
 
+// encodeJob returns the payload type and the payload of job.
+func encodeJob(job scrapemate.IJob) (string, []byte, error) {
 	var buf bytes.Buffer
 	enc := gob.NewEncoder(&buf)
 
@@ -120,29 +168,25 @@ func (p *provider) Push(ctx context.Context, job scrapemate.IJob) error {
 		payloadType = "search"
 
 		if err := enc.Encode(j); err != nil {
-			return err
+			return "", nil, err
 		}
 	case *gmaps.PlaceJob:
 		payloadType = "place"
 
 		if err := enc.Encode(j); err != nil {
-			return err
+			return "", nil, err
 		}
 	case *gmaps.EmailExtractJob:
 		payloadType = "email"
 
 		if err := enc.Encode(j); err != nil {
-			return err
+			return "", nil, err
 		}
 	default:
-		return fmt.Errorf("invalid job type %T", job)
+		return "", nil, fmt.Errorf("invalid job type %T", job)
 	}
 
-	_, err := p.db.ExecContext(ctx, q,
-		job.GetID(), job.GetPriority(), payloadType, buf.Bytes(), time.Now().UTC(), statusNew,
-	)
-
-	return err
+	return payloadType, buf.Bytes(), nil
 }
 
 func (p *provider) fetchJobs(ctx context.Context) {
@@ -152,7 +196,7 @@ func (p *provider) fetchJobs(ctx context.Context) {
 	q := `
 	WITH updated AS (
 		UPDATE gmaps_jobs
-		SET status = $1
+		SET status = $1, worker_id = NULLIF($4, ''), queued_at = now()
 		WHERE id IN (
 			SELECT id from gmaps_jobs
 			WHERE status = $2
@@ -178,7 +222,7 @@ func (p *provider) fetchJobs(ctx context.Context) {
 		default:
 		}
 
-		rows, err := p.db.QueryContext(ctx, q, statusQueued, statusNew, p.batchSize)
+		rows, err := p.db.QueryContext(ctx, q, statusQueued, statusNew, p.batchSize, p.workerID)
 		if err != nil {
 			p.errc <- err
 
@@ -197,7 +241,7 @@ func (p *provider) fetchJobs(ctx context.Context) {
 				return
 			}
 
-			job, err := decodeJob(payloadType, payload)
+			job, err := decodeJob(payloadType, payload, p.settings)
 			if err != nil {
 				p.errc <- err
 
@@ -248,7 +292,8 @@ type encjob struct {
 	Data scrapemate.IJob
 }
 
-func decodeJob(payloadType string, payload []byte) (scrapemate.IJob, error) {
+// decodeJob decodes a job of encodeJob and gives it the settings.
+func decodeJob(payloadType string, payload []byte, settings *gmaps.Settings) (scrapemate.IJob, error) {
 	buf := bytes.NewBuffer(payload)
 	dec := gob.NewDecoder(buf)
 
@@ -259,6 +304,8 @@ func decodeJob(payloadType string, payload []byte) (scrapemate.IJob, error) {
 			return nil, fmt.Errorf("failed to decode search job: %w", err)
 		}
 
+		gmaps.WithSettings(settings)(j)
+
 		return j, nil
 	case "place":
 		j := new(gmaps.PlaceJob)
@@ -266,6 +313,8 @@ func decodeJob(payloadType string, payload []byte) (scrapemate.IJob, error) {
 			return nil, fmt.Errorf("failed to decode place job: %w", err)
 		}
 
+		gmaps.WithPlaceJobSettings(settings)(j)
+
 		return j, nil
 	case "email":
 		j := new(gmaps.EmailExtractJob)
@@ -273,6 +322,8 @@ func decodeJob(payloadType string, payload []byte) (scrapemate.IJob, error) {
 			return nil, fmt.Errorf("failed to decode email job: %w", err)
 		}
 
+		gmaps.WithEmailJobSettings(settings)(j)
+
 		return j, nil
 	default:
 		return nil, fmt.Errorf("invalid payload type: %s", payloadType)
@@ -14,12 +14,30 @@ import (
 	"github.com/gosom/google-maps-scraper/gmaps"
 )
 
-func NewResultWriter(db *sql.DB) scrapemate.ResultWriter {
-	return &resultWriter{db: db}
+type ResultWriterOption func(*resultWriter)
+
+// WithReviewsTable stores the reviews of every entry in the reviews table
+// (see migrations/0005_create_reviews.up.sql), one row per review linked to the row of the entry
+// in results, instead of inside the entry.
+func WithReviewsTable() ResultWriterOption {
+	return func(r *resultWriter) {
+		r.reviewsTable = true
+	}
+}
+
+func NewResultWriter(db *sql.DB, opts ...ResultWriterOption) scrapemate.ResultWriter {
+	w := resultWriter{db: db}
+
+	for _, opt := range opts {
+		opt(&w)
+	}
+
+	return &w
 }
 
 type resultWriter struct {
-	db *sql.DB
+	db           *sql.DB
+	reviewsTable bool
 }
 
 func (r *resultWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
@@ -62,6 +80,10 @@ func (r *resultWriter) batchSave(ctx context.Context, entries []*gmaps.Entry) er
 		return nil
 	}
 
+	if r.reviewsTable {
+		return r.saveWithReviews(ctx, entries)
+	}
+
 	q := `INSERT INTO results
 		(data)
 		VALUES
@@ -100,3 +122,59 @@ func (r *resultWriter) batchSave(ctx context.Context, entries []*gmaps.Entry) er
 
 	return err
 }
+
+// saveWithReviews inserts the entries one by one, since the reviews need
+// the id of their entry, in a single transaction.
+func (r *resultWriter) saveWithReviews(ctx context.Context, entries []*gmaps.Entry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, entry := range entries {
+		reviews := entry.ReviewRows()
+
+		place := *entry
+		place.UserReviews = []gmaps.Review{}
+		place.UserReviewsExtended = []gmaps.Review{}
+
+		data, err := json.Marshal(&place)
+		if err != nil {
+			return err
+		}
+
+		var resultID int
+
+		err = tx.QueryRowContext(ctx,
+			`INSERT INTO results (data) VALUES ($1) ON CONFLICT DO NOTHING RETURNING id`, data,
+		).Scan(&resultID)
+
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			continue
+		case err != nil:
+			return err
+		}
+
+		for _, review := range reviews {
+			data, err := json.Marshal(review)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO reviews (result_id, place_id, data) VALUES ($1, $2, $3)`,
+				resultID, review.PlaceID, data,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
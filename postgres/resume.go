@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gosom/scrapemate"
+)
+
+// LeaseTimeout is how long the jobs a worker took from the queue stay its
+// own without being renewed. TrackDoneJobs renews the jobs of its worker
+// every LeaseTimeout/4, so only the jobs of stopped workers go stale.
+const LeaseTimeout = 2 * time.Minute
+
+// SeedHash returns the content hash of the seed job job: the hash of its
+// type and of the URL it fetches, which includes the query and the
+// language. Unlike the ID, it is the same for the seed job created from the
+// same input line by every run.
+func SeedHash(job scrapemate.IJob) (string, error) {
+	payloadType, _, err := encodeJob(job)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(payloadType + " " + job.GetFullURL()))
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// PushSeedJob pushes the seed job job to the queue of db unless a seed job
+// with the same SeedHash is there already, processed or not. It reports
+// whether job was pushed, so that producing the seed jobs of an input again,
+// e.g. after a crash, does not duplicate the work.
+func PushSeedJob(ctx context.Context, db *sql.DB, job scrapemate.IJob) (bool, error) {
+	hash, err := SeedHash(job)
+	if err != nil {
+		return false, err
+	}
+
+	return pushJob(ctx, db, job, hash)
+}
+
+// NewWorkerID returns an id for the worker of this process, see
+// WithWorkerID: the host name and the process id, which tell the worker in
+// the queue, and a random suffix, which keeps it unique.
+func NewWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "worker"
+	}
+
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8])
+}
+
+// ResumeJobs puts the jobs that workers took from the queue of db and did
+// not finish back in the queue, and returns their number. Only the jobs
+// whose lease is stale are put back: the ones taken more than LeaseTimeout
+// ago and not renewed since, i.e. the jobs of stopped workers. The workers
+// tell the jobs they finished and renew the leases of the others with
+// TrackDoneJobs: the unfinished jobs of workers that did not use it are put
+// back once their lease is stale, even if the worker is still running, and
+// so are the jobs taken by the versions without leases.
+func ResumeJobs(ctx context.Context, db *sql.DB) (int64, error) {
+	res, err := db.ExecContext(ctx, `
+		UPDATE gmaps_jobs SET status = $1, worker_id = NULL, queued_at = NULL
+		WHERE status = $2 AND (queued_at IS NULL OR queued_at < now() - $3 * interval '1 second')`,
+		statusNew, statusQueued, LeaseTimeout.Seconds())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+var _ scrapemate.JobProvider = (*doneTracker)(nil)
+
+// TrackDoneJobs wraps provider, the provider of the queue of db given to
+// scrapemate, to mark the jobs the workers finish as done, for ResumeJobs.
+// provider must take the jobs with WithWorkerID(workerID): while the
+// workers run, the leases of the jobs of workerID are renewed, and the
+// stale jobs of the other workers are put back in the queue.
+//
+// Scrapemate does not tell when a job is finished, but every worker reads
+// the jobs from a channel of its own one at a time: a worker taking a job
+// is done with the one it took before. The last job of every worker stays
+// queued and is processed again when resumed.
+func TrackDoneJobs(provider scrapemate.JobProvider, db *sql.DB, workerID string) scrapemate.JobProvider {
+	return &doneTracker{JobProvider: provider, db: db, workerID: workerID}
+}
+
+type doneTracker struct {
+	scrapemate.JobProvider
+	db       *sql.DB
+	workerID string
+	leases   sync.Once
+}
+
+//nolint:gocritic // we need to return a read only channel
+func (t *doneTracker) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	t.leases.Do(func() {
+		go t.renewLeases(ctx)
+	})
+
+	in, errc := t.JobProvider.Jobs(ctx)
+	out := make(chan scrapemate.IJob)
+
+	go func() {
+		defer close(out)
+
+		var prev scrapemate.IJob
+
+		for {
+			var job scrapemate.IJob
+
+			select {
+			case <-ctx.Done():
+				return
+			case j, ok := <-in:
+				if !ok {
+					return
+				}
+
+				job = j
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case out <- job:
+			}
+
+			if prev != nil {
+				// a job not marked is processed again when resumed
+				_, _ = t.db.ExecContext(ctx, `UPDATE gmaps_jobs SET status = $1 WHERE id = $2`, statusDone, prev.GetID())
+			}
+
+			prev = job
+		}
+	}()
+
+	return out, errc
+}
+
+// renewLeases renews the leases of the jobs of the worker, and puts the
+// stale jobs of the other workers back in the queue, until ctx is done.
+func (t *doneTracker) renewLeases(ctx context.Context) {
+	ticker := time.NewTicker(LeaseTimeout / 4)
+	defer ticker.Stop()
+
+	for {
+		// a lease not renewed is only put back once stale
+		_, _ = t.db.ExecContext(ctx, `UPDATE gmaps_jobs SET queued_at = now() WHERE status = $1 AND worker_id = $2`,
+			statusQueued, t.workerID)
+		_, _ = ResumeJobs(ctx, t.db)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/postgres"
+)
+
+func Test_SeedHash(t *testing.T) {
+	hash := func(job scrapemate.IJob) string {
+		t.Helper()
+
+		h, err := postgres.SeedHash(job)
+		require.NoError(t, err)
+
+		return h
+	}
+
+	cafe := hash(gmaps.NewGmapJob("", "en", "cafe in athens", 10, false, "", 0))
+
+	// the seed jobs of the same input line of two runs
+	require.Equal(t, cafe, hash(gmaps.NewGmapJob("", "en", "cafe in athens", 10, false, "", 0,
+		gmaps.WithTrace(gmaps.Trace{RunID: "3f9a1c", SeedID: "1"}))))
+
+	require.NotEqual(t, cafe, hash(gmaps.NewGmapJob("", "en", "bar in athens", 10, false, "", 0)))
+	require.NotEqual(t, cafe, hash(gmaps.NewGmapJob("", "el", "cafe in athens", 10, false, "", 0)))
+
+	_, err := postgres.SeedHash(&scrapemate.Job{})
+	require.Error(t, err)
+}
+
+func Test_EncodeJob(t *testing.T) {
+	jobs := []scrapemate.IJob{
+		gmaps.NewGmapJob("", "en", "cafe in athens", 10, false, "", 0,
+			gmaps.WithTrace(gmaps.Trace{RunID: "3f9a1c", SeedID: "1"})),
+		gmaps.NewPlaceJob("", "en", "https://www.google.com/maps/place/x", false, false),
+	}
+
+	for _, job := range jobs {
+		payloadType, payload, err := postgres.EncodeJob(job)
+		require.NoError(t, err)
+
+		decoded, err := postgres.DecodeJob(payloadType, payload, nil)
+		require.NoError(t, err)
+		require.Equal(t, job.GetID(), decoded.GetID())
+		require.Equal(t, job.GetFullURL(), decoded.GetFullURL())
+	}
+
+	// the settings are not encoded, the decoded job gets the ones of the
+	// provider
+	settings := &gmaps.Settings{CacheTTL: time.Hour, Throttle: gmaps.NewThrottle(4, 0)}
+	job := gmaps.NewGmapJob("", "en", "cafe in athens", 10, false, "", 0, gmaps.WithSettings(settings))
+
+	payloadType, payload, err := postgres.EncodeJob(job)
+	require.NoError(t, err)
+
+	decoded, err := postgres.DecodeJob(payloadType, payload, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, job.GetCacheKey(), decoded.GetCacheKey())
+
+	decoded, err = postgres.DecodeJob(payloadType, payload, settings)
+	require.NoError(t, err)
+	require.Equal(t, job.GetCacheKey(), decoded.GetCacheKey())
+
+	// the clocks of -keyword-timeout are not shared through the database
+	_, _, err = postgres.EncodeJob(gmaps.NewGmapJob("", "en", "cafe in athens", 10, false, "", 0,
+		gmaps.WithKeywordTimeout(gmaps.NewKeywordTimeout(time.Minute))))
+	require.Error(t, err)
+}
+
+func Test_PushSeedJob(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, postgres.Migrate(ctx, db))
+
+	push := func(query string) bool {
+		t.Helper()
+
+		pushed, err := postgres.PushSeedJob(ctx, db, gmaps.NewGmapJob("", "en", query, 10, false, "", 0))
+		require.NoError(t, err)
+
+		return pushed
+	}
+
+	require.True(t, push("cafe in athens"))
+	require.True(t, push("bar in athens"))
+
+	// a producer run again after a crash
+	require.False(t, push("cafe in athens"))
+
+	// the seed jobs already processed are skipped too
+	_, err := db.ExecContext(ctx, `UPDATE gmaps_jobs SET status = 'done'`)
+	require.NoError(t, err)
+
+	require.False(t, push("bar in athens"))
+	require.True(t, push("pub in athens"))
+
+	require.Equal(t, 3, countJobs(t, db, ""))
+
+	// the jobs pushed by the workers are not deduplicated
+	provider := postgres.NewProvider(db)
+	require.NoError(t, provider.Push(ctx, gmaps.NewGmapJob("", "en", "cafe in athens", 10, false, "", 0)))
+	require.Equal(t, 4, countJobs(t, db, ""))
+}
+
+// queueProvider hands out the jobs of its channel.
+type queueProvider struct {
+	scrapemate.JobProvider
+
+	jobs chan scrapemate.IJob
+}
+
+//nolint:gocritic // it implements scrapemate.JobProvider
+func (p *queueProvider) Jobs(context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	return p.jobs, make(chan error)
+}
+
+func Test_TrackDoneJobsAndResume(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, postgres.Migrate(ctx, db))
+
+	inner := &queueProvider{jobs: make(chan scrapemate.IJob, 3)}
+
+	for _, query := range []string{"cafe in athens", "bar in athens", "pub in athens"} {
+		job := gmaps.NewGmapJob("", "en", query, 10, false, "", 0)
+
+		_, err := postgres.PushSeedJob(ctx, db, job)
+		require.NoError(t, err)
+
+		inner.jobs <- job
+	}
+
+	// the jobs the provider took from the queue an hour ago, for the worker
+	// and for a worker that crashed
+	_, err := db.ExecContext(ctx, `
+		UPDATE gmaps_jobs SET status = 'queued', worker_id = 'w1', queued_at = now() - interval '1 hour'`)
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO gmaps_jobs (id, priority, payload_type, payload, created_at, status, worker_id, queued_at)
+		SELECT gen_random_uuid(), priority, payload_type, payload, created_at, status, 'crashed', queued_at
+		FROM gmaps_jobs LIMIT 1`)
+	require.NoError(t, err)
+
+	wctx, cancel := context.WithCancel(ctx)
+
+	jobc, _ := postgres.TrackDoneJobs(inner, db, "w1").Jobs(wctx)
+
+	// a worker processing two jobs and crashing during the third
+	for range 3 {
+		<-jobc
+	}
+
+	// the stale job of the crashed worker is put back, the lease of the
+	// running worker is renewed
+	require.Eventually(t, func() bool {
+		return countJobs(t, db, "done") == 2 && countJobs(t, db, "new") == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	require.Equal(t, 1, countJobs(t, db, "queued"))
+
+	// the job of the worker that is still running is not put back
+	resumed, err := postgres.ResumeJobs(ctx, db)
+	require.NoError(t, err)
+	require.Zero(t, resumed)
+
+	// until its lease is stale
+	_, err = db.ExecContext(ctx, `UPDATE gmaps_jobs SET queued_at = now() - interval '1 hour' WHERE status = 'queued'`)
+	require.NoError(t, err)
+
+	resumed, err = postgres.ResumeJobs(ctx, db)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, resumed)
+
+	require.Equal(t, 2, countJobs(t, db, "new"))
+	require.Equal(t, 2, countJobs(t, db, "done"))
+}
+
+// countJobs returns the number of jobs of the queue of db with status, or
+// of all of them when status is empty.
+func countJobs(t *testing.T, db *sql.DB, status string) int {
+	t.Helper()
+
+	var n int
+
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM gmaps_jobs WHERE $1 = '' OR status = $1`, status).Scan(&n))
+
+	return n
+}
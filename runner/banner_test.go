@@ -0,0 +1,21 @@
+package runner_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_BannerEnabled(t *testing.T) {
+	for _, flag := range []string{"-quiet", "-no-banner"} {
+		cfg, err := parseArgs("-c", "1", "-input", "queries.txt", flag)
+		require.NoError(t, err)
+		require.True(t, cfg.Quiet, flag)
+		require.False(t, runner.BannerEnabled(cfg), flag)
+	}
+
+	// go test does not run with a terminal on stderr
+	require.False(t, runner.BannerEnabled(&runner.Config{}))
+}
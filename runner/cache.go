@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// CacheType is the scrapemate cache backend used with -cache-enabled.
+const CacheType = "leveldb"
+
+// SetupCache removes the entries of the cache in dir that expired with the
+// cache TTL of the jobs, see gmaps.Settings.CacheTTL. It returns the number
+// of removed entries.
+//
+// It must run before the scrapemate app opens the cache, since leveldb
+// allows only one process to hold the database.
+func SetupCache(dir string, ttl time.Duration) (int, error) {
+	if ttl <= 0 {
+		return 0, nil
+	}
+
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	defer db.Close()
+
+	now := time.Now()
+	batch := new(leveldb.Batch)
+
+	iter := db.NewIterator(nil, nil)
+
+	for iter.Next() {
+		if gmaps.CacheKeyExpired(string(iter.Key()), ttl, now) {
+			batch.Delete(append([]byte(nil), iter.Key()...))
+		}
+	}
+
+	iter.Release()
+
+	if err := iter.Error(); err != nil {
+		return 0, err
+	}
+
+	if err := db.Write(batch, nil); err != nil {
+		return 0, err
+	}
+
+	return batch.Len(), nil
+}
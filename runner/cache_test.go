@@ -0,0 +1,49 @@
+package runner_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_SetupCache(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := leveldb.OpenFile(dir, nil)
+	require.NoError(t, err)
+
+	current := time.Now().UTC().Truncate(time.Hour).Unix()
+
+	require.NoError(t, db.Put([]byte("legacy"), []byte("{}"), nil))
+	require.NoError(t, db.Put([]byte("0:old"), []byte("{}"), nil))
+	require.NoError(t, db.Put([]byte(strconv.FormatInt(current, 10)+":fresh"), []byte("{}"), nil))
+	require.NoError(t, db.Close())
+
+	removed, err := runner.SetupCache(dir, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+
+	db, err = leveldb.OpenFile(dir, nil)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	ok, err := db.Has([]byte(strconv.FormatInt(current, 10)+":fresh"), nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = db.Has([]byte("legacy"), nil)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func Test_SetupCacheWithoutTTL(t *testing.T) {
+	removed, err := runner.SetupCache(t.TempDir(), 0)
+	require.NoError(t, err)
+	require.Zero(t, removed)
+}
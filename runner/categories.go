@@ -0,0 +1,198 @@
+package runner
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultCategories is the category mapping bundled with -expand-category.
+//
+//go:embed categories.json
+var defaultCategories []byte
+
+// CategoryExpander expands the keywords that contain a macro category, e.g.
+// "restaurants in Rome", into one keyword per subcategory of its mapping,
+// e.g. "italian restaurant in Rome", "chinese restaurant in Rome" and so
+// on, to find more places than a single search returns.
+//
+// The categories of a mapping are singular and lowercase. They match the
+// keywords as whole words, in any case and with an optional plural s.
+type CategoryExpander struct {
+	categories map[string][]string
+	// macros are the categories, longest first, so that "sports bar" is
+	// matched before "bar"
+	macros   []string
+	keywords int
+	searches int
+}
+
+// LoadCategoryExpander reads the mapping of path, a JSON object of
+// categories to the list of their subcategories. An empty path loads the
+// bundled mapping.
+func LoadCategoryExpander(path string) (*CategoryExpander, error) {
+	data := defaultCategories
+
+	if path != "" {
+		var err error
+
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var categories map[string][]string
+
+	if err := json.Unmarshal(data, &categories); err != nil {
+		return nil, fmt.Errorf("invalid category mapping: %w", err)
+	}
+
+	return NewCategoryExpander(categories)
+}
+
+// CategoryExpanderFromConfig returns the expander of -expand-category and
+// -category-map, or nil when the keywords are not expanded.
+func CategoryExpanderFromConfig(cfg *Config) (*CategoryExpander, error) {
+	if !cfg.ExpandCategory {
+		return nil, nil
+	}
+
+	return LoadCategoryExpander(cfg.CategoryMapFile)
+}
+
+// NewCategoryExpander returns an expander for the given mapping of
+// categories to their subcategories.
+func NewCategoryExpander(categories map[string][]string) (*CategoryExpander, error) {
+	e := &CategoryExpander{
+		categories: make(map[string][]string, len(categories)),
+		macros:     make([]string, 0, len(categories)),
+	}
+
+	for category, subcategories := range categories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category == "" {
+			return nil, fmt.Errorf("invalid category mapping: empty category")
+		}
+
+		if _, ok := e.categories[category]; ok {
+			return nil, fmt.Errorf("invalid category mapping: category %q is repeated", category)
+		}
+
+		subs := make([]string, 0, len(subcategories))
+
+		for _, sub := range subcategories {
+			if sub = strings.TrimSpace(sub); sub != "" {
+				subs = append(subs, sub)
+			}
+		}
+
+		if len(subs) == 0 {
+			return nil, fmt.Errorf("invalid category mapping: category %q has no subcategories", category)
+		}
+
+		e.categories[category] = subs
+		e.macros = append(e.macros, category)
+	}
+
+	sort.Slice(e.macros, func(i, j int) bool {
+		if len(e.macros[i]) != len(e.macros[j]) {
+			return len(e.macros[i]) > len(e.macros[j])
+		}
+
+		return e.macros[i] < e.macros[j]
+	})
+
+	return e, nil
+}
+
+// Expand returns the keywords query expands to: query itself followed by
+// query with its category replaced by every subcategory, without
+// duplicates. A query without a category is returned as is.
+func (e *CategoryExpander) Expand(query string) []string {
+	for _, macro := range e.macros {
+		start, end, ok := matchCategory(query, macro)
+		if !ok {
+			continue
+		}
+
+		queries := []string{query}
+		seen := map[string]bool{strings.ToLower(query): true}
+
+		for _, sub := range e.categories[macro] {
+			q := query[:start] + sub + query[end:]
+
+			if key := strings.ToLower(q); !seen[key] {
+				seen[key] = true
+
+				queries = append(queries, q)
+			}
+		}
+
+		e.keywords++
+		e.searches += len(queries)
+
+		return queries
+	}
+
+	return []string{query}
+}
+
+// Expanded returns the number of keywords expanded so far and the number
+// of searches they expanded to.
+func (e *CategoryExpander) Expanded() (keywords, searches int) {
+	return e.keywords, e.searches
+}
+
+// matchCategory returns the bounds of the first whole word match of
+// category, with an optional plural s, in query.
+func matchCategory(query, category string) (start, end int, ok bool) {
+	lower := strings.ToLower(query)
+
+	// lowercasing does not change the length of ASCII text, the bounds
+	// found in lower are only valid in query then
+	if len(lower) != len(query) {
+		return 0, 0, false
+	}
+
+	for offset := 0; offset < len(lower); {
+		i := strings.Index(lower[offset:], category)
+		if i < 0 {
+			return 0, 0, false
+		}
+
+		start = offset + i
+		end = start + len(category)
+
+		if end < len(lower) && lower[end] == 's' {
+			if e := end + 1; e == len(lower) || !isWordRune(lower[e:]) {
+				end = e
+			}
+		}
+
+		if (start == 0 || !isWordRuneBefore(lower[:start])) && (end == len(lower) || !isWordRune(lower[end:])) {
+			return start, end, true
+		}
+
+		offset = start + 1
+	}
+
+	return 0, 0, false
+}
+
+func isWordRune(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isWordRuneBefore(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
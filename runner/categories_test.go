@@ -0,0 +1,139 @@
+package runner_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+var sampleCategories = map[string][]string{
+	"restaurant": {"italian restaurant", "chinese restaurant", "Italian Restaurant"},
+	"bar":        {"wine bar", "pub"},
+	"sports bar": {"sports pub"},
+}
+
+func Test_CategoryExpanderExpand(t *testing.T) {
+	e, err := runner.NewCategoryExpander(sampleCategories)
+	require.NoError(t, err)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{
+			query:    "restaurants in Rome",
+			expected: []string{"restaurants in Rome", "italian restaurant in Rome", "chinese restaurant in Rome"},
+		},
+		{
+			query:    "Best Restaurant near me",
+			expected: []string{"Best Restaurant near me", "Best italian restaurant near me", "Best chinese restaurant near me"},
+		},
+		{
+			// the longest category wins
+			query:    "sports bars in Dublin",
+			expected: []string{"sports bars in Dublin", "sports pub in Dublin"},
+		},
+		{
+			// only whole words match
+			query:    "barber in Dublin",
+			expected: []string{"barber in Dublin"},
+		},
+		{
+			query:    "barbershop bar",
+			expected: []string{"barbershop bar", "barbershop wine bar", "barbershop pub"},
+		},
+		{
+			query:    "dentist in Athens",
+			expected: []string{"dentist in Athens"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			require.Equal(t, tc.expected, e.Expand(tc.query))
+		})
+	}
+
+	keywords, searches := e.Expanded()
+	require.Equal(t, 4, keywords)
+	require.Equal(t, 11, searches)
+}
+
+func Test_NewCategoryExpanderInvalid(t *testing.T) {
+	_, err := runner.NewCategoryExpander(map[string][]string{"bar": {" "}})
+	require.Error(t, err)
+
+	_, err = runner.NewCategoryExpander(map[string][]string{"Bar": {"pub"}, "bar ": {"pub"}})
+	require.Error(t, err)
+
+	_, err = runner.NewCategoryExpander(map[string][]string{"": {"pub"}})
+	require.Error(t, err)
+}
+
+func Test_LoadCategoryExpander(t *testing.T) {
+	// the bundled mapping
+	e, err := runner.LoadCategoryExpander("")
+	require.NoError(t, err)
+	require.Contains(t, e.Expand("restaurants in Rome"), "italian restaurant in Rome")
+
+	path := filepath.Join(t.TempDir(), "categories.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"cafe": ["coffee shop", "tea house"]}`), 0o600))
+
+	// a mapping file replaces the bundled one
+	e, err = runner.LoadCategoryExpander(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"cafes in Rome", "coffee shop in Rome", "tea house in Rome"}, e.Expand("cafes in Rome"))
+	require.Equal(t, []string{"restaurants in Rome"}, e.Expand("restaurants in Rome"))
+
+	require.NoError(t, os.WriteFile(path, []byte(`["cafe"]`), 0o600))
+
+	_, err = runner.LoadCategoryExpander(path)
+	require.Error(t, err)
+
+	_, err = runner.LoadCategoryExpander(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+func Test_CreateSeedJobsExpandCategory(t *testing.T) {
+	e, err := runner.NewCategoryExpander(sampleCategories)
+	require.NoError(t, err)
+
+	input := "restaurants in Rome #!# r\nrestaurant in Rome\nrestaurant in {location}\nhttps://www.google.com/maps/place/Bar/@1,2,17z\n"
+
+	jobs, err := runner.CreateSeedJobs(strings.NewReader(input), runner.SeedJobOptions{
+		LangCode:   "en",
+		MaxDepth:   10,
+		Radius:     10000,
+		InputType:  runner.InputTypeAuto,
+		Locations:  []string{"Milan"},
+		Categories: e,
+	})
+	require.NoError(t, err)
+
+	var keywords, ids []string
+
+	for _, job := range jobs {
+		if gj, ok := job.(*gmaps.GmapJob); ok {
+			keywords = append(keywords, gj.Keyword)
+			ids = append(ids, gj.GetID())
+		}
+	}
+
+	// the second line expands to searches of the first one, which are dropped
+	require.Equal(t, []string{
+		"restaurants in Rome", "italian restaurant in Rome", "chinese restaurant in Rome",
+		"restaurant in Rome",
+		"restaurant in Milan", "italian restaurant in Milan", "chinese restaurant in Milan",
+	}, keywords)
+	require.Equal(t, []string{"r-1", "r-2", "r-3"}, ids[:3])
+
+	// place URLs are not expanded
+	require.Len(t, jobs, len(keywords)+1)
+	require.IsType(t, &gmaps.PlaceJob{}, jobs[len(jobs)-1])
+}
@@ -0,0 +1,527 @@
+package runner
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/gosom/scrapemate/adapters/cache/leveldbcache"
+	parser "github.com/gosom/scrapemate/adapters/parsers/goqueryparser"
+	"github.com/gosom/scrapemate/scrapemateapp"
+	"github.com/playwright-community/playwright-go"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// cdpCheckTimeout bounds the check of the endpoint of -cdp-endpoint.
+const cdpCheckTimeout = 10 * time.Second
+
+// The size of the pages opened in the browser of -cdp-endpoint, the one of
+// the browser scrapemate launches.
+const cdpViewportWidth, cdpViewportHeight = 1920, 1080
+
+// App is a scrapemate app: scrapemateapp.ScrapemateApp, or the app of
+// -cdp-endpoint and of labelled proxies.
+type App interface {
+	Start(ctx context.Context, seedJobs ...scrapemate.IJob) error
+	Close() error
+}
+
+// NewApp returns the app of matecfg. With -cdp-endpoint the pages are
+// rendered in the browser of the endpoint instead of one launched by
+// scrapemate. Runs that fetch the pages over HTTP, i.e. in fast mode, do
+// not use the endpoint.
+//
+// With labelled proxies the pages are rendered the same way, in a browser
+// launched by the app, since scrapemate does not tell which proxy fetched
+// a page. The app follows the options of BrowserOptions, the user agent of
+// -stealth included, but every page gets a browser context of its own, so
+// parseConfig rejects the reuse limits of ReuseOptions with either.
+func NewApp(cfg *Config, matecfg *scrapemateapp.Config) (App, error) {
+	if !matecfg.UseJS || (cfg.CDPEndpoint == "" && len(cfg.ProxyLabels) == 0) {
+		return scrapemateapp.NewScrapeMateApp(matecfg)
+	}
+
+	app := cdpApp{cfg: matecfg, endpoint: cfg.CDPEndpoint, labels: cfg.ProxyLabels}
+
+	if cfg.CDPEndpoint != "" {
+		size := cfg.CDPSessions
+		if size == 0 {
+			size = matecfg.Concurrency
+		}
+
+		app.sessions = newSessionPool(size, cfg.CDPSessionTimeout)
+	}
+
+	return &app, nil
+}
+
+// ValidateCDPEndpoint checks that endpoint is the URL of a Chrome DevTools
+// Protocol endpoint, e.g. ws://127.0.0.1:9222/devtools/browser/<id> or the
+// http://127.0.0.1:9222 of a Chrome started with --remote-debugging-port.
+// It does not connect to it, see ResolveCDPEndpoint.
+func ValidateCDPEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+
+	switch u.Scheme {
+	case "ws", "wss", "http", "https":
+	default:
+		return fmt.Errorf("%q is not a ws://, wss://, http:// or https:// URL", endpoint)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", endpoint)
+	}
+
+	return nil
+}
+
+// ResolveCDPEndpoint connects to endpoint and returns its WebSocket URL.
+// An http(s) endpoint is resolved with the /json/version of the DevTools
+// HTTP server, then the WebSocket handshake is done with the URL. The
+// errors wrap ErrConnection.
+func ResolveCDPEndpoint(ctx context.Context, endpoint string) (string, error) {
+	if err := ValidateCDPEndpoint(endpoint); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cdpCheckTimeout)
+	defer cancel()
+
+	wsURL := endpoint
+
+	if strings.HasPrefix(endpoint, "http") {
+		var err error
+
+		wsURL, err = cdpVersionURL(ctx, endpoint)
+		if err != nil {
+			return "", fmt.Errorf("%w: cdp endpoint %s: %w", ErrConnection, endpoint, err)
+		}
+	}
+
+	if err := cdpHandshake(ctx, wsURL); err != nil {
+		return "", fmt.Errorf("%w: cdp endpoint %s: %w", ErrConnection, wsURL, err)
+	}
+
+	return wsURL, nil
+}
+
+// cdpVersionURL returns the webSocketDebuggerUrl of the /json/version of
+// the DevTools HTTP server at endpoint.
+func cdpVersionURL(ctx context.Context, endpoint string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/json/version", http.NoBody)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("/json/version: unexpected status %s", resp.Status)
+	}
+
+	var version struct {
+		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return "", fmt.Errorf("/json/version: %w", err)
+	}
+
+	if version.WebSocketDebuggerURL == "" {
+		return "", errors.New("/json/version: no webSocketDebuggerUrl")
+	}
+
+	return version.WebSocketDebuggerURL, nil
+}
+
+// cdpHandshake does the opening handshake of the WebSocket at wsURL and
+// closes the connection.
+func cdpHandshake(ctx context.Context, wsURL string) error {
+	u, err := url.Parse(wsURL)
+	if err != nil {
+		return err
+	}
+
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	default:
+		return fmt.Errorf("%q is not a ws:// or wss:// URL", wsURL)
+	}
+
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(key))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return fmt.Errorf("websocket handshake: unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// cdpApp is scrapemateapp.ScrapemateApp with a fetcher that renders the
+// pages in the browser of a CDP endpoint, or in one it launches without
+// an endpoint.
+type cdpApp struct {
+	cfg      *scrapemateapp.Config
+	endpoint string
+	labels   []string
+	sessions *sessionPool
+}
+
+func (a *cdpApp) Start(ctx context.Context, seedJobs ...scrapemate.IJob) error {
+	var wsURL string
+
+	if a.endpoint != "" {
+		var err error
+
+		wsURL, err = ResolveCDPEndpoint(ctx, a.endpoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	fetcher, err := newCDPFetcher(wsURL, a.cfg, a.labels, a.sessions)
+	if err != nil {
+		return err
+	}
+
+	defer fetcher.Close()
+
+	provider := a.cfg.Provider
+	if provider == nil {
+		provider = NewMemoryProvider()
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	defer cancel(errors.New("closing app"))
+
+	params := []func(*scrapemate.ScrapeMate) error{
+		scrapemate.WithContext(ctx, cancel),
+		scrapemate.WithJobProvider(provider),
+		scrapemate.WithHTTPFetcher(fetcher),
+		scrapemate.WithHTMLParser(parser.New()),
+		scrapemate.WithConcurrency(a.cfg.Concurrency),
+		scrapemate.WithExitBecauseOfInactivity(a.cfg.ExitOnInactivityDuration),
+	}
+
+	if a.cfg.CacheType == CacheType {
+		cacher, err := leveldbcache.NewLevelDBCache(a.cfg.CachePath)
+		if err != nil {
+			return err
+		}
+
+		defer cacher.Close()
+
+		params = append(params, scrapemate.WithCache(cacher))
+	}
+
+	if a.cfg.InitJob != nil {
+		params = append(params, scrapemate.WithInitJob(a.cfg.InitJob))
+	}
+
+	mate, err := scrapemate.New(params...)
+	if err != nil {
+		return err
+	}
+
+	defer mate.Close()
+
+	for _, writer := range a.cfg.Writers {
+		g.Go(func() error {
+			if err := writer.Run(ctx, mate.Results()); err != nil {
+				cancel(err)
+
+				return err
+			}
+
+			return nil
+		})
+	}
+
+	g.Go(mate.Start)
+
+	g.Go(func() error {
+		for _, job := range seedJobs {
+			if err := provider.Push(ctx, job); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return g.Wait()
+}
+
+func (a *cdpApp) Close() error {
+	return nil
+}
+
+var _ scrapemate.HTTPFetcher = (*cdpFetcher)(nil)
+
+// cdpFetcher renders every page in a new context of a browser connected
+// over CDP, so the jobs do not share cookies and every page gets the
+// next proxy. The browser of an endpoint is not closed: it belongs to
+// whoever started it.
+type cdpFetcher struct {
+	pw       *playwright.Playwright
+	browser  playwright.Browser
+	rotator  *proxyRotator
+	sessions *sessionPool
+	ua       string
+}
+
+// newCDPFetcher returns a fetcher connected to the browser at wsURL, or
+// to a Chromium it launches when wsURL is empty. labels are the labels of
+// the proxies of cfg, see proxyRotator. sessions, if not nil, limits the
+// pages open at a time in the browser.
+func newCDPFetcher(wsURL string, cfg *scrapemateapp.Config, labels []string, sessions *sessionPool) (*cdpFetcher, error) {
+	// only the driver is needed with an endpoint, the browser is its own
+	opts := &playwright.RunOptions{
+		Browsers:            []string{"chromium"},
+		SkipInstallBrowsers: wsURL != "",
+	}
+
+	if err := playwright.Install(opts); err != nil {
+		return nil, err
+	}
+
+	pw, err := playwright.Run(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var browser playwright.Browser
+
+	if wsURL != "" {
+		browser, err = pw.Chromium.ConnectOverCDP(wsURL)
+		if err != nil {
+			_ = pw.Stop()
+
+			return nil, fmt.Errorf("%w: cdp endpoint %s: %w", ErrConnection, wsURL, err)
+		}
+	} else {
+		launch := playwright.BrowserTypeLaunchOptions{
+			Headless: playwright.Bool(!cfg.JSOpts.Headfull),
+		}
+
+		if cfg.JSOpts.DisableImages {
+			launch.Args = append(launch.Args, "--blink-settings=imagesEnabled=false")
+		}
+
+		browser, err = pw.Chromium.Launch(launch)
+		if err != nil {
+			_ = pw.Stop()
+
+			return nil, err
+		}
+	}
+
+	ans := cdpFetcher{
+		pw:       pw,
+		browser:  browser,
+		sessions: sessions,
+		ua:       cfg.JSOpts.UA,
+	}
+
+	if len(cfg.Proxies) > 0 {
+		ans.rotator, err = newProxyRotator(cfg.Proxies, labels)
+		if err != nil {
+			_ = ans.Close()
+
+			return nil, err
+		}
+	}
+
+	return &ans, nil
+}
+
+func (f *cdpFetcher) Fetch(ctx context.Context, job scrapemate.IJob) scrapemate.Response {
+	if !f.browser.IsConnected() {
+		return scrapemate.Response{Error: fmt.Errorf("%w: cdp browser disconnected", ErrConnection)}
+	}
+
+	opts := playwright.BrowserNewContextOptions{
+		Viewport: &playwright.Size{Width: cdpViewportWidth, Height: cdpViewportHeight},
+	}
+
+	if f.ua != "" {
+		opts.UserAgent = playwright.String(f.ua)
+	}
+
+	var label string
+
+	if f.rotator != nil {
+		var next scrapemate.Proxy
+
+		next, label = f.rotator.Next()
+
+		opts.Proxy = &playwright.Proxy{
+			Server:   next.URL,
+			Username: playwright.String(next.Username),
+			Password: playwright.String(next.Password),
+		}
+	}
+
+	page, closePage, err := f.newPage(ctx, opts)
+	if err != nil {
+		return scrapemate.Response{Error: err}
+	}
+
+	defer closePage()
+
+	if job.GetTimeout() > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, job.GetTimeout())
+		defer cancel()
+
+		page.SetDefaultTimeout(float64(job.GetTimeout().Milliseconds()))
+	}
+
+	resp := job.BrowserActions(ctx, page)
+
+	if label != "" {
+		if resp.Meta == nil {
+			resp.Meta = make(map[string]any)
+		}
+
+		resp.Meta[gmaps.MetaProxy] = label
+	}
+
+	return resp
+}
+
+// cdpSession is a page opened in a new context of the browser.
+type cdpSession struct {
+	bctx playwright.BrowserContext
+	page playwright.Page
+	err  error
+}
+
+// newPage opens a page in a new context of the browser and returns the
+// function closing it. With a session pool it waits for a free session
+// first, and gives up when the browser does not open the page within the
+// timeout of the pool either: an overloaded endpoint may never answer.
+func (f *cdpFetcher) newPage(ctx context.Context, opts playwright.BrowserNewContextOptions) (playwright.Page, func(), error) {
+	release := func() {}
+
+	var expired <-chan time.Time
+
+	if f.sessions != nil {
+		var err error
+
+		release, err = f.sessions.acquire(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if f.sessions.timeout > 0 {
+			timer := time.NewTimer(f.sessions.timeout)
+			defer timer.Stop()
+
+			expired = timer.C
+		}
+	}
+
+	opened := make(chan cdpSession, 1)
+
+	go func() {
+		bctx, err := f.browser.NewContext(opts)
+		if err != nil {
+			opened <- cdpSession{err: err}
+
+			return
+		}
+
+		page, err := bctx.NewPage()
+		if err != nil {
+			_ = bctx.Close()
+
+			opened <- cdpSession{err: err}
+
+			return
+		}
+
+		opened <- cdpSession{bctx: bctx, page: page}
+	}()
+
+	closeSession := func(s cdpSession) {
+		if s.bctx != nil {
+			_ = s.bctx.Close()
+		}
+
+		release()
+	}
+
+	// a page opened after giving up is closed as soon as it is, and only
+	// then is its session released
+	select {
+	case s := <-opened:
+		if s.err != nil {
+			release()
+
+			return nil, nil, s.err
+		}
+
+		return s.page, func() { closeSession(s) }, nil
+	case <-expired:
+		go func() { closeSession(<-opened) }()
+
+		return nil, nil, f.sessions.timeoutError()
+	case <-ctx.Done():
+		go func() { closeSession(<-opened) }()
+
+		return nil, nil, ctx.Err()
+	}
+}
+
+// Close disconnects from the browser of an endpoint, leaving it running,
+// or closes the one it launched.
+func (f *cdpFetcher) Close() error {
+	_ = f.browser.Close()
+
+	return f.pw.Stop()
+}
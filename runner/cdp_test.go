@@ -0,0 +1,116 @@
+package runner_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+// newFakeCDPServer returns a DevTools HTTP server whose /json/version
+// points to a WebSocket that accepts the handshake and hangs up.
+func newFakeCDPServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	wsURL := "ws://" + srv.Listener.Addr().String() + "/devtools/browser/fake"
+
+	mux.HandleFunc("/json/version", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"Browser":              "Chrome/124.0.0.0",
+			"webSocketDebuggerUrl": wsURL,
+		})
+	})
+
+	mux.HandleFunc("/devtools/browser/fake", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || r.Header.Get("Sec-WebSocket-Key") == "" {
+			http.Error(w, "not a websocket handshake", http.StatusBadRequest)
+
+			return
+		}
+
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		_ = buf.Flush()
+	})
+
+	return srv
+}
+
+func Test_ResolveCDPEndpoint(t *testing.T) {
+	srv := newFakeCDPServer(t)
+	wsURL := "ws://" + srv.Listener.Addr().String() + "/devtools/browser/fake"
+
+	// the http endpoint of --remote-debugging-port is resolved to its ws URL
+	got, err := runner.ResolveCDPEndpoint(context.Background(), srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, wsURL, got)
+
+	got, err = runner.ResolveCDPEndpoint(context.Background(), wsURL)
+	require.NoError(t, err)
+	require.Equal(t, wsURL, got)
+}
+
+func Test_ResolveCDPEndpointFailures(t *testing.T) {
+	srv := newFakeCDPServer(t)
+	addr := srv.Listener.Addr().String()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	closedAddr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	tests := []struct {
+		name     string
+		endpoint string
+	}{
+		{name: "nothing listening", endpoint: "http://" + closedAddr},
+		{name: "not a websocket", endpoint: "ws://" + addr + "/json/version"},
+		{name: "no devtools server", endpoint: "http://" + addr + "/devtools/browser/fake"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := runner.ResolveCDPEndpoint(context.Background(), tc.endpoint)
+			require.ErrorIs(t, err, runner.ErrConnection)
+			require.Equal(t, runner.ExitConnection, runner.ExitCode(err))
+		})
+	}
+}
+
+func Test_ValidateCDPEndpoint(t *testing.T) {
+	for _, endpoint := range []string{
+		"ws://127.0.0.1:9222/devtools/browser/0b5c",
+		"wss://chrome.example.com/devtools/browser/0b5c",
+		"http://127.0.0.1:9222",
+		"https://chrome.example.com",
+	} {
+		require.NoError(t, runner.ValidateCDPEndpoint(endpoint), endpoint)
+	}
+
+	for _, endpoint := range []string{
+		"127.0.0.1:9222",
+		"ftp://127.0.0.1:9222",
+		"ws:///devtools/browser/0b5c",
+	} {
+		require.Error(t, runner.ValidateCDPEndpoint(endpoint), endpoint)
+	}
+}
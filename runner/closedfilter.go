@@ -0,0 +1,34 @@
+package runner
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// ClosedFilterWriter is a scrapemate.ResultWriter that drops permanently
+// and temporarily closed places and passes everything else to the wrapped
+// writer.
+type ClosedFilterWriter struct {
+	next     scrapemate.ResultWriter
+	excluded atomic.Int64
+}
+
+// NewClosedFilterWriter wraps next with a filter that drops closed places.
+func NewClosedFilterWriter(next scrapemate.ResultWriter) *ClosedFilterWriter {
+	return &ClosedFilterWriter{
+		next: next,
+	}
+}
+
+// Excluded returns the number of places dropped so far.
+func (w *ClosedFilterWriter) Excluded() int {
+	return int(w.excluded.Load())
+}
+
+func (w *ClosedFilterWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	return runEntryFilter(ctx, in, w.next, func(e *gmaps.Entry) bool { return !e.IsClosed() }, &w.excluded)
+}
@@ -0,0 +1,37 @@
+package runner_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_ClosedFilterWriterRun(t *testing.T) {
+	inner := &collectWriter{}
+	w := runner.NewClosedFilterWriter(inner)
+
+	in := make(chan scrapemate.Result, 2)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "a", BusinessStatus: gmaps.BusinessStatusPermanentlyClosed}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{
+		{Title: "b", BusinessStatus: gmaps.BusinessStatusOperational},
+		{Title: "c", BusinessStatus: gmaps.BusinessStatusTemporarilyClosed},
+		{Title: "d"},
+	}}
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+
+	require.Len(t, inner.results, 1)
+
+	entries := inner.results[0].Data.([]*gmaps.Entry)
+	require.Len(t, entries, 2)
+	require.Equal(t, "b", entries[0].Title)
+	require.Equal(t, "d", entries[1].Title)
+
+	require.Equal(t, 2, w.Excluded())
+}
@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// CompressionExtension returns the file extension used for the given compression.
+func CompressionExtension(algo string) string {
+	switch algo {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// NewCompressedWriter wraps w in a compressor. The returned writer must be
+// closed to flush the remaining data; closing it does not close w.
+func NewCompressedWriter(algo string, w io.Writer) (io.WriteCloser, error) {
+	switch algo {
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("invalid compression: %s (supported: gzip, zstd)", algo)
+	}
+}
@@ -0,0 +1,90 @@
+package runner_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"io"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
+	"github.com/gosom/scrapemate/adapters/writers/jsonwriter"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func writeEntries(t *testing.T, writerFn func(io.Writer) scrapemate.ResultWriter, w io.Writer) {
+	t.Helper()
+
+	entries := []*gmaps.Entry{
+		{Title: "Kipriakon", Category: "Restaurant", Address: "Old port, Limassol 3042", ReviewCount: 396, ReviewRating: 4.2},
+		{Title: "Seaview Hotel", Category: "Hotel", Address: "12 Makarios Ave, Nicosia 1065", ReviewCount: 1234, ReviewRating: 4.5},
+	}
+
+	in := make(chan scrapemate.Result, len(entries))
+	for _, e := range entries {
+		in <- scrapemate.Result{Data: e}
+	}
+
+	close(in)
+
+	require.NoError(t, writerFn(w).Run(context.Background(), in))
+}
+
+func Test_NewCompressedWriter(t *testing.T) {
+	formats := map[string]func(io.Writer) scrapemate.ResultWriter{
+		"csv": func(w io.Writer) scrapemate.ResultWriter {
+			return csvwriter.NewCsvWriter(csv.NewWriter(w))
+		},
+		"json": jsonwriter.NewJSONWriter,
+	}
+
+	decompressors := map[string]func(io.Reader) (io.Reader, error){
+		runner.CompressionGzip: func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+		runner.CompressionZstd: func(r io.Reader) (io.Reader, error) {
+			return zstd.NewReader(r)
+		},
+	}
+
+	for format, writerFn := range formats {
+		for algo, decompress := range decompressors {
+			t.Run(format+"-"+algo, func(t *testing.T) {
+				var plain bytes.Buffer
+
+				writeEntries(t, writerFn, &plain)
+
+				var compressed bytes.Buffer
+
+				cw, err := runner.NewCompressedWriter(algo, &compressed)
+				require.NoError(t, err)
+
+				writeEntries(t, writerFn, cw)
+				require.NoError(t, cw.Close())
+
+				r, err := decompress(&compressed)
+				require.NoError(t, err)
+
+				got, err := io.ReadAll(r)
+				require.NoError(t, err)
+
+				require.NotEmpty(t, plain.Bytes())
+				require.Equal(t, plain.Bytes(), got)
+			})
+		}
+	}
+}
+
+func Test_NewCompressedWriterInvalid(t *testing.T) {
+	_, err := runner.NewCompressedWriter("brotli", io.Discard)
+	require.Error(t, err)
+	require.Empty(t, runner.CompressionExtension("brotli"))
+	require.Equal(t, ".gz", runner.CompressionExtension(runner.CompressionGzip))
+	require.Equal(t, ".zst", runner.CompressionExtension(runner.CompressionZstd))
+}
@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// The formats of the coordinates of -coord-format.
+const (
+	CoordFormatDD  = "dd"
+	CoordFormatDMS = "dms"
+)
+
+// FormatDMS formats a latitude, or a longitude with lon, in degrees,
+// minutes and seconds to a tenth of a second, e.g. 37°58'48.0"N.
+func FormatDMS(coord float64, lon bool) string {
+	hemisphere := "N"
+
+	switch {
+	case lon && coord < 0:
+		hemisphere = "W"
+	case lon:
+		hemisphere = "E"
+	case coord < 0:
+		hemisphere = "S"
+	}
+
+	// in tenths of a second, so the rounding carries over to the minutes
+	// and the degrees
+	tenths := int64(math.Round(math.Abs(coord) * 36000))
+
+	degrees := tenths / 36000
+	minutes := tenths % 36000 / 600
+	seconds := float64(tenths%600) / 10
+
+	return fmt.Sprintf("%d°%d'%.1f\"%s", degrees, minutes, seconds, hemisphere)
+}
+
+// dmsEntry is a place whose coordinates are written in degrees, minutes
+// and seconds. In JSON its fields hide the ones of the entry.
+type dmsEntry struct {
+	*gmaps.Entry
+
+	Latitude   string `json:"latitude"`
+	Longtitude string `json:"longtitude"`
+}
+
+// coordColumns returns the columns of the coordinates in the CSV rows.
+var coordColumns = sync.OnceValues(func() (int, int) {
+	headers := (&gmaps.Entry{}).CsvHeaders()
+
+	return slices.Index(headers, "latitude"), slices.Index(headers, "longitude")
+})
+
+func (e *dmsEntry) CsvRow() []string {
+	row := e.Entry.CsvRow()
+	lat, lon := coordColumns()
+
+	row[lat] = e.Latitude
+	row[lon] = e.Longtitude
+
+	return row
+}
+
+func newDMSEntry(e *gmaps.Entry) *dmsEntry {
+	return &dmsEntry{
+		Entry:      e,
+		Latitude:   FormatDMS(e.Latitude, false),
+		Longtitude: FormatDMS(e.Longtitude, true),
+	}
+}
+
+// DMSWriter is a scrapemate.ResultWriter that passes the places to the
+// wrapped writer with their coordinates in degrees, minutes and seconds,
+// for -coord-format dms. The places it passes on are not *gmaps.Entry, so
+// it wraps the writer of the output directly: the csv, json and ndjson
+// writers only need the places to be CSV rows or JSON objects.
+type DMSWriter struct {
+	next scrapemate.ResultWriter
+}
+
+// NewDMSWriter wraps next with a writer of the coordinates in DMS.
+func NewDMSWriter(next scrapemate.ResultWriter) *DMSWriter {
+	return &DMSWriter{next: next}
+}
+
+func (w *DMSWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	out := make(chan scrapemate.Result)
+
+	go func() {
+		defer close(out)
+
+		for result := range in {
+			switch data := result.Data.(type) {
+			case *gmaps.Entry:
+				result.Data = newDMSEntry(data)
+			case []*gmaps.Entry:
+				// []any, so the ndjson writer still splits them
+				entries := make([]any, len(data))
+
+				for i, entry := range data {
+					entries[i] = newDMSEntry(entry)
+				}
+
+				result.Data = entries
+			}
+
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return w.next.Run(ctx, out)
+}
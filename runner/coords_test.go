@@ -0,0 +1,77 @@
+package runner_test
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_FormatDMS(t *testing.T) {
+	tests := []struct {
+		name  string
+		coord float64
+		lon   bool
+		want  string
+	}{
+		{name: "athens latitude", coord: 37.98, want: `37°58'48.0"N`},
+		{name: "athens longitude", coord: 23.72, lon: true, want: `23°43'12.0"E`},
+		{name: "sydney latitude", coord: -33.8688, want: `33°52'7.7"S`},
+		{name: "sydney longitude", coord: 151.2093, lon: true, want: `151°12'33.5"E`},
+		{name: "new york longitude", coord: -74.006, lon: true, want: `74°0'21.6"W`},
+		{name: "rio de janeiro latitude", coord: -22.9068, want: `22°54'24.5"S`},
+		{name: "just south of the equator", coord: -0.5, want: `0°30'0.0"S`},
+		{name: "equator", coord: 0, want: `0°0'0.0"N`},
+		{name: "rounding carries to the degree", coord: -12.99999999, lon: true, want: `13°0'0.0"W`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, runner.FormatDMS(tc.coord, tc.lon))
+		})
+	}
+}
+
+func Test_DMSWriterRun(t *testing.T) {
+	inner := &collectWriter{}
+	w := runner.NewDMSWriter(inner)
+
+	in := make(chan scrapemate.Result, 2)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "a", Latitude: -33.8688, Longtitude: 151.2093}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{
+		{Title: "b", Latitude: 37.98, Longtitude: 23.72},
+		{Title: "c", Latitude: 40.7128, Longtitude: -74.006},
+	}}
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+	require.Len(t, inner.results, 2)
+
+	headers := (&gmaps.Entry{}).CsvHeaders()
+	lat, lon := slices.Index(headers, "latitude"), slices.Index(headers, "longitude")
+
+	row := inner.results[0].Data.(scrapemate.CsvCapable).CsvRow()
+	require.Len(t, row, len(headers))
+	require.Equal(t, "a", row[slices.Index(headers, "title")])
+	require.Equal(t, `33°52'7.7"S`, row[lat])
+	require.Equal(t, `151°12'33.5"E`, row[lon])
+
+	entries := inner.results[1].Data.([]any)
+	require.Len(t, entries, 2)
+
+	raw, err := json.Marshal(entries[1])
+	require.NoError(t, err)
+
+	var got map[string]any
+
+	require.NoError(t, json.Unmarshal(raw, &got))
+	require.Equal(t, "c", got["title"])
+	require.Equal(t, `40°42'46.1"N`, got["latitude"])
+	require.Equal(t, `74°0'21.6"W`, got["longtitude"])
+}
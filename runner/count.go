@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// CountWriter is a scrapemate.ResultWriter that counts the places passed
+// to the wrapped writer. Error rows are passed but not counted.
+type CountWriter struct {
+	next  scrapemate.ResultWriter
+	count atomic.Int64
+}
+
+// NewCountWriter wraps next with a counter.
+func NewCountWriter(next scrapemate.ResultWriter) *CountWriter {
+	return &CountWriter{next: next}
+}
+
+// Count returns the number of places written so far.
+func (w *CountWriter) Count() int {
+	return int(w.count.Load())
+}
+
+func (w *CountWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	return runEntryFilter(ctx, in, w.next, w.keep, nil)
+}
+
+func (w *CountWriter) keep(entry *gmaps.Entry) bool {
+	if entry.Error == "" {
+		w.count.Add(1)
+	}
+
+	return true
+}
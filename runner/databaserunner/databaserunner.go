@@ -4,7 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"io"
+	"log"
 	"os"
 
 	// postgres driver
@@ -21,7 +21,7 @@ type dbrunner struct {
 	cfg      *runner.Config
 	provider scrapemate.JobProvider
 	produce  bool
-	app      *scrapemateapp.ScrapemateApp
+	app      runner.App
 	conn     *sql.DB
 }
 
@@ -32,12 +32,19 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 
 	conn, err := openPsqlConn(cfg.Dsn)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", runner.ErrConnection, err)
+	}
+
+	if err := postgres.Migrate(context.Background(), conn); err != nil {
+		return nil, fmt.Errorf("migrating the database: %w", err)
 	}
 
+	settings := runner.JobSettings(cfg)
+	workerID := postgres.NewWorkerID()
+
 	ans := dbrunner{
 		cfg:      cfg,
-		provider: postgres.NewProvider(conn),
+		provider: postgres.NewProvider(conn, postgres.WithSettings(settings), postgres.WithWorkerID(workerID)),
 		produce:  cfg.ProduceOnly,
 		conn:     conn,
 	}
@@ -46,16 +53,60 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 		return &ans, nil
 	}
 
-	psqlWriter := postgres.NewResultWriter(conn)
+	var writerOpts []postgres.ResultWriterOption
+
+	if cfg.ReviewsFormat == runner.ReviewsFormatTable {
+		writerOpts = append(writerOpts, postgres.WithReviewsTable())
+	}
+
+	psqlWriter := postgres.NewResultWriter(conn, writerOpts...)
+
+	if cfg.ExcludeNamesFile != "" {
+		names, err := runner.LoadExcludedNames(cfg.ExcludeNamesFile)
+		if err != nil {
+			return nil, err
+		}
+
+		psqlWriter = runner.NewExcludeNamesWriter(psqlWriter, names)
+	}
+
+	if cfg.MinReviews > 0 || cfg.MinRating > 0 || cfg.MaxRating > 0 {
+		psqlWriter = runner.NewRatingFilterWriter(psqlWriter, cfg.MinReviews, cfg.MinRating, cfg.MaxRating)
+	}
+
+	if cfg.ExcludeClosed {
+		psqlWriter = runner.NewClosedFilterWriter(psqlWriter)
+	}
+
+	if len(cfg.Transforms) > 0 {
+		transformers, err := runner.EntryTransformers(cfg.Transforms)
+		if err != nil {
+			return nil, err
+		}
+
+		psqlWriter = runner.NewTransformWriter(psqlWriter, transformers...)
+	}
 
 	writers := []scrapemate.ResultWriter{
 		psqlWriter,
 	}
 
+	provider := runner.LimitEmailJobs(ans.provider, cfg.EmailConcurrency)
+
+	if cfg.ResumeFromDB {
+		resumed, err := postgres.ResumeJobs(context.Background(), conn)
+		if err != nil {
+			return nil, fmt.Errorf("resuming the jobs: %w", err)
+		}
+
+		log.Printf("resumed %d unfinished jobs", resumed)
+
+		provider = postgres.TrackDoneJobs(provider, conn, workerID)
+	}
+
 	opts := []func(*scrapemateapp.Config) error{
-		// scrapemateapp.WithCache("leveldb", "cache"),
 		scrapemateapp.WithConcurrency(cfg.Concurrency),
-		scrapemateapp.WithProvider(ans.provider),
+		scrapemateapp.WithProvider(provider),
 		scrapemateapp.WithExitOnInactivity(cfg.ExitOnInactivityDuration),
 	}
 
@@ -65,26 +116,29 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 		)
 	}
 
-	if !cfg.FastMode {
-		if cfg.Debug {
-			opts = append(opts, scrapemateapp.WithJS(
-				scrapemateapp.Headfull(),
-				scrapemateapp.DisableImages(),
-			))
-		} else {
-			opts = append(opts, scrapemateapp.WithJS(scrapemateapp.DisableImages()))
-		}
-	} else {
-		opts = append(opts, scrapemateapp.WithStealth("firefox"))
+	opts = append(opts, runner.BrowserOptions(cfg, cfg.FastMode)...)
+
+	if err := runner.SetupStorageState(context.Background(), cfg, settings); err != nil {
+		return nil, err
 	}
 
-	if !cfg.DisablePageReuse {
+	if cfg.CacheEnabled {
+		removed, err := runner.SetupCache(cfg.CacheDir, cfg.CacheTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		if removed > 0 {
+			log.Printf("removed %d expired cache entries", removed)
+		}
+
 		opts = append(opts,
-			scrapemateapp.WithPageReuseLimit(2),
-			scrapemateapp.WithPageReuseLimit(200),
+			scrapemateapp.WithCache(runner.CacheType, cfg.CacheDir),
 		)
 	}
 
+	opts = append(opts, runner.ReuseOptions(cfg)...)
+
 	matecfg, err := scrapemateapp.NewConfig(
 		writers,
 		opts...,
@@ -93,7 +147,7 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 		return nil, err
 	}
 
-	ans.app, err = scrapemateapp.NewScrapeMateApp(matecfg)
+	ans.app, err = runner.NewApp(cfg, matecfg)
 	if err != nil {
 		return nil, err
 	}
@@ -124,43 +178,102 @@ func (d *dbrunner) Close(context.Context) error {
 }
 
 func (d *dbrunner) produceSeedJobs(ctx context.Context) error {
-	var input io.Reader
-
-	switch d.cfg.InputFile {
-	case "stdin":
-		input = os.Stdin
-	default:
-		f, err := os.Open(d.cfg.InputFile)
-		if err != nil {
-			return err
-		}
+	input, err := runner.OpenSeedInput(d.cfg)
+	if err != nil {
+		return err
+	}
 
+	if f, ok := input.(*os.File); ok && f != os.Stdin {
 		defer f.Close()
+	}
 
-		input = f
-	}
-
-	jobs, err := runner.CreateSeedJobs(
-		d.cfg.FastMode,
-		d.cfg.LangCode,
-		input,
-		d.cfg.MaxDepth,
-		d.cfg.Email,
-		d.cfg.GeoCoordinates,
-		d.cfg.Zoom,
-		d.cfg.Radius,
-		nil,
-		nil,
-		d.cfg.ExtraReviews,
-	)
+	locations, err := runner.LoadLocations(d.cfg.LocationsFile)
+	if err != nil {
+		return err
+	}
+
+	categories, err := runner.CategoryExpanderFromConfig(d.cfg)
+	if err != nil {
+		return err
+	}
+
+	opts := runner.SeedJobOptions{
+		FastMode:          d.cfg.FastMode,
+		GeoCoordinates:    d.cfg.GeoCoordinates,
+		Zoom:              d.cfg.Zoom,
+		Radius:            d.cfg.Radius,
+		FastModeDetails:   d.cfg.FastModeDetails,
+		LangCode:          d.cfg.LangCode,
+		MaxDepth:          d.cfg.MaxDepth,
+		Email:             d.cfg.Email,
+		ExtraReviews:      d.cfg.ExtraReviews,
+		Country:           d.cfg.Country,
+		InputType:         d.cfg.InputType,
+		AutoDepthPatience: d.cfg.AutoDepthPatience,
+		MaxEmptyScrolls:   d.cfg.MaxEmptyScrolls,
+		// MaxPerKeyword is left out: the keyword limiter is in memory and
+		// can't be shared with the workers through the database
+		ExpandNearby:      d.cfg.ExpandNearby,
+		PlaceWaitSelector: d.cfg.PlaceWaitSelector,
+		WaitTimeout:       d.cfg.WaitTimeout,
+		ScreenshotsDir:    d.cfg.ScreenshotsDir,
+		ScreenshotsMax:    d.cfg.ScreenshotsMax,
+		RawJSONDir:        d.cfg.SaveRawJSON,
+		RawJSONMax:        d.cfg.RawJSONMax,
+		EnrichWebsite:     d.cfg.EnrichWebsite,
+		Locations:         locations,
+		MaxTemplateJobs:   d.cfg.MaxTemplateJobs,
+		Categories:        categories,
+		MaxInputJobs:      d.cfg.MaxInputJobs,
+		TruncateInputJobs: d.cfg.TruncateInputJobs,
+		Shuffle:           d.cfg.ShuffleSeeds,
+	}
+
+	if d.cfg.CorrelationID {
+		opts.RunID = d.cfg.RunID
+	}
+
+	jobs, err := runner.CreateSeedJobs(input, opts)
 	if err != nil {
 		return err
 	}
 
+	if categories != nil {
+		keywords, searches := categories.Expanded()
+		log.Printf("expanded %d category keywords into %d searches", keywords, searches)
+	}
+
+	if d.cfg.CorrelationID {
+		log.Printf("run ID %s, the correlation IDs are %s/<seed job>", d.cfg.RunID, d.cfg.RunID)
+	}
+
+	if len(locations) > 0 {
+		log.Printf("created %d seed jobs using %d locations", len(jobs), len(locations))
+	}
+
+	skipped := 0
+
 	for i := range jobs {
-		if err := d.provider.Push(ctx, jobs[i]); err != nil {
+		if !d.cfg.ResumeFromDB {
+			if err := d.provider.Push(ctx, jobs[i]); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		pushed, err := postgres.PushSeedJob(ctx, d.conn, jobs[i])
+		if err != nil {
 			return err
 		}
+
+		if !pushed {
+			skipped++
+		}
+	}
+
+	if d.cfg.ResumeFromDB {
+		log.Printf("pushed %d seed jobs, skipped %d already in the database", len(jobs)-skipped, skipped)
 	}
 
 	_ = runner.Telemetry().Send(ctx, tlmt.NewEvent("databaserunner.produceSeedJobs", map[string]any{
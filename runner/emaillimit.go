@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/scrapemate"
+)
+
+var _ scrapemate.JobProvider = (*emailLimitProvider)(nil)
+
+// LimitEmailJobs wraps provider so that at most limit email extraction jobs
+// are handed to the workers at the same time. Email jobs fetch arbitrary
+// websites and can take minutes, so without a limit they may occupy every
+// worker and stall place scraping.
+// Email jobs over the limit are held back while other jobs keep flowing.
+// A limit < 1 returns the provider unchanged.
+func LimitEmailJobs(provider scrapemate.JobProvider, limit int) scrapemate.JobProvider {
+	if limit < 1 {
+		return provider
+	}
+
+	return &emailLimitProvider{
+		JobProvider: provider,
+		sem:         make(chan struct{}, limit),
+	}
+}
+
+type emailLimitProvider struct {
+	scrapemate.JobProvider
+	sem chan struct{}
+}
+
+//nolint:gocritic // we need to return a read only channel
+func (p *emailLimitProvider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	in, errc := p.JobProvider.Jobs(ctx)
+	out := make(chan scrapemate.IJob)
+
+	go func() {
+		defer close(out)
+
+		var (
+			pending []*gmaps.EmailExtractJob
+			next    scrapemate.IJob
+		)
+
+		for {
+			if in == nil && next == nil && len(pending) == 0 {
+				return
+			}
+
+			var (
+				recv    <-chan scrapemate.IJob
+				send    chan<- scrapemate.IJob
+				acquire chan<- struct{}
+			)
+
+			switch {
+			case next != nil:
+				send = out
+			default:
+				recv = in
+
+				if len(pending) > 0 {
+					acquire = p.sem
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case job, ok := <-recv:
+				if !ok {
+					in = nil
+
+					continue
+				}
+
+				if emailJob, isEmail := job.(*gmaps.EmailExtractJob); isEmail {
+					pending = append(pending, emailJob)
+				} else {
+					next = job
+				}
+			case acquire <- struct{}{}:
+				emailJob := pending[0]
+				pending = pending[1:]
+
+				emailJob.Release = sync.OnceFunc(p.release)
+				next = emailJob
+			case send <- next:
+				next = nil
+			}
+		}
+	}()
+
+	return out, errc
+}
+
+func (p *emailLimitProvider) release() {
+	<-p.sem
+}
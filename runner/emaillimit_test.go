@@ -0,0 +1,92 @@
+package runner_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+type chanProvider struct {
+	jobs chan scrapemate.IJob
+}
+
+func (p *chanProvider) Jobs(_ context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	return p.jobs, make(chan error)
+}
+
+func (p *chanProvider) Push(_ context.Context, job scrapemate.IJob) error {
+	p.jobs <- job
+
+	return nil
+}
+
+func receiveJob(t *testing.T, jobs <-chan scrapemate.IJob) scrapemate.IJob {
+	t.Helper()
+
+	select {
+	case job := <-jobs:
+		return job
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for job")
+	}
+
+	return nil
+}
+
+func Test_LimitEmailJobs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inner := &chanProvider{jobs: make(chan scrapemate.IJob, 10)}
+
+	email1 := gmaps.NewEmailJob("p1", &gmaps.Entry{WebSite: "https://example.com/1"})
+	email2 := gmaps.NewEmailJob("p2", &gmaps.Entry{WebSite: "https://example.com/2"})
+	place := gmaps.NewPlaceJob("s1", "en", "https://www.google.com/maps/place/x", false, false)
+
+	require.NoError(t, inner.Push(ctx, email1))
+	require.NoError(t, inner.Push(ctx, email2))
+	require.NoError(t, inner.Push(ctx, place))
+
+	provider := runner.LimitEmailJobs(inner, 1)
+	jobs, _ := provider.Jobs(ctx)
+
+	// the first email job and the place job are handed out,
+	// the second email job is held back
+	got := []scrapemate.IJob{receiveJob(t, jobs), receiveJob(t, jobs)}
+
+	require.ElementsMatch(t, []scrapemate.IJob{email1, place}, got)
+	require.NotNil(t, email1.Release)
+
+	select {
+	case job := <-jobs:
+		t.Fatalf("unexpected job %s before the first email job finished", job.GetID())
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// the job releases its slot when fetched and when processed
+	email1.Release()
+	email1.Release()
+
+	require.Same(t, email2, receiveJob(t, jobs))
+
+	// only one slot was released
+	require.NoError(t, inner.Push(ctx, gmaps.NewEmailJob("p3", &gmaps.Entry{WebSite: "https://example.com/3"})))
+
+	select {
+	case job := <-jobs:
+		t.Fatalf("unexpected job %s before the second email job finished", job.GetID())
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func Test_LimitEmailJobsDisabled(t *testing.T) {
+	inner := &chanProvider{jobs: make(chan scrapemate.IJob)}
+
+	require.Same(t, scrapemate.JobProvider(inner), runner.LimitEmailJobs(inner, 0))
+}
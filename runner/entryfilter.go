@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// runEntryFilter passes the results read from in to next, keeping only the
+// places for which keep returns true. Dropped places are counted in
+// dropped when it's not nil. Results that are not places pass unchanged.
+func runEntryFilter(
+	ctx context.Context,
+	in <-chan scrapemate.Result,
+	next scrapemate.ResultWriter,
+	keep func(*gmaps.Entry) bool,
+	dropped *atomic.Int64,
+) error {
+	return runEntryTransform(ctx, in, next, func(e *gmaps.Entry) (*gmaps.Entry, bool) {
+		return e, keep(e)
+	}, dropped)
+}
+
+// runEntryTransform passes the results read from in to next, replacing
+// every place with the one returned by transform, or dropping it when
+// transform returns false. Dropped places are counted in dropped when it's
+// not nil. Results that are not places pass unchanged.
+func runEntryTransform(
+	ctx context.Context,
+	in <-chan scrapemate.Result,
+	next scrapemate.ResultWriter,
+	transform func(*gmaps.Entry) (*gmaps.Entry, bool),
+	dropped *atomic.Int64,
+) error {
+	out := make(chan scrapemate.Result)
+
+	go func() {
+		defer close(out)
+
+		for result := range in {
+			var ok bool
+
+			result.Data, ok = transformEntries(result.Data, transform, dropped)
+			if !ok {
+				continue
+			}
+
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return next.Run(ctx, out)
+}
+
+func transformEntries(data any, transform func(*gmaps.Entry) (*gmaps.Entry, bool), dropped *atomic.Int64) (any, bool) {
+	switch v := data.(type) {
+	case *gmaps.Entry:
+		entry, ok := transform(v)
+		if !ok || entry == nil {
+			if dropped != nil {
+				dropped.Add(1)
+			}
+
+			return nil, false
+		}
+
+		return entry, true
+	case []*gmaps.Entry:
+		kept := make([]*gmaps.Entry, 0, len(v))
+
+		for _, entry := range v {
+			entry, ok := transform(entry)
+			if !ok || entry == nil {
+				if dropped != nil {
+					dropped.Add(1)
+				}
+
+				continue
+			}
+
+			kept = append(kept, entry)
+		}
+
+		return kept, len(kept) > 0
+	default:
+		return data, true
+	}
+}
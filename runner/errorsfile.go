@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var _ gmaps.FailureReporter = (*ErrorsFile)(nil)
+
+// ErrorsFile is a gmaps.FailureReporter that appends the failed jobs of
+// -errors-file to a file that can be used as the -input of a retry run:
+// every failure is the keyword or place URL and the ID of its input line.
+// The errors go to the log of the file, see ErrorsLogPath, as the input
+// has no room for them.
+//
+// Every failure is written with a single unbuffered write to each file,
+// opened in append mode, so the files are usable even when the run crashes
+// and several runs may share them.
+type ErrorsFile struct {
+	mu     sync.Mutex
+	f      *os.File
+	log    *os.File
+	failed int
+}
+
+// ErrorsLogPath returns the path of the log of the errors file at path,
+// with the error of every failure after its input line and a tab.
+func ErrorsLogPath(path string) string {
+	return path + ".log"
+}
+
+// OpenErrorsFile opens path and its log for appending, creating them when
+// needed.
+func OpenErrorsFile(path string) (*ErrorsFile, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	errorsLog, err := os.OpenFile(ErrorsLogPath(path), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		_ = f.Close()
+
+		return nil, err
+	}
+
+	return &ErrorsFile{f: f, log: errorsLog}, nil
+}
+
+// JobFailed appends the failure of the job with input and id.
+func (e *ErrorsFile) JobFailed(input, id string, err error) {
+	line := oneLine(input)
+	if id != "" {
+		line += " #!# " + oneLine(id)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := e.f.WriteString(line + "\n"); err != nil {
+		log.Printf("errors file: %v", err)
+
+		return
+	}
+
+	e.failed++
+
+	if _, err := fmt.Fprintf(e.log, "%s\t%s\n", line, oneLine(err.Error())); err != nil {
+		log.Printf("errors file: %v", err)
+	}
+}
+
+// Failed returns the number of failures written so far.
+func (e *ErrorsFile) Failed() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.failed
+}
+
+// Close syncs the file and its log to disk and closes them.
+func (e *ErrorsFile) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return errors.Join(closeSynced(e.f), closeSynced(e.log))
+}
+
+func closeSynced(f *os.File) error {
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// oneLine replaces the line breaks of s, which would split a record.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
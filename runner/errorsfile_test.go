@@ -0,0 +1,65 @@
+package runner_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_ErrorsFile(t *testing.T) {
+	const placeURL = "https://www.google.com/maps/place/Kipriakon/data=!4m2!3m1!1s0x14e732fd76f0d90d:0xe5415928d6702b47"
+
+	path := filepath.Join(t.TempDir(), "errors.txt")
+
+	errorsFile, err := runner.OpenErrorsFile(path)
+	require.NoError(t, err)
+
+	errorsFile.JobFailed("cafe in athens", "line-1", errors.New("playwright: timeout:\nTimeout 30000ms exceeded."))
+	errorsFile.JobFailed(placeURL, "line-2", gmaps.ErrPlaceNotFound)
+	require.Equal(t, 2, errorsFile.Failed())
+	require.NoError(t, errorsFile.Close())
+
+	// a second run appends
+	errorsFile, err = runner.OpenErrorsFile(path)
+	require.NoError(t, err)
+
+	errorsFile.JobFailed("bakery", "", errors.New("could not convert to goquery document"))
+	require.NoError(t, errorsFile.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "cafe in athens #!# line-1\n"+
+		placeURL+" #!# line-2\n"+
+		"bakery\n", string(data))
+
+	errorsLog, err := os.ReadFile(runner.ErrorsLogPath(path))
+	require.NoError(t, err)
+	require.Equal(t, "cafe in athens #!# line-1\tplaywright: timeout: Timeout 30000ms exceeded.\n"+
+		placeURL+" #!# line-2\tplace not found\n"+
+		"bakery\tcould not convert to goquery document\n", string(errorsLog))
+
+	// the file is the input of the retry run
+	jobs, err := createTemplateJobs(string(data), nil, 0)
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+
+	search, ok := jobs[0].(*gmaps.GmapJob)
+	require.True(t, ok)
+	require.Equal(t, "cafe in athens", search.Keyword)
+	require.Equal(t, "line-1", search.GetID())
+
+	place, ok := jobs[1].(*gmaps.PlaceJob)
+	require.True(t, ok)
+	require.Equal(t, placeURL, place.GetURL())
+	require.Equal(t, "line-2", place.ParentID)
+
+	search, ok = jobs[2].(*gmaps.GmapJob)
+	require.True(t, ok)
+	require.Equal(t, "bakery", search.Keyword)
+}
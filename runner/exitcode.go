@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Exit codes of the program, see ExitCode.
+const (
+	ExitOK = 0
+	// ExitError is used for the errors that have no specific code.
+	ExitError = 1
+	// ExitConfig is used for invalid flags and configuration values.
+	// The flag package uses it too for flags that cannot be parsed.
+	ExitConfig = 2
+	// ExitConnection is used when the browser cannot be started or the
+	// database cannot be reached.
+	ExitConnection = 3
+	// ExitPartial is used when the run finished but some keywords failed.
+	ExitPartial = 4
+	// ExitNoResults is used when the run finished without writing a place.
+	ExitNoResults = 5
+)
+
+var (
+	ErrConfig     = errors.New("invalid configuration")
+	ErrConnection = errors.New("connection failed")
+	ErrPartial    = errors.New("some keywords failed")
+	ErrNoResults  = errors.New("no results")
+)
+
+// connectionMessages are parts of the error messages of playwright and
+// network failures, in lower case, for the errors of libraries that cannot
+// be wrapped with ErrConnection.
+var connectionMessages = []string{
+	"could not start driver",
+	"could not run driver",
+	"could not install driver",
+	"could not install browsers",
+	"browsertype.launch",
+	"connection refused",
+	"no such host",
+	"dial tcp",
+}
+
+// ExitCode returns the exit code of the program for err, the error of
+// ParseConfig or of a runner. An interrupted run (context.Canceled) exits
+// with ExitOK.
+func ExitCode(err error) int {
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return ExitOK
+	case errors.Is(err, ErrConfig):
+		return ExitConfig
+	case errors.Is(err, ErrConnection), isConnectionError(err):
+		return ExitConnection
+	case errors.Is(err, ErrPartial):
+		return ExitPartial
+	case errors.Is(err, ErrNoResults):
+		return ExitNoResults
+	default:
+		return ExitError
+	}
+}
+
+func isConnectionError(err error) bool {
+	msg := strings.ToLower(err.Error())
+
+	for _, s := range connectionMessages {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func configError(msg string) error {
+	return fmt.Errorf("%w: %s", ErrConfig, msg)
+}
@@ -0,0 +1,170 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func parseArgs(args ...string) (*runner.Config, error) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	return runner.ParseConfigArgs(fs, args)
+}
+
+func Test_ParseConfigExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "valid", args: []string{"-c", "1", "-input", "queries.txt"}, code: runner.ExitOK},
+		{name: "unknown flag", args: []string{"-c", "1", "-no-such-flag"}, code: runner.ExitConfig},
+		{name: "bad value", args: []string{"-c", "one"}, code: runner.ExitConfig},
+		{name: "zero concurrency", args: []string{"-c", "0"}, code: runner.ExitConfig},
+		{name: "rating out of range", args: []string{"-c", "1", "-min-rating", "6"}, code: runner.ExitConfig},
+		{name: "unknown since", args: []string{"-c", "1", "-since", "yesterday"}, code: runner.ExitConfig},
+		{name: "unknown format", args: []string{"-c", "1", "-format", "xml"}, code: runner.ExitConfig},
+		{name: "zero empty scrolls", args: []string{"-c", "1", "-max-empty-scrolls", "0"}, code: runner.ExitConfig},
+		{name: "unknown wait until", args: []string{"-c", "1", "-wait-until", "idle"}, code: runner.ExitConfig},
+		{name: "place delay max below min", args: []string{"-c", "1", "-place-delay-min", "2s", "-place-delay-max", "1s"}, code: runner.ExitConfig},
+		{name: "valid reviews file", args: []string{"-c", "1", "-input", "queries.txt", "-reviews-file", "reviews.csv"}, code: runner.ExitOK},
+		{name: "valid reviews table", args: []string{"-c", "1", "-input", "queries.txt", "-format", "sqlite", "-results", "r.db", "-reviews-format", "table"}, code: runner.ExitOK},
+		{name: "unknown reviews format", args: []string{"-c", "1", "-reviews-format", "xml"}, code: runner.ExitConfig},
+		{name: "reviews table with csv", args: []string{"-c", "1", "-input", "queries.txt", "-reviews-format", "table"}, code: runner.ExitConfig},
+		{name: "reviews file outside file mode", args: []string{"-c", "1", "-web", "-reviews-file", "reviews.csv"}, code: runner.ExitConfig},
+		{name: "negative page reuse limit", args: []string{"-c", "1", "-page-reuse-limit", "-1"}, code: runner.ExitConfig},
+		{name: "valid keywords", args: []string{"-c", "1", "-keywords", "cafe in athens, bar in athens"}, code: runner.ExitOK},
+		{name: "empty keywords", args: []string{"-c", "1", "-keywords", " ; "}, code: runner.ExitConfig},
+		{name: "keywords with input", args: []string{"-c", "1", "-keywords", "cafe", "-input", "queries.txt"}, code: runner.ExitConfig},
+		{name: "append timestamp", args: []string{"-c", "1", "-input", "queries.txt", "-append-timestamp", "-timestamp-format", "2006-01-02"}, code: runner.ExitOK},
+		{name: "timestamp format with separator", args: []string{"-c", "1", "-input", "queries.txt", "-append-timestamp", "-timestamp-format", "2006/01/02"}, code: runner.ExitConfig},
+		{name: "append timestamp with output dir", args: []string{"-c", "1", "-input", "queries.txt", "-append-timestamp", "-output-dir", "runs"}, code: runner.ExitConfig},
+		{name: "stealth chromium", args: []string{"-c", "1", "-input", "queries.txt", "-stealth", "chromium"}, code: runner.ExitOK},
+		{name: "invalid stealth", args: []string{"-c", "1", "-input", "queries.txt", "-stealth", "safari"}, code: runner.ExitConfig},
+		{name: "min concurrency", args: []string{"-c", "8", "-min-concurrency", "2", "-input", "queries.txt"}, code: runner.ExitOK},
+		{name: "min concurrency above c", args: []string{"-c", "2", "-min-concurrency", "4", "-input", "queries.txt"}, code: runner.ExitConfig},
+		{name: "negative min concurrency", args: []string{"-c", "2", "-min-concurrency", "-1", "-input", "queries.txt"}, code: runner.ExitConfig},
+		{name: "quiet", args: []string{"-c", "1", "-input", "queries.txt", "-quiet"}, code: runner.ExitOK},
+		{name: "no banner", args: []string{"-c", "1", "-input", "queries.txt", "-no-banner"}, code: runner.ExitOK},
+		{name: "errors file", args: []string{"-c", "1", "-input", "queries.txt", "-errors-file", "errors.txt"}, code: runner.ExitOK},
+		{name: "errors file with dsn", args: []string{"-c", "1", "-dsn", "postgres://localhost/db", "-errors-file", "errors.txt"}, code: runner.ExitConfig},
+		{name: "web max concurrent jobs", args: []string{"-c", "1", "-input", "queries.txt", "-web-max-concurrent-jobs", "3"}, code: runner.ExitOK},
+		{name: "zero web max concurrent jobs", args: []string{"-c", "1", "-web", "-web-max-concurrent-jobs", "0"}, code: runner.ExitConfig},
+		{name: "feed selectors", args: []string{"-c", "1", "-input", "queries.txt", "-feed-selector", "div[role='feed']", "-feed-selector", "div.results"}, code: runner.ExitOK},
+		{name: "empty place selector", args: []string{"-c", "1", "-input", "queries.txt", "-place-selector", " "}, code: runner.ExitConfig},
+		{name: "expand category", args: []string{"-c", "1", "-input", "queries.txt", "-expand-category", "-category-map", "categories.json"}, code: runner.ExitOK},
+		{name: "category map without expand category", args: []string{"-c", "1", "-input", "queries.txt", "-category-map", "categories.json"}, code: runner.ExitConfig},
+		{name: "keyword timeout", args: []string{"-c", "1", "-input", "queries.txt", "-keyword-timeout", "10m"}, code: runner.ExitOK},
+		{name: "negative keyword timeout", args: []string{"-c", "1", "-input", "queries.txt", "-keyword-timeout", "-1s"}, code: runner.ExitConfig},
+		{name: "keyword timeout with dsn", args: []string{"-c", "1", "-dsn", "postgres://localhost/db", "-keyword-timeout", "10m"}, code: runner.ExitConfig},
+		{name: "cdp endpoint", args: []string{"-c", "1", "-input", "queries.txt", "-cdp-endpoint", "http://127.0.0.1:9222"}, code: runner.ExitOK},
+		{name: "cdp endpoint not a url", args: []string{"-c", "1", "-input", "queries.txt", "-cdp-endpoint", "127.0.0.1:9222"}, code: runner.ExitConfig},
+		{name: "cdp endpoint in fast mode", args: []string{"-c", "1", "-input", "queries.txt", "-fast-mode", "-cdp-endpoint", "ws://127.0.0.1:9222/devtools/browser/0b5c"}, code: runner.ExitConfig},
+		{name: "cdp sessions", args: []string{"-c", "4", "-input", "queries.txt", "-cdp-endpoint", "http://127.0.0.1:9222", "-cdp-sessions", "2", "-cdp-session-timeout", "30s"}, code: runner.ExitOK},
+		{name: "cdp sessions without endpoint", args: []string{"-c", "1", "-input", "queries.txt", "-cdp-sessions", "2"}, code: runner.ExitConfig},
+		{name: "negative cdp session timeout", args: []string{"-c", "1", "-input", "queries.txt", "-cdp-session-timeout", "-1s"}, code: runner.ExitConfig},
+		{name: "storage state", args: []string{"-c", "1", "-input", "queries.txt", "-storage-state", "state.json"}, code: runner.ExitOK},
+		{name: "storage state and save storage state", args: []string{"-c", "1", "-input", "queries.txt", "-storage-state", "state.json", "-save-storage-state", "saved.json"}, code: runner.ExitConfig},
+		{name: "storage state in fast mode", args: []string{"-c", "1", "-input", "queries.txt", "-fast-mode", "-storage-state", "state.json"}, code: runner.ExitConfig},
+		{name: "group by keyword json", args: []string{"-c", "1", "-input", "queries.txt", "-group-by-keyword-json"}, code: runner.ExitOK},
+		{name: "group by keyword json with csv", args: []string{"-c", "1", "-input", "queries.txt", "-group-by-keyword-json", "-format", "csv"}, code: runner.ExitConfig},
+		{name: "group by keyword json in fast mode", args: []string{"-c", "1", "-input", "queries.txt", "-group-by-keyword-json", "-fast-mode"}, code: runner.ExitConfig},
+		{name: "group by keyword json with retries", args: []string{"-c", "1", "-input", "queries.txt", "-group-by-keyword-json", "-retry-empty-keywords"}, code: runner.ExitConfig},
+		{name: "negative buffer memory", args: []string{"-c", "1", "-input", "queries.txt", "-group-by-keyword-json", "-buffer-memory", "-1"}, code: runner.ExitConfig},
+		{name: "correlation id", args: []string{"-c", "1", "-input", "queries.txt", "-correlation-id", "-run-id", "nightly-42"}, code: runner.ExitOK},
+		{name: "run id without correlation id", args: []string{"-c", "1", "-input", "queries.txt", "-run-id", "nightly-42"}, code: runner.ExitConfig},
+		{name: "run id with a slash", args: []string{"-c", "1", "-input", "queries.txt", "-correlation-id", "-run-id", "a/b"}, code: runner.ExitConfig},
+		{name: "correlation id in fast mode", args: []string{"-c", "1", "-input", "queries.txt", "-correlation-id", "-fast-mode"}, code: runner.ExitConfig},
+		{name: "block resources", args: []string{"-c", "1", "-input", "queries.txt", "-block-resources", "images, fonts,media,analytics"}, code: runner.ExitOK},
+		{name: "block no resources", args: []string{"-c", "1", "-input", "queries.txt", "-block-resources", "none"}, code: runner.ExitOK},
+		{name: "block unknown resource", args: []string{"-c", "1", "-input", "queries.txt", "-block-resources", "fonts,scripts"}, code: runner.ExitConfig},
+		{name: "block none and fonts", args: []string{"-c", "1", "-input", "queries.txt", "-block-resources", "none,fonts"}, code: runner.ExitConfig},
+		{name: "max input jobs", args: []string{"-c", "1", "-input", "queries.txt", "-max-input-jobs", "50", "-truncate-input-jobs"}, code: runner.ExitOK},
+		{name: "negative max input jobs", args: []string{"-c", "1", "-input", "queries.txt", "-max-input-jobs", "-1"}, code: runner.ExitConfig},
+		{name: "truncate input jobs without a cap", args: []string{"-c", "1", "-input", "queries.txt", "-max-input-jobs", "0", "-truncate-input-jobs"}, code: runner.ExitConfig},
+		{name: "proxy labels", args: []string{"-c", "1", "-input", "queries.txt", "-proxies", "http://localhost:9050#us-east"}, code: runner.ExitOK},
+		{name: "proxy labels in fast mode", args: []string{"-c", "1", "-input", "queries.txt", "-fast-mode", "-proxies", "http://localhost:9050#us-east"}, code: runner.ExitConfig},
+		{name: "proxy labels in web mode", args: []string{"-c", "1", "-web", "-proxies", "http://localhost:9050#us-east"}, code: runner.ExitConfig},
+		{name: "proxy labels with page reuse limit", args: []string{"-c", "1", "-input", "queries.txt", "-proxies", "http://localhost:9050#us-east", "-page-reuse-limit", "5"}, code: runner.ExitConfig},
+		{name: "proxy labels with browser reuse limit", args: []string{"-c", "1", "-input", "queries.txt", "-proxies", "http://localhost:9050#us-east", "-browser-reuse-limit", "50"}, code: runner.ExitConfig},
+		{name: "proxy labels with stealth", args: []string{"-c", "1", "-input", "queries.txt", "-proxies", "http://localhost:9050#us-east", "-stealth", "chromium"}, code: runner.ExitOK},
+		{name: "cdp endpoint with page reuse limit", args: []string{"-c", "1", "-input", "queries.txt", "-cdp-endpoint", "http://127.0.0.1:9222", "-page-reuse-limit", "5"}, code: runner.ExitConfig},
+		{name: "proxies with page reuse limit", args: []string{"-c", "1", "-input", "queries.txt", "-proxies", "http://localhost:9050", "-page-reuse-limit", "5"}, code: runner.ExitOK},
+		{name: "resume from db in file mode", args: []string{"-c", "1", "-input", "queries.txt", "-resume-from-db"}, code: runner.ExitConfig},
+		{name: "expand related", args: []string{"-c", "1", "-input", "queries.txt", "-expand-related", "2"}, code: runner.ExitOK},
+		{name: "negative expand related", args: []string{"-c", "1", "-input", "queries.txt", "-expand-related", "-1"}, code: runner.ExitConfig},
+		{name: "expand related with dsn", args: []string{"-c", "1", "-dsn", "postgres://localhost/db", "-expand-related", "1"}, code: runner.ExitConfig},
+		{name: "expand related in fast mode", args: []string{"-c", "1", "-input", "queries.txt", "-fast-mode", "-geo", "37.98,23.72", "-expand-related", "1"}, code: runner.ExitConfig},
+		{name: "drain timeout", args: []string{"-c", "1", "-input", "queries.txt", "-drain-timeout", "30s"}, code: runner.ExitOK},
+		{name: "negative drain timeout", args: []string{"-c", "1", "-input", "queries.txt", "-drain-timeout", "-1s"}, code: runner.ExitConfig},
+		{name: "drain timeout in web mode", args: []string{"-c", "1", "-web", "-drain-timeout", "30s"}, code: runner.ExitConfig},
+		{name: "webhook headers", args: []string{"-c", "1", "-input", "queries.txt", "-format", "webhook", "-webhook-url", "http://127.0.0.1:8080/hook", "-webhook-header", "Authorization: Bearer abc", "-webhook-header", "X-Run: {run_id}"}, code: runner.ExitOK},
+		{name: "forbidden webhook header", args: []string{"-c", "1", "-input", "queries.txt", "-format", "webhook", "-webhook-url", "http://127.0.0.1:8080/hook", "-webhook-header", "Host: example.com"}, code: runner.ExitConfig},
+		{name: "webhook header without webhook format", args: []string{"-c", "1", "-input", "queries.txt", "-webhook-header", "Authorization: Bearer abc"}, code: runner.ExitConfig},
+		{name: "warmup", args: []string{"-c", "1", "-input", "queries.txt", "-warmup"}, code: runner.ExitOK},
+		{name: "warmup in fast mode", args: []string{"-c", "1", "-input", "queries.txt", "-fast-mode", "-geo", "37.98,23.72", "-warmup"}, code: runner.ExitConfig},
+		{name: "coord format dms", args: []string{"-c", "1", "-input", "queries.txt", "-coord-format", "dms"}, code: runner.ExitOK},
+		{name: "unknown coord format", args: []string{"-c", "1", "-input", "queries.txt", "-coord-format", "utm"}, code: runner.ExitConfig},
+		{name: "coord format dms with sqlite", args: []string{"-c", "1", "-input", "queries.txt", "-format", "sqlite", "-coord-format", "dms"}, code: runner.ExitConfig},
+		{name: "coord format dms in web mode", args: []string{"-c", "1", "-web", "-coord-format", "dms"}, code: runner.ExitConfig},
+		{name: "coord format dms with group by keyword json", args: []string{"-c", "1", "-input", "queries.txt", "-group-by-keyword-json", "-coord-format", "dms"}, code: runner.ExitConfig},
+		{name: "missing proxies file", args: []string{"-c", "1", "-proxies-file", "does-not-exist.txt"}, code: runner.ExitConfig},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := parseArgs(tc.args...)
+			require.Equal(t, tc.code, runner.ExitCode(err), err)
+
+			if tc.code == runner.ExitOK {
+				require.NotNil(t, cfg)
+				require.Equal(t, runner.RunModeFile, cfg.RunMode)
+			} else {
+				require.ErrorIs(t, err, runner.ErrConfig)
+			}
+		})
+	}
+}
+
+func Test_ExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "success", err: nil, code: runner.ExitOK},
+		{name: "interrupted", err: fmt.Errorf("run: %w", context.Canceled), code: runner.ExitOK},
+		{name: "database", err: fmt.Errorf("%w: %w", runner.ErrConnection, errors.New("password authentication failed")), code: runner.ExitConnection},
+		{name: "browser", err: errors.New("could not start driver: fork/exec node: no such file or directory"), code: runner.ExitConnection},
+		{name: "remote browser", err: errors.New("dial tcp 127.0.0.1:3000: connect: connection refused"), code: runner.ExitConnection},
+		{name: "partial", err: fmt.Errorf("%w: dentist in athens", runner.ErrPartial), code: runner.ExitPartial},
+		{name: "no results", err: runner.ErrNoResults, code: runner.ExitNoResults},
+		{name: "other", err: errors.New("open queries.txt: permission denied"), code: runner.ExitError},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.code, runner.ExitCode(tc.err))
+		})
+	}
+}
+
+func Test_ParseConfigResumeFromDB(t *testing.T) {
+	cfg, err := parseArgs("-c", "1", "-dsn", "postgres://localhost/db", "-resume-from-db")
+	require.NoError(t, err)
+	require.Equal(t, runner.RunModeDatabase, cfg.RunMode)
+	require.True(t, cfg.ResumeFromDB)
+
+	cfg, err = parseArgs("-c", "1", "-dsn", "postgres://localhost/db", "-produce", "-resume-from-db")
+	require.NoError(t, err)
+	require.Equal(t, runner.RunModeDatabaseProduce, cfg.RunMode)
+}
@@ -0,0 +1,33 @@
+package runner
+
+import (
+	"context"
+
+	"github.com/gosom/scrapemate"
+	"github.com/playwright-community/playwright-go"
+)
+
+// exported for testing
+var (
+	ParseConfigArgs  = parseConfig
+	RenderProgress   = renderProgress
+	CheckPluginBuild = checkPluginBuild
+	PluginWriter     = pluginWriter
+	NewSessionPool   = newSessionPool
+)
+
+// Acquire exports acquire for testing.
+func (p *sessionPool) Acquire(ctx context.Context) (func(), error) {
+	return p.acquire(ctx)
+}
+
+// NewCDPFetcher returns the fetcher of -cdp-endpoint rendering the pages
+// in browser, through proxies labelled with labels.
+func NewCDPFetcher(browser playwright.Browser, proxies, labels []string) (scrapemate.HTTPFetcher, error) {
+	rotator, err := newProxyRotator(proxies, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cdpFetcher{browser: browser, rotator: rotator}, nil
+}
@@ -1,30 +1,58 @@
 package filerunner
 
 import (
+	"bufio"
+	"bytes"
 	"context"
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gosom/google-maps-scraper/deduper"
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
 	"github.com/gosom/google-maps-scraper/runner"
 	"github.com/gosom/google-maps-scraper/tlmt"
 	"github.com/gosom/scrapemate"
-	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
-	"github.com/gosom/scrapemate/adapters/writers/jsonwriter"
 	"github.com/gosom/scrapemate/scrapemateapp"
 )
 
 type fileRunner struct {
-	cfg     *runner.Config
-	input   io.Reader
-	writers []scrapemate.ResultWriter
-	app     *scrapemateapp.ScrapemateApp
-	outfile *os.File
+	cfg        *runner.Config
+	input      io.Reader
+	writers    []scrapemate.ResultWriter
+	app        runner.App
+	outfile    *os.File
+	compressor io.WriteCloser
+	flusher    *runner.FlushWriter
+	counter    *runner.CountWriter
+	requests   int
+	nameFilter *runner.ExcludeNamesWriter
+	rateFilter *runner.RatingFilterWriter
+	closed     *runner.ClosedFilterWriter
+	since      *runner.SinceWriter
+	reviews    *runner.ReviewsWriter
+	transform  *runner.TransformWriter
+	groups     *runner.KeywordGroupWriter
+	grouped    *runner.GroupedJSONWriter
+	errorsFile *runner.ErrorsFile
+	closers    []io.Closer
+
+	// set with -keyword-timeout
+	keywordTimeout *gmaps.KeywordTimeout
+
+	// the settings of the jobs, see runner.JobSettings
+	settings *gmaps.Settings
+
+	// set with -output-dir
+	runDir      string
+	inputHash   string
+	resultsPath string
 }
 
 func New(cfg *runner.Config) (runner.Runner, error) {
@@ -36,14 +64,26 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 		cfg: cfg,
 	}
 
+	if cfg.KeywordTimeout > 0 {
+		ans.keywordTimeout = gmaps.NewKeywordTimeout(cfg.KeywordTimeout)
+	}
+
 	if err := ans.setInput(); err != nil {
 		return nil, err
 	}
 
+	if err := ans.setOutputDir(); err != nil {
+		return nil, err
+	}
+
 	if err := ans.setWriters(); err != nil {
 		return nil, err
 	}
 
+	if err := ans.setErrorsFile(); err != nil {
+		return nil, err
+	}
+
 	if err := ans.setApp(); err != nil {
 		return nil, err
 	}
@@ -54,6 +94,13 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 func (r *fileRunner) Run(ctx context.Context) (err error) {
 	var seedJobs []scrapemate.IJob
 
+	dedup, closeReport, err := r.newDeduper()
+	if err != nil {
+		return err
+	}
+
+	defer closeReport()
+
 	t0 := time.Now().UTC()
 
 	defer func() {
@@ -67,33 +114,251 @@ func (r *fileRunner) Run(ctx context.Context) (err error) {
 			params["error"] = err.Error()
 		}
 
+		params["duplicates"] = dedup.Duplicates()
+		params["requests"] = r.requests
+
+		log.Printf("dropped %d duplicates", dedup.Duplicates())
+
+		if r.cfg.RequestBudget > 0 {
+			log.Printf("used %d of %d requests", r.requests, r.cfg.RequestBudget)
+		}
+
+		if throttled := r.settings.Throttle.Engaged(); throttled > 0 {
+			params["throttled"] = throttled
+
+			log.Printf("throttled %d times because google was blocking requests", throttled)
+		}
+
+		if r.nameFilter != nil {
+			params["excluded_names"] = r.nameFilter.Excluded()
+
+			log.Printf("excluded %d places matching %s", r.nameFilter.Excluded(), r.cfg.ExcludeNamesFile)
+		}
+
+		if r.rateFilter != nil {
+			params["filtered"] = r.rateFilter.Filtered()
+
+			log.Printf("filtered %d places by review count or rating", r.rateFilter.Filtered())
+		}
+
+		if r.closed != nil {
+			params["excluded_closed"] = r.closed.Excluded()
+
+			log.Printf("excluded %d closed places", r.closed.Excluded())
+		}
+
+		if r.transform != nil {
+			params["transform_dropped"] = r.transform.Dropped()
+
+			log.Printf("transformers dropped %d places", r.transform.Dropped())
+		}
+
+		if r.since != nil {
+			params["since_new"] = r.since.Added()
+			params["since_changed"] = r.since.Changed()
+
+			log.Printf("wrote %d new and %d changed places, skipped %d places written by a previous run",
+				r.since.Added(), r.since.Changed(), r.since.Unchanged())
+		}
+
+		if r.errorsFile != nil {
+			params["failed_jobs"] = r.errorsFile.Failed()
+
+			log.Printf("wrote %d failed jobs to %s", r.errorsFile.Failed(), r.cfg.ErrorsFile)
+		}
+
+		if r.keywordTimeout != nil {
+			limited := r.keywordTimeout.Limited()
+			params["time_limited_keywords"] = len(limited)
+
+			if len(limited) > 0 {
+				log.Printf("%d keywords ran out of time: %s", len(limited), strings.Join(limited, ", "))
+			}
+		}
+
+		if r.grouped != nil && r.grouped.Spilled() > 0 {
+			log.Printf("moved %d MB of places to a temporary file while grouping them by keyword", r.grouped.Spilled()>>20)
+		}
+
+		if r.reviews != nil {
+			params["reviews"] = r.reviews.Written()
+
+			log.Printf("wrote %d reviews to %s", r.reviews.Written(), r.cfg.ReviewsFile)
+		}
+
+		if r.runDir != "" {
+			r.writeSummary(t0, len(seedJobs), dedup.Duplicates(), err)
+		}
+
 		evt := tlmt.NewEvent("file_runner", params)
 
 		_ = runner.Telemetry().Send(ctx, evt)
 	}()
 
-	dedup := deduper.New()
 	exitMonitor := exiter.New()
 
-	seedJobs, err = runner.CreateSeedJobs(
-		r.cfg.FastMode,
-		r.cfg.LangCode,
-		r.input,
-		r.cfg.MaxDepth,
-		r.cfg.Email,
-		r.cfg.GeoCoordinates,
-		r.cfg.Zoom,
-		r.cfg.Radius,
-		dedup,
-		exitMonitor,
-		r.cfg.ExtraReviews,
-	)
+	locations, err := runner.LoadLocations(r.cfg.LocationsFile)
+	if err != nil {
+		return err
+	}
+
+	categories, err := runner.CategoryExpanderFromConfig(r.cfg)
+	if err != nil {
+		return err
+	}
+
+	tracker := runner.NewSeedTracker()
+
+	opts := runner.SeedJobOptions{
+		FastMode:          r.cfg.FastMode,
+		GeoCoordinates:    r.cfg.GeoCoordinates,
+		Zoom:              r.cfg.Zoom,
+		Radius:            r.cfg.Radius,
+		FastModeDetails:   r.cfg.FastModeDetails,
+		LangCode:          r.cfg.LangCode,
+		MaxDepth:          r.cfg.MaxDepth,
+		Email:             r.cfg.Email,
+		ExtraReviews:      r.cfg.ExtraReviews,
+		Country:           r.cfg.Country,
+		InputType:         r.cfg.InputType,
+		Dedup:             dedup,
+		ExitMonitor:       exitMonitor,
+		AutoDepthPatience: r.cfg.AutoDepthPatience,
+		MaxEmptyScrolls:   r.cfg.MaxEmptyScrolls,
+		MaxPerKeyword:     r.cfg.MaxPerKeyword,
+		ExpandNearby:      r.cfg.ExpandNearby,
+		ExpandRelated:     r.cfg.ExpandRelated,
+		KeywordTimeout:    r.keywordTimeout,
+		PlaceWaitSelector: r.cfg.PlaceWaitSelector,
+		WaitTimeout:       r.cfg.WaitTimeout,
+		ScreenshotsDir:    r.cfg.ScreenshotsDir,
+		ScreenshotsMax:    r.cfg.ScreenshotsMax,
+		RawJSONDir:        r.cfg.SaveRawJSON,
+		RawJSONMax:        r.cfg.RawJSONMax,
+		EnrichWebsite:     r.cfg.EnrichWebsite,
+		Locations:         locations,
+		MaxTemplateJobs:   r.cfg.MaxTemplateJobs,
+		Categories:        categories,
+		MaxInputJobs:      r.cfg.MaxInputJobs,
+		TruncateInputJobs: r.cfg.TruncateInputJobs,
+		Shuffle:           r.cfg.ShuffleSeeds,
+		SeedReporter:      tracker,
+		Settings:          r.settings,
+	}
+
+	// the nil writers must not become non-nil interfaces
+	if r.groups != nil {
+		opts.KeywordTracker = r.groups
+	}
+
+	if r.errorsFile != nil {
+		opts.FailureReporter = r.errorsFile
+	}
+
+	if r.cfg.CorrelationID {
+		opts.RunID = r.cfg.RunID
+	}
+
+	seedJobs, err = runner.CreateSeedJobs(r.input, opts)
 	if err != nil {
 		return err
 	}
 
+	if categories != nil {
+		keywords, searches := categories.Expanded()
+		log.Printf("expanded %d category keywords into %d searches", keywords, searches)
+	}
+
+	if len(locations) > 0 {
+		log.Printf("created %d seed jobs using %d locations", len(seedJobs), len(locations))
+	}
+
+	if r.cfg.CorrelationID {
+		log.Printf("run ID %s, the correlation IDs are %s/<seed job>", r.cfg.RunID, r.cfg.RunID)
+	}
+
+	err = r.start(ctx, exitMonitor, seedJobs)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return err
+	}
+
+	if r.cfg.RetryEmptyKeywords {
+		err = r.retryEmptySeeds(ctx, tracker, seedJobs)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+	}
+
+	return r.checkResults(tracker, seedJobs)
+}
+
+// checkResults returns runner.ErrNoResults when the run wrote no place and
+// runner.ErrPartial when some keywords failed, so that the program exits
+// with the matching code.
+func (r *fileRunner) checkResults(tracker *runner.SeedTracker, seedJobs []scrapemate.IJob) error {
+	if r.counter.Count() == 0 {
+		return runner.ErrNoResults
+	}
+
+	failed := tracker.FailedSeeds(seedJobs)
+	if len(failed) == 0 {
+		return nil
+	}
+
+	keywords := make([]string, 0, len(failed))
+	for _, job := range failed {
+		keywords = append(keywords, job.Keyword)
+	}
+
+	return fmt.Errorf("%w: %s", runner.ErrPartial, strings.Join(keywords, ", "))
+}
+
+// newDeduper returns the run's deduper. With -dedup-report the dropped
+// keys are also written to the report file, which is flushed and closed by
+// the returned func.
+func (r *fileRunner) newDeduper() (deduper.Deduper, func(), error) {
+	if r.cfg.DedupReport == "" {
+		return deduper.New(), func() {}, nil
+	}
+
+	f, err := os.Create(r.cfg.DedupReport)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w := bufio.NewWriter(f)
+
+	closeReport := func() {
+		if err := w.Flush(); err != nil {
+			log.Printf("dedup report: %v", err)
+		}
+
+		_ = f.Close()
+	}
+
+	return deduper.NewWithReport(w), closeReport, nil
+}
+
+func (r *fileRunner) start(ctx context.Context, exitMonitor exiter.Exiter, seedJobs []scrapemate.IJob) error {
 	exitMonitor.SetSeedCount(len(seedJobs))
 
+	// the budget is shared with the retries of empty keywords
+	if r.cfg.RequestBudget > 0 {
+		exitMonitor.SetRequestBudget(r.cfg.RequestBudget - r.requests)
+	}
+
+	defer func() {
+		r.requests += exitMonitor.Progress().Requests
+	}()
+
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -101,46 +366,224 @@ func (r *fileRunner) Run(ctx context.Context) (err error) {
 
 	go exitMonitor.Run(ctx)
 
-	err = r.app.Start(ctx, seedJobs...)
+	if runner.ProgressEnabled(r.cfg) {
+		stop := r.showProgress(exitMonitor)
+		defer stop()
+	}
+
+	return r.app.Start(ctx, seedJobs...)
+}
+
+// showProgress draws the progress of the run on stderr, with the log
+// lines above it, until the returned function is called.
+func (r *fileRunner) showProgress(exitMonitor exiter.Exiter) func() {
+	display := runner.NewProgressDisplay(os.Stderr, 0)
+
+	log.SetOutput(display)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		display.Run(ctx, exitMonitor)
+	}()
+
+	return func() {
+		cancel()
+		<-done
+
+		log.SetOutput(os.Stderr)
+	}
+}
+
+// retryEmptySeeds runs the searches that found no places once more,
+// with a longer timeout, and logs the keywords that are still empty.
+func (r *fileRunner) retryEmptySeeds(ctx context.Context, tracker *runner.SeedTracker, seedJobs []scrapemate.IJob) error {
+	empty := tracker.EmptySeeds(seedJobs)
+	if len(empty) == 0 {
+		return nil
+	}
+
+	if r.cfg.RequestBudget > 0 && r.requests >= r.cfg.RequestBudget {
+		log.Printf("not retrying %d keywords without results: the request budget is used up", len(empty))
+
+		return nil
+	}
+
+	log.Printf("retrying %d keywords without results", len(empty))
+
+	exitMonitor := exiter.New()
+	retryJobs := runner.RetrySeedJobs(empty, exitMonitor)
+
+	err := r.start(ctx, exitMonitor, retryJobs)
+
+	if still := tracker.EmptySeeds(retryJobs); len(still) > 0 {
+		keywords := make([]string, 0, len(still))
+		for _, job := range still {
+			keywords = append(keywords, job.Keyword)
+		}
+
+		log.Printf("keywords without results after retry: %s", strings.Join(keywords, ", "))
+	}
 
 	return err
 }
 
 func (r *fileRunner) Close(context.Context) error {
+	var errs []error
+
 	if r.app != nil {
-		return r.app.Close()
+		errs = append(errs, r.app.Close())
 	}
 
 	if r.input != nil {
 		if closer, ok := r.input.(io.Closer); ok {
-			return closer.Close()
+			errs = append(errs, closer.Close())
 		}
 	}
 
+	for _, closer := range r.closers {
+		errs = append(errs, closer.Close())
+	}
+
+	if r.errorsFile != nil {
+		errs = append(errs, r.errorsFile.Close())
+	}
+
+	if r.flusher != nil {
+		errs = append(errs, r.flusher.Close())
+	}
+
+	// the compressor must be closed before the file so the trailing data gets written
+	if r.compressor != nil {
+		errs = append(errs, r.compressor.Close())
+	}
+
 	if r.outfile != nil {
-		return r.outfile.Close()
+		errs = append(errs, r.outfile.Close())
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 func (r *fileRunner) setInput() error {
-	switch r.cfg.InputFile {
-	case "stdin":
-		r.input = os.Stdin
-	default:
-		f, err := os.Open(r.cfg.InputFile)
-		if err != nil {
-			return err
+	input, err := runner.OpenSeedInput(r.cfg)
+	if err != nil {
+		return err
+	}
+
+	r.input = input
+
+	return nil
+}
+
+// setOutputDir creates the run's subfolder of -output-dir and points the
+// results file and the dedup report to it.
+// The input is read in memory to name the folder after its hash.
+func (r *fileRunner) setOutputDir() error {
+	if r.cfg.OutputDir == "" {
+		return nil
+	}
+
+	input, err := io.ReadAll(r.input)
+	if err != nil {
+		return err
+	}
+
+	if closer, ok := r.input.(io.Closer); ok && r.input != os.Stdin {
+		_ = closer.Close()
+	}
+
+	r.input = bytes.NewReader(input)
+	r.inputHash = runner.InputHash(input)
+
+	r.runDir, err = runner.CreateRunDir(r.cfg.OutputDir, time.Now(), input)
+	if err != nil {
+		return err
+	}
+
+	if sink, err := runner.GetSink(r.cfg.Format); err == nil && sink.Ext != "" && r.cfg.CustomWriter == "" {
+		name := "results" + sink.Ext
+		if r.cfg.ResultsFile != "" && r.cfg.ResultsFile != "stdout" {
+			name = filepath.Base(r.cfg.ResultsFile)
 		}
 
-		r.input = f
+		r.cfg.ResultsFile = filepath.Join(r.runDir, name)
+		r.resultsPath = r.cfg.ResultsFile
+	}
+
+	dedupReport := "duplicates.txt"
+	if r.cfg.DedupReport != "" {
+		dedupReport = filepath.Base(r.cfg.DedupReport)
 	}
 
+	r.cfg.DedupReport = filepath.Join(r.runDir, dedupReport)
+
+	if r.cfg.ErrorsFile != "" {
+		r.cfg.ErrorsFile = filepath.Join(r.runDir, filepath.Base(r.cfg.ErrorsFile))
+	}
+
+	log.Printf("writing run output to %s", r.runDir)
+
 	return nil
 }
 
+// writeSummary writes summary.json to the run's folder.
+func (r *fileRunner) writeSummary(t0 time.Time, seedJobs, duplicates int, runErr error) {
+	finished := time.Now().UTC()
+
+	summary := runner.RunSummary{
+		StartedAt:  t0,
+		FinishedAt: finished,
+		Duration:   finished.Sub(t0).String(),
+		Input:      r.cfg.InputFile,
+		InputHash:  r.inputHash,
+		Results:    r.resultsPath,
+		SeedJobs:   seedJobs,
+		Duplicates: duplicates,
+		Requests:   r.requests,
+	}
+
+	if r.nameFilter != nil {
+		summary.Excluded = r.nameFilter.Excluded()
+	}
+
+	if r.rateFilter != nil {
+		summary.Filtered = r.rateFilter.Filtered()
+	}
+
+	if r.closed != nil {
+		summary.Excluded += r.closed.Excluded()
+	}
+
+	if r.keywordTimeout != nil {
+		summary.TimeLimited = r.keywordTimeout.Limited()
+	}
+
+	if runErr != nil {
+		summary.Error = runErr.Error()
+	}
+
+	if err := runner.WriteRunSummary(filepath.Join(r.runDir, "summary.json"), &summary); err != nil {
+		log.Printf("could not write run summary: %v", err)
+
+		return
+	}
+
+	log.Printf("run output written to %s", r.runDir)
+}
+
 func (r *fileRunner) setWriters() error {
+	if err := r.appendTimestamp(); err != nil {
+		return err
+	}
+
+	if err := r.checkWritable(); err != nil {
+		return err
+	}
+
 	if r.cfg.CustomWriter != "" {
 		parts := strings.Split(r.cfg.CustomWriter, ":")
 		if len(parts) != 2 {
@@ -156,68 +599,293 @@ func (r *fileRunner) setWriters() error {
 
 		r.writers = append(r.writers, customWriter)
 	} else {
+		sink, err := runner.GetSink(r.cfg.Format)
+		if err != nil {
+			return err
+		}
+
 		var resultsWriter io.Writer
 
-		switch r.cfg.ResultsFile {
-		case "stdout":
-			resultsWriter = os.Stdout
-		default:
-			f, err := os.Create(r.cfg.ResultsFile)
+		if sink.Stream {
+			resultsWriter, err = r.openResultsFile()
+			if err != nil {
+				return err
+			}
+		}
+
+		var writer scrapemate.ResultWriter
+
+		if r.cfg.GroupByKeywordJSON {
+			r.grouped = runner.NewGroupedJSONWriter(resultsWriter, int64(r.cfg.BufferMemory)<<20)
+			writer = r.grouped
+		} else {
+			writer, err = sink.New(r.cfg, resultsWriter)
 			if err != nil {
 				return err
 			}
+		}
 
-			r.outfile = f
+		if closer, ok := writer.(io.Closer); ok {
+			r.closers = append(r.closers, closer)
+		}
 
-			resultsWriter = r.outfile
+		// the coordinates are converted last, so the other writers still
+		// see *gmaps.Entry
+		if r.cfg.CoordFormat == runner.CoordFormatDMS {
+			writer = runner.NewDMSWriter(writer)
 		}
 
-		csvWriter := csvwriter.NewCsvWriter(csv.NewWriter(resultsWriter))
+		r.writers = append(r.writers, writer)
+	}
 
-		if r.cfg.JSON {
-			r.writers = append(r.writers, jsonwriter.NewJSONWriter(resultsWriter))
-		} else {
-			r.writers = append(r.writers, csvWriter)
+	// the reviews are split off after the filters, so only the reviews of
+	// the places written end up in the reviews file
+	if r.cfg.ReviewsFile != "" {
+		reviews, err := r.openReviewsWriter()
+		if err != nil {
+			return err
+		}
+
+		r.reviews = runner.NewReviewsWriter(r.writers[0], reviews)
+		r.writers[0] = r.reviews
+	}
+
+	// the counter wraps the output directly, so it sees what the filters keep
+	r.counter = runner.NewCountWriter(r.writers[0])
+	r.writers[0] = r.counter
+
+	if r.cfg.ExcludeNamesFile != "" {
+		names, err := runner.LoadExcludedNames(r.cfg.ExcludeNamesFile)
+		if err != nil {
+			return err
+		}
+
+		r.nameFilter = runner.NewExcludeNamesWriter(r.writers[0], names)
+		r.writers[0] = r.nameFilter
+	}
+
+	if r.cfg.MinReviews > 0 || r.cfg.MinRating > 0 || r.cfg.MaxRating > 0 {
+		r.rateFilter = runner.NewRatingFilterWriter(r.writers[0], r.cfg.MinReviews, r.cfg.MinRating, r.cfg.MaxRating)
+		r.writers[0] = r.rateFilter
+	}
+
+	if r.cfg.ExcludeClosed {
+		r.closed = runner.NewClosedFilterWriter(r.writers[0])
+		r.writers[0] = r.closed
+	}
+
+	if r.cfg.Since != "" {
+		since, err := runner.NewSinceWriter(r.writers[0], r.cfg.SinceFile, r.cfg.DetectChanges)
+		if err != nil {
+			return err
 		}
+
+		r.since = since
+		r.writers[0] = r.since
+	}
+
+	// the transformers run first, so the filters see the transformed places
+	if len(r.cfg.Transforms) > 0 {
+		transformers, err := runner.EntryTransformers(r.cfg.Transforms)
+		if err != nil {
+			return err
+		}
+
+		r.transform = runner.NewTransformWriter(r.writers[0], transformers...)
+		r.writers[0] = r.transform
+	}
+
+	// the keywords are followed before the filters, which drop places
+	if r.cfg.GroupByKeywordJSON {
+		r.groups = runner.NewKeywordGroupWriter(r.writers[0])
+		r.writers[0] = r.groups
 	}
 
 	return nil
 }
 
+// checkWritable fails before anything is scraped when the results or the
+// reviews file cannot be written. Some sinks, like sqlite, only write to
+// their file once the first place is scraped.
+func (r *fileRunner) checkWritable() error {
+	var paths []string
+
+	if r.cfg.CustomWriter == "" && r.cfg.ResultsFile != "" && r.cfg.ResultsFile != "stdout" {
+		sink, err := runner.GetSink(r.cfg.Format)
+		if err != nil {
+			return err
+		}
+
+		fname := r.cfg.ResultsFile
+
+		if ext := runner.CompressionExtension(r.cfg.Compress); sink.Stream && ext != "" && !strings.HasSuffix(fname, ext) {
+			fname += ext
+		}
+
+		if sink.Stream || sink.Ext != "" {
+			paths = append(paths, fname)
+		}
+	}
+
+	if r.cfg.ReviewsFile != "" {
+		paths = append(paths, r.cfg.ReviewsFile)
+	}
+
+	for _, path := range paths {
+		if err := runner.CheckWritable(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setErrorsFile opens the file of -errors-file.
+func (r *fileRunner) setErrorsFile() error {
+	if r.cfg.ErrorsFile == "" {
+		return nil
+	}
+
+	errorsFile, err := runner.OpenErrorsFile(r.cfg.ErrorsFile)
+	if err != nil {
+		return err
+	}
+
+	r.errorsFile = errorsFile
+
+	return nil
+}
+
+// openReviewsWriter creates the reviews file and returns a writer in
+// -reviews-format for it. The file is closed with the runner.
+func (r *fileRunner) openReviewsWriter() (scrapemate.ResultWriter, error) {
+	sink, err := runner.GetSink(r.cfg.ReviewsFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(r.cfg.ReviewsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	r.closers = append(r.closers, f)
+
+	return sink.New(r.cfg, f)
+}
+
+// appendTimestamp inserts the start time of the run in the name of the
+// results file for -append-timestamp, so runs writing to the same path do
+// not overwrite each other.
+func (r *fileRunner) appendTimestamp() error {
+	if !r.cfg.AppendTimestamp || r.cfg.CustomWriter != "" ||
+		r.cfg.ResultsFile == "" || r.cfg.ResultsFile == "stdout" {
+		return nil
+	}
+
+	fname := runner.TimestampedPath(r.cfg.ResultsFile, time.Now(), r.cfg.TimestampFormat)
+
+	if ext := runner.CompressionExtension(r.cfg.Compress); ext != "" && !strings.HasSuffix(fname, ext) {
+		fname += ext
+	}
+
+	r.cfg.ResultsFile = fname
+
+	log.Printf("writing results to %s", fname)
+
+	return nil
+}
+
+// openResultsFile opens the results file (or stdout), wrapped in the
+// configured compressor.
+func (r *fileRunner) openResultsFile() (io.Writer, error) {
+	var resultsWriter io.Writer
+
+	switch r.cfg.ResultsFile {
+	case "stdout":
+		resultsWriter = os.Stdout
+	default:
+		fname := r.cfg.ResultsFile
+
+		if ext := runner.CompressionExtension(r.cfg.Compress); ext != "" && !strings.HasSuffix(fname, ext) {
+			fname += ext
+		}
+
+		f, err := os.Create(fname)
+		if err != nil {
+			return nil, err
+		}
+
+		r.resultsPath = fname
+
+		r.outfile = f
+
+		resultsWriter = r.outfile
+	}
+
+	if r.cfg.Compress != "" {
+		compressor, err := runner.NewCompressedWriter(r.cfg.Compress, resultsWriter)
+		if err != nil {
+			return nil, err
+		}
+
+		r.compressor = compressor
+
+		resultsWriter = r.compressor
+	}
+
+	if r.cfg.FlushInterval > 0 {
+		r.flusher = runner.NewFlushWriter(resultsWriter, r.cfg.FlushInterval)
+
+		resultsWriter = r.flusher
+	}
+
+	return resultsWriter, nil
+}
+
 func (r *fileRunner) setApp() error {
 	opts := []func(*scrapemateapp.Config) error{
-		// scrapemateapp.WithCache("leveldb", "cache"),
 		scrapemateapp.WithConcurrency(r.cfg.Concurrency),
 		scrapemateapp.WithExitOnInactivity(r.cfg.ExitOnInactivityDuration),
 	}
 
+	if r.cfg.Email && r.cfg.EmailConcurrency > 0 {
+		opts = append(opts,
+			scrapemateapp.WithProvider(runner.LimitEmailJobs(runner.NewMemoryProvider(), r.cfg.EmailConcurrency)),
+		)
+	}
+
 	if len(r.cfg.Proxies) > 0 {
 		opts = append(opts,
 			scrapemateapp.WithProxies(r.cfg.Proxies),
 		)
 	}
 
-	if !r.cfg.FastMode {
-		if r.cfg.Debug {
-			opts = append(opts, scrapemateapp.WithJS(
-				scrapemateapp.Headfull(),
-				scrapemateapp.DisableImages(),
-			),
-			)
-		} else {
-			opts = append(opts, scrapemateapp.WithJS(scrapemateapp.DisableImages()))
-		}
-	} else {
-		opts = append(opts, scrapemateapp.WithStealth("firefox"))
+	opts = append(opts, runner.BrowserOptions(r.cfg, r.cfg.FastMode)...)
+
+	r.settings = runner.JobSettings(r.cfg)
+
+	if err := runner.SetupStorageState(context.Background(), r.cfg, r.settings); err != nil {
+		return err
 	}
 
-	if !r.cfg.DisablePageReuse {
+	if r.cfg.CacheEnabled {
+		removed, err := runner.SetupCache(r.cfg.CacheDir, r.cfg.CacheTTL)
+		if err != nil {
+			return err
+		}
+
+		if removed > 0 {
+			log.Printf("removed %d expired cache entries", removed)
+		}
+
 		opts = append(opts,
-			scrapemateapp.WithPageReuseLimit(2),
-			scrapemateapp.WithPageReuseLimit(200),
+			scrapemateapp.WithCache(runner.CacheType, r.cfg.CacheDir),
 		)
 	}
 
+	opts = append(opts, runner.ReuseOptions(r.cfg)...)
+
 	matecfg, err := scrapemateapp.NewConfig(
 		r.writers,
 		opts...,
@@ -226,7 +894,7 @@ func (r *fileRunner) setApp() error {
 		return err
 	}
 
-	r.app, err = scrapemateapp.NewScrapeMateApp(matecfg)
+	r.app, err = runner.NewApp(r.cfg, matecfg)
 	if err != nil {
 		return err
 	}
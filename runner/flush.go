@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"time"
+)
+
+type flusher interface {
+	Flush() error
+}
+
+// FlushWriter buffers the writes to an io.Writer and flushes them at a
+// fixed interval, so that a consumer of the output sees the results while
+// the run goes on. If the wrapped writer can be flushed too (e.g. a
+// compressor), it is flushed after the buffer.
+//
+// Writes and flushes are serialized, so the writer is safe for concurrent
+// use.
+type FlushWriter struct {
+	mu  sync.Mutex
+	buf *bufio.Writer
+	w   io.Writer
+
+	done chan struct{}
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// NewFlushWriter wraps w and starts flushing it every interval until Close
+// is called.
+func NewFlushWriter(w io.Writer, interval time.Duration) *FlushWriter {
+	f := FlushWriter{
+		buf:  bufio.NewWriter(w),
+		w:    w,
+		done: make(chan struct{}),
+	}
+
+	f.wg.Add(1)
+
+	go func() {
+		defer f.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-f.done:
+				return
+			case <-ticker.C:
+				// a failed flush is returned again by the next write or by Close
+				_ = f.Flush()
+			}
+		}
+	}()
+
+	return &f
+}
+
+func (f *FlushWriter) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.buf.Write(p)
+}
+
+// Flush writes the buffered data to the wrapped writer and flushes it.
+func (f *FlushWriter) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.buf.Flush(); err != nil {
+		return err
+	}
+
+	if fl, ok := f.w.(flusher); ok {
+		return fl.Flush()
+	}
+
+	return nil
+}
+
+// Close stops the periodic flushing and flushes the remaining data.
+// It does not close the wrapped writer.
+func (f *FlushWriter) Close() error {
+	f.once.Do(func() {
+		close(f.done)
+	})
+
+	f.wg.Wait()
+
+	return f.Flush()
+}
@@ -0,0 +1,96 @@
+package runner_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+func Test_FlushWriter(t *testing.T) {
+	var dst syncBuffer
+
+	w := runner.NewFlushWriter(&dst, 10*time.Millisecond)
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range 100 {
+				_, err := w.Write([]byte("line\n"))
+				require.NoError(t, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	require.Eventually(t, func() bool {
+		return len(dst.Bytes()) == 1000*len("line\n")
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, w.Close())
+	require.Equal(t, strings.Repeat("line\n", 1000), string(dst.Bytes()))
+}
+
+func Test_FlushWriterCompressed(t *testing.T) {
+	var dst syncBuffer
+
+	gz := gzip.NewWriter(&dst)
+	w := runner.NewFlushWriter(gz, 10*time.Millisecond)
+
+	_, err := w.Write([]byte("first\n"))
+	require.NoError(t, err)
+
+	// the flushed gzip stream can be read up to the data written so far
+	require.Eventually(t, func() bool {
+		r, err := gzip.NewReader(bytes.NewReader(dst.Bytes()))
+		if err != nil {
+			return false
+		}
+
+		got, _ := io.ReadAll(r)
+
+		return string(got) == "first\n"
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, w.Close())
+	require.NoError(t, gz.Close())
+
+	r, err := gzip.NewReader(bytes.NewReader(dst.Bytes()))
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "first\n", string(got))
+}
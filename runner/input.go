@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// InputFileStats describes one of several input files.
+type InputFileStats struct {
+	Path       string
+	Keywords   int
+	Duplicates int
+}
+
+// SplitKeywords splits the value of -keywords into queries. Queries are
+// separated by commas or semicolons; blank ones are dropped.
+func SplitKeywords(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';'
+	})
+
+	keywords := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			keywords = append(keywords, f)
+		}
+	}
+
+	return keywords
+}
+
+// OpenSeedInput returns the queries of the run: the -keywords, one per
+// line, or else the -input file(s), see OpenInput.
+func OpenSeedInput(cfg *Config) (io.Reader, error) {
+	if len(cfg.Keywords) > 0 {
+		return strings.NewReader(strings.Join(cfg.Keywords, "\n")), nil
+	}
+
+	return OpenInput(cfg.InputFile)
+}
+
+// OpenInput opens the -input value for reading. "stdin" reads standard input.
+//
+// The value can also be a comma separated list of files. They are read in
+// order as one input, without the lines already seen in a previous line or
+// file, and the number of keywords of each file is logged. All the paths
+// are checked first, so a bad one fails before anything is scraped.
+func OpenInput(input string) (io.Reader, error) {
+	if input == "stdin" {
+		return os.Stdin, nil
+	}
+
+	paths := strings.Split(input, ",")
+	if len(paths) == 1 {
+		return os.Open(input)
+	}
+
+	r, stats, err := ConcatInputFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range stats {
+		log.Printf("input %s: %d keywords (%d duplicates skipped)", s.Path, s.Keywords, s.Duplicates)
+	}
+
+	return r, nil
+}
+
+// ConcatInputFiles reads the files in order and returns their lines as one
+// input, skipping empty lines and lines that already appeared.
+func ConcatInputFiles(paths []string) (io.Reader, []InputFileStats, error) {
+	var missing []string
+
+	for i, p := range paths {
+		paths[i] = strings.TrimSpace(p)
+
+		if _, err := os.Stat(paths[i]); err != nil {
+			missing = append(missing, paths[i])
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, nil, fmt.Errorf("input files not found: %s", strings.Join(missing, ", "))
+	}
+
+	var buf bytes.Buffer
+
+	seen := map[string]bool{}
+	stats := make([]InputFileStats, 0, len(paths))
+
+	for _, p := range paths {
+		s, err := appendInputFile(&buf, p, seen)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		stats = append(stats, s)
+	}
+
+	return &buf, stats, nil
+}
+
+func appendInputFile(buf *bytes.Buffer, path string, seen map[string]bool) (InputFileStats, error) {
+	stats := InputFileStats{Path: path}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return stats, err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if seen[line] {
+			stats.Duplicates++
+
+			continue
+		}
+
+		seen[line] = true
+		stats.Keywords++
+
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	if err := scanner.Err(); err != nil {
+		return stats, errors.Join(fmt.Errorf("reading %s", path), err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,88 @@
+package runner_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func writeInputFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func Test_ConcatInputFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	cafes := writeInputFile(t, dir, "cafes.txt", "cafe in athens\n\ncafe in patra\ncafe in athens\n")
+	bars := writeInputFile(t, dir, "bars.txt", "bar in athens\ncafe in patra\n  bar in patra  \n")
+
+	r, stats, err := runner.ConcatInputFiles([]string{cafes, " " + bars})
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	require.Equal(t, "cafe in athens\ncafe in patra\nbar in athens\nbar in patra\n", string(data))
+	require.Equal(t, []runner.InputFileStats{
+		{Path: cafes, Keywords: 2, Duplicates: 1},
+		{Path: bars, Keywords: 2, Duplicates: 1},
+	}, stats)
+}
+
+func Test_ConcatInputFilesMissing(t *testing.T) {
+	dir := t.TempDir()
+
+	cafes := writeInputFile(t, dir, "cafes.txt", "cafe in athens\n")
+	missing1 := filepath.Join(dir, "bars.txt")
+	missing2 := filepath.Join(dir, "shops.txt")
+
+	_, _, err := runner.ConcatInputFiles([]string{missing1, cafes, missing2})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), missing1+", "+missing2)
+}
+
+func Test_OpenInputSingleFile(t *testing.T) {
+	path := writeInputFile(t, t.TempDir(), "q.txt", "a\na\n")
+
+	r, err := runner.OpenInput(path)
+	require.NoError(t, err)
+
+	defer r.(io.Closer).Close()
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	// a single file is read as is
+	require.Equal(t, "a\na\n", string(data))
+}
+
+func Test_SplitKeywords(t *testing.T) {
+	require.Equal(t,
+		[]string{"cafe in athens", "bar in athens", "pizza #!# p1"},
+		runner.SplitKeywords(" cafe in athens, bar in athens;; pizza #!# p1 ;"),
+	)
+	require.Empty(t, runner.SplitKeywords(" , ; "))
+}
+
+func Test_OpenSeedInputKeywords(t *testing.T) {
+	cfg, err := parseArgs("-c", "1", "-keywords", "cafe in athens;bar in athens")
+	require.NoError(t, err)
+	require.Equal(t, runner.RunModeFile, cfg.RunMode)
+
+	r, err := runner.OpenSeedInput(cfg)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "cafe in athens\nbar in athens", string(data))
+}
@@ -2,43 +2,137 @@ package runner
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"log"
+	"math/rand/v2"
+	"net/url"
 	"os"
 	"path/filepath"
 	"plugin"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/gosom/google-maps-scraper/deduper"
 	"github.com/gosom/google-maps-scraper/exiter"
 	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/limiter"
 	"github.com/gosom/scrapemate"
 )
 
-func CreateSeedJobs(
-	fastmode bool,
-	langCode string,
-	r io.Reader,
-	maxDepth int,
-	email bool,
-	geoCoordinates string,
-	zoom int,
-	radius float64,
-	dedup deduper.Deduper,
-	exitMonitor exiter.Exiter,
-	extraReviews bool,
-) (jobs []scrapemate.IJob, err error) {
+const (
+	// InputTypeAuto treats lines that are Google Maps place URLs as places
+	// and every other line as a search query.
+	InputTypeAuto = "auto"
+	// InputTypeKeywords treats every line as a search query.
+	InputTypeKeywords = "keywords"
+	// InputTypeURLs requires every line to be a Google Maps place URL.
+	InputTypeURLs = "urls"
+)
+
+// langCodeRe matches the language codes accepted by Google in the hl
+// parameter, e.g. fr or pt-BR.
+var langCodeRe = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,4})?$`)
+
+// LocationPlaceholder is replaced by every location of -locations-file in
+// the input lines that contain it.
+const LocationPlaceholder = "{location}"
+
+// SeedJobOptions are the options of the seed jobs of CreateSeedJobs. The
+// zero value creates searches in normal mode with the defaults of gmaps.
+type SeedJobOptions struct {
+	// FastMode creates the search jobs of fast mode, around GeoCoordinates
+	// within Radius meters at Zoom.
+	FastMode       bool
+	GeoCoordinates string
+	Zoom           int
+	Radius         float64
+	// FastModeDetails opens the place of every result of fast mode.
+	FastModeDetails bool
+
+	// LangCode is the language of the lines that set none.
+	LangCode     string
+	MaxDepth     int
+	Email        bool
+	ExtraReviews bool
+	// Country is the ISO 3166-1 alpha-2 code of the country of the
+	// searches, see NormalizeCountryCode.
+	Country string
+	// InputType tells the lines that are place URLs from the searches, one
+	// of the InputType constants. Empty is InputTypeAuto.
+	InputType string
+
+	Dedup       deduper.Deduper
+	ExitMonitor exiter.Exiter
+
+	AutoDepthPatience int
+	MaxEmptyScrolls   int
+	// MaxPerKeyword caps the places of every search. The limiter is in
+	// memory, so it only applies to the jobs of this process.
+	MaxPerKeyword int
+	ExpandNearby  int
+	ExpandRelated int
+	// KeywordTimeout caps the time spent on every search.
+	KeywordTimeout *gmaps.KeywordTimeout
+
+	PlaceWaitSelector string
+	WaitTimeout       time.Duration
+	ScreenshotsDir    string
+	ScreenshotsMax    int
+	RawJSONDir        string
+	RawJSONMax        int
+	EnrichWebsite     bool
+
+	// Locations replace LocationPlaceholder in the lines that contain it,
+	// in at most MaxTemplateJobs jobs in all (0 means no limit).
+	Locations       []string
+	MaxTemplateJobs int
+	// Categories expands the keywords that are categories, see
+	// CategoryExpander.
+	Categories *CategoryExpander
+	// MaxInputJobs caps the jobs of the input (0 means no limit): more
+	// jobs are an error, or are dropped with TruncateInputJobs.
+	MaxInputJobs      int
+	TruncateInputJobs bool
+	// Shuffle starts the jobs of the same priority in a random order.
+	Shuffle bool
+
+	// SeedReporter, KeywordTracker and FailureReporter are told about the
+	// searches and places of the seeds, see their gmaps options.
+	SeedReporter    gmaps.SeedReporter
+	KeywordTracker  gmaps.KeywordTracker
+	FailureReporter gmaps.FailureReporter
+	// RunID traces the seed jobs with the run, see TraceSeedJobs.
+	RunID string
+
+	// Settings are the settings of the jobs of the run, see JobSettings.
+	Settings *gmaps.Settings
+}
+
+// CreateSeedJobs creates the seed jobs of the input r, one line per search
+// or place URL, see SeedJobOptions.
+func CreateSeedJobs(r io.Reader, opts SeedJobOptions) (jobs []scrapemate.IJob, err error) {
 	var lat, lon float64
 
-	if fastmode {
-		if geoCoordinates == "" {
+	countryCode, err := NormalizeCountryCode(opts.Country)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.FastMode {
+		if opts.GeoCoordinates == "" {
 			return nil, fmt.Errorf("geo coordinates are required in fast mode")
 		}
 
-		parts := strings.Split(geoCoordinates, ",")
+		parts := strings.Split(opts.GeoCoordinates, ",")
 		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid geo coordinates: %s", geoCoordinates)
+			return nil, fmt.Errorf("invalid geo coordinates: %s", opts.GeoCoordinates)
 		}
 
 		lat, err = strconv.ParseFloat(parts[0], 64)
@@ -59,77 +153,471 @@ func CreateSeedJobs(
 			return nil, fmt.Errorf("invalid longitude: %f", lon)
 		}
 
-		if zoom < 1 || zoom > 21 {
-			return nil, fmt.Errorf("invalid zoom level: %d", zoom)
+		if opts.Zoom < 1 || opts.Zoom > 21 {
+			return nil, fmt.Errorf("invalid zoom level: %d", opts.Zoom)
 		}
 
-		if radius < 0 {
-			return nil, fmt.Errorf("invalid radius: %f", radius)
+		if opts.Radius < 0 {
+			return nil, fmt.Errorf("invalid radius: %f", opts.Radius)
 		}
 	}
 
+	switch opts.InputType {
+	case "", InputTypeAuto, InputTypeKeywords, InputTypeURLs:
+	default:
+		return nil, fmt.Errorf("invalid input type: %s", opts.InputType)
+	}
+
+	var keywordLimiter limiter.Limiter
+
+	if opts.MaxPerKeyword > 0 {
+		keywordLimiter = limiter.New(opts.MaxPerKeyword)
+	}
+
 	scanner := bufio.NewScanner(r)
 
-	for scanner.Scan() {
+	lineNum := 0
+
+	// expanded counts the jobs created from templated lines, seenQueries
+	// drops the expansions that repeat an earlier one
+	expanded := 0
+	seenQueries := make(map[string]struct{})
+
+	// priorities holds the priority of the input line of every job
+	var priorities []int
+
+	// truncated stops the input at MaxInputJobs with TruncateInputJobs
+	truncated := false
+
+	for !truncated && scanner.Scan() {
+		lineNum++
+
 		query := strings.TrimSpace(scanner.Text())
 		if query == "" {
 			continue
 		}
 
-		var id string
+		var (
+			id       string
+			priority int
+		)
+
+		// the optional fields of a line are query #!# id #!# priority #!# lang
+		lineLang := opts.LangCode
 
 		if before, after, ok := strings.Cut(query, "#!#"); ok {
 			query = strings.TrimSpace(before)
-			id = strings.TrimSpace(after)
+			fields := strings.SplitN(after, "#!#", 3)
+			id = strings.TrimSpace(fields[0])
+
+			if len(fields) > 1 {
+				if prio := strings.TrimSpace(fields[1]); prio != "" {
+					priority, err = strconv.Atoi(prio)
+					if err != nil {
+						return nil, fmt.Errorf("line %d: invalid priority: %s", lineNum, prio)
+					}
+				}
+			}
+
+			if len(fields) > 2 {
+				if lang := strings.TrimSpace(fields[2]); lang != "" {
+					if !langCodeRe.MatchString(lang) {
+						return nil, fmt.Errorf("line %d: invalid language code: %s", lineNum, lang)
+					}
+
+					lineLang = lang
+				}
+			}
+		}
+
+		queries := []string{query}
+
+		if opts.Categories != nil && (opts.InputType == InputTypeKeywords || !IsPlaceURL(query)) {
+			queries = opts.Categories.Expand(query)
+
+			// the subcategories of different keywords can end up in the
+			// same searches
+			if len(queries) > 1 && !strings.Contains(query, LocationPlaceholder) {
+				queries = dropSeenQueries(queries, seenQueries)
+			}
 		}
 
-		var job scrapemate.IJob
+		if strings.Contains(query, LocationPlaceholder) {
+			if len(opts.Locations) == 0 {
+				return nil, fmt.Errorf("line %d: %s is used but no locations are given", lineNum, LocationPlaceholder)
+			}
 
-		if !fastmode {
-			opts := []gmaps.GmapJobOptions{}
+			templates := queries
+			queries = nil
 
-			if dedup != nil {
-				opts = append(opts, gmaps.WithDeduper(dedup))
+			for _, template := range templates {
+				queries = append(queries, expandLocations(template, opts.Locations, seenQueries)...)
 			}
 
-			if exitMonitor != nil {
-				opts = append(opts, gmaps.WithExitMonitor(exitMonitor))
+			expanded += len(queries)
+			if opts.MaxTemplateJobs > 0 && expanded > opts.MaxTemplateJobs {
+				return nil, fmt.Errorf("line %d: %s expands to more than %d jobs", lineNum, LocationPlaceholder, opts.MaxTemplateJobs)
 			}
+		}
+
+		for i, query := range queries {
+			jobID := id
 
-			if extraReviews {
-				opts = append(opts, gmaps.WithExtraReviews())
+			if len(queries) > 1 && id != "" {
+				jobID = id + "-" + strconv.Itoa(i+1)
 			}
 
-			job = gmaps.NewGmapJob(id, langCode, query, maxDepth, email, geoCoordinates, zoom, opts...)
-		} else {
-			jparams := gmaps.MapSearchParams{
-				Location: gmaps.MapLocation{
-					Lat:     lat,
-					Lon:     lon,
-					ZoomLvl: float64(zoom),
-					Radius:  radius,
-				},
-				Query:     query,
-				ViewportW: 1920,
-				ViewportH: 450,
-				Hl:        langCode,
+			isPlace := opts.InputType != InputTypeKeywords && IsPlaceURL(query)
+
+			if opts.InputType == InputTypeURLs && !isPlace {
+				return nil, fmt.Errorf("line %d: not a Google Maps place URL: %s", lineNum, query)
 			}
 
-			opts := []gmaps.SearchJobOptions{}
+			var job scrapemate.IJob
+
+			if isPlace {
+				if opts.FastMode {
+					return nil, fmt.Errorf("line %d: place URLs are not supported in fast mode", lineNum)
+				}
+
+				placeJob := createPlaceSeedJob(jobID, lineLang, query, opts.Email, opts.ExtraReviews, countryCode, opts.Dedup, opts.ExitMonitor)
+				if placeJob == nil {
+					continue
+				}
+
+				if opts.ExpandNearby > 0 {
+					gmaps.WithPlaceJobExpandNearby(opts.ExpandNearby, opts.MaxDepth, nearbyZoom(opts.Zoom), opts.Dedup)(placeJob)
+				}
 
-			if exitMonitor != nil {
-				opts = append(opts, gmaps.WithSearchJobExitMonitor(exitMonitor))
+				gmaps.WithPlaceJobWait(opts.PlaceWaitSelector, opts.WaitTimeout)(placeJob)
+
+				if opts.ScreenshotsDir != "" {
+					gmaps.WithPlaceJobScreenshots(opts.ScreenshotsDir, opts.ScreenshotsMax)(placeJob)
+				}
+
+				if opts.RawJSONDir != "" {
+					gmaps.WithPlaceJobRawJSON(opts.RawJSONDir, opts.RawJSONMax)(placeJob)
+				}
+
+				if opts.EnrichWebsite {
+					gmaps.WithPlaceJobWebsiteEnrichment()(placeJob)
+				}
+
+				if opts.ExpandRelated > 0 {
+					gmaps.WithPlaceJobExpandRelated(opts.ExpandRelated, opts.Dedup)(placeJob)
+				}
+
+				if opts.Settings != nil {
+					gmaps.WithPlaceJobSettings(opts.Settings)(placeJob)
+				}
+
+				if opts.FailureReporter != nil {
+					gmaps.WithPlaceJobFailureReporter(opts.FailureReporter)(placeJob)
+				}
+
+				job = placeJob
+			} else if !opts.FastMode {
+				jopts := []gmaps.GmapJobOptions{}
+
+				if opts.Dedup != nil {
+					jopts = append(jopts, gmaps.WithDeduper(opts.Dedup))
+				}
+
+				if opts.ExitMonitor != nil {
+					jopts = append(jopts, gmaps.WithExitMonitor(opts.ExitMonitor))
+				}
+
+				if opts.ExtraReviews {
+					jopts = append(jopts, gmaps.WithExtraReviews())
+				}
+
+				if countryCode != "" {
+					jopts = append(jopts, gmaps.WithCountry(countryCode))
+				}
+
+				if opts.AutoDepthPatience > 0 {
+					jopts = append(jopts, gmaps.WithAutoDepth(opts.AutoDepthPatience))
+				}
+
+				if keywordLimiter != nil {
+					jopts = append(jopts, gmaps.WithKeywordLimiter(keywordLimiter))
+				}
+
+				if opts.MaxEmptyScrolls > 1 {
+					jopts = append(jopts, gmaps.WithMaxEmptyScrolls(opts.MaxEmptyScrolls))
+				}
+
+				if opts.ExpandNearby > 0 {
+					jopts = append(jopts, gmaps.WithExpandNearby(opts.ExpandNearby, nearbyZoom(opts.Zoom)))
+				}
+
+				if opts.PlaceWaitSelector != "" || opts.WaitTimeout > 0 {
+					jopts = append(jopts, gmaps.WithWait(opts.PlaceWaitSelector, opts.WaitTimeout))
+				}
+
+				if opts.ScreenshotsDir != "" {
+					jopts = append(jopts, gmaps.WithScreenshots(opts.ScreenshotsDir, opts.ScreenshotsMax))
+				}
+
+				if opts.RawJSONDir != "" {
+					jopts = append(jopts, gmaps.WithRawJSON(opts.RawJSONDir, opts.RawJSONMax))
+				}
+
+				if opts.EnrichWebsite {
+					jopts = append(jopts, gmaps.WithWebsiteEnrichment())
+				}
+
+				if opts.ExpandRelated > 0 {
+					jopts = append(jopts, gmaps.WithExpandRelated(opts.ExpandRelated))
+				}
+
+				if opts.KeywordTimeout != nil {
+					jopts = append(jopts, gmaps.WithKeywordTimeout(opts.KeywordTimeout))
+				}
+
+				if opts.SeedReporter != nil {
+					jopts = append(jopts, gmaps.WithSeedReporter(opts.SeedReporter))
+				}
+
+				if opts.KeywordTracker != nil {
+					jopts = append(jopts, gmaps.WithKeywordTracker(opts.KeywordTracker))
+				}
+
+				if opts.FailureReporter != nil {
+					jopts = append(jopts, gmaps.WithFailureReporter(opts.FailureReporter))
+				}
+
+				if opts.Settings != nil {
+					jopts = append(jopts, gmaps.WithSettings(opts.Settings))
+				}
+
+				job = gmaps.NewGmapJob(jobID, lineLang, query, opts.MaxDepth, opts.Email, opts.GeoCoordinates, opts.Zoom, jopts...)
+			} else {
+				jparams := gmaps.MapSearchParams{
+					Location: gmaps.MapLocation{
+						Lat:     lat,
+						Lon:     lon,
+						ZoomLvl: float64(opts.Zoom),
+						Radius:  opts.Radius,
+					},
+					Query:     query,
+					ViewportW: 1920,
+					ViewportH: 450,
+					Hl:        lineLang,
+					Gl:        countryCode,
+				}
+
+				jopts := []gmaps.SearchJobOptions{}
+
+				if opts.ExitMonitor != nil {
+					jopts = append(jopts, gmaps.WithSearchJobExitMonitor(opts.ExitMonitor))
+				}
+
+				if opts.FastModeDetails {
+					jopts = append(jopts, gmaps.WithSearchJobDetails())
+				}
+
+				if opts.Settings != nil {
+					jopts = append(jopts, gmaps.WithSearchJobSettings(opts.Settings))
+				}
+
+				job = gmaps.NewSearchJob(&jparams, jopts...)
 			}
 
-			job = gmaps.NewSearchJob(&jparams, opts...)
+			if opts.MaxInputJobs > 0 && len(jobs) == opts.MaxInputJobs {
+				if !opts.TruncateInputJobs {
+					return nil, fmt.Errorf("line %d: the input expands to more than %d jobs", lineNum, opts.MaxInputJobs)
+				}
+
+				log.Printf("the input expands to more than %d jobs, dropped the jobs from line %d on", opts.MaxInputJobs, lineNum)
+
+				truncated = true
+
+				break
+			}
+
+			jobs = append(jobs, job)
+			priorities = append(priorities, priority)
 		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	jobs = orderSeedJobs(jobs, priorities, opts.Shuffle)
+
+	if opts.RunID != "" {
+		TraceSeedJobs(opts.RunID, jobs)
+	}
+
+	return jobs, nil
+}
+
+// orderSeedJobs returns the jobs in the order they must be started:
+// higher priorities first and input order, or a random order with shuffle,
+// among jobs with the same priority.
+//
+// scrapemate's own job priority is left alone, since its three levels
+// order the kinds of jobs (places before new searches). The seed jobs are
+// pushed in the returned order instead, which NewMemoryProvider and the
+// postgres provider keep for jobs of the same kind.
+func orderSeedJobs(jobs []scrapemate.IJob, priorities []int, shuffle bool) []scrapemate.IJob {
+	idx := make([]int, len(jobs))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	if shuffle {
+		rand.Shuffle(len(idx), func(i, j int) {
+			idx[i], idx[j] = idx[j], idx[i]
+		})
+	}
+
+	sort.SliceStable(idx, func(i, j int) bool {
+		return priorities[idx[i]] > priorities[idx[j]]
+	})
 
-		jobs = append(jobs, job)
+	ans := make([]scrapemate.IJob, len(idx))
+	for i := range idx {
+		ans[i] = jobs[idx[i]]
 	}
 
-	return jobs, scanner.Err()
+	return ans
 }
 
+// expandLocations returns query with the placeholder replaced by each of
+// the locations, leaving out the queries in seen. The returned queries are
+// added to seen.
+func expandLocations(query string, locations []string, seen map[string]struct{}) []string {
+	queries := make([]string, 0, len(locations))
+
+	for _, location := range locations {
+		q := strings.ReplaceAll(query, LocationPlaceholder, location)
+
+		key := strings.ToLower(q)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		queries = append(queries, q)
+	}
+
+	return queries
+}
+
+// dropSeenQueries returns the queries not in seen and adds them to seen.
+func dropSeenQueries(queries []string, seen map[string]struct{}) []string {
+	ans := queries[:0]
+
+	for _, q := range queries {
+		key := strings.ToLower(q)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		ans = append(ans, q)
+	}
+
+	return ans
+}
+
+// nearbyZoom is the zoom level of nearby searches: the configured zoom,
+// or a neighbourhood level view when none is set.
+func nearbyZoom(zoom int) int {
+	const defaultNearbyZoom = 15
+
+	if zoom > 0 {
+		return zoom
+	}
+
+	return defaultNearbyZoom
+}
+
+// IsPlaceURL reports whether s is a Google Maps place URL
+// like https://www.google.com/maps/place/...
+func IsPlaceURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if !strings.HasPrefix(host, "google.") && !strings.Contains(host, ".google.") {
+		return false
+	}
+
+	return strings.Contains(u.Path, "/maps/place/")
+}
+
+// createPlaceSeedJob creates a place job that skips the search phase.
+// It returns nil when the place was already seen.
+func createPlaceSeedJob(
+	id, langCode, u string,
+	email, extraReviews bool,
+	countryCode string,
+	dedup deduper.Deduper,
+	exitMonitor exiter.Exiter,
+) *gmaps.PlaceJob {
+	if dedup != nil && !dedup.AddIfNotExists(context.Background(), gmaps.PlaceKey(u)) {
+		return nil
+	}
+
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	opts := []gmaps.PlaceJobOptions{}
+
+	if exitMonitor != nil {
+		opts = append(opts, gmaps.WithPlaceJobExitMonitor(exitMonitor))
+
+		// a place seed has no search phase: it is completed and has found exactly one place
+		exitMonitor.IncrSeedCompleted(1)
+		exitMonitor.IncrPlacesFound(1)
+	}
+
+	if countryCode != "" {
+		opts = append(opts, gmaps.WithPlaceJobCountry(countryCode))
+	}
+
+	return gmaps.NewPlaceJob(id, langCode, u, email, extraReviews, opts...)
+}
+
+// TraceSeedJobs gives every seed job a gmaps.Trace of the run runID, with
+// the position of the job, from 1, as its seed ID. The trace is passed on
+// to the jobs the seed creates, so their log lines and places carry the
+// correlation ID of the seed.
+func TraceSeedJobs(runID string, seedJobs []scrapemate.IJob) {
+	for i, job := range seedJobs {
+		trace := gmaps.Trace{RunID: runID, SeedID: strconv.Itoa(i + 1)}
+
+		switch j := job.(type) {
+		case *gmaps.GmapJob:
+			j.Trace = trace
+		case *gmaps.PlaceJob:
+			j.Trace = trace
+		}
+	}
+}
+
+// NewRunID returns a random ID for -run-id.
+func NewRunID() string {
+	return strings.ReplaceAll(uuid.New().String(), "-", "")[:12]
+}
+
+// LoadCustomWriter loads the writer pluginName of the first plugin of
+// pluginDir. The plugin is checked before it is opened, so a plugin built
+// with another version of Go or of the shared modules, or whose symbol is
+// not a scrapemate.ResultWriter, is reported with a clear error.
 func LoadCustomWriter(pluginDir, pluginName string) (scrapemate.ResultWriter, error) {
 	files, err := os.ReadDir(pluginDir)
 	if err != nil {
@@ -147,6 +635,10 @@ func LoadCustomWriter(pluginDir, pluginName string) (scrapemate.ResultWriter, er
 
 		pluginPath := filepath.Join(pluginDir, file.Name())
 
+		if err := checkPluginFile(pluginPath); err != nil {
+			return nil, err
+		}
+
 		p, err := plugin.Open(pluginPath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to open plugin %s: %w", file.Name(), err)
@@ -157,12 +649,7 @@ func LoadCustomWriter(pluginDir, pluginName string) (scrapemate.ResultWriter, er
 			return nil, fmt.Errorf("failed to lookup symbol %s: %w", pluginName, err)
 		}
 
-		writer, ok := symWriter.(*scrapemate.ResultWriter)
-		if !ok {
-			return nil, fmt.Errorf("unexpected type %T from writer symbol in plugin %s", symWriter, file.Name())
-		}
-
-		return *writer, nil
+		return pluginWriter(pluginPath, pluginName, symWriter)
 	}
 
 	return nil, fmt.Errorf("no plugin found in %s", pluginDir)
@@ -0,0 +1,346 @@
+package runner_test
+
+import (
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_CreateSeedJobsCountry(t *testing.T) {
+	tests := []struct {
+		name     string
+		fastmode bool
+		country  string
+		expected string
+	}{
+		{name: "germany", country: "de", expected: "de"},
+		{name: "uppercase is normalized", country: "US", expected: "us"},
+		{name: "fast mode", fastmode: true, country: "gr", expected: "gr"},
+		{name: "no country", country: "", expected: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jobs, err := runner.CreateSeedJobs(strings.NewReader("dentist\n"), runner.SeedJobOptions{
+				FastMode:       tc.fastmode,
+				LangCode:       "en",
+				MaxDepth:       10,
+				GeoCoordinates: "37.7749,-122.4194",
+				Zoom:           15,
+				Radius:         10000,
+				Country:        tc.country,
+				InputType:      runner.InputTypeAuto,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobs, 1)
+
+			u, err := url.Parse(jobs[0].GetFullURL())
+			require.NoError(t, err)
+
+			require.Equal(t, "en", u.Query().Get("hl"))
+			require.Equal(t, tc.expected, u.Query().Get("gl"))
+		})
+	}
+}
+
+func Test_CreateSeedJobsInvalidCountry(t *testing.T) {
+	_, err := runner.CreateSeedJobs(strings.NewReader("dentist\n"), runner.SeedJobOptions{
+		LangCode:  "en",
+		MaxDepth:  10,
+		Radius:    10000,
+		Country:   "xx",
+		InputType: runner.InputTypeAuto,
+	})
+	require.Error(t, err)
+}
+
+func Test_CreateSeedJobsPlaceURLs(t *testing.T) {
+	const placeURL = "https://www.google.com/maps/place/Kipriakon/data=!4m7!3m6!1s0x14e732fd76f0d90d:0xe5415928d6702b47"
+
+	tests := []struct {
+		name      string
+		inputType string
+		input     string
+		places    int
+		searches  int
+		wantErr   bool
+	}{
+		{name: "urls mode", inputType: runner.InputTypeURLs, input: placeURL + "\n", places: 1},
+		{name: "auto detects place urls", inputType: runner.InputTypeAuto, input: placeURL + "\ndentist\n", places: 1, searches: 1},
+		{name: "keywords mode never detects", inputType: runner.InputTypeKeywords, input: placeURL + "\n", searches: 1},
+		{name: "urls mode rejects queries", inputType: runner.InputTypeURLs, input: placeURL + "\ndentist\n", wantErr: true},
+		{name: "urls mode rejects non place links", inputType: runner.InputTypeURLs, input: "https://www.google.com/maps/search/dentist\n", wantErr: true},
+		{name: "invalid input type", inputType: "foo", input: placeURL + "\n", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jobs, err := runner.CreateSeedJobs(strings.NewReader(tc.input), runner.SeedJobOptions{
+				LangCode:  "en",
+				MaxDepth:  10,
+				Radius:    10000,
+				InputType: tc.inputType,
+			})
+			if tc.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			var places, searches int
+
+			for _, job := range jobs {
+				switch j := job.(type) {
+				case *gmaps.PlaceJob:
+					places++
+
+					require.Equal(t, placeURL, j.URL)
+				case *gmaps.GmapJob:
+					searches++
+				}
+			}
+
+			require.Equal(t, tc.places, places)
+			require.Equal(t, tc.searches, searches)
+		})
+	}
+}
+
+func Test_IsPlaceURL(t *testing.T) {
+	require.True(t, runner.IsPlaceURL("https://www.google.com/maps/place/Foo/@1,2,17z"))
+	require.True(t, runner.IsPlaceURL("https://google.de/maps/place/Foo"))
+	require.False(t, runner.IsPlaceURL("https://www.google.com/maps/search/foo"))
+	require.False(t, runner.IsPlaceURL("https://example.com/maps/place/Foo"))
+	require.False(t, runner.IsPlaceURL("coffee in /maps/place/"))
+}
+
+func createTemplateJobs(input string, locations []string, maxJobs int) ([]scrapemate.IJob, error) {
+	return runner.CreateSeedJobs(strings.NewReader(input), runner.SeedJobOptions{
+		LangCode:        "en",
+		MaxDepth:        10,
+		Radius:          10000,
+		InputType:       runner.InputTypeAuto,
+		Locations:       locations,
+		MaxTemplateJobs: maxJobs,
+	})
+}
+
+func Test_CreateSeedJobsLocations(t *testing.T) {
+	locations := []string{"Athens", "Berlin", "athens"}
+
+	jobs, err := createTemplateJobs("dentist in {location} #!# d\nbakery\nplumber {location}\n", locations, 0)
+	require.NoError(t, err)
+
+	keywords := make([]string, 0, len(jobs))
+	ids := make([]string, 0, len(jobs))
+
+	for _, job := range jobs {
+		gj := job.(*gmaps.GmapJob)
+		keywords = append(keywords, gj.Keyword)
+		ids = append(ids, gj.GetID())
+	}
+
+	// the lowercase duplicate location is dropped
+	require.Equal(t, []string{"dentist in Athens", "dentist in Berlin", "bakery", "plumber Athens", "plumber Berlin"}, keywords)
+	require.Equal(t, []string{"d-1", "d-2"}, ids[:2])
+
+	_, err = createTemplateJobs("dentist in {location}\nplumber {location}\n", locations, 3)
+	require.Error(t, err)
+
+	jobs, err = createTemplateJobs("dentist in {location}\nplumber {location}\n", locations, 4)
+	require.NoError(t, err)
+	require.Len(t, jobs, 4)
+
+	_, err = createTemplateJobs("dentist in {location}\n", nil, 0)
+	require.Error(t, err)
+}
+
+func Test_CreateSeedJobsHashQuery(t *testing.T) {
+	// a leading # is part of the query, not a comment
+	jobs, err := createTemplateJobs("#1 pizza in athens\n", nil, 0)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, "#1 pizza in athens", jobs[0].(*gmaps.GmapJob).Keyword)
+}
+
+func createCappedJobs(input string, locations []string, maxInputJobs int, truncate bool) ([]scrapemate.IJob, error) {
+	return runner.CreateSeedJobs(strings.NewReader(input), runner.SeedJobOptions{
+		LangCode:          "en",
+		MaxDepth:          10,
+		Radius:            10000,
+		InputType:         runner.InputTypeAuto,
+		Locations:         locations,
+		MaxInputJobs:      maxInputJobs,
+		TruncateInputJobs: truncate,
+	})
+}
+
+func Test_CreateSeedJobsMaxInputJobs(t *testing.T) {
+	locations := make([]string, 1000)
+	for i := range locations {
+		locations[i] = "zone " + strconv.Itoa(i)
+	}
+
+	// a template expanding to 2000 jobs, e.g. a typo in the locations
+	input := "bakery\ndentist in {location}\nplumber in {location}\n"
+
+	_, err := createCappedJobs(input, locations, 500, false)
+	require.ErrorContains(t, err, "line 2: the input expands to more than 500 jobs")
+
+	jobs, err := createCappedJobs(input, locations, 500, true)
+	require.NoError(t, err)
+	require.Len(t, jobs, 500)
+	require.Equal(t, "bakery", jobs[0].(*gmaps.GmapJob).Keyword)
+	require.Equal(t, "dentist in zone 498", jobs[499].(*gmaps.GmapJob).Keyword)
+
+	jobs, err = createCappedJobs(input, locations, 2001, false)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2001)
+
+	jobs, err = createCappedJobs(input, locations, 0, false)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2001)
+}
+
+func createPriorityJobs(input string, shuffle bool) ([]scrapemate.IJob, error) {
+	return runner.CreateSeedJobs(strings.NewReader(input), runner.SeedJobOptions{
+		LangCode:  "en",
+		MaxDepth:  10,
+		Radius:    10000,
+		InputType: runner.InputTypeAuto,
+		Shuffle:   shuffle,
+	})
+}
+
+func Test_CreateSeedJobsPriority(t *testing.T) {
+	keywords := func(jobs []scrapemate.IJob) []string {
+		ans := make([]string, 0, len(jobs))
+		for _, job := range jobs {
+			ans = append(ans, job.(*gmaps.GmapJob).Keyword)
+		}
+
+		return ans
+	}
+
+	input := "later #!# l #!# -1\nplain\nurgent #!# u #!# 10\nalso urgent #!##!# 10\nplain too\n"
+
+	jobs, err := createPriorityJobs(input, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"urgent", "also urgent", "plain", "plain too", "later"}, keywords(jobs))
+	require.Equal(t, "u", jobs[0].GetID())
+	require.Equal(t, "l", jobs[4].GetID())
+
+	for range 10 {
+		jobs, err = createPriorityJobs(input, true)
+		require.NoError(t, err)
+
+		got := keywords(jobs)
+		require.ElementsMatch(t, []string{"urgent", "also urgent"}, got[:2])
+		require.ElementsMatch(t, []string{"plain", "plain too"}, got[2:4])
+		require.Equal(t, "later", got[4])
+	}
+
+	_, err = createPriorityJobs("urgent #!# u #!# high\n", false)
+	require.Error(t, err)
+}
+
+func Test_CreateSeedJobsLang(t *testing.T) {
+	const placeURL = "https://www.google.com/maps/place/Kipriakon/data=!4m7!3m6!1s0x14e732fd76f0d90d:0xe5415928d6702b47"
+
+	input := "boulangerie paris #!##!##!# fr\n" +
+		"bäckerei berlin #!# b #!# 5 #!# de\n" +
+		"dentist athens\n" +
+		"padaria lisboa #!##!##!# pt-BR\n" +
+		placeURL + " #!# p #!##!# el\n"
+
+	jobs, err := createPriorityJobs(input, false)
+	require.NoError(t, err)
+	require.Len(t, jobs, 5)
+
+	hl := make(map[string]string, len(jobs))
+
+	for _, job := range jobs {
+		switch j := job.(type) {
+		case *gmaps.GmapJob:
+			hl[j.Keyword] = j.URLParams["hl"]
+		case *gmaps.PlaceJob:
+			hl["place"] = j.URLParams["hl"]
+		}
+	}
+
+	require.Equal(t, map[string]string{
+		"boulangerie paris": "fr",
+		"bäckerei berlin":   "de",
+		"dentist athens":    "en",
+		"padaria lisboa":    "pt-BR",
+		"place":             "el",
+	}, hl)
+
+	_, err = createPriorityJobs("cafe #!##!##!# french\n", false)
+	require.Error(t, err)
+}
+
+func Test_TraceSeedJobs(t *testing.T) {
+	seeds := []scrapemate.IJob{
+		gmaps.NewGmapJob("line-1", "en", "cafe", 1, false, "", 0),
+		gmaps.NewPlaceJob("line-2", "en", "https://www.google.com/maps/place/x", false, false),
+		gmaps.NewGmapJob("line-3", "en", "bakery", 1, false, "", 0),
+	}
+
+	runner.TraceSeedJobs("3f9a1c", seeds)
+
+	require.Equal(t, "3f9a1c/1", seeds[0].(*gmaps.GmapJob).Trace.ID())
+	require.Equal(t, "3f9a1c/2", seeds[1].(*gmaps.PlaceJob).Trace.ID())
+	require.Equal(t, "3f9a1c/3", seeds[2].(*gmaps.GmapJob).Trace.ID())
+
+	require.Len(t, runner.NewRunID(), 12)
+	require.NotEqual(t, runner.NewRunID(), runner.NewRunID())
+}
+
+func Test_CreateSeedJobsReporters(t *testing.T) {
+	const placeURL = "https://www.google.com/maps/place/Kipriakon/data=!4m7!3m6!1s0x14e732fd76f0d90d:0xe5415928d6702b47"
+
+	tracker := runner.NewSeedTracker()
+	timeout := gmaps.NewKeywordTimeout(time.Minute)
+
+	errorsFile, err := runner.OpenErrorsFile(filepath.Join(t.TempDir(), "errors.txt"))
+	require.NoError(t, err)
+
+	defer errorsFile.Close()
+
+	jobs, err := runner.CreateSeedJobs(strings.NewReader("cafe\n"+placeURL+"\n"), runner.SeedJobOptions{
+		LangCode:        "en",
+		MaxDepth:        1,
+		ExpandRelated:   2,
+		KeywordTimeout:  timeout,
+		SeedReporter:    tracker,
+		FailureReporter: errorsFile,
+		RunID:           "3f9a1c",
+	})
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+
+	search := jobs[0].(*gmaps.GmapJob)
+	require.Equal(t, 2, search.ExpandRelated)
+	require.Same(t, timeout, search.KeywordTimeout)
+	require.Equal(t, tracker, search.SeedReporter)
+	require.Equal(t, errorsFile, search.FailureReporter)
+	require.Nil(t, search.KeywordTracker)
+	require.Equal(t, "3f9a1c/1", search.Trace.ID())
+
+	place := jobs[1].(*gmaps.PlaceJob)
+	require.Equal(t, 2, place.ExpandRelated)
+	require.Equal(t, errorsFile, place.FailureReporter)
+	require.Equal(t, "3f9a1c/2", place.Trace.ID())
+}
@@ -0,0 +1,334 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+var _ gmaps.KeywordTracker = (*KeywordGroupWriter)(nil)
+
+// KeywordGroup is the object written per keyword by -group-by-keyword-json:
+// the keyword, the parameters of its search and its places. Params is nil
+// for places whose search is not known, e.g. the ones of -expand-nearby.
+type KeywordGroup struct {
+	Keyword string               `json:"keyword"`
+	Params  *gmaps.KeywordSearch `json:"params,omitempty"`
+	Places  []*gmaps.Entry       `json:"places"`
+}
+
+// keywordDone is passed down the writers after the last place of a
+// keyword. The filters pass it unchanged, so it arrives after the places
+// they kept.
+type keywordDone struct {
+	search gmaps.KeywordSearch
+}
+
+// keywordProgress counts the places of the searches of a keyword.
+type keywordProgress struct {
+	search   gmaps.KeywordSearch
+	expected int
+	ended    int
+}
+
+// KeywordGroupWriter is a scrapemate.ResultWriter that follows the places
+// of every keyword, as the gmaps.KeywordTracker of the searches, and tells
+// the wrapped writer when all of them are done. It goes before the
+// filters, which may drop places, and the wrapped writers end with a
+// GroupedJSONWriter.
+type KeywordGroupWriter struct {
+	next scrapemate.ResultWriter
+
+	mu       sync.Mutex
+	keywords map[string]*keywordProgress
+	order    []string
+	done     []gmaps.KeywordSearch
+	signal   chan struct{}
+}
+
+// NewKeywordGroupWriter wraps next.
+func NewKeywordGroupWriter(next scrapemate.ResultWriter) *KeywordGroupWriter {
+	return &KeywordGroupWriter{
+		next:     next,
+		keywords: make(map[string]*keywordProgress),
+		signal:   make(chan struct{}, 1),
+	}
+}
+
+// SearchDone starts waiting for the places of search.
+func (w *KeywordGroupWriter) SearchDone(search gmaps.KeywordSearch, places int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	p, ok := w.keywords[search.Keyword]
+	if !ok {
+		p = &keywordProgress{search: search}
+		w.keywords[search.Keyword] = p
+		w.order = append(w.order, search.Keyword)
+	} else {
+		p.search.Found += search.Found
+	}
+
+	p.expected += places
+
+	w.check(search.Keyword)
+}
+
+// PlaceSkipped counts a place of keyword that has no result.
+func (w *KeywordGroupWriter) PlaceSkipped(keyword string) {
+	w.placeEnded(keyword)
+}
+
+// placeEnded counts a place of keyword that reached the writer or was
+// skipped.
+func (w *KeywordGroupWriter) placeEnded(keyword string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if p, ok := w.keywords[keyword]; ok {
+		p.ended++
+
+		w.check(keyword)
+	}
+}
+
+// check moves keyword to the done keywords once all of its places ended.
+// It must be called with mu held.
+func (w *KeywordGroupWriter) check(keyword string) {
+	p := w.keywords[keyword]
+	if p.ended < p.expected {
+		return
+	}
+
+	w.done = append(w.done, p.search)
+	w.forget(keyword)
+
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+// forget stops following keyword. It must be called with mu held.
+func (w *KeywordGroupWriter) forget(keyword string) {
+	delete(w.keywords, keyword)
+
+	for i, k := range w.order {
+		if k == keyword {
+			w.order = append(w.order[:i], w.order[i+1:]...)
+
+			break
+		}
+	}
+}
+
+// takeDone returns the keywords done since the last call. With all, the
+// keywords still waiting for places are returned too, in the order their
+// searches were done.
+func (w *KeywordGroupWriter) takeDone(all bool) []gmaps.KeywordSearch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	done := w.done
+	w.done = nil
+
+	if all {
+		for _, keyword := range w.order {
+			done = append(done, w.keywords[keyword].search)
+		}
+
+		w.keywords = make(map[string]*keywordProgress)
+		w.order = nil
+	}
+
+	return done
+}
+
+// Run passes the results read from in to the wrapped writer, each keyword
+// followed by a keywordDone once its places are done. The keywords whose
+// places did not all end, e.g. because some failed, are done when in is
+// closed.
+func (w *KeywordGroupWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	out := make(chan scrapemate.Result)
+
+	go func() {
+		defer close(out)
+
+		send := func(result scrapemate.Result) bool {
+			select {
+			case out <- result:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		sendDone := func(all bool) bool {
+			for _, search := range w.takeDone(all) {
+				if !send(scrapemate.Result{Data: keywordDone{search: search}}) {
+					return false
+				}
+			}
+
+			return true
+		}
+
+		for {
+			select {
+			case result, ok := <-in:
+				if !ok {
+					sendDone(true)
+
+					return
+				}
+
+				if !send(result) {
+					return
+				}
+
+				for _, entry := range resultEntries(result.Data) {
+					w.placeEnded(entry.SourceKeyword)
+				}
+
+				if !sendDone(false) {
+					return
+				}
+			case <-w.signal:
+				if !sendDone(false) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return w.next.Run(ctx, out)
+}
+
+// resultEntries returns the places of the data of a result.
+func resultEntries(data any) []*gmaps.Entry {
+	switch v := data.(type) {
+	case *gmaps.Entry:
+		return []*gmaps.Entry{v}
+	case []*gmaps.Entry:
+		return v
+	default:
+		return nil
+	}
+}
+
+// GroupedJSONWriter is the writer of -group-by-keyword-json. It keeps the
+// places of every keyword until the KeywordGroupWriter in front of it
+// tells that the keyword is done, then encodes them to w as one
+// KeywordGroup per line. The places left when the results end are written
+// last, grouped the same way.
+//
+// The encoded places are kept in memory up to a limit, past which they are
+// moved to a temporary file until their keyword is written, so large runs
+// do not run out of memory.
+type GroupedJSONWriter struct {
+	w         io.Writer
+	maxMemory int64
+	spilled   atomic.Int64
+}
+
+// NewGroupedJSONWriter returns a GroupedJSONWriter writing to w that keeps
+// up to maxMemory bytes of places in memory, 0 meaning no limit.
+func NewGroupedJSONWriter(w io.Writer, maxMemory int64) *GroupedJSONWriter {
+	return &GroupedJSONWriter{w: w, maxMemory: maxMemory}
+}
+
+// Spilled returns the number of bytes of places moved to the temporary
+// file so far.
+func (g *GroupedJSONWriter) Spilled() int64 {
+	return g.spilled.Load()
+}
+
+func (g *GroupedJSONWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	buf := newSpillBuffer(g.maxMemory)
+
+	defer func() {
+		g.spilled.Store(buf.Spilled())
+
+		_ = buf.Close()
+	}()
+
+	var (
+		groups = make(map[string]bool)
+		order  []string
+	)
+
+	for result := range in {
+		if done, ok := result.Data.(keywordDone); ok {
+			if err := g.writeGroup(buf, done.search.Keyword, &done.search); err != nil {
+				return err
+			}
+
+			delete(groups, done.search.Keyword)
+
+			continue
+		}
+
+		for _, entry := range resultEntries(result.Data) {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+
+			if !groups[entry.SourceKeyword] {
+				groups[entry.SourceKeyword] = true
+				order = append(order, entry.SourceKeyword)
+			}
+
+			if err := buf.Add(entry.SourceKeyword, data); err != nil {
+				return err
+			}
+		}
+
+		g.spilled.Store(buf.Spilled())
+	}
+
+	for _, keyword := range order {
+		if groups[keyword] {
+			if err := g.writeGroup(buf, keyword, nil); err != nil {
+				return err
+			}
+
+			delete(groups, keyword)
+		}
+	}
+
+	return nil
+}
+
+// writeGroup writes the KeywordGroup of keyword with the places of buf,
+// streaming them instead of decoding them back.
+func (g *GroupedJSONWriter) writeGroup(buf *spillBuffer, keyword string, params *gmaps.KeywordSearch) error {
+	head, err := json.Marshal(KeywordGroup{Keyword: keyword, Params: params})
+	if err != nil {
+		return err
+	}
+
+	// head ends with "places":null}
+	head = bytes.TrimSuffix(head, []byte("null}"))
+
+	if _, err := g.w.Write(append(head, '[')); err != nil {
+		return err
+	}
+
+	if err := buf.WriteTo(keyword, g.w); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(g.w, "]}\n")
+
+	return err
+}
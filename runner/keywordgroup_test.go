@@ -0,0 +1,168 @@
+package runner_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_KeywordGroupWriter(t *testing.T) {
+	var out bytes.Buffer
+
+	// the closed filter drops a place of "cafe", which still completes it
+	w := runner.NewKeywordGroupWriter(runner.NewClosedFilterWriter(runner.NewGroupedJSONWriter(&out, 0)))
+
+	place := func(keyword, title string) scrapemate.Result {
+		return scrapemate.Result{Data: &gmaps.Entry{Title: title, SourceKeyword: keyword}}
+	}
+
+	w.SearchDone(gmaps.KeywordSearch{Keyword: "cafe", InputID: "1", LangCode: "en", Depth: 10, Found: 2}, 2)
+	w.SearchDone(gmaps.KeywordSearch{Keyword: "bakery", InputID: "2", LangCode: "en", Depth: 10, Found: 3}, 2)
+	w.SearchDone(gmaps.KeywordSearch{Keyword: "gym", InputID: "3", LangCode: "en", Depth: 10, Found: 2}, 2)
+
+	in := make(chan scrapemate.Result)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- w.Run(context.Background(), in)
+	}()
+
+	in <- place("bakery", "Crumb")
+	in <- place("cafe", "Bean There")
+	in <- scrapemate.Result{Data: &gmaps.Entry{
+		Title:          "Old Brew",
+		SourceKeyword:  "cafe",
+		BusinessStatus: gmaps.BusinessStatusPermanentlyClosed,
+	}}
+	in <- place("gym", "Lift")
+	in <- place("climbing gym", "Nearby Wall")
+
+	// the other place of "bakery" was skipped and "pool" found nothing
+	w.PlaceSkipped("bakery")
+	w.SearchDone(gmaps.KeywordSearch{Keyword: "pool", InputID: "4", LangCode: "en", Depth: 10}, 0)
+
+	// "gym" never gets its second place, it is written when the run ends
+	close(in)
+	require.NoError(t, <-done)
+
+	var groups []runner.KeywordGroup
+
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var group runner.KeywordGroup
+
+		require.NoError(t, json.Unmarshal([]byte(line), &group))
+
+		groups = append(groups, group)
+	}
+
+	keywords := make([]string, 0, len(groups))
+	for _, group := range groups {
+		keywords = append(keywords, group.Keyword)
+	}
+
+	require.Equal(t, []string{"cafe", "bakery", "pool", "gym", "climbing gym"}, keywords)
+
+	titles := func(group runner.KeywordGroup) []string {
+		ans := []string{}
+		for _, place := range group.Places {
+			ans = append(ans, place.Title)
+		}
+
+		return ans
+	}
+
+	require.Equal(t, []string{"Bean There"}, titles(groups[0]))
+	require.Equal(t, []string{"Crumb"}, titles(groups[1]))
+	require.Equal(t, []string{}, titles(groups[2]))
+	require.Equal(t, []string{"Lift"}, titles(groups[3]))
+	require.Equal(t, []string{"Nearby Wall"}, titles(groups[4]))
+
+	require.NotNil(t, groups[0].Params)
+	require.Equal(t, gmaps.KeywordSearch{InputID: "1", LangCode: "en", Depth: 10, Found: 2}, *groups[0].Params)
+	require.NotNil(t, groups[3].Params)
+	require.Equal(t, "3", groups[3].Params.InputID)
+
+	// the search of the places of -expand-nearby is not known
+	require.Nil(t, groups[4].Params)
+
+	require.Contains(t, out.String(), `"places":[]`)
+}
+
+// groupPlaces writes the places of three interleaved keywords through a
+// GroupedJSONWriter of maxMemory bytes and returns its output.
+func groupPlaces(t *testing.T, maxMemory int64) (string, *runner.GroupedJSONWriter) {
+	t.Helper()
+
+	var out bytes.Buffer
+
+	grouped := runner.NewGroupedJSONWriter(&out, maxMemory)
+	w := runner.NewKeywordGroupWriter(grouped)
+
+	keywords := []string{"cafe", "bakery", "gym"}
+
+	for i, keyword := range keywords {
+		w.SearchDone(gmaps.KeywordSearch{Keyword: keyword, InputID: strconv.Itoa(i), LangCode: "en", Depth: 10, Found: 20}, 20)
+	}
+
+	in := make(chan scrapemate.Result, 60)
+
+	for i := range 20 {
+		for _, keyword := range keywords {
+			in <- scrapemate.Result{Data: &gmaps.Entry{
+				Title:         fmt.Sprintf("%s %d", keyword, i),
+				SourceKeyword: keyword,
+				Address:       strings.Repeat("x", 100),
+			}}
+		}
+	}
+
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+
+	return out.String(), grouped
+}
+
+func Test_GroupedJSONWriterSpill(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	want, inMemory := groupPlaces(t, 0)
+	require.Zero(t, inMemory.Spilled())
+
+	// a few places fit in memory, so the places of every keyword are split
+	// between several parts of the file and memory
+	got, spilled := groupPlaces(t, 2000)
+	require.Positive(t, spilled.Spilled())
+	require.Equal(t, want, got)
+
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	require.Len(t, lines, 3)
+
+	for i, line := range lines {
+		var group runner.KeywordGroup
+
+		require.NoError(t, json.Unmarshal([]byte(line), &group))
+		require.Len(t, group.Places, 20)
+
+		for j, place := range group.Places {
+			require.Equal(t, fmt.Sprintf("%s %d", group.Keyword, j), place.Title, i)
+		}
+	}
+
+	// the temporary file is removed
+	files, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
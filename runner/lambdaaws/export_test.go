@@ -0,0 +1,24 @@
+package lambdaaws
+
+import (
+	"time"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+var (
+	ParseSQSMessage = parseSQSMessage
+	SplitChunks     = splitChunks
+)
+
+type LInput = lInput
+
+type LambdaClient = lambdaClient
+
+// NewTestInvoker returns an invoker of payloads using client and uploader
+// instead of AWS, retrying without waiting.
+func NewTestInvoker(client LambdaClient, uploader runner.S3Uploader, payloads []LInput) runner.Runner {
+	invokeBackoff = time.Millisecond
+
+	return &invoker{lclient: client, uploader: uploader, payloads: payloads}
+}
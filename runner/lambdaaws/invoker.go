@@ -2,12 +2,16 @@ package lambdaaws
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
-	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
@@ -19,8 +23,29 @@ import (
 
 var _ runner.Runner = (*invoker)(nil)
 
+const (
+	// maxChunkBytes keeps the keywords of a chunk well below the 256 KB
+	// payload limit of asynchronous invocations.
+	maxChunkBytes = 200 * 1024
+	// maxInvokeAttempts is the number of times an invocation is tried
+	// before its chunk is marked as failed in the manifest.
+	maxInvokeAttempts = 4
+	// maxConcurrentInvocations is the number of invocations in flight.
+	maxConcurrentInvocations = 4
+)
+
+// invokeBackoff is the wait before the second attempt of an invocation.
+// It doubles with every attempt.
+var invokeBackoff = time.Second
+
+// lambdaClient is the part of the lambda client used by the invoker.
+type lambdaClient interface {
+	Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
 type invoker struct {
-	lclient  *lambda.Client
+	lclient  lambdaClient
+	uploader runner.S3Uploader
 	payloads []lInput
 }
 
@@ -44,7 +69,8 @@ func NewInvoker(cfg *runner.Config) (runner.Runner, error) {
 	}
 
 	ans := invoker{
-		lclient: lambda.NewFromConfig(awscfg),
+		lclient:  lambda.NewFromConfig(awscfg),
+		uploader: cfg.S3Uploader,
 	}
 
 	if err := ans.setPayloads(cfg); err != nil {
@@ -54,14 +80,87 @@ func NewInvoker(cfg *runner.Config) (runner.Runner, error) {
 	return &ans, nil
 }
 
+// Run invokes the function once per chunk, a few at a time, and then
+// uploads the manifest of the job next to the results. A chunk whose
+// invocation still fails after the retries is listed as not invoked in the
+// manifest and makes Run return an error, after the other chunks were
+// invoked.
 func (i *invoker) Run(ctx context.Context) error {
+	errs := make([]error, len(i.payloads))
+	sem := make(chan struct{}, maxConcurrentInvocations)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+
 	for j := range i.payloads {
-		if err := i.invoke(ctx, i.payloads[j]); err != nil {
-			return err
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			errs[j] = i.invokeWithRetry(ctx, i.payloads[j])
+
+			mu.Lock()
+			done++
+			log.Printf("invoked %d/%d chunks", done, len(i.payloads))
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	var failed []error
+
+	for j, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Errorf("part %d: %w", i.payloads[j].Part, err))
 		}
 	}
 
-	return nil
+	err := i.writeManifest(ctx, newManifest(i.payloads, errs, time.Now()))
+
+	return errors.Join(append(failed, err)...)
+}
+
+// invokeWithRetry invokes the function, retrying failed invocations with
+// an exponential backoff.
+//
+//nolint:gocritic // let's pass the input as is
+func (i *invoker) invokeWithRetry(ctx context.Context, input lInput) error {
+	wait := invokeBackoff
+
+	var err error
+
+	for attempt := 1; attempt <= maxInvokeAttempts; attempt++ {
+		if err = i.invoke(ctx, input); err == nil {
+			return nil
+		}
+
+		if attempt == maxInvokeAttempts {
+			break
+		}
+
+		log.Printf("invoking part %d failed (attempt %d/%d), retrying in %s: %v",
+			input.Part, attempt, maxInvokeAttempts, wait, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		wait *= 2
+	}
+
+	return err
 }
 
 //nolint:gocritic // let's pass the input as is
@@ -82,32 +181,58 @@ func (i *invoker) invoke(ctx context.Context, input lInput) error {
 		return err
 	}
 
+	if result.FunctionError != nil {
+		return fmt.Errorf("function error: %s", *result.FunctionError)
+	}
+
 	log.Printf("Lambda function %s invoked with JobID %s, Part %d, StatusCode %d\n",
 		input.FunctionName, input.JobID, input.Part, result.StatusCode)
 
 	return nil
 }
 
+// writeManifest uploads m to the bucket of the job. Without an uploader
+// the manifest is logged instead.
+func (i *invoker) writeManifest(ctx context.Context, m manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if i.uploader == nil {
+		log.Printf("no uploader set, manifest of job %s:\n%s", m.JobID, data)
+
+		return nil
+	}
+
+	key := manifestKey(m.JobID)
+
+	if err := i.uploader.Upload(ctx, m.BucketName, key, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("uploading manifest: %w", err)
+	}
+
+	log.Printf("manifest of job %s written to s3://%s/%s", m.JobID, m.BucketName, key)
+
+	return nil
+}
+
 func (i *invoker) Close(context.Context) error {
 	return nil
 }
 
 func (i *invoker) setPayloads(cfg *runner.Config) error {
-	f, err := os.Open(cfg.InputFile)
+	f, err := runner.OpenSeedInput(cfg)
 	if err != nil {
 		return err
 	}
 
-	defer f.Close()
+	if closer, ok := f.(io.Closer); ok {
+		defer closer.Close()
+	}
 
 	scanner := bufio.NewScanner(f)
 
-	chunkSize := cfg.AwsLambdaChunkSize
-
-	var currentChunk []string
-
-	chunkNumber := 0
-	jobID := uuid.New().String()
+	var keywords []string
 
 	for scanner.Scan() {
 		keyword := strings.TrimSpace(scanner.Text())
@@ -115,45 +240,28 @@ func (i *invoker) setPayloads(cfg *runner.Config) error {
 			continue
 		}
 
-		currentChunk = append(currentChunk, keyword)
-
-		// When we reach chunkSize or EOF, create a new payload
-		if len(currentChunk) >= chunkSize {
-			payload := lInput{
-				JobID:        jobID,
-				Part:         chunkNumber,
-				BucketName:   cfg.S3Bucket,
-				Keywords:     currentChunk,
-				Depth:        cfg.MaxDepth,
-				Concurrency:  cfg.Concurrency,
-				Language:     cfg.LangCode,
-				FunctionName: cfg.FunctionName,
-				ExtraReviews: cfg.ExtraReviews,
-			}
-			i.payloads = append(i.payloads, payload)
-
-			currentChunk = []string{}
-			chunkNumber++
-		}
+		keywords = append(keywords, keyword)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
 	}
 
-	if len(currentChunk) > 0 {
-		payload := lInput{
+	jobID := uuid.New().String()
+
+	for part, chunk := range splitChunks(keywords, cfg.AwsLambdaChunkSize, maxChunkBytes) {
+		i.payloads = append(i.payloads, lInput{
 			JobID:        jobID,
-			Part:         chunkNumber,
+			Part:         part,
 			BucketName:   cfg.S3Bucket,
-			Keywords:     currentChunk,
+			Keywords:     chunk,
 			Depth:        cfg.MaxDepth,
 			Concurrency:  cfg.Concurrency,
 			Language:     cfg.LangCode,
 			FunctionName: cfg.FunctionName,
 			ExtraReviews: cfg.ExtraReviews,
-		}
-		i.payloads = append(i.payloads, payload)
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
+			Country:      cfg.Country,
+		})
 	}
 
 	if len(i.payloads) == 0 {
@@ -162,3 +270,35 @@ func (i *invoker) setPayloads(cfg *runner.Config) error {
 
 	return nil
 }
+
+// splitChunks groups the keywords in chunks of at most size keywords and
+// maxBytes bytes. A keyword, i.e. an input line with its id, priority and
+// language, is never split: one longer than maxBytes gets a chunk of its
+// own. A size of 0 or less puts all the keywords in one chunk, within
+// maxBytes.
+func splitChunks(keywords []string, size, maxBytes int) [][]string {
+	var (
+		chunks [][]string
+		chunk  []string
+		nbytes int
+	)
+
+	for _, keyword := range keywords {
+		full := size > 0 && len(chunk) >= size
+		tooBig := len(chunk) > 0 && nbytes+len(keyword) > maxBytes
+
+		if full || tooBig {
+			chunks = append(chunks, chunk)
+			chunk, nbytes = nil, 0
+		}
+
+		chunk = append(chunk, keyword)
+		nbytes += len(keyword)
+	}
+
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
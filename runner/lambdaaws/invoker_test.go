@@ -0,0 +1,163 @@
+package lambdaaws_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner/lambdaaws"
+)
+
+func Test_SplitChunks(t *testing.T) {
+	long := strings.Repeat("x", 60)
+
+	tests := []struct {
+		name     string
+		keywords []string
+		size     int
+		maxBytes int
+		expected [][]string
+	}{
+		{
+			name:     "by count",
+			keywords: []string{"a", "b", "c", "d", "e"},
+			size:     2,
+			maxBytes: 100,
+			expected: [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+		},
+		{
+			name:     "by bytes",
+			keywords: []string{"pizza in athens", "cafe in rome #!# rome-1", "bar"},
+			size:     10,
+			maxBytes: 40,
+			expected: [][]string{{"pizza in athens", "cafe in rome #!# rome-1"}, {"bar"}},
+		},
+		{
+			name:     "keyword longer than a chunk",
+			keywords: []string{"a", long, "b"},
+			size:     10,
+			maxBytes: 50,
+			expected: [][]string{{"a"}, {long}, {"b"}},
+		},
+		{
+			name:     "no size",
+			keywords: []string{"a", "b", "c"},
+			size:     0,
+			maxBytes: 100,
+			expected: [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:     "no keywords",
+			size:     2,
+			maxBytes: 100,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, lambdaaws.SplitChunks(tc.keywords, tc.size, tc.maxBytes))
+		})
+	}
+}
+
+type fakeLambda struct {
+	mu       sync.Mutex
+	failures map[int]int // part -> failures left, -1 always fails
+	calls    map[int]int
+}
+
+func (f *fakeLambda) Invoke(_ context.Context, params *lambda.InvokeInput, _ ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	var input lambdaaws.LInput
+	if err := json.Unmarshal(params.Payload, &input); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls[input.Part]++
+
+	switch left := f.failures[input.Part]; {
+	case left < 0:
+		return nil, errors.New("throttled")
+	case left > 0:
+		f.failures[input.Part]--
+
+		return nil, errors.New("throttled")
+	}
+
+	return &lambda.InvokeOutput{StatusCode: 202}, nil
+}
+
+type fakeUploader struct {
+	bucket, key string
+	body        []byte
+}
+
+func (u *fakeUploader) Upload(_ context.Context, bucket, key string, body io.Reader) error {
+	u.bucket, u.key = bucket, key
+
+	var err error
+
+	u.body, err = io.ReadAll(body)
+
+	return err
+}
+
+func Test_InvokerRun(t *testing.T) {
+	payloads := []lambdaaws.LInput{
+		{JobID: "job", Part: 0, BucketName: "bucket", FunctionName: "fn", Keywords: []string{"a", "b"}},
+		{JobID: "job", Part: 1, BucketName: "bucket", FunctionName: "fn", Keywords: []string{"c"}},
+		{JobID: "job", Part: 2, BucketName: "bucket", FunctionName: "fn", Keywords: []string{"d"}},
+	}
+
+	client := &fakeLambda{
+		failures: map[int]int{1: 2, 2: -1},
+		calls:    map[int]int{},
+	}
+	uploader := &fakeUploader{}
+
+	err := lambdaaws.NewTestInvoker(client, uploader, payloads).Run(context.Background())
+	require.ErrorContains(t, err, "part 2: throttled")
+	require.NotContains(t, err.Error(), "part 1")
+
+	require.Equal(t, map[int]int{0: 1, 1: 3, 2: 4}, client.calls)
+
+	require.Equal(t, "bucket", uploader.bucket)
+	require.Equal(t, "job-manifest.json", uploader.key)
+
+	var manifest struct {
+		JobID        string `json:"job_id"`
+		FunctionName string `json:"function_name"`
+		Parts        []struct {
+			Part      int    `json:"part"`
+			ResultKey string `json:"result_key"`
+			Keywords  int    `json:"keywords"`
+			Invoked   bool   `json:"invoked"`
+			Error     string `json:"error"`
+		} `json:"parts"`
+	}
+
+	require.NoError(t, json.Unmarshal(uploader.body, &manifest))
+	require.Equal(t, "job", manifest.JobID)
+	require.Equal(t, "fn", manifest.FunctionName)
+	require.Len(t, manifest.Parts, 3)
+
+	require.Equal(t, "job-0.csv", manifest.Parts[0].ResultKey)
+	require.Equal(t, 2, manifest.Parts[0].Keywords)
+	require.True(t, manifest.Parts[0].Invoked)
+
+	require.True(t, manifest.Parts[1].Invoked)
+
+	require.Equal(t, "job-2.csv", manifest.Parts[2].ResultKey)
+	require.False(t, manifest.Parts[2].Invoked)
+	require.Equal(t, "throttled", manifest.Parts[2].Error)
+}
@@ -11,4 +11,5 @@ type lInput struct {
 	FunctionName     string   `json:"function_name"`
 	DisablePageReuse bool     `json:"disable_page_reuse"`
 	ExtraReviews     bool     `json:"extra_reviews"`
+	Country          string   `json:"country"`
 }
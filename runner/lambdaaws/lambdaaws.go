@@ -16,6 +16,7 @@ import (
 	"github.com/aws/aws-lambda-go/lambda"
 
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
 	"github.com/gosom/google-maps-scraper/runner"
 	"github.com/gosom/scrapemate"
 	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
@@ -26,6 +27,7 @@ var _ runner.Runner = (*lambdaAwsRunner)(nil)
 
 type lambdaAwsRunner struct {
 	uploader runner.S3Uploader
+	sqs      bool
 }
 
 func New(cfg *runner.Config) (runner.Runner, error) {
@@ -35,13 +37,18 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 
 	ans := lambdaAwsRunner{
 		uploader: cfg.S3Uploader,
+		sqs:      cfg.AwsLambdaSQS,
 	}
 
 	return &ans, nil
 }
 
 func (l *lambdaAwsRunner) Run(context.Context) error {
-	lambda.Start(l.handler)
+	if l.sqs {
+		lambda.Start(l.sqsHandler)
+	} else {
+		lambda.Start(l.handler)
+	}
 
 	return nil
 }
@@ -78,19 +85,17 @@ func (l *lambdaAwsRunner) handler(ctx context.Context, input lInput) error {
 
 	exitMonitor := exiter.New()
 
-	seedJobs, err = runner.CreateSeedJobs(
-		false, // TODO supoort fast mode
-		input.Language,
-		in,
-		input.Depth,
-		false,
-		"",
-		0,
-		10000, // TODO support radius
-		nil,
-		exitMonitor,
-		input.ExtraReviews,
-	)
+	seedJobs, err = runner.CreateSeedJobs(in, runner.SeedJobOptions{
+		// TODO supoort fast mode
+		Radius:       10000, // TODO support radius
+		LangCode:     input.Language,
+		MaxDepth:     input.Depth,
+		ExtraReviews: input.ExtraReviews,
+		Country:      input.Country,
+		InputType:    runner.InputTypeAuto,
+		ExitMonitor:  exitMonitor,
+		Settings:     &gmaps.Settings{Throttle: gmaps.NewThrottle(max(1, input.Concurrency), 0)},
+	})
 	if err != nil {
 		return err
 	}
@@ -112,7 +117,7 @@ func (l *lambdaAwsRunner) handler(ctx context.Context, input lInput) error {
 	out.Close()
 
 	if l.uploader != nil {
-		key := fmt.Sprintf("%s-%d.csv", input.JobID, input.Part)
+		key := resultKey(input.JobID, input.Part)
 
 		fd, err := os.Open(out.Name())
 		if err != nil {
@@ -0,0 +1,66 @@
+package lambdaaws
+
+import (
+	"fmt"
+	"time"
+)
+
+// resultKey is the S3 key of the results of a part of a job.
+func resultKey(jobID string, part int) string {
+	return fmt.Sprintf("%s-%d.csv", jobID, part)
+}
+
+// manifestKey is the S3 key of the manifest of a job.
+func manifestKey(jobID string) string {
+	return jobID + "-manifest.json"
+}
+
+// manifest lists the parts of a job invoked by the invoker, so a
+// coordinator can wait for their results and assemble them.
+type manifest struct {
+	JobID        string         `json:"job_id"`
+	FunctionName string         `json:"function_name"`
+	BucketName   string         `json:"bucket_name"`
+	CreatedAt    time.Time      `json:"created_at"`
+	Parts        []manifestPart `json:"parts"`
+}
+
+type manifestPart struct {
+	Part      int    `json:"part"`
+	ResultKey string `json:"result_key"`
+	Keywords  int    `json:"keywords"`
+	Invoked   bool   `json:"invoked"`
+	Error     string `json:"error,omitempty"`
+}
+
+// newManifest returns the manifest of the payloads. errs holds the error
+// of the invocation of every payload, nil when it was invoked.
+func newManifest(payloads []lInput, errs []error, createdAt time.Time) manifest {
+	m := manifest{
+		CreatedAt: createdAt.UTC(),
+		Parts:     make([]manifestPart, 0, len(payloads)),
+	}
+
+	if len(payloads) > 0 {
+		m.JobID = payloads[0].JobID
+		m.FunctionName = payloads[0].FunctionName
+		m.BucketName = payloads[0].BucketName
+	}
+
+	for i := range payloads {
+		part := manifestPart{
+			Part:      payloads[i].Part,
+			ResultKey: resultKey(payloads[i].JobID, payloads[i].Part),
+			Keywords:  len(payloads[i].Keywords),
+			Invoked:   errs[i] == nil,
+		}
+
+		if errs[i] != nil {
+			part.Error = errs[i].Error()
+		}
+
+		m.Parts = append(m.Parts, part)
+	}
+
+	return m
+}
@@ -0,0 +1,65 @@
+package lambdaaws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var errNoKeywords = errors.New("no keywords")
+
+// parseSQSMessage decodes the lInput in the body of msg. When the body has
+// no job_id the message ID is used, so each message gets its own results
+// file.
+func parseSQSMessage(msg events.SQSMessage) (lInput, error) {
+	var input lInput
+
+	if err := json.Unmarshal([]byte(msg.Body), &input); err != nil {
+		return input, fmt.Errorf("message %s: %w", msg.MessageId, err)
+	}
+
+	if len(input.Keywords) == 0 {
+		return input, fmt.Errorf("message %s: %w", msg.MessageId, errNoKeywords)
+	}
+
+	if input.JobID == "" {
+		input.JobID = msg.MessageId
+	}
+
+	return input, nil
+}
+
+// sqsHandler processes the messages of an SQS batch one after the other.
+// Messages that fail are reported back as batch item failures, so only
+// those return to the queue. Messages that cannot be parsed are dropped,
+// since retrying them would fail again.
+//
+// The event source mapping must have ReportBatchItemFailures enabled.
+func (l *lambdaAwsRunner) sqsHandler(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+	var resp events.SQSEventResponse
+
+	for i := range event.Records {
+		msg := event.Records[i]
+
+		input, err := parseSQSMessage(msg)
+		if err != nil {
+			log.Printf("dropping invalid message: %v", err)
+
+			continue
+		}
+
+		if err := l.handler(ctx, input); err != nil {
+			log.Printf("message %s failed: %v", msg.MessageId, err)
+
+			resp.BatchItemFailures = append(resp.BatchItemFailures, events.SQSBatchItemFailure{
+				ItemIdentifier: msg.MessageId,
+			})
+		}
+	}
+
+	return resp, nil
+}
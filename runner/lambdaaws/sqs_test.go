@@ -0,0 +1,46 @@
+package lambdaaws_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner/lambdaaws"
+)
+
+func Test_ParseSQSMessage(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		input, err := lambdaaws.ParseSQSMessage(events.SQSMessage{
+			MessageId: "msg-1",
+			Body:      `{"job_id":"job","part":2,"bucket_name":"bucket","keywords":["a","b"],"depth":3,"language":"de","extra_reviews":true}`,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "job", input.JobID)
+		require.Equal(t, 2, input.Part)
+		require.Equal(t, "bucket", input.BucketName)
+		require.Equal(t, []string{"a", "b"}, input.Keywords)
+		require.Equal(t, 3, input.Depth)
+		require.Equal(t, "de", input.Language)
+		require.True(t, input.ExtraReviews)
+	})
+
+	t.Run("job id defaults to message id", func(t *testing.T) {
+		input, err := lambdaaws.ParseSQSMessage(events.SQSMessage{
+			MessageId: "msg-1",
+			Body:      `{"keywords":["a"]}`,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "msg-1", input.JobID)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := lambdaaws.ParseSQSMessage(events.SQSMessage{MessageId: "msg-1", Body: "{"})
+		require.Error(t, err)
+	})
+
+	t.Run("no keywords", func(t *testing.T) {
+		_, err := lambdaaws.ParseSQSMessage(events.SQSMessage{MessageId: "msg-1", Body: `{"job_id":"job"}`})
+		require.Error(t, err)
+	})
+}
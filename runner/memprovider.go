@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+)
+
+var _ scrapemate.JobProvider = (*memoryProvider)(nil)
+
+// NewMemoryProvider returns an in-memory job provider that hands out the
+// jobs of the same scrapemate priority in the order they were pushed, so
+// that the seed jobs start in the order of orderSeedJobs. scrapemate's
+// memory provider pushes every job from its own goroutine, which loses that
+// order. Jobs of a higher priority are still handed out first.
+func NewMemoryProvider() scrapemate.JobProvider {
+	return &memoryProvider{
+		pushed: make(chan struct{}),
+	}
+}
+
+type memoryProvider struct {
+	mu     sync.Mutex
+	queues [scrapemate.PriorityLow + 1][]scrapemate.IJob
+	// pushed is closed, and replaced, when a job is pushed, to wake up the
+	// Jobs goroutines waiting for one
+	pushed chan struct{}
+}
+
+// Push queues the job after the jobs of the same priority.
+func (p *memoryProvider) Push(_ context.Context, job scrapemate.IJob) error {
+	prio := job.GetPriority()
+	if prio < 0 || prio >= len(p.queues) {
+		prio = scrapemate.PriorityHigh
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.queues[prio] = append(p.queues[prio], job)
+
+	close(p.pushed)
+	p.pushed = make(chan struct{})
+
+	return nil
+}
+
+//nolint:gocritic // we need to return a read only channel
+func (p *memoryProvider) Jobs(ctx context.Context) (<-chan scrapemate.IJob, <-chan error) {
+	out := make(chan scrapemate.IJob)
+	errc := make(chan error, 1)
+
+	go func() {
+		for {
+			job, pushed := p.pop()
+			if job == nil {
+				select {
+				case <-ctx.Done():
+					errc <- ctx.Err()
+
+					return
+				case <-pushed:
+				}
+
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				errc <- ctx.Err()
+
+				return
+			case out <- job:
+			}
+		}
+	}()
+
+	return out, errc
+}
+
+// pop removes and returns the first job of the highest priority. When no
+// job is queued it returns nil and the channel closed by the next Push.
+func (p *memoryProvider) pop() (scrapemate.IJob, <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := range p.queues {
+		if len(p.queues[i]) == 0 {
+			continue
+		}
+
+		job := p.queues[i][0]
+		p.queues[i][0] = nil
+		p.queues[i] = p.queues[i][1:]
+
+		return job, nil
+	}
+
+	return nil, p.pushed
+}
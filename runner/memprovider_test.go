@@ -0,0 +1,87 @@
+package runner_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_MemoryProviderOrder(t *testing.T) {
+	input := "later #!# l #!# -1\nplain\nurgent #!# u #!# 10\nalso urgent #!##!# 10\nplain too\n"
+
+	seeds, err := createPriorityJobs(input, false)
+	require.NoError(t, err)
+
+	for range 20 {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		provider := runner.NewMemoryProvider()
+
+		for _, job := range seeds {
+			require.NoError(t, provider.Push(ctx, job))
+		}
+
+		// jobs of a higher priority are handed out before the seeds
+		email := gmaps.NewEmailJob("place", &gmaps.Entry{WebSite: "https://example.com"})
+		require.NoError(t, provider.Push(ctx, email))
+
+		jobs, _ := provider.Jobs(ctx)
+
+		got := make([]string, 0, len(seeds)+1)
+
+		for range len(seeds) + 1 {
+			select {
+			case job := <-jobs:
+				if gjob, ok := job.(*gmaps.GmapJob); ok {
+					got = append(got, gjob.Keyword)
+				} else {
+					got = append(got, job.GetID())
+				}
+			case <-time.After(time.Second):
+				t.Fatal("the provider did not hand out every job")
+			}
+		}
+
+		cancel()
+
+		require.Equal(t, []string{email.GetID(), "urgent", "also urgent", "plain", "plain too", "later"}, got)
+	}
+}
+
+func Test_MemoryProviderWaitsForPush(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	provider := runner.NewMemoryProvider()
+	jobs, errc := provider.Jobs(ctx)
+
+	job := &gmaps.GmapJob{Job: scrapemate.Job{ID: "late"}}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+
+		_ = provider.Push(ctx, job)
+	}()
+
+	select {
+	case got := <-jobs:
+		require.Equal(t, job, got)
+	case <-time.After(time.Second):
+		t.Fatal("the pushed job was not handed out")
+	}
+
+	cancel()
+
+	select {
+	case err := <-errc:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the provider did not stop with the context")
+	}
+}
@@ -0,0 +1,132 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"github.com/gosom/scrapemate"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// LoadExcludedNames reads one name per line from path.
+// Empty lines and lines starting with # are ignored.
+func LoadExcludedNames(path string) ([]string, error) {
+	return loadLines(path)
+}
+
+// LoadLocations reads one location per line from path, like
+// LoadExcludedNames. An empty path means no locations.
+func LoadLocations(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	return loadLines(path)
+}
+
+func loadLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var lines []string
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// ExcludeNamesWriter is a scrapemate.ResultWriter that drops places whose
+// title matches one of the excluded names and passes everything else to the
+// wrapped writer.
+//
+// Matching ignores case, accents and punctuation. A name matches when it
+// equals the title or appears in it as whole words, so "starbucks" excludes
+// both "Starbucks" and "Starbucks Coffee - Main St".
+type ExcludeNamesWriter struct {
+	next     scrapemate.ResultWriter
+	names    []string
+	excluded atomic.Int64
+}
+
+// NewExcludeNamesWriter wraps next with a filter that drops the given names.
+func NewExcludeNamesWriter(next scrapemate.ResultWriter, names []string) *ExcludeNamesWriter {
+	w := ExcludeNamesWriter{
+		next: next,
+	}
+
+	for _, name := range names {
+		if n := normalizeName(name); n != "" {
+			w.names = append(w.names, n)
+		}
+	}
+
+	return &w
+}
+
+// Excluded returns the number of places dropped so far.
+func (w *ExcludeNamesWriter) Excluded() int {
+	return int(w.excluded.Load())
+}
+
+func (w *ExcludeNamesWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	return runEntryFilter(ctx, in, w.next, func(e *gmaps.Entry) bool { return !w.Match(e.Title) }, &w.excluded)
+}
+
+// Match reports whether title matches one of the excluded names.
+func (w *ExcludeNamesWriter) Match(title string) bool {
+	t := " " + normalizeName(title) + " "
+
+	for _, name := range w.names {
+		if strings.Contains(t, " "+name+" ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeName lowercases s, strips accents and apostrophes and replaces
+// any other non alphanumeric characters with single spaces.
+func normalizeName(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	s, _, err := transform.String(t, s)
+	if err != nil {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(r)
+		case r == '\'' || r == '’':
+		default:
+			sb.WriteRune(' ')
+		}
+	}
+
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
@@ -0,0 +1,71 @@
+package runner_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+type collectWriter struct {
+	results []scrapemate.Result
+}
+
+func (w *collectWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		w.results = append(w.results, result)
+	}
+
+	return nil
+}
+
+func Test_ExcludeNamesWriterMatch(t *testing.T) {
+	w := runner.NewExcludeNamesWriter(&collectWriter{}, []string{"Starbucks", "McDonald's", "Café Nero", "  "})
+
+	tests := []struct {
+		title    string
+		expected bool
+	}{
+		{title: "Starbucks", expected: true},
+		{title: "STARBUCKS Coffee - Main St", expected: true},
+		{title: "McDonalds", expected: true},
+		{title: "McDonald’s Drive-Thru", expected: true},
+		{title: "Cafe Nero", expected: true},
+		{title: "CAFÉ NERO Piccadilly", expected: true},
+		{title: "Starbucksy Bakery", expected: false},
+		{title: "Nero's Pizza", expected: false},
+		{title: "", expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.title, func(t *testing.T) {
+			require.Equal(t, tc.expected, w.Match(tc.title))
+		})
+	}
+}
+
+func Test_ExcludeNamesWriterRun(t *testing.T) {
+	inner := &collectWriter{}
+	w := runner.NewExcludeNamesWriter(inner, []string{"starbucks"})
+
+	in := make(chan scrapemate.Result, 3)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "Starbucks"}}
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "Joe's Coffee"}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{{Title: "Starbucks Reserve"}, {Title: "Blue Bottle"}}}
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+
+	require.Len(t, inner.results, 2)
+	require.Equal(t, "Joe's Coffee", inner.results[0].Data.(*gmaps.Entry).Title)
+
+	entries := inner.results[1].Data.([]*gmaps.Entry)
+	require.Len(t, entries, 1)
+	require.Equal(t, "Blue Bottle", entries[0].Title)
+
+	require.Equal(t, 2, w.Excluded())
+}
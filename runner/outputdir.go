@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RunDirName returns the name of the -output-dir subfolder of a run that
+// started at start with the given input, e.g. 20250131-091500-1a2b3c4d.
+// Runs of the same input sort by time and share the hash suffix.
+func RunDirName(start time.Time, input []byte) string {
+	return start.UTC().Format("20060102-150405") + "-" + InputHash(input)
+}
+
+// InputHash returns a short hash of the input.
+func InputHash(input []byte) string {
+	sum := sha256.Sum256(input)
+
+	return hex.EncodeToString(sum[:4])
+}
+
+// CreateRunDir creates the subfolder of dir for a run, including any
+// missing parents, and returns its path.
+func CreateRunDir(dir string, start time.Time, input []byte) (string, error) {
+	runDir := filepath.Join(dir, RunDirName(start, input))
+
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		return "", err
+	}
+
+	return runDir, nil
+}
+
+// RunSummary describes a finished run. With -output-dir it is written to
+// summary.json in the run's folder.
+type RunSummary struct {
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Duration    string    `json:"duration"`
+	Input       string    `json:"input"`
+	InputHash   string    `json:"input_hash"`
+	Results     string    `json:"results,omitempty"`
+	SeedJobs    int       `json:"seed_jobs"`
+	Duplicates  int       `json:"duplicates"`
+	Excluded    int       `json:"excluded"`
+	Filtered    int       `json:"filtered"`
+	Requests    int       `json:"requests"`
+	TimeLimited []string  `json:"time_limited_keywords,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// WriteRunSummary writes s as indented JSON to path.
+func WriteRunSummary(path string, s *RunSummary) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
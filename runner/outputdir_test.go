@@ -0,0 +1,55 @@
+package runner_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_RunDirName(t *testing.T) {
+	start := time.Date(2025, time.January, 31, 9, 15, 0, 0, time.UTC)
+
+	name := runner.RunDirName(start, []byte("coffee in athens\n"))
+	require.Regexp(t, `^20250131-091500-[0-9a-f]{8}$`, name)
+
+	// same input, same hash
+	later := runner.RunDirName(start.Add(time.Hour), []byte("coffee in athens\n"))
+	require.Equal(t, name[len(name)-8:], later[len(later)-8:])
+	require.NotEqual(t, name, later)
+
+	other := runner.RunDirName(start, []byte("tea in athens\n"))
+	require.NotEqual(t, name, other)
+}
+
+func Test_CreateRunDirAndSummary(t *testing.T) {
+	start := time.Date(2025, time.January, 31, 9, 15, 0, 0, time.UTC)
+
+	runDir, err := runner.CreateRunDir(filepath.Join(t.TempDir(), "runs", "nested"), start, []byte("q"))
+	require.NoError(t, err)
+	require.DirExists(t, runDir)
+
+	summary := &runner.RunSummary{
+		StartedAt:  start,
+		FinishedAt: start.Add(time.Minute),
+		Duration:   "1m0s",
+		Input:      "queries.txt",
+		SeedJobs:   3,
+		Duplicates: 2,
+	}
+
+	path := filepath.Join(runDir, "summary.json")
+	require.NoError(t, runner.WriteRunSummary(path, summary))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var got runner.RunSummary
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, *summary, got)
+}
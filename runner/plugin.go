@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"debug/buildinfo"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/gosom/scrapemate"
+)
+
+// checkPluginFile checks that the plugin at path was built like the
+// scraper, see checkPluginBuild. Go refuses to load a plugin built with
+// another version of Go or of a shared package, with an error that does not
+// tell which plugin is the problem, or crashes on some mismatches.
+func checkPluginFile(path string) error {
+	plugin, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("plugin %s: not a Go plugin: %w", path, err)
+	}
+
+	host, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	return checkPluginBuild(path, plugin, host)
+}
+
+// checkPluginBuild returns an error listing the differences between the
+// build of the plugin at path and the one of the host: the Go version
+// and the versions of the modules both use.
+func checkPluginBuild(path string, plugin, host *debug.BuildInfo) error {
+	var errs []error
+
+	if plugin.GoVersion != host.GoVersion {
+		errs = append(errs, fmt.Errorf("built with %s, the scraper with %s", plugin.GoVersion, host.GoVersion))
+	}
+
+	hostModules := make(map[string]string, len(host.Deps)+1)
+	hostModules[host.Main.Path] = moduleVersion(&host.Main)
+
+	for _, dep := range host.Deps {
+		hostModules[dep.Path] = moduleVersion(dep)
+	}
+
+	modules := append([]*debug.Module{&plugin.Main}, plugin.Deps...)
+
+	for _, mod := range modules {
+		want, ok := hostModules[mod.Path]
+		if !ok || want == "(devel)" {
+			continue
+		}
+
+		if got := moduleVersion(mod); got != "(devel)" && got != want {
+			errs = append(errs, fmt.Errorf("built with %s %s, the scraper with %s", mod.Path, got, want))
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("plugin %s does not match the scraper, rebuild it with the same Go version and modules: %w", path, errors.Join(errs...))
+}
+
+// moduleVersion returns the version of mod, or of its replacement.
+func moduleVersion(mod *debug.Module) string {
+	if mod.Replace != nil {
+		return mod.Replace.Version
+	}
+
+	return mod.Version
+}
+
+// pluginWriter returns the writer of the symbol name looked up in the
+// plugin at path. The symbol must be a variable implementing
+// scrapemate.ResultWriter, e.g. `var MyWriter scrapemate.ResultWriter = ...`.
+func pluginWriter(path, name string, sym any) (scrapemate.ResultWriter, error) {
+	switch v := sym.(type) {
+	case *scrapemate.ResultWriter:
+		if v == nil || *v == nil {
+			return nil, fmt.Errorf("plugin %s: symbol %s is a nil scrapemate.ResultWriter", path, name)
+		}
+
+		return *v, nil
+	case scrapemate.ResultWriter:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("plugin %s: symbol %s is a %T, expected a variable implementing scrapemate.ResultWriter "+
+			"(Run(context.Context, <-chan scrapemate.Result) error)", path, name, sym)
+	}
+}
@@ -0,0 +1,89 @@
+package runner_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+type nopWriter struct{}
+
+func (nopWriter) Run(context.Context, <-chan scrapemate.Result) error {
+	return nil
+}
+
+func Test_PluginWriter(t *testing.T) {
+	var writer scrapemate.ResultWriter = nopWriter{}
+
+	got, err := runner.PluginWriter("w.so", "Writer", &writer)
+	require.NoError(t, err)
+	require.Equal(t, writer, got)
+
+	got, err = runner.PluginWriter("w.so", "Writer", &nopWriter{})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	var nilWriter scrapemate.ResultWriter
+
+	_, err = runner.PluginWriter("w.so", "Writer", &nilWriter)
+	require.ErrorContains(t, err, "plugin w.so: symbol Writer is a nil scrapemate.ResultWriter")
+}
+
+func Test_PluginWriterNotAWriter(t *testing.T) {
+	// the symbol of a dummy plugin declaring `var DummyPrinter = "dummy"`
+	dummy := "dummy"
+
+	_, err := runner.PluginWriter("/plugins/dummy.so", "DummyPrinter", &dummy)
+	require.EqualError(t, err, "plugin /plugins/dummy.so: symbol DummyPrinter is a *string, expected a variable "+
+		"implementing scrapemate.ResultWriter (Run(context.Context, <-chan scrapemate.Result) error)")
+}
+
+func Test_CheckPluginBuild(t *testing.T) {
+	host := &debug.BuildInfo{
+		GoVersion: "go1.23.4",
+		Main:      debug.Module{Path: "github.com/gosom/google-maps-scraper", Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "github.com/gosom/scrapemate", Version: "v0.9.5"},
+			{Path: "github.com/google/uuid", Version: "v1.6.0"},
+		},
+	}
+
+	plugin := &debug.BuildInfo{
+		GoVersion: "go1.23.4",
+		Main:      debug.Module{Path: "example.com/mywriter", Version: "(devel)"},
+		Deps: []*debug.Module{
+			{Path: "github.com/gosom/google-maps-scraper", Version: "v1.8.0"},
+			{Path: "github.com/gosom/scrapemate", Version: "v0.9.5"},
+			{Path: "golang.org/x/text", Version: "v0.21.0"},
+		},
+	}
+
+	require.NoError(t, runner.CheckPluginBuild("w.so", plugin, host))
+
+	plugin.GoVersion = "go1.22.1"
+	plugin.Deps[1] = &debug.Module{
+		Path:    "github.com/gosom/scrapemate",
+		Version: "v0.9.5",
+		Replace: &debug.Module{Path: "github.com/gosom/scrapemate", Version: "v0.9.4"},
+	}
+
+	err := runner.CheckPluginBuild("w.so", plugin, host)
+	require.ErrorContains(t, err, "plugin w.so does not match the scraper")
+	require.ErrorContains(t, err, "built with go1.22.1, the scraper with go1.23.4")
+	require.ErrorContains(t, err, "built with github.com/gosom/scrapemate v0.9.4, the scraper with v0.9.5")
+}
+
+func Test_LoadCustomWriterNotAPlugin(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.so"), []byte("not a plugin"), 0o600))
+
+	_, err := runner.LoadCustomWriter(dir, "Writer")
+	require.ErrorContains(t, err, "broken.so: not a Go plugin")
+}
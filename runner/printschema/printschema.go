@@ -0,0 +1,38 @@
+package printschema
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+type printer struct {
+	w io.Writer
+}
+
+func New(cfg *runner.Config) (runner.Runner, error) {
+	if cfg.RunMode != runner.RunModePrintSchema {
+		return nil, fmt.Errorf("%w: %d", runner.ErrInvalidRunMode, cfg.RunMode)
+	}
+
+	return &printer{w: os.Stdout}, nil
+}
+
+func (p *printer) Run(context.Context) error {
+	schema, err := gmaps.EntryJSONSchema()
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(p.w, string(schema))
+
+	return err
+}
+
+func (p *printer) Close(context.Context) error {
+	return nil
+}
@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+)
+
+// progressInterval is how often the progress line is redrawn.
+const progressInterval = 500 * time.Millisecond
+
+// ProgressEnabled reports whether the progress display of -progress can be
+// shown. It is turned off when stdout is not a terminal or carries the
+// results, so it never ends up mixed with the output.
+func ProgressEnabled(cfg *Config) bool {
+	return cfg.Progress && cfg.ResultsFile != "stdout" && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// ProgressDisplay draws a progress line on a terminal, driven by the
+// counters of an exiter.Exiter. It implements io.Writer so it can be set
+// as the log output: log lines are printed above the progress line
+// instead of breaking it.
+type ProgressDisplay struct {
+	out   io.Writer
+	width int
+
+	mu    sync.Mutex
+	start time.Time
+	last  string
+}
+
+// NewProgressDisplay returns a display that draws to out. A width of 0
+// means the width of the terminal.
+func NewProgressDisplay(out io.Writer, width int) *ProgressDisplay {
+	if width <= 0 {
+		var err error
+
+		width, _, err = term.GetSize(int(os.Stderr.Fd()))
+		if err != nil {
+			width = 80
+		}
+	}
+
+	return &ProgressDisplay{out: out, width: width, start: time.Now()}
+}
+
+// Run redraws the progress line until ctx is done, then prints a summary
+// of the run.
+func (p *ProgressDisplay) Run(ctx context.Context, exitMonitor exiter.Exiter) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.finish(exitMonitor.Progress())
+
+			return
+		case <-ticker.C:
+			p.draw(exitMonitor.Progress())
+		}
+	}
+}
+
+// Write prints a log line above the progress line.
+func (p *ProgressDisplay) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// clear the progress line, it is drawn again on the next tick
+	if p.last != "" {
+		_, _ = io.WriteString(p.out, "\r\033[K")
+		p.last = ""
+	}
+
+	return p.out.Write(b)
+}
+
+func (p *ProgressDisplay) draw(progress exiter.Progress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// one column is left free, a line as wide as the terminal wraps
+	line := renderProgress(progress, time.Since(p.start), p.width-1)
+	if line == p.last {
+		return
+	}
+
+	_, _ = io.WriteString(p.out, "\r\033[K"+line)
+	p.last = line
+}
+
+func (p *ProgressDisplay) finish(progress exiter.Progress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.last != "" {
+		_, _ = io.WriteString(p.out, "\r\033[K")
+		p.last = ""
+	}
+
+	elapsed := time.Since(p.start)
+
+	_, _ = io.WriteString(p.out, banner([]string{
+		fmt.Sprintf("keywords: %d/%d", progress.SeedCompleted, progress.SeedCount),
+		fmt.Sprintf("places: %d/%d", progress.PlacesCompleted, progress.PlacesFound),
+		"duration: " + elapsed.Round(time.Second).String(),
+	}, min(p.width, 60)))
+}
+
+// renderProgress returns the progress line: the keywords done with a bar,
+// the places scraped out of the places found, the rate of places per
+// second and the estimated time left. The ETA is estimated from the
+// keywords done while searches are running and from the places left once
+// they are all done. The line is cut to width.
+func renderProgress(progress exiter.Progress, elapsed time.Duration, width int) string {
+	const barWidth = 20
+
+	var fraction float64
+	if progress.SeedCount > 0 {
+		fraction = min(float64(progress.SeedCompleted)/float64(progress.SeedCount), 1)
+	}
+
+	filled := int(fraction * barWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(progress.PlacesCompleted) / elapsed.Seconds()
+	}
+
+	eta := "?"
+
+	switch {
+	case fraction > 0 && fraction < 1:
+		eta = time.Duration(float64(elapsed) * (1 - fraction) / fraction).Round(time.Second).String()
+	case fraction == 1 && rate > 0:
+		left := max(progress.PlacesFound-progress.PlacesCompleted, 0)
+		eta = time.Duration(float64(left) / rate * float64(time.Second)).Round(time.Second).String()
+	}
+
+	line := fmt.Sprintf("%s %d/%d keywords · %d/%d places · %.1f/s · ETA %s",
+		bar, progress.SeedCompleted, progress.SeedCount,
+		progress.PlacesCompleted, progress.PlacesFound, rate, eta,
+	)
+
+	return runewidth.Truncate(line, width, "…")
+}
@@ -0,0 +1,83 @@
+package runner_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_RenderProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress exiter.Progress
+		elapsed  time.Duration
+		expected string
+	}{
+		{
+			name:     "not started",
+			progress: exiter.Progress{SeedCount: 4},
+			elapsed:  0,
+			expected: "░░░░░░░░░░░░░░░░░░░░ 0/4 keywords · 0/0 places · 0.0/s · ETA ?",
+		},
+		{
+			name:     "searching",
+			progress: exiter.Progress{SeedCount: 4, SeedCompleted: 1, PlacesFound: 40, PlacesCompleted: 20},
+			elapsed:  10 * time.Second,
+			expected: "█████░░░░░░░░░░░░░░░ 1/4 keywords · 20/40 places · 2.0/s · ETA 30s",
+		},
+		{
+			name:     "places left",
+			progress: exiter.Progress{SeedCount: 4, SeedCompleted: 4, PlacesFound: 100, PlacesCompleted: 80},
+			elapsed:  40 * time.Second,
+			expected: "████████████████████ 4/4 keywords · 80/100 places · 2.0/s · ETA 10s",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, runner.RenderProgress(tc.progress, tc.elapsed, 120))
+		})
+	}
+
+	line := runner.RenderProgress(exiter.Progress{SeedCount: 4}, 0, 30)
+	require.Equal(t, 30, runewidth.StringWidth(line))
+	require.True(t, strings.HasSuffix(line, "…"))
+}
+
+func Test_ProgressDisplay(t *testing.T) {
+	var out bytes.Buffer
+
+	display := runner.NewProgressDisplay(&out, 80)
+
+	monitor := exiter.New()
+	monitor.SetSeedCount(2)
+	monitor.IncrSeedCompleted(2)
+	monitor.IncrPlacesFound(3)
+	monitor.IncrPlacesCompleted(3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	display.Run(ctx, monitor)
+
+	_, err := display.Write([]byte("a log line\n"))
+	require.NoError(t, err)
+
+	require.Contains(t, out.String(), "keywords: 2/2")
+	require.Contains(t, out.String(), "places: 3/3")
+	require.True(t, strings.HasSuffix(out.String(), "a log line\n"))
+}
+
+func Test_ProgressEnabled(t *testing.T) {
+	// go test does not run with a terminal on stdout
+	require.False(t, runner.ProgressEnabled(&runner.Config{Progress: true, ResultsFile: "results.csv"}))
+	require.False(t, runner.ProgressEnabled(&runner.Config{Progress: true, ResultsFile: "stdout"}))
+}
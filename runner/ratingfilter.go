@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// RatingFilterWriter is a scrapemate.ResultWriter that drops places with too
+// few reviews or a rating outside the configured range and passes everything
+// else to the wrapped writer.
+//
+// All bounds are inclusive. A zero MinRating or MaxRating disables that bound.
+type RatingFilterWriter struct {
+	next       scrapemate.ResultWriter
+	minReviews int
+	minRating  float64
+	maxRating  float64
+	filtered   atomic.Int64
+}
+
+// NewRatingFilterWriter wraps next with a filter on review count and rating.
+func NewRatingFilterWriter(next scrapemate.ResultWriter, minReviews int, minRating, maxRating float64) *RatingFilterWriter {
+	return &RatingFilterWriter{
+		next:       next,
+		minReviews: minReviews,
+		minRating:  minRating,
+		maxRating:  maxRating,
+	}
+}
+
+// Filtered returns the number of places dropped so far.
+func (w *RatingFilterWriter) Filtered() int {
+	return int(w.filtered.Load())
+}
+
+func (w *RatingFilterWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	return runEntryFilter(ctx, in, w.next, w.Keep, &w.filtered)
+}
+
+// Keep reports whether entry passes the review count and rating bounds.
+func (w *RatingFilterWriter) Keep(entry *gmaps.Entry) bool {
+	if entry.ReviewCount < w.minReviews {
+		return false
+	}
+
+	if w.minRating > 0 && entry.ReviewRating < w.minRating {
+		return false
+	}
+
+	if w.maxRating > 0 && entry.ReviewRating > w.maxRating {
+		return false
+	}
+
+	return true
+}
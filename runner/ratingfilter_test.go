@@ -0,0 +1,69 @@
+package runner_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_RatingFilterWriterKeep(t *testing.T) {
+	tests := []struct {
+		name       string
+		minReviews int
+		minRating  float64
+		maxRating  float64
+		entry      gmaps.Entry
+		keep       bool
+	}{
+		{name: "no bounds keeps unrated", entry: gmaps.Entry{}, keep: true},
+		{name: "min reviews equal", minReviews: 10, entry: gmaps.Entry{ReviewCount: 10}, keep: true},
+		{name: "min reviews below", minReviews: 10, entry: gmaps.Entry{ReviewCount: 9}, keep: false},
+		{name: "only with reviews", minReviews: 1, entry: gmaps.Entry{ReviewCount: 0}, keep: false},
+		{name: "min rating equal", minRating: 4.5, entry: gmaps.Entry{ReviewRating: 4.5}, keep: true},
+		{name: "min rating below", minRating: 4.5, entry: gmaps.Entry{ReviewRating: 4.4}, keep: false},
+		{name: "min rating drops unrated", minRating: 1, entry: gmaps.Entry{}, keep: false},
+		{name: "max rating equal", maxRating: 3, entry: gmaps.Entry{ReviewRating: 3}, keep: true},
+		{name: "max rating above", maxRating: 3, entry: gmaps.Entry{ReviewRating: 3.1}, keep: false},
+		{name: "max rating keeps unrated", maxRating: 3, entry: gmaps.Entry{}, keep: true},
+		{name: "max rating five", maxRating: 5, entry: gmaps.Entry{ReviewRating: 5}, keep: true},
+		{name: "range lower edge", minRating: 2, maxRating: 4, entry: gmaps.Entry{ReviewRating: 2}, keep: true},
+		{name: "range upper edge", minRating: 2, maxRating: 4, entry: gmaps.Entry{ReviewRating: 4}, keep: true},
+		{name: "all bounds", minReviews: 5, minRating: 4, maxRating: 5, entry: gmaps.Entry{ReviewCount: 4, ReviewRating: 4.8}, keep: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := runner.NewRatingFilterWriter(&collectWriter{}, tc.minReviews, tc.minRating, tc.maxRating)
+
+			require.Equal(t, tc.keep, w.Keep(&tc.entry))
+		})
+	}
+}
+
+func Test_RatingFilterWriterRun(t *testing.T) {
+	inner := &collectWriter{}
+	w := runner.NewRatingFilterWriter(inner, 10, 4, 0)
+
+	in := make(chan scrapemate.Result, 4)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "a", ReviewCount: 100, ReviewRating: 4.2}}
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "b", ReviewCount: 3, ReviewRating: 5}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{{Title: "c", ReviewCount: 50, ReviewRating: 3.9}, {Title: "d", ReviewCount: 10, ReviewRating: 4}}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{{Title: "e"}}}
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+
+	require.Len(t, inner.results, 2)
+	require.Equal(t, "a", inner.results[0].Data.(*gmaps.Entry).Title)
+
+	entries := inner.results[1].Data.([]*gmaps.Entry)
+	require.Len(t, entries, 1)
+	require.Equal(t, "d", entries[0].Title)
+
+	require.Equal(t, 3, w.Filtered())
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// RetryTimeout is the timeout of search jobs that are retried because
+// their first run found no places.
+const RetryTimeout = 3 * time.Minute
+
+var _ gmaps.SeedReporter = (*SeedTracker)(nil)
+
+// SeedTracker records how many places each search keyword found.
+type SeedTracker struct {
+	mu    sync.Mutex
+	found map[string]int
+}
+
+func NewSeedTracker() *SeedTracker {
+	return &SeedTracker{
+		found: make(map[string]int),
+	}
+}
+
+func (t *SeedTracker) SeedDone(keyword string, places int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.found[keyword] += places
+}
+
+// EmptySeeds returns the search jobs among jobs whose keyword found no
+// places, either because there were none or because the search failed.
+func (t *SeedTracker) EmptySeeds(jobs []scrapemate.IJob) []*gmaps.GmapJob {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var empty []*gmaps.GmapJob
+
+	for _, job := range jobs {
+		gj, ok := job.(*gmaps.GmapJob)
+		if !ok {
+			continue
+		}
+
+		if t.found[gj.Keyword] == 0 {
+			empty = append(empty, gj)
+		}
+	}
+
+	return empty
+}
+
+// FailedSeeds returns the search jobs among jobs whose keyword never
+// finished, because the search failed even after its retries.
+func (t *SeedTracker) FailedSeeds(jobs []scrapemate.IJob) []*gmaps.GmapJob {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var failed []*gmaps.GmapJob
+
+	for _, job := range jobs {
+		gj, ok := job.(*gmaps.GmapJob)
+		if !ok {
+			continue
+		}
+
+		if _, done := t.found[gj.Keyword]; !done {
+			failed = append(failed, gj)
+		}
+	}
+
+	return failed
+}
+
+// RetrySeedJobs returns copies of jobs with RetryTimeout set that report
+// to exitMonitor. The copies keep the job ID so results are attributed to
+// the same input.
+func RetrySeedJobs(jobs []*gmaps.GmapJob, exitMonitor exiter.Exiter) []scrapemate.IJob {
+	ans := make([]scrapemate.IJob, 0, len(jobs))
+
+	for _, job := range jobs {
+		retry := *job
+		retry.Timeout = RetryTimeout
+		retry.ExitMonitor = exitMonitor
+
+		ans = append(ans, &retry)
+	}
+
+	return ans
+}
@@ -0,0 +1,53 @@
+package runner_test
+
+import (
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_SeedTrackerRetry(t *testing.T) {
+	cafe := gmaps.NewGmapJob("id1", "en", "cafe", 10, false, "", 0)
+	dentist := gmaps.NewGmapJob("id2", "en", "dentist", 10, false, "", 0)
+	failed := gmaps.NewGmapJob("id3", "en", "bakery", 10, false, "", 0)
+	place := gmaps.NewPlaceJob("id4", "en", "https://www.google.com/maps/place/x", false, false)
+
+	jobs := []scrapemate.IJob{cafe, dentist, failed, place}
+
+	tracker := runner.NewSeedTracker()
+	tracker.SeedDone("cafe", 12)
+	tracker.SeedDone("dentist", 0)
+	// bakery never reports: its search failed
+
+	empty := tracker.EmptySeeds(jobs)
+	require.Equal(t, []*gmaps.GmapJob{dentist, failed}, empty)
+	require.Equal(t, []*gmaps.GmapJob{failed}, tracker.FailedSeeds(jobs))
+
+	exitMonitor := exiter.New()
+	retries := runner.RetrySeedJobs(empty, exitMonitor)
+	require.Len(t, retries, 2)
+
+	for i, job := range retries {
+		retry, ok := job.(*gmaps.GmapJob)
+		require.True(t, ok)
+
+		require.NotSame(t, empty[i], retry)
+		require.Equal(t, empty[i].ID, retry.ID)
+		require.Equal(t, empty[i].Keyword, retry.Keyword)
+		require.Equal(t, runner.RetryTimeout, retry.GetTimeout())
+		require.Equal(t, exitMonitor, retry.ExitMonitor)
+		require.Zero(t, empty[i].Timeout)
+	}
+
+	tracker.SeedDone("bakery", 3)
+
+	still := tracker.EmptySeeds(retries)
+	require.Len(t, still, 1)
+	require.Equal(t, "dentist", still[0].Keyword)
+	require.Empty(t, tracker.FailedSeeds(jobs))
+}
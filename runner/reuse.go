@@ -0,0 +1,20 @@
+package runner
+
+import (
+	"github.com/gosom/scrapemate/scrapemateapp"
+)
+
+// ReuseOptions returns the scrapemateapp options that set how many jobs a
+// browser page and a browser are used for, see -page-reuse-limit and
+// -browser-reuse-limit. With -disable-page-reuse no limit is set, so every
+// job opens a new page.
+func ReuseOptions(cfg *Config) []func(*scrapemateapp.Config) error {
+	if cfg.DisablePageReuse {
+		return nil
+	}
+
+	return []func(*scrapemateapp.Config) error{
+		scrapemateapp.WithPageReuseLimit(cfg.PageReuseLimit),
+		scrapemateapp.WithBrowserReuseLimit(cfg.BrowserReuseLimit),
+	}
+}
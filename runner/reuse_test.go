@@ -0,0 +1,51 @@
+package runner_test
+
+import (
+	"testing"
+
+	"github.com/gosom/scrapemate/scrapemateapp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_ReuseOptions(t *testing.T) {
+	apply := func(t *testing.T, cfg *runner.Config) scrapemateapp.Config {
+		t.Helper()
+
+		var matecfg scrapemateapp.Config
+
+		for _, opt := range runner.ReuseOptions(cfg) {
+			require.NoError(t, opt(&matecfg))
+		}
+
+		return matecfg
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := parseArgs("-c", "1", "-input", "queries.txt")
+		require.NoError(t, err)
+
+		matecfg := apply(t, cfg)
+		require.Equal(t, 2, matecfg.PageReuseLimit)
+		require.Equal(t, 200, matecfg.BrowserReuseLimit)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		cfg, err := parseArgs("-c", "1", "-input", "queries.txt", "-page-reuse-limit", "5", "-browser-reuse-limit", "50")
+		require.NoError(t, err)
+
+		matecfg := apply(t, cfg)
+		require.Equal(t, 5, matecfg.PageReuseLimit)
+		require.Equal(t, 50, matecfg.BrowserReuseLimit)
+	})
+
+	t.Run("disabled page reuse", func(t *testing.T) {
+		cfg, err := parseArgs("-c", "1", "-input", "queries.txt", "-disable-page-reuse", "-browser-reuse-limit", "50")
+		require.NoError(t, err)
+
+		matecfg := apply(t, cfg)
+		require.Zero(t, matecfg.PageReuseLimit)
+		require.Zero(t, matecfg.BrowserReuseLimit)
+	})
+}
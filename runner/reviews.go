@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// ReviewsFormatTable is the -reviews-format that stores the reviews in a
+// reviews table of the sqlite or postgres results, see
+// sqlite.WithReviewsTable and postgres.WithReviewsTable.
+const ReviewsFormatTable = "table"
+
+// ReviewsWriter is a scrapemate.ResultWriter that moves the reviews of
+// the places to a separate writer, one gmaps.ReviewRow per review, and
+// passes the places without them to the wrapped writer. The review count
+// and rating of the places are kept.
+type ReviewsWriter struct {
+	next    scrapemate.ResultWriter
+	reviews scrapemate.ResultWriter
+
+	written atomic.Int64
+}
+
+// NewReviewsWriter wraps next, writing the reviews to reviews.
+func NewReviewsWriter(next, reviews scrapemate.ResultWriter) *ReviewsWriter {
+	return &ReviewsWriter{next: next, reviews: reviews}
+}
+
+// Written returns the number of reviews passed to the reviews writer.
+func (w *ReviewsWriter) Written() int {
+	return int(w.written.Load())
+}
+
+// Run splits the results until in is closed. It returns once both writers
+// are done, so the reviews are flushed when the places are.
+func (w *ReviewsWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	reviewsIn := make(chan scrapemate.Result)
+	reviewsDone := make(chan error, 1)
+
+	go func() {
+		reviewsDone <- w.reviews.Run(ctx, reviewsIn)
+	}()
+
+	var (
+		reviewsErr    error
+		reviewsFailed bool
+	)
+
+	// send returns false when the reviews writer stopped or ctx is done.
+	send := func(row *gmaps.ReviewRow) bool {
+		if reviewsFailed {
+			return false
+		}
+
+		select {
+		case reviewsIn <- scrapemate.Result{Data: row}:
+			w.written.Add(1)
+
+			return true
+		case reviewsErr = <-reviewsDone:
+			reviewsFailed = true
+
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	out := make(chan scrapemate.Result)
+
+	go func() {
+		defer close(out)
+		defer close(reviewsIn)
+
+		for result := range in {
+			var entries []*gmaps.Entry
+
+			switch data := result.Data.(type) {
+			case *gmaps.Entry:
+				entries = []*gmaps.Entry{data}
+			case []*gmaps.Entry:
+				entries = data
+			}
+
+			for _, entry := range entries {
+				for _, row := range entry.ReviewRows() {
+					if !send(row) {
+						break
+					}
+				}
+
+				entry.UserReviews = []gmaps.Review{}
+				entry.UserReviewsExtended = []gmaps.Review{}
+			}
+
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := w.next.Run(ctx, out); err != nil {
+		return err
+	}
+
+	if reviewsFailed {
+		return reviewsErr
+	}
+
+	return <-reviewsDone
+}
@@ -0,0 +1,82 @@
+package runner_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Run(context.Context, <-chan scrapemate.Result) error {
+	return errors.New("disk full")
+}
+
+func Test_ReviewsWriter(t *testing.T) {
+	places, reviews := &collectWriter{}, &collectWriter{}
+
+	w := runner.NewReviewsWriter(places, reviews)
+
+	in := make(chan scrapemate.Result, 2)
+	in <- scrapemate.Result{Data: &gmaps.Entry{
+		PlaceID:     "1",
+		Title:       "a",
+		ReviewCount: 2,
+		UserReviews: []gmaps.Review{{Name: "short", Rating: 3}},
+		UserReviewsExtended: []gmaps.Review{
+			{Name: "ann", Rating: 5, Description: "great"},
+			{Name: "bob", Rating: 4},
+		},
+	}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{
+		{PlaceID: "2", Title: "b", UserReviews: []gmaps.Review{{Name: "cid", Rating: 1}}},
+		{PlaceID: "3", Title: "c"},
+	}}
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+	require.Equal(t, 3, w.Written())
+
+	require.Len(t, places.results, 2)
+
+	entry := places.results[0].Data.(*gmaps.Entry)
+	require.Empty(t, entry.UserReviews)
+	require.Empty(t, entry.UserReviewsExtended)
+	require.Equal(t, 2, entry.ReviewCount)
+
+	require.Len(t, reviews.results, 3)
+
+	row := reviews.results[0].Data.(*gmaps.ReviewRow)
+	require.Equal(t, "1", row.PlaceID)
+	require.Equal(t, "a", row.PlaceTitle)
+	require.Equal(t, "ann", row.Author)
+	require.Equal(t, "great", row.Text)
+
+	row = reviews.results[2].Data.(*gmaps.ReviewRow)
+	require.Equal(t, "2", row.PlaceID)
+	require.Equal(t, "cid", row.Author)
+}
+
+func Test_ReviewsWriterFailure(t *testing.T) {
+	places := &collectWriter{}
+
+	w := runner.NewReviewsWriter(places, failingWriter{})
+
+	in := make(chan scrapemate.Result, 2)
+	for _, id := range []string{"1", "2"} {
+		in <- scrapemate.Result{Data: &gmaps.Entry{
+			PlaceID:     id,
+			UserReviews: []gmaps.Review{{Name: "ann"}, {Name: "bob"}},
+		}}
+	}
+	close(in)
+
+	require.EqualError(t, w.Run(context.Background(), in), "disk full")
+	require.Len(t, places.results, 2)
+}
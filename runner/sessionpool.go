@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// sessionPool limits the sessions open at a time in the browser of
+// -cdp-endpoint, e.g. to the concurrent sessions of a Browserless
+// instance, and how long a page waits for one. Without it a saturated
+// endpoint queues the pages, and the run stalls until it frees a session.
+type sessionPool struct {
+	slots   chan struct{}
+	timeout time.Duration
+}
+
+// newSessionPool returns a pool of size sessions. A page waits at most
+// timeout for a session, forever with 0.
+func newSessionPool(size int, timeout time.Duration) *sessionPool {
+	return &sessionPool{
+		slots:   make(chan struct{}, size),
+		timeout: timeout,
+	}
+}
+
+// acquire waits for a free session of the pool and returns the function
+// releasing it. It fails when no session is released within the timeout
+// of the pool, so the job is retried or given up like after any other
+// page error.
+func (p *sessionPool) acquire(ctx context.Context) (func(), error) {
+	var expired <-chan time.Time
+
+	if p.timeout > 0 {
+		timer := time.NewTimer(p.timeout)
+		defer timer.Stop()
+
+		expired = timer.C
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+		return func() { <-p.slots }, nil
+	case <-expired:
+		return nil, p.timeoutError()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *sessionPool) timeoutError() error {
+	return fmt.Errorf("could not acquire a remote session within %s", p.timeout)
+}
@@ -0,0 +1,65 @@
+package runner_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_SessionPoolTimeout(t *testing.T) {
+	pool := runner.NewSessionPool(1, 50*time.Millisecond)
+
+	type acquired struct {
+		release func()
+		err     error
+	}
+
+	results := make(chan acquired, 2)
+
+	for range 2 {
+		go func() {
+			release, err := pool.Acquire(context.Background())
+			results <- acquired{release: release, err: err}
+		}()
+	}
+
+	// one acquisition gets the only session, which is not released, and
+	// the other one times out
+	first := <-results
+	second := <-results
+
+	if first.err != nil {
+		first, second = second, first
+	}
+
+	require.NoError(t, first.err)
+	require.EqualError(t, second.err, "could not acquire a remote session within 50ms")
+
+	// a released session is acquired again
+	first.release()
+
+	release, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+
+	release()
+}
+
+func Test_SessionPoolCanceled(t *testing.T) {
+	// without a timeout, an acquisition waits until its context is done
+	pool := runner.NewSessionPool(1, 0)
+
+	release, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = pool.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
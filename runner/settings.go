@@ -0,0 +1,28 @@
+package runner
+
+import "github.com/gosom/google-maps-scraper/gmaps"
+
+// JobSettings returns the settings of the jobs of a run with cfg, with a
+// throttle for cfg.Concurrency navigations.
+func JobSettings(cfg *Config) *gmaps.Settings {
+	settings := &gmaps.Settings{
+		WaitUntil:           cfg.WaitUntil,
+		BlockedResources:    BlockedResources(cfg),
+		PlaceDelayMin:       cfg.PlaceDelayMin,
+		PlaceDelayMax:       cfg.PlaceDelayMax,
+		StripTrackingParams: cfg.StripTrackingParams,
+		FeedSelectors:       cfg.FeedSelectors,
+		PlaceSelectors:      cfg.PlaceSelectors,
+		PlaceMarker:         cfg.PlaceMarker,
+		Warmup:              cfg.Warmup,
+		DrainTimeout:        cfg.DrainTimeout,
+		Artifacts:           &gmaps.ArtifactCounter{},
+		Throttle:            gmaps.NewThrottle(cfg.Concurrency, cfg.MinConcurrency),
+	}
+
+	if cfg.CacheEnabled {
+		settings.CacheTTL = cfg.CacheTTL
+	}
+
+	return settings
+}
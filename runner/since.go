@@ -0,0 +1,206 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// SinceLastRun is the only value accepted by -since.
+const SinceLastRun = "last-run"
+
+// Values of gmaps.Entry.Change for entries written with -since.
+const (
+	ChangeNew     = "new"
+	ChangeChanged = "changed"
+)
+
+type seenPlace struct {
+	ID           string  `json:"id"`
+	ReviewCount  int     `json:"review_count"`
+	ReviewRating float64 `json:"review_rating"`
+}
+
+// SinceWriter is a scrapemate.ResultWriter that only passes places that
+// were not emitted by a previous run to the wrapped writer.
+//
+// The places emitted so far are kept in a JSON lines file, one place per
+// line, which is read when the writer is created and rewritten when it
+// finishes. With detectChanges, places seen before whose rating or review
+// count changed are passed too, marked as changed.
+type SinceWriter struct {
+	next          scrapemate.ResultWriter
+	path          string
+	detectChanges bool
+
+	mu   sync.Mutex
+	seen map[string]seenPlace
+
+	added     atomic.Int64
+	changed   atomic.Int64
+	unchanged atomic.Int64
+}
+
+// NewSinceWriter wraps next with a filter on the places stored in path.
+// A missing file means that there was no previous run.
+func NewSinceWriter(next scrapemate.ResultWriter, path string, detectChanges bool) (*SinceWriter, error) {
+	w := SinceWriter{
+		next:          next,
+		path:          path,
+		detectChanges: detectChanges,
+		seen:          make(map[string]seenPlace),
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &w, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var p seenPlace
+		if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+			return nil, err
+		}
+
+		w.seen[p.ID] = p
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// Added returns the number of places not seen in a previous run.
+func (w *SinceWriter) Added() int {
+	return int(w.added.Load())
+}
+
+// Changed returns the number of places passed because they changed.
+func (w *SinceWriter) Changed() int {
+	return int(w.changed.Load())
+}
+
+// Unchanged returns the number of places dropped because they were
+// emitted by a previous run.
+func (w *SinceWriter) Unchanged() int {
+	return int(w.unchanged.Load())
+}
+
+// Run filters the results and saves the places seen when in is closed.
+func (w *SinceWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	err := runEntryFilter(ctx, in, w.next, w.Check, nil)
+
+	return errors.Join(err, w.save())
+}
+
+// Check reports whether entry must be written and sets its Change field.
+// Error rows are always written and not remembered, so the place is
+// tried again by the next run.
+func (w *SinceWriter) Check(entry *gmaps.Entry) bool {
+	if entry.Error != "" {
+		return true
+	}
+
+	p := seenPlace{
+		ID:           placeKey(entry),
+		ReviewCount:  entry.ReviewCount,
+		ReviewRating: entry.ReviewRating,
+	}
+
+	w.mu.Lock()
+	prev, ok := w.seen[p.ID]
+	w.seen[p.ID] = p
+	w.mu.Unlock()
+
+	switch {
+	case !ok:
+		w.added.Add(1)
+
+		entry.Change = ChangeNew
+
+		return true
+	case w.detectChanges && (prev.ReviewCount != p.ReviewCount || prev.ReviewRating != p.ReviewRating):
+		w.changed.Add(1)
+
+		entry.Change = ChangeChanged
+
+		return true
+	default:
+		w.unchanged.Add(1)
+
+		return false
+	}
+}
+
+// save rewrites the file with all the places seen so far. It writes to a
+// temporary file first, so an interrupted save keeps the previous state.
+func (w *SinceWriter) save() error {
+	tmp, err := os.CreateTemp(filepath.Dir(w.path), filepath.Base(w.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	bw := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(bw)
+
+	w.mu.Lock()
+
+	for _, p := range w.seen {
+		if err = enc.Encode(p); err != nil {
+			break
+		}
+	}
+
+	w.mu.Unlock()
+
+	if err == nil {
+		err = bw.Flush()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), w.path)
+}
+
+// placeKey returns a stable identifier of the place.
+func placeKey(entry *gmaps.Entry) string {
+	switch {
+	case entry.Cid != "":
+		return entry.Cid
+	case entry.DataID != "":
+		return entry.DataID
+	default:
+		return entry.Link
+	}
+}
@@ -0,0 +1,71 @@
+package runner_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func runSince(t *testing.T, path string, detectChanges bool, entries ...*gmaps.Entry) (*runner.SinceWriter, []*gmaps.Entry) {
+	t.Helper()
+
+	inner := &collectWriter{}
+
+	w, err := runner.NewSinceWriter(inner, path, detectChanges)
+	require.NoError(t, err)
+
+	in := make(chan scrapemate.Result, len(entries))
+	for _, e := range entries {
+		in <- scrapemate.Result{Data: e}
+	}
+
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+
+	written := make([]*gmaps.Entry, 0, len(inner.results))
+	for _, result := range inner.results {
+		written = append(written, result.Data.(*gmaps.Entry))
+	}
+
+	return w, written
+}
+
+func Test_SinceWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen.jsonl")
+
+	w, written := runSince(t, path, false,
+		&gmaps.Entry{Cid: "1", Title: "a", ReviewCount: 10, ReviewRating: 4.5},
+		&gmaps.Entry{DataID: "0x1:0x2", Title: "b"},
+	)
+	require.Len(t, written, 2)
+	require.Equal(t, runner.ChangeNew, written[0].Change)
+	require.Equal(t, 2, w.Added())
+
+	w, written = runSince(t, path, false,
+		&gmaps.Entry{Cid: "1", Title: "a", ReviewCount: 11, ReviewRating: 4.5},
+		&gmaps.Entry{DataID: "0x1:0x2", Title: "b"},
+		&gmaps.Entry{Link: "https://www.google.com/maps/place/c", Title: "c"},
+	)
+	require.Len(t, written, 1)
+	require.Equal(t, "c", written[0].Title)
+	require.Equal(t, 1, w.Added())
+	require.Equal(t, 2, w.Unchanged())
+
+	w, written = runSince(t, path, true,
+		&gmaps.Entry{Cid: "1", Title: "a", ReviewCount: 11, ReviewRating: 4.6},
+		&gmaps.Entry{DataID: "0x1:0x2", Title: "b"},
+		&gmaps.Entry{Link: "https://www.google.com/maps/place/c", Title: "c"},
+	)
+	require.Len(t, written, 1)
+	require.Equal(t, "a", written[0].Title)
+	require.Equal(t, runner.ChangeChanged, written[0].Change)
+	require.Equal(t, 1, w.Changed())
+	require.Equal(t, 2, w.Unchanged())
+}
@@ -0,0 +1,193 @@
+package runner
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gosom/scrapemate"
+	"github.com/gosom/scrapemate/adapters/writers/csvwriter"
+	"github.com/gosom/scrapemate/adapters/writers/jsonwriter"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/sqlite"
+	"github.com/gosom/google-maps-scraper/webhook"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatJSON    = "json"
+	FormatNDJSON  = "ndjson"
+	FormatWebhook = "webhook"
+	FormatSqlite  = "sqlite"
+)
+
+// Sink is an output format that results can be written to.
+type Sink struct {
+	// Stream is true for sinks that write to the results file (or stdout).
+	// They receive it, compressed if requested, as w.
+	// Other sinks manage their destination themselves and receive a nil w.
+	Stream bool
+	// Ext is the extension of the results file the sink writes to, e.g. ".csv".
+	// It is used to name the results file inside -output-dir and is empty
+	// for sinks that don't write to a file.
+	Ext string
+	New func(cfg *Config, w io.Writer) (scrapemate.ResultWriter, error)
+}
+
+var (
+	sinksMu sync.RWMutex
+	sinks   = map[string]Sink{}
+)
+
+// RegisterSink makes a sink available under name, so it can be selected
+// with -format. It panics if name is already registered.
+func RegisterSink(name string, s Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+
+	if _, ok := sinks[name]; ok {
+		panic("sink already registered: " + name)
+	}
+
+	sinks[name] = s
+}
+
+// GetSink returns the sink registered under name.
+func GetSink(name string) (Sink, error) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+
+	s, ok := sinks[name]
+	if !ok {
+		return Sink{}, fmt.Errorf("unknown format %q, available formats: %v", name, sinkNames())
+	}
+
+	return s, nil
+}
+
+// SinkNames returns the names of the registered sinks, sorted.
+func SinkNames() []string {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+
+	return sinkNames()
+}
+
+func sinkNames() []string {
+	names := make([]string, 0, len(sinks))
+	for name := range sinks {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func init() {
+	RegisterSink(FormatCSV, Sink{
+		Stream: true,
+		Ext:    ".csv",
+		New: func(_ *Config, w io.Writer) (scrapemate.ResultWriter, error) {
+			return csvwriter.NewCsvWriter(csv.NewWriter(w)), nil
+		},
+	})
+
+	RegisterSink(FormatJSON, Sink{
+		Stream: true,
+		Ext:    ".json",
+		New: func(_ *Config, w io.Writer) (scrapemate.ResultWriter, error) {
+			return jsonwriter.NewJSONWriter(w), nil
+		},
+	})
+
+	RegisterSink(FormatNDJSON, Sink{
+		Stream: true,
+		Ext:    ".ndjson",
+		New: func(_ *Config, w io.Writer) (scrapemate.ResultWriter, error) {
+			return NewNDJSONWriter(w), nil
+		},
+	})
+
+	RegisterSink(FormatWebhook, Sink{
+		New: func(cfg *Config, _ io.Writer) (scrapemate.ResultWriter, error) {
+			if cfg.WebhookURL == "" {
+				return nil, errors.New("webhook format requires -webhook-url")
+			}
+
+			headers := make(http.Header, len(cfg.WebhookHeaders))
+
+			for _, h := range cfg.WebhookHeaders {
+				key, value, err := webhook.ParseHeader(h)
+				if err != nil {
+					return nil, err
+				}
+
+				headers.Add(key, strings.ReplaceAll(value, webhook.RunIDPlaceholder, cfg.RunID))
+			}
+
+			return webhook.NewResultWriter(cfg.WebhookURL, webhook.WithHeaders(headers)), nil
+		},
+	})
+
+	RegisterSink(FormatSqlite, Sink{
+		Ext: ".db",
+		New: func(cfg *Config, _ io.Writer) (scrapemate.ResultWriter, error) {
+			if cfg.ResultsFile == "" || cfg.ResultsFile == "stdout" {
+				return nil, errors.New("sqlite format requires -results to be a file path")
+			}
+
+			var opts []sqlite.ResultWriterOption
+
+			if cfg.ReviewsFormat == ReviewsFormatTable {
+				opts = append(opts, sqlite.WithReviewsTable())
+			}
+
+			return sqlite.NewResultWriter(cfg.ResultsFile, opts...)
+		},
+	})
+}
+
+// NewNDJSONWriter returns a writer that encodes one entry per line.
+// Unlike the json format, search results with several entries (fast mode)
+// are split into separate lines.
+func NewNDJSONWriter(w io.Writer) scrapemate.ResultWriter {
+	return &ndjsonWriter{enc: json.NewEncoder(w)}
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Run(_ context.Context, in <-chan scrapemate.Result) error {
+	for result := range in {
+		var items []any
+
+		switch data := result.Data.(type) {
+		case []*gmaps.Entry:
+			for _, entry := range data {
+				items = append(items, entry)
+			}
+		case []any:
+			items = data
+		default:
+			items = append(items, data)
+		}
+
+		for _, item := range items {
+			if err := n.enc.Encode(item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package runner_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_BuiltinSinks(t *testing.T) {
+	names := runner.SinkNames()
+
+	for _, name := range []string{"csv", "json", "ndjson", "webhook", "sqlite"} {
+		require.Contains(t, names, name)
+	}
+
+	_, err := runner.GetSink("xml")
+	require.Error(t, err)
+}
+
+func Test_RegisterSink(t *testing.T) {
+	called := false
+
+	runner.RegisterSink("test-sink", runner.Sink{
+		New: func(_ *runner.Config, _ io.Writer) (scrapemate.ResultWriter, error) {
+			called = true
+
+			return &collectWriter{}, nil
+		},
+	})
+
+	sink, err := runner.GetSink("test-sink")
+	require.NoError(t, err)
+
+	_, err = sink.New(&runner.Config{}, nil)
+	require.NoError(t, err)
+	require.True(t, called)
+
+	require.Panics(t, func() {
+		runner.RegisterSink("test-sink", runner.Sink{})
+	})
+}
+
+func Test_SinkRequirements(t *testing.T) {
+	sink, err := runner.GetSink(runner.FormatWebhook)
+	require.NoError(t, err)
+	require.False(t, sink.Stream)
+
+	_, err = sink.New(&runner.Config{}, nil)
+	require.Error(t, err)
+
+	sink, err = runner.GetSink(runner.FormatSqlite)
+	require.NoError(t, err)
+
+	_, err = sink.New(&runner.Config{ResultsFile: "stdout"}, nil)
+	require.Error(t, err)
+}
+
+func Test_WebhookSinkHeaders(t *testing.T) {
+	var got http.Header
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sink, err := runner.GetSink(runner.FormatWebhook)
+	require.NoError(t, err)
+
+	writer, err := sink.New(&runner.Config{
+		WebhookURL:     srv.URL,
+		WebhookHeaders: []string{"Authorization: Bearer abc", "X-Run: run-{run_id}"},
+		RunID:          "3f2a9c",
+	}, nil)
+	require.NoError(t, err)
+
+	in := make(chan scrapemate.Result, 1)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "one"}}
+	close(in)
+
+	require.NoError(t, writer.Run(context.Background(), in))
+
+	require.Equal(t, "Bearer abc", got.Get("Authorization"))
+	require.Equal(t, "run-3f2a9c", got.Get("X-Run"))
+}
+
+func Test_NDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	in := make(chan scrapemate.Result, 2)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "one"}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{{Title: "two"}, {Title: "three"}}}
+	close(in)
+
+	require.NoError(t, runner.NewNDJSONWriter(&buf).Run(context.Background(), in))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Contains(t, lines[0], `"title":"one"`)
+	require.Contains(t, lines[1], `"title":"two"`)
+	require.Contains(t, lines[2], `"title":"three"`)
+}
@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// spillBuffer keeps records, e.g. encoded places, under keys until they are
+// taken. They are kept in memory up to maxMemory bytes; past it all the
+// records in memory are moved to a temporary file, which is read back
+// when their key is taken. A maxMemory of 0 keeps everything in memory.
+type spillBuffer struct {
+	maxMemory int64
+	memory    int64
+	keys      map[string]*spillKey
+
+	file    *os.File
+	size    int64
+	spilled int64
+}
+
+// spillKey holds the records of a key: the ones moved to the file, in
+// order, followed by the ones still in memory.
+type spillKey struct {
+	segments []spillSegment
+	records  [][]byte
+}
+
+// spillSegment is a part of the file with records separated by commas.
+type spillSegment struct {
+	offset int64
+	length int64
+}
+
+func newSpillBuffer(maxMemory int64) *spillBuffer {
+	return &spillBuffer{
+		maxMemory: maxMemory,
+		keys:      make(map[string]*spillKey),
+	}
+}
+
+// Add appends record to the records of key.
+func (b *spillBuffer) Add(key string, record []byte) error {
+	k, ok := b.keys[key]
+	if !ok {
+		k = &spillKey{}
+		b.keys[key] = k
+	}
+
+	k.records = append(k.records, record)
+	b.memory += int64(len(record))
+
+	if b.maxMemory > 0 && b.memory > b.maxMemory {
+		return b.spill()
+	}
+
+	return nil
+}
+
+// Spilled returns the number of bytes moved to the file so far.
+func (b *spillBuffer) Spilled() int64 {
+	return b.spilled
+}
+
+// spill moves the records in memory to the file.
+func (b *spillBuffer) spill() error {
+	if b.file == nil {
+		f, err := os.CreateTemp("", "google-maps-scraper-*.spill")
+		if err != nil {
+			return err
+		}
+
+		b.file = f
+	}
+
+	w := bufio.NewWriter(b.file)
+
+	for _, k := range b.keys {
+		if len(k.records) == 0 {
+			continue
+		}
+
+		seg := spillSegment{offset: b.size}
+
+		for i, record := range k.records {
+			if i > 0 {
+				if err := w.WriteByte(','); err != nil {
+					return err
+				}
+
+				seg.length++
+			}
+
+			if _, err := w.Write(record); err != nil {
+				return err
+			}
+
+			seg.length += int64(len(record))
+		}
+
+		b.size += seg.length
+		b.spilled += seg.length
+		k.segments = append(k.segments, seg)
+		k.records = nil
+	}
+
+	b.memory = 0
+
+	return w.Flush()
+}
+
+// WriteTo writes the records of key to w, separated by commas, and
+// forgets them.
+func (b *spillBuffer) WriteTo(key string, w io.Writer) error {
+	k, ok := b.keys[key]
+	if !ok {
+		return nil
+	}
+
+	delete(b.keys, key)
+
+	first := true
+
+	sep := func() error {
+		if first {
+			first = false
+
+			return nil
+		}
+
+		_, err := w.Write([]byte{','})
+
+		return err
+	}
+
+	for _, seg := range k.segments {
+		if err := sep(); err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(w, io.NewSectionReader(b.file, seg.offset, seg.length)); err != nil {
+			return err
+		}
+	}
+
+	for _, record := range k.records {
+		if err := sep(); err != nil {
+			return err
+		}
+
+		if _, err := w.Write(record); err != nil {
+			return err
+		}
+
+		b.memory -= int64(len(record))
+	}
+
+	return nil
+}
+
+// Close removes the file.
+func (b *spillBuffer) Close() error {
+	if b.file == nil {
+		return nil
+	}
+
+	name := b.file.Name()
+	err := b.file.Close()
+
+	b.file = nil
+
+	if rerr := os.Remove(name); err == nil {
+		err = rerr
+	}
+
+	return err
+}
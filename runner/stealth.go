@@ -0,0 +1,106 @@
+package runner
+
+import (
+	"slices"
+
+	"github.com/gosom/scrapemate/scrapemateapp"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// Values of -stealth. An empty value keeps the default of the mode:
+// StealthFirefox in fast mode and a plain headless browser otherwise.
+// StealthFirefox is only valid in fast mode.
+const (
+	StealthChromium = "chromium"
+	StealthFirefox  = "firefox"
+	StealthOff      = "off"
+)
+
+// BlockResourcesNone is the value of -block-resources that blocks nothing,
+// not even the images blocked by default.
+const BlockResourcesNone = "none"
+
+// desktopUserAgent replaces the user agent of the headless browser, which
+// says HeadlessChrome, with -stealth in normal mode.
+const desktopUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) " +
+	"Chrome/124.0.0.0 Safari/537.36"
+
+// stealthBrowsers maps the values of -stealth to the browsers of the
+// stealth fetcher of scrapemate.
+var stealthBrowsers = map[string]string{
+	StealthChromium: "chrome",
+	StealthFirefox:  "firefox",
+}
+
+// BrowserOptions returns the scrapemateapp options of the fetcher of a run
+// in fast mode or not, following -stealth and -debug.
+//
+// Fast mode fetches the pages over HTTP: -stealth picks the browser whose
+// TLS fingerprint and headers are impersonated, or a plain HTTP client with
+// off. Normal mode renders the pages in Chromium: -stealth chromium sets the
+// user agent of a desktop Chrome instead of the headless one, and firefox,
+// which parseConfig only allows there for the normal mode jobs of the web
+// UI, leaves the browser as is. The images of -block-resources are disabled
+// in the browser.
+func BrowserOptions(cfg *Config, fastMode bool) []func(*scrapemateapp.Config) error {
+	if fastMode {
+		browser := StealthBrowser(cfg)
+		if browser == "" {
+			return nil
+		}
+
+		return []func(*scrapemateapp.Config) error{
+			scrapemateapp.WithStealth(browser),
+		}
+	}
+
+	opts := []func(*scrapemateapp.Config) error{
+		scrapemateapp.WithJS(),
+	}
+
+	if slices.Contains(cfg.BlockResources, gmaps.ResourceImages) {
+		opts = append(opts, scrapemateapp.WithJS(scrapemateapp.DisableImages()))
+	}
+
+	if cfg.Debug {
+		opts = append(opts, scrapemateapp.WithJS(scrapemateapp.Headfull()))
+	}
+
+	if cfg.Stealth == StealthChromium {
+		opts = append(opts, scrapemateapp.WithJS(scrapemateapp.WithUA(desktopUserAgent)))
+	}
+
+	return opts
+}
+
+// StealthBrowser returns the browser impersonated by the fetcher of fast
+// mode following -stealth, as named by the stealth fetcher of scrapemate,
+// or "" for a plain HTTP client.
+func StealthBrowser(cfg *Config) string {
+	if cfg.Stealth == "" {
+		return stealthBrowsers[StealthFirefox]
+	}
+
+	return stealthBrowsers[cfg.Stealth]
+}
+
+// BlockedResources returns the resources of -block-resources to block with
+// gmaps.Settings.BlockedResources. The images are disabled in the browser
+// scrapemate launches, see BrowserOptions, which is cheaper than routing
+// every request, so they are only routed in the browser of -cdp-endpoint.
+func BlockedResources(cfg *Config) []string {
+	if cfg.CDPEndpoint != "" {
+		return cfg.BlockResources
+	}
+
+	var ans []string
+
+	for _, name := range cfg.BlockResources {
+		if name != gmaps.ResourceImages {
+			ans = append(ans, name)
+		}
+	}
+
+	return ans
+}
@@ -0,0 +1,105 @@
+package runner_test
+
+import (
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/gosom/scrapemate/scrapemateapp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_BrowserOptions(t *testing.T) {
+	apply := func(t *testing.T, cfg *runner.Config, fastMode bool) scrapemateapp.Config {
+		t.Helper()
+
+		// the options validate the whole config
+		matecfg := scrapemateapp.Config{
+			Concurrency: 1,
+			Writers:     []scrapemate.ResultWriter{nil},
+		}
+
+		for _, opt := range runner.BrowserOptions(cfg, fastMode) {
+			require.NoError(t, opt(&matecfg))
+		}
+
+		return matecfg
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		fastMode bool
+		js       bool
+		stealth  string
+		headfull bool
+		ua       bool
+		images   bool
+	}{
+		{name: "fast mode default", args: []string{"-fast-mode"}, fastMode: true, stealth: "firefox"},
+		{name: "fast mode firefox", args: []string{"-fast-mode", "-stealth", "firefox"}, fastMode: true, stealth: "firefox"},
+		{name: "fast mode chromium", args: []string{"-fast-mode", "-stealth", "chromium"}, fastMode: true, stealth: "chrome"},
+		{name: "fast mode off", args: []string{"-fast-mode", "-stealth", "off"}, fastMode: true},
+		{name: "normal mode default", js: true},
+		{name: "normal mode chromium", args: []string{"-stealth", "chromium"}, js: true, ua: true},
+		{name: "web fast mode firefox", args: []string{"-web", "-stealth", "firefox"}, fastMode: true, stealth: "firefox"},
+		{name: "web normal mode firefox", args: []string{"-web", "-stealth", "firefox"}, js: true},
+		{name: "web normal mode chromium", args: []string{"-web", "-stealth", "chromium"}, js: true, ua: true},
+		{name: "normal mode off", args: []string{"-stealth", "off"}, js: true},
+		{name: "normal mode debug", args: []string{"-debug"}, js: true, headfull: true},
+		{name: "normal mode images", args: []string{"-block-resources", "none"}, js: true, images: true},
+		{name: "normal mode fonts", args: []string{"-block-resources", "fonts"}, js: true, images: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := parseArgs(append([]string{"-c", "1", "-input", "queries.txt"}, tc.args...)...)
+			require.NoError(t, err)
+
+			matecfg := apply(t, cfg, tc.fastMode)
+			require.Equal(t, tc.js, matecfg.UseJS)
+			require.Equal(t, tc.stealth != "", matecfg.UseStealth)
+			require.Equal(t, tc.stealth, matecfg.StealthBrowser)
+			require.Equal(t, tc.headfull, matecfg.JSOpts.Headfull)
+			require.Equal(t, tc.js && !tc.images, matecfg.JSOpts.DisableImages)
+
+			if tc.ua {
+				require.NotContains(t, matecfg.JSOpts.UA, "Headless")
+				require.Contains(t, matecfg.JSOpts.UA, "Chrome/")
+			} else {
+				require.Empty(t, matecfg.JSOpts.UA)
+			}
+		})
+	}
+}
+
+func Test_ParseConfigStealthFirefox(t *testing.T) {
+	// normal mode has no Firefox to impersonate
+	_, err := parseArgs("-c", "1", "-input", "queries.txt", "-stealth", "firefox")
+	require.EqualError(t, err, "invalid configuration: Stealth firefox requires FastMode")
+
+	_, err = parseArgs("-c", "1", "-input", "queries.txt", "-stealth", "firefox", "-fast-mode")
+	require.NoError(t, err)
+}
+
+func Test_BlockedResources(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "default"},
+		{name: "none", args: []string{"-block-resources", "none"}},
+		{name: "images disabled in the browser", args: []string{"-block-resources", "images,fonts,analytics"}, want: []string{"fonts", "analytics"}},
+		{name: "images routed over cdp", args: []string{"-block-resources", "images,fonts", "-cdp-endpoint", "http://127.0.0.1:9222"}, want: []string{"images", "fonts"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := parseArgs(append([]string{"-c", "1", "-input", "queries.txt"}, tc.args...)...)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, runner.BlockedResources(cfg))
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/playwright-community/playwright-go"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// SetupStorageState sets the storage state of -storage-state in the
+// settings of the jobs, see gmaps.Settings.StorageState. With
+// -save-storage-state the state is first saved, after a visit to Google
+// Maps past the consent form, and then used for the run.
+func SetupStorageState(ctx context.Context, cfg *Config, settings *gmaps.Settings) error {
+	path := cfg.StorageState
+
+	if cfg.SaveStorageState != "" {
+		if err := saveStorageState(ctx, cfg, settings); err != nil {
+			return fmt.Errorf("saving the storage state: %w", err)
+		}
+
+		log.Printf("storage state saved to %s", cfg.SaveStorageState)
+
+		path = cfg.SaveStorageState
+	}
+
+	if path == "" {
+		settings.StorageState = nil
+
+		return nil
+	}
+
+	state, err := gmaps.LoadStorageState(path)
+	if err != nil {
+		return configError(fmt.Sprintf("storage-state %s", err))
+	}
+
+	settings.StorageState = state
+
+	return nil
+}
+
+// saveStorageState saves the storage state of -save-storage-state with a
+// browser of its own: the one of -cdp-endpoint or a new Chromium.
+func saveStorageState(ctx context.Context, cfg *Config, settings *gmaps.Settings) error {
+	opts := &playwright.RunOptions{
+		Browsers:            []string{"chromium"},
+		SkipInstallBrowsers: cfg.CDPEndpoint != "",
+	}
+
+	if err := playwright.Install(opts); err != nil {
+		return err
+	}
+
+	pw, err := playwright.Run(opts)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = pw.Stop()
+	}()
+
+	var browser playwright.Browser
+
+	if cfg.CDPEndpoint != "" {
+		wsURL, err := ResolveCDPEndpoint(ctx, cfg.CDPEndpoint)
+		if err != nil {
+			return err
+		}
+
+		browser, err = pw.Chromium.ConnectOverCDP(wsURL)
+		if err != nil {
+			return fmt.Errorf("%w: cdp endpoint %s: %w", ErrConnection, wsURL, err)
+		}
+	} else {
+		browser, err = pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+			Headless: playwright.Bool(!cfg.Debug),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	defer func() {
+		_ = browser.Close()
+	}()
+
+	bctx, err := browser.NewContext(playwright.BrowserNewContextOptions{
+		Viewport: &playwright.Size{Width: cdpViewportWidth, Height: cdpViewportHeight},
+	})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = bctx.Close()
+	}()
+
+	page, err := bctx.NewPage()
+	if err != nil {
+		return err
+	}
+
+	return settings.SaveStorageState(page, cfg.LangCode, cfg.SaveStorageState)
+}
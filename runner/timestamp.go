@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTimestampFormat is the default -timestamp-format, the same as the
+// -output-dir folders.
+const DefaultTimestampFormat = "20060102-150405"
+
+// TimestampedPath inserts t, in UTC and formatted with layout, in the file
+// name of path before its extension, e.g. results.csv becomes
+// results-20250131-091500.csv. A compression extension stays after the
+// extension it compresses: results.csv.gz becomes
+// results-20250131-091500.csv.gz.
+func TimestampedPath(path string, t time.Time, layout string) string {
+	var compressed string
+
+	for _, algo := range []string{CompressionGzip, CompressionZstd} {
+		if ext := CompressionExtension(algo); strings.HasSuffix(path, ext) {
+			path, compressed = strings.TrimSuffix(path, ext), ext
+
+			break
+		}
+	}
+
+	ext := filepath.Ext(path)
+	// a dot file such as .results has no extension
+	if ext == filepath.Base(path) {
+		ext = ""
+	}
+
+	return strings.TrimSuffix(path, ext) + "-" + t.UTC().Format(layout) + ext + compressed
+}
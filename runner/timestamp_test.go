@@ -0,0 +1,35 @@
+package runner_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_TimestampedPath(t *testing.T) {
+	start := time.Date(2025, time.January, 31, 11, 15, 0, 0, time.FixedZone("EET", 2*60*60))
+
+	tests := []struct {
+		path   string
+		layout string
+		want   string
+	}{
+		{path: "results.csv", layout: runner.DefaultTimestampFormat, want: "results-20250131-091500.csv"},
+		{path: "out/results.json", layout: runner.DefaultTimestampFormat, want: "out/results-20250131-091500.json"},
+		{path: "results.csv.gz", layout: runner.DefaultTimestampFormat, want: "results-20250131-091500.csv.gz"},
+		{path: "results.ndjson.zst", layout: "2006-01-02", want: "results-2025-01-31.ndjson.zst"},
+		{path: "results", layout: runner.DefaultTimestampFormat, want: "results-20250131-091500"},
+		{path: "data.v2/results", layout: "20060102", want: "data.v2/results-20250131"},
+		{path: ".results", layout: "20060102", want: ".results-20250131"},
+		{path: "results.db", layout: "20060102T150405Z", want: "results-20250131T091500Z.db"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			require.Equal(t, tc.want, runner.TimestampedPath(tc.path, start, tc.layout))
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// EntryTransformer changes the places before they are written, e.g. to
+// clean up or enrich them. Transform returns the place to write, which may
+// be the one it was given, or false to drop the place.
+type EntryTransformer interface {
+	Transform(*gmaps.Entry) (*gmaps.Entry, bool)
+}
+
+// EntryTransformerFunc is a function used as an EntryTransformer.
+type EntryTransformerFunc func(*gmaps.Entry) (*gmaps.Entry, bool)
+
+func (f EntryTransformerFunc) Transform(e *gmaps.Entry) (*gmaps.Entry, bool) {
+	return f(e)
+}
+
+// Names of the built-in transformers of -transform.
+const (
+	TransformLowercaseEmails = "lowercase-emails"
+	TransformTrimWhitespace  = "trim-whitespace"
+	TransformDropNoContact   = "drop-no-contact"
+)
+
+var (
+	transformersMu sync.RWMutex
+	transformers   = map[string]EntryTransformer{
+		TransformLowercaseEmails: EntryTransformerFunc(lowercaseEmails),
+		TransformTrimWhitespace:  EntryTransformerFunc(trimWhitespace),
+		TransformDropNoContact:   EntryTransformerFunc(dropNoContact),
+	}
+)
+
+// RegisterEntryTransformer makes t available to -transform under name.
+// Programs embedding the scraper call it before ParseConfig. It panics if
+// name is empty, contains a comma or is already registered.
+func RegisterEntryTransformer(name string, t EntryTransformer) {
+	transformersMu.Lock()
+	defer transformersMu.Unlock()
+
+	if name == "" || strings.Contains(name, ",") {
+		panic(fmt.Sprintf("runner: invalid transformer name %q", name))
+	}
+
+	if t == nil {
+		panic("runner: RegisterEntryTransformer transformer is nil")
+	}
+
+	if _, ok := transformers[name]; ok {
+		panic("runner: RegisterEntryTransformer called twice for " + name)
+	}
+
+	transformers[name] = t
+}
+
+// EntryTransformers returns the registered transformers with the given
+// names, in the same order.
+func EntryTransformers(names []string) ([]EntryTransformer, error) {
+	transformersMu.RLock()
+	defer transformersMu.RUnlock()
+
+	ans := make([]EntryTransformer, 0, len(names))
+
+	for _, name := range names {
+		t, ok := transformers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown transformer %q, available: %s", name, strings.Join(transformerNames(), ", "))
+		}
+
+		ans = append(ans, t)
+	}
+
+	return ans, nil
+}
+
+// transformerNames returns the sorted names of the registered transformers.
+// It must be called with transformersMu held.
+func transformerNames() []string {
+	names := make([]string, 0, len(transformers))
+
+	for name := range transformers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// TransformWriter is a scrapemate.ResultWriter that passes every place
+// through a chain of EntryTransformers before the wrapped writer. A place
+// dropped by a transformer is not given to the next ones.
+type TransformWriter struct {
+	next         scrapemate.ResultWriter
+	transformers []EntryTransformer
+	dropped      atomic.Int64
+}
+
+// NewTransformWriter wraps next, applying the transformers in order.
+func NewTransformWriter(next scrapemate.ResultWriter, transformers ...EntryTransformer) *TransformWriter {
+	return &TransformWriter{
+		next:         next,
+		transformers: transformers,
+	}
+}
+
+// Dropped returns the number of places dropped by the transformers so far.
+func (w *TransformWriter) Dropped() int {
+	return int(w.dropped.Load())
+}
+
+// Transform applies the transformers to e.
+func (w *TransformWriter) Transform(e *gmaps.Entry) (*gmaps.Entry, bool) {
+	for _, t := range w.transformers {
+		var ok bool
+
+		e, ok = t.Transform(e)
+		if !ok || e == nil {
+			return nil, false
+		}
+	}
+
+	return e, true
+}
+
+func (w *TransformWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	return runEntryTransform(ctx, in, w.next, w.Transform, &w.dropped)
+}
+
+// lowercaseEmails lowercases the emails of the place and removes the
+// duplicates this creates.
+func lowercaseEmails(e *gmaps.Entry) (*gmaps.Entry, bool) {
+	emails := make([]string, 0, len(e.Emails))
+
+	for _, email := range e.Emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email != "" && !slices.Contains(emails, email) {
+			emails = append(emails, email)
+		}
+	}
+
+	e.Emails = emails
+
+	return e, true
+}
+
+// trimWhitespace removes the leading and trailing whitespace of the text
+// fields of the place.
+func trimWhitespace(e *gmaps.Entry) (*gmaps.Entry, bool) {
+	for _, s := range []*string{
+		&e.Title, &e.Category, &e.Address, &e.WebSite, &e.Phone, &e.PlusCode,
+		&e.Description, &e.PriceRange, &e.Status,
+		&e.CompleteAddress.Borough, &e.CompleteAddress.Street, &e.CompleteAddress.City,
+		&e.CompleteAddress.PostalCode, &e.CompleteAddress.State, &e.CompleteAddress.Country,
+	} {
+		*s = strings.TrimSpace(*s)
+	}
+
+	for i := range e.Categories {
+		e.Categories[i] = strings.TrimSpace(e.Categories[i])
+	}
+
+	return e, true
+}
+
+// dropNoContact drops the places without a phone, a website or an email.
+func dropNoContact(e *gmaps.Entry) (*gmaps.Entry, bool) {
+	return e, strings.TrimSpace(e.Phone) != "" || strings.TrimSpace(e.WebSite) != "" || len(e.Emails) > 0
+}
@@ -0,0 +1,125 @@
+package runner_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func transformOne(t *testing.T, name string, e *gmaps.Entry) (*gmaps.Entry, bool) {
+	t.Helper()
+
+	transformers, err := runner.EntryTransformers([]string{name})
+	require.NoError(t, err)
+
+	return runner.NewTransformWriter(&collectWriter{}, transformers...).Transform(e)
+}
+
+func Test_TransformBuiltins(t *testing.T) {
+	t.Run("lowercase emails", func(t *testing.T) {
+		e, ok := transformOne(t, runner.TransformLowercaseEmails, &gmaps.Entry{
+			Emails: []string{"Info@Example.com", " info@example.com", "SALES@example.com", ""},
+		})
+		require.True(t, ok)
+		require.Equal(t, []string{"info@example.com", "sales@example.com"}, e.Emails)
+	})
+
+	t.Run("trim whitespace", func(t *testing.T) {
+		e, ok := transformOne(t, runner.TransformTrimWhitespace, &gmaps.Entry{
+			Title:           "  Kipriakon\n",
+			Categories:      []string{" Restaurant "},
+			Phone:           "\t25 101555 ",
+			CompleteAddress: gmaps.Address{City: " Limassol "},
+		})
+		require.True(t, ok)
+		require.Equal(t, "Kipriakon", e.Title)
+		require.Equal(t, []string{"Restaurant"}, e.Categories)
+		require.Equal(t, "25 101555", e.Phone)
+		require.Equal(t, "Limassol", e.CompleteAddress.City)
+	})
+
+	t.Run("drop no contact", func(t *testing.T) {
+		for _, e := range []*gmaps.Entry{
+			{Phone: "25 101555"},
+			{WebSite: "https://example.com"},
+			{Emails: []string{"info@example.com"}},
+		} {
+			_, ok := transformOne(t, runner.TransformDropNoContact, e)
+			require.True(t, ok)
+		}
+
+		_, ok := transformOne(t, runner.TransformDropNoContact, &gmaps.Entry{Title: "a", Phone: "  "})
+		require.False(t, ok)
+	})
+}
+
+func Test_TransformWriterRun(t *testing.T) {
+	rename := runner.EntryTransformerFunc(func(e *gmaps.Entry) (*gmaps.Entry, bool) {
+		return &gmaps.Entry{Title: "renamed " + e.Title, Phone: e.Phone}, true
+	})
+
+	calls := 0
+	count := runner.EntryTransformerFunc(func(e *gmaps.Entry) (*gmaps.Entry, bool) {
+		calls++
+
+		return e, true
+	})
+
+	dropNoContact, err := runner.EntryTransformers([]string{runner.TransformDropNoContact})
+	require.NoError(t, err)
+
+	inner := &collectWriter{}
+	w := runner.NewTransformWriter(inner, dropNoContact[0], rename, count)
+
+	in := make(chan scrapemate.Result, 4)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "a"}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{
+		{Title: "b", Phone: "1"},
+		{Title: "c"},
+	}}
+	in <- scrapemate.Result{Data: "not a place"}
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "d", Phone: "2"}}
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+
+	require.Len(t, inner.results, 3)
+
+	entries := inner.results[0].Data.([]*gmaps.Entry)
+	require.Len(t, entries, 1)
+	require.Equal(t, "renamed b", entries[0].Title)
+	require.Equal(t, "not a place", inner.results[1].Data)
+	require.Equal(t, "renamed d", inner.results[2].Data.(*gmaps.Entry).Title)
+
+	require.Equal(t, 2, w.Dropped())
+	// the dropped places never reach the following transformers
+	require.Equal(t, 2, calls)
+}
+
+func Test_RegisterEntryTransformer(t *testing.T) {
+	runner.RegisterEntryTransformer("test-uppercase-title", runner.EntryTransformerFunc(func(e *gmaps.Entry) (*gmaps.Entry, bool) {
+		e.Title = "UPPER"
+
+		return e, true
+	}))
+
+	require.Panics(t, func() {
+		runner.RegisterEntryTransformer("test-uppercase-title", runner.EntryTransformerFunc(nil))
+	})
+	require.Panics(t, func() {
+		runner.RegisterEntryTransformer(runner.TransformTrimWhitespace, runner.EntryTransformerFunc(nil))
+	})
+
+	cfg, err := parseArgs("-c", "1", "-input", "queries.txt", "-transform", "trim-whitespace, test-uppercase-title")
+	require.NoError(t, err)
+	require.Equal(t, []string{"trim-whitespace", "test-uppercase-title"}, cfg.Transforms)
+
+	_, err = parseArgs("-c", "1", "-input", "queries.txt", "-transform", "trim-whitespace,no-such-transform")
+	require.ErrorIs(t, err, runner.ErrConfig)
+	require.ErrorContains(t, err, "no-such-transform")
+}
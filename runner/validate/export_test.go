@@ -0,0 +1,11 @@
+package validate
+
+import (
+	"io"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func NewWithWriter(cfg *runner.Config, w io.Writer) runner.Runner {
+	return &validator{w: w, checks: checks(cfg)}
+}
@@ -0,0 +1,351 @@
+// Package validate implements -validate-only: it runs the setup steps of
+// the selected run mode and reports which of them fail, without scraping.
+package validate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // postgres driver
+	"github.com/playwright-community/playwright-go"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+// checkTimeout bounds the checks that connect to another host.
+const checkTimeout = 10 * time.Second
+
+type check struct {
+	name string
+	fn   func(context.Context) (string, error)
+}
+
+type validator struct {
+	w      io.Writer
+	checks []check
+}
+
+// New returns a runner that validates cfg for its run mode and prints a
+// line per check. Run fails with runner.ErrConnection if only checks that
+// connect to a browser, a database or a proxy fail, and with
+// runner.ErrConfig if any other check fails.
+func New(cfg *runner.Config) (runner.Runner, error) {
+	return &validator{w: os.Stdout, checks: checks(cfg)}, nil
+}
+
+// checks returns the checks that apply to the run mode of cfg, in the
+// order the runner does the same steps.
+func checks(cfg *runner.Config) []check {
+	ans := []check{{name: "config", fn: func(context.Context) (string, error) {
+		return runModeName(cfg.RunMode), nil
+	}}}
+
+	input := check{name: "input", fn: func(context.Context) (string, error) {
+		if len(cfg.Keywords) > 0 {
+			return fmt.Sprintf("%d keywords from -keywords", len(cfg.Keywords)), nil
+		}
+
+		return checkInput(cfg.InputFile)
+	}}
+
+	database := check{name: "database", fn: func(ctx context.Context) (string, error) {
+		return checkDatabase(ctx, cfg.Dsn)
+	}}
+
+	browser := check{name: "browser", fn: func(ctx context.Context) (string, error) {
+		if cfg.CDPEndpoint != "" {
+			return runner.ResolveCDPEndpoint(ctx, cfg.CDPEndpoint)
+		}
+
+		return checkBrowser(cfg)
+	}}
+
+	proxies := check{name: "proxies", fn: func(ctx context.Context) (string, error) {
+		return checkProxies(ctx, cfg.Proxies)
+	}}
+
+	switch cfg.RunMode {
+	case runner.RunModeFile:
+		ans = append(ans, input, check{name: "output", fn: func(context.Context) (string, error) {
+			return checkOutput(cfg)
+		}})
+	case runner.RunModeDatabase:
+		ans = append(ans, database)
+	case runner.RunModeDatabaseProduce:
+		return append(ans, input, database)
+	case runner.RunModeWeb:
+		ans = append(ans, check{name: "data folder", fn: func(context.Context) (string, error) {
+			return cfg.DataFolder, runner.CheckDirWritable(cfg.DataFolder)
+		}})
+	case runner.RunModeAwsLambda:
+	default:
+		return ans
+	}
+
+	ans = append(ans, browser)
+
+	if cfg.StorageState != "" {
+		ans = append(ans, check{name: "storage state", fn: func(context.Context) (string, error) {
+			return checkStorageState(cfg.StorageState)
+		}})
+	}
+
+	if len(cfg.Proxies) > 0 {
+		ans = append(ans, proxies)
+	}
+
+	return ans
+}
+
+func (v *validator) Run(ctx context.Context) error {
+	failed, unreachable := 0, 0
+
+	for _, c := range v.checks {
+		detail, err := c.fn(ctx)
+
+		if err != nil {
+			failed++
+
+			if runner.ExitCode(err) == runner.ExitConnection {
+				unreachable++
+			}
+
+			fmt.Fprintf(v.w, "FAIL  %-12s %v\n", c.name, err)
+
+			continue
+		}
+
+		fmt.Fprintf(v.w, "PASS  %-12s %s\n", c.name, detail)
+	}
+
+	if failed > 0 {
+		// the configuration is fine, a host is down or unreachable
+		sentinel := runner.ErrConfig
+		if unreachable == failed {
+			sentinel = runner.ErrConnection
+		}
+
+		return fmt.Errorf("%w: %d of %d checks failed", sentinel, failed, len(v.checks))
+	}
+
+	fmt.Fprintf(v.w, "all %d checks passed\n", len(v.checks))
+
+	return nil
+}
+
+func (v *validator) Close(context.Context) error {
+	return nil
+}
+
+func runModeName(mode int) string {
+	switch mode {
+	case runner.RunModeFile:
+		return "file mode"
+	case runner.RunModeDatabase:
+		return "database mode"
+	case runner.RunModeDatabaseProduce:
+		return "database produce mode"
+	case runner.RunModeWeb:
+		return "web mode"
+	case runner.RunModeAwsLambda:
+		return "aws lambda mode"
+	case runner.RunModeAwsLambdaInvoker:
+		return "aws lambda invoker mode"
+	default:
+		return fmt.Sprintf("run mode %d", mode)
+	}
+}
+
+func checkInput(input string) (string, error) {
+	if input == "stdin" {
+		return "standard input", nil
+	}
+
+	r, err := runner.OpenInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	if closer, ok := r.(io.Closer); ok {
+		_ = closer.Close()
+	}
+
+	return input, nil
+}
+
+// checkOutput checks the results file the same way the file runner names
+// it: inside the run folder with -output-dir and with the extension of
+// -compress.
+func checkOutput(cfg *runner.Config) (string, error) {
+	if cfg.OutputDir != "" {
+		return cfg.OutputDir, runner.CheckDirWritable(cfg.OutputDir)
+	}
+
+	if cfg.ResultsFile == "stdout" || cfg.CustomWriter != "" {
+		return "standard output", nil
+	}
+
+	fname := cfg.ResultsFile
+	if ext := runner.CompressionExtension(cfg.Compress); ext != "" && !strings.HasSuffix(fname, ext) {
+		fname += ext
+	}
+
+	return fname, runner.CheckWritable(fname)
+}
+
+func checkDatabase(ctx context.Context, dsn string) (string, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return "", err
+	}
+
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return "", fmt.Errorf("%w: %w", runner.ErrConnection, err)
+	}
+
+	return "connected", nil
+}
+
+// checkBrowser checks the browser of the run, see runner.BrowserOptions.
+// Fast mode fetches the pages over HTTP with the TLS fingerprint of the
+// browser of -stealth, so nothing is launched. Normal mode renders them in
+// Chromium, with -stealth chromium or firefox too: they only change its
+// user agent. Web mode runs jobs of both modes. With -cdp-endpoint the
+// endpoint is checked instead, see runner.ResolveCDPEndpoint.
+func checkBrowser(cfg *runner.Config) (string, error) {
+	var details []string
+
+	if cfg.FastMode || cfg.RunMode == runner.RunModeWeb {
+		if browser := runner.StealthBrowser(cfg); browser != "" {
+			details = append(details, "fast mode impersonates "+browser)
+		} else {
+			details = append(details, "fast mode uses a plain HTTP client")
+		}
+	}
+
+	if !cfg.FastMode || cfg.RunMode == runner.RunModeWeb {
+		version, err := launchChromium()
+		if err != nil {
+			return "", err
+		}
+
+		detail := "chromium " + version
+		if cfg.Stealth == runner.StealthChromium {
+			detail += " with a desktop user agent"
+		}
+
+		details = append(details, detail)
+	}
+
+	return strings.Join(details, ", "), nil
+}
+
+// launchChromium installs Chromium like scrapemate does when it starts,
+// then launches and closes it.
+func launchChromium() (string, error) {
+	opts := &playwright.RunOptions{
+		Browsers: []string{"chromium"},
+	}
+
+	if err := playwright.Install(opts); err != nil {
+		return "", fmt.Errorf("%w: %w", runner.ErrConnection, err)
+	}
+
+	pw, err := playwright.Run(opts)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", runner.ErrConnection, err)
+	}
+
+	defer func() {
+		_ = pw.Stop()
+	}()
+
+	br, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{Headless: playwright.Bool(true)})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", runner.ErrConnection, err)
+	}
+
+	version := br.Version()
+
+	if err := br.Close(); err != nil {
+		return "", err
+	}
+
+	return version, nil
+}
+
+// checkStorageState reads the storage state file of -storage-state.
+func checkStorageState(path string) (string, error) {
+	state, err := gmaps.LoadStorageState(path)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s, %d cookies", path, len(state.Cookies)), nil
+}
+
+// checkProxies connects to every proxy. The credentials of the proxies are
+// not printed.
+func checkProxies(ctx context.Context, proxies []string) (string, error) {
+	var (
+		dialer net.Dialer
+		failed []string
+	)
+
+	for _, p := range proxies {
+		u, err := url.Parse(p)
+		if err != nil {
+			failed = append(failed, err.Error())
+
+			continue
+		}
+
+		host := u.Host
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), defaultPort(u.Scheme))
+		}
+
+		dctx, cancel := context.WithTimeout(ctx, checkTimeout)
+		conn, err := dialer.DialContext(dctx, "tcp", host)
+
+		cancel()
+
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", u.Redacted(), err))
+
+			continue
+		}
+
+		_ = conn.Close()
+	}
+
+	if len(failed) > 0 {
+		return "", fmt.Errorf("%w: %d of %d proxies unreachable: %s", runner.ErrConnection, len(failed), len(proxies), strings.Join(failed, "; "))
+	}
+
+	return fmt.Sprintf("%d reachable", len(proxies)), nil
+}
+
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "https":
+		return "443"
+	case "socks5":
+		return "1080"
+	default:
+		return "80"
+	}
+}
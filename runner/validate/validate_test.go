@@ -0,0 +1,185 @@
+package validate_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+	"github.com/gosom/google-maps-scraper/runner/validate"
+)
+
+func fileConfig(t *testing.T) *runner.Config {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	input := filepath.Join(dir, "input.txt")
+	require.NoError(t, os.WriteFile(input, []byte("dentist\n"), 0o600))
+
+	return &runner.Config{
+		RunMode:     runner.RunModeFile,
+		InputFile:   input,
+		ResultsFile: filepath.Join(dir, "results.csv"),
+		// fast mode needs no browser
+		FastMode: true,
+	}
+}
+
+func Test_ValidateFileMode(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer ln.Close()
+
+	cfg := fileConfig(t)
+	cfg.Proxies = []string{"socks5://user:secret@" + ln.Addr().String()}
+
+	var out bytes.Buffer
+
+	err = validate.NewWithWriter(cfg, &out).Run(context.Background())
+	require.NoError(t, err, out.String())
+	require.Contains(t, out.String(), "PASS  output")
+	require.Contains(t, out.String(), "PASS  browser      fast mode impersonates firefox\n")
+	require.Contains(t, out.String(), "PASS  proxies")
+	require.Contains(t, out.String(), "all 5 checks passed")
+
+	// nothing is written
+	_, err = os.Stat(cfg.ResultsFile)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func Test_ValidateFailures(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	closedAddr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	cfg := fileConfig(t)
+	cfg.InputFile = filepath.Join(t.TempDir(), "missing.txt")
+	cfg.ResultsFile = filepath.Join(t.TempDir(), "missing", "results.csv")
+	cfg.Proxies = []string{"http://user:secret@" + closedAddr}
+
+	var out bytes.Buffer
+
+	err = validate.NewWithWriter(cfg, &out).Run(context.Background())
+	require.ErrorIs(t, err, runner.ErrConfig)
+	require.Equal(t, runner.ExitConfig, runner.ExitCode(err))
+	require.Contains(t, err.Error(), "3 of 5 checks failed")
+
+	require.Contains(t, out.String(), "PASS  config")
+	require.Contains(t, out.String(), "FAIL  input")
+	require.Contains(t, out.String(), "FAIL  output")
+	require.Contains(t, out.String(), "FAIL  proxies")
+	require.NotContains(t, out.String(), "secret")
+}
+
+func Test_ValidateUnreachableProxy(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	closedAddr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	cfg := fileConfig(t)
+	cfg.Proxies = []string{"http://" + closedAddr}
+
+	var out bytes.Buffer
+
+	// only a connection fails, the configuration is fine
+	err = validate.NewWithWriter(cfg, &out).Run(context.Background())
+	require.ErrorIs(t, err, runner.ErrConnection)
+	require.Equal(t, runner.ExitConnection, runner.ExitCode(err))
+	require.Contains(t, out.String(), "FAIL  proxies")
+}
+
+func Test_ValidateStealthBrowser(t *testing.T) {
+	tests := []struct {
+		stealth string
+		want    string
+	}{
+		{stealth: runner.StealthChromium, want: "fast mode impersonates chrome"},
+		{stealth: runner.StealthFirefox, want: "fast mode impersonates firefox"},
+		{stealth: runner.StealthOff, want: "fast mode uses a plain HTTP client"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.stealth, func(t *testing.T) {
+			cfg := fileConfig(t)
+			cfg.Stealth = tc.stealth
+
+			var out bytes.Buffer
+
+			err := validate.NewWithWriter(cfg, &out).Run(context.Background())
+			require.NoError(t, err, out.String())
+			require.Contains(t, out.String(), "PASS  browser      "+tc.want+"\n")
+		})
+	}
+}
+
+func Test_ValidateCDPEndpoint(t *testing.T) {
+	// a DevTools WebSocket that accepts the handshake
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		_ = buf.Flush()
+	}))
+
+	defer srv.Close()
+
+	// the browser of the endpoint is checked instead of launching one
+	cfg := fileConfig(t)
+	cfg.FastMode = false
+	cfg.CDPEndpoint = "ws://" + srv.Listener.Addr().String() + "/devtools/browser/fake"
+
+	var out bytes.Buffer
+
+	err := validate.NewWithWriter(cfg, &out).Run(context.Background())
+	require.NoError(t, err, out.String())
+	require.Contains(t, out.String(), "PASS  browser      "+cfg.CDPEndpoint)
+
+	srv.Close()
+
+	out.Reset()
+
+	err = validate.NewWithWriter(cfg, &out).Run(context.Background())
+	require.ErrorIs(t, err, runner.ErrConnection)
+	require.Equal(t, runner.ExitConnection, runner.ExitCode(err))
+	require.Contains(t, out.String(), "FAIL  browser")
+}
+
+func Test_ValidateStorageState(t *testing.T) {
+	cfg := fileConfig(t)
+	cfg.StorageState = filepath.Join(t.TempDir(), "state.json")
+
+	state := `{"cookies": [{"name": "SOCS", "value": "CAE", "domain": ".google.com", "path": "/"}], "origins": []}`
+	require.NoError(t, os.WriteFile(cfg.StorageState, []byte(state), 0o600))
+
+	var out bytes.Buffer
+
+	err := validate.NewWithWriter(cfg, &out).Run(context.Background())
+	require.NoError(t, err, out.String())
+	require.Contains(t, out.String(), "PASS  storage state "+cfg.StorageState+", 1 cookies")
+
+	require.NoError(t, os.WriteFile(cfg.StorageState, []byte("cookies"), 0o600))
+
+	out.Reset()
+
+	err = validate.NewWithWriter(cfg, &out).Run(context.Background())
+	require.ErrorIs(t, err, runner.ErrConfig)
+	require.Contains(t, out.String(), "FAIL  storage state")
+}
@@ -10,10 +10,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gosom/google-maps-scraper/deduper"
 	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/gmaps"
 	"github.com/gosom/google-maps-scraper/runner"
 	"github.com/gosom/google-maps-scraper/tlmt"
 	"github.com/gosom/google-maps-scraper/web"
@@ -28,6 +30,9 @@ type webrunner struct {
 	srv *web.Server
 	svc *web.Service
 	cfg *runner.Config
+
+	// settings are copied for every job, see jobSettings
+	settings *gmaps.Settings
 }
 
 func New(cfg *runner.Config) (runner.Runner, error) {
@@ -50,15 +55,26 @@ func New(cfg *runner.Config) (runner.Runner, error) {
 
 	svc := web.NewService(repo, cfg.DataFolder)
 
-	srv, err := web.New(svc, cfg.Addr)
-	if err != nil {
+	settings := runner.JobSettings(cfg)
+
+	if err := runner.SetupStorageState(context.Background(), cfg, settings); err != nil {
 		return nil, err
 	}
 
 	ans := webrunner{
-		srv: srv,
-		svc: svc,
-		cfg: cfg,
+		svc:      svc,
+		cfg:      cfg,
+		settings: settings,
+	}
+
+	opts := []web.ServerOption{web.WithStreamFunc(ans.streamJob)}
+	if cfg.AdminToken != "" {
+		opts = append(opts, web.WithAdminToken(cfg.AdminToken))
+	}
+
+	ans.srv, err = web.New(svc, cfg.Addr, opts...)
+	if err != nil {
+		return nil, err
 	}
 
 	return &ans, nil
@@ -82,7 +98,17 @@ func (w *webrunner) Close(context.Context) error {
 	return nil
 }
 
+// work starts the pending jobs, up to -web-max-concurrent-jobs at a time.
+// Every job runs with its own scrapemate app; a failing job does not stop
+// the others. The running jobs are canceled with ctx and work returns once
+// they are done.
 func (w *webrunner) work(ctx context.Context) error {
+	slots := make(chan struct{}, w.cfg.WebMaxConcurrentJobs)
+
+	var wg sync.WaitGroup
+
+	defer wg.Wait()
+
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
@@ -91,53 +117,91 @@ func (w *webrunner) work(ctx context.Context) error {
 		case <-ctx.Done():
 			return nil
 		case <-ticker.C:
-			jobs, err := w.svc.SelectPending(ctx)
+			if w.svc.Paused() {
+				continue
+			}
+
+			free := cap(slots) - len(slots)
+			if free == 0 {
+				continue
+			}
+
+			jobs, err := w.svc.SelectPending(ctx, free)
 			if err != nil {
 				return err
 			}
 
 			for i := range jobs {
-				select {
-				case <-ctx.Done():
+				if ctx.Err() != nil {
 					return nil
-				default:
-					t0 := time.Now().UTC()
-					if err := w.scrapeJob(ctx, &jobs[i]); err != nil {
-						params := map[string]any{
-							"job_count": len(jobs[i].Data.Keywords),
-							"duration":  time.Now().UTC().Sub(t0).String(),
-							"error":     err.Error(),
-						}
-
-						evt := tlmt.NewEvent("web_runner", params)
-
-						_ = runner.Telemetry().Send(ctx, evt)
-
-						log.Printf("error scraping job %s: %v", jobs[i].ID, err)
-					} else {
-						params := map[string]any{
-							"job_count": len(jobs[i].Data.Keywords),
-							"duration":  time.Now().UTC().Sub(t0).String(),
-						}
-
-						_ = runner.Telemetry().Send(ctx, tlmt.NewEvent("web_runner", params))
-
-						log.Printf("job %s scraped successfully", jobs[i].ID)
-					}
 				}
+
+				job := jobs[i]
+
+				// the job leaves the pending ones before the next tick
+				job.Status = web.StatusWorking
+
+				if err := w.svc.Update(ctx, &job); err != nil {
+					return err
+				}
+
+				slots <- struct{}{}
+
+				wg.Add(1)
+
+				go func() {
+					defer func() {
+						<-slots
+						wg.Done()
+					}()
+
+					w.runPendingJob(ctx, &job)
+				}()
 			}
 		}
 	}
 }
 
-func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
-	job.Status = web.StatusWorking
+// runPendingJob scrapes job and reports the outcome. A panic fails the job
+// instead of the runner.
+func (w *webrunner) runPendingJob(ctx context.Context, job *web.Job) {
+	t0 := time.Now().UTC()
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+
+				job.Status = web.StatusFailed
+
+				if err2 := w.svc.Update(ctx, job); err2 != nil {
+					log.Printf("failed to update job status: %v", err2)
+				}
+			}
+		}()
+
+		return w.scrapeJob(ctx, job)
+	}()
+
+	params := map[string]any{
+		"job_count": len(job.Data.Keywords),
+		"duration":  time.Now().UTC().Sub(t0).String(),
+	}
 
-	err := w.svc.Update(ctx, job)
 	if err != nil {
-		return err
+		params["error"] = err.Error()
+
+		log.Printf("error scraping job %s: %v", job.ID, err)
+	} else {
+		log.Printf("job %s scraped successfully", job.ID)
 	}
 
+	_ = runner.Telemetry().Send(ctx, tlmt.NewEvent("web_runner", params))
+}
+
+// scrapeJob scrapes job, which is already marked as working, to its CSV
+// file in the data folder.
+func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 	if len(job.Data.Keywords) == 0 {
 		job.Status = web.StatusFailed
 
@@ -146,16 +210,24 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 
 	outpath := filepath.Join(w.cfg.DataFolder, job.ID+".csv")
 
+	// the file is created before the scrape starts, so a data folder that
+	// is not writable fails the job instead of wasting it
 	outfile, err := os.Create(outpath)
 	if err != nil {
-		return err
+		job.Status = web.StatusFailed
+
+		if err2 := w.svc.Update(ctx, job); err2 != nil {
+			log.Printf("failed to update job status: %v", err2)
+		}
+
+		return fmt.Errorf("%s is not writable: %w", outpath, err)
 	}
 
 	defer func() {
 		_ = outfile.Close()
 	}()
 
-	mate, err := w.setupMate(ctx, outfile, job)
+	err = w.runJob(ctx, job, csvwriter.NewCsvWriter(csv.NewWriter(outfile)), true)
 	if err != nil {
 		job.Status = web.StatusFailed
 
@@ -167,6 +239,25 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 		return err
 	}
 
+	job.Status = web.StatusOK
+
+	return w.svc.Update(ctx, job)
+}
+
+// streamJob runs a job of the /scrape/stream endpoint. The job is not
+// stored; its places are written to out as soon as they are scraped.
+func (w *webrunner) streamJob(ctx context.Context, job *web.Job, out io.Writer) error {
+	return w.runJob(ctx, job, runner.NewNDJSONWriter(out), false)
+}
+
+// runJob scrapes the keywords of job and sends the results to writer.
+// With trackProgress the progress of the job is reported by the service.
+func (w *webrunner) runJob(ctx context.Context, job *web.Job, writer scrapemate.ResultWriter, trackProgress bool) error {
+	mate, err := w.setupMate(ctx, writer, job)
+	if err != nil {
+		return err
+	}
+
 	defer mate.Close()
 
 	var coords string
@@ -177,94 +268,104 @@ func (w *webrunner) scrapeJob(ctx context.Context, job *web.Job) error {
 	dedup := deduper.New()
 	exitMonitor := exiter.New()
 
-	seedJobs, err := runner.CreateSeedJobs(
-		job.Data.FastMode,
-		job.Data.Lang,
-		strings.NewReader(strings.Join(job.Data.Keywords, "\n")),
-		job.Data.Depth,
-		job.Data.Email,
-		coords,
-		job.Data.Zoom,
-		func() float64 {
-			if job.Data.Radius <= 0 {
-				return 10000 // 10 km
-			}
+	radius := float64(job.Data.Radius)
+	if radius <= 0 {
+		radius = 10000 // 10 km
+	}
 
-			return float64(job.Data.Radius)
-		}(),
-		dedup,
-		exitMonitor,
-		w.cfg.ExtraReviews,
-	)
+	seedJobs, err := runner.CreateSeedJobs(strings.NewReader(strings.Join(job.Data.Keywords, "\n")), runner.SeedJobOptions{
+		FastMode:          job.Data.FastMode,
+		GeoCoordinates:    coords,
+		Zoom:              job.Data.Zoom,
+		Radius:            radius,
+		FastModeDetails:   w.cfg.FastModeDetails,
+		LangCode:          job.Data.Lang,
+		MaxDepth:          job.Data.Depth,
+		Email:             job.Data.Email,
+		ExtraReviews:      w.cfg.ExtraReviews,
+		Country:           w.cfg.Country,
+		InputType:         runner.InputTypeAuto,
+		Dedup:             dedup,
+		ExitMonitor:       exitMonitor,
+		MaxEmptyScrolls:   w.cfg.MaxEmptyScrolls,
+		PlaceWaitSelector: w.cfg.PlaceWaitSelector,
+		WaitTimeout:       w.cfg.WaitTimeout,
+		ScreenshotsDir:    w.cfg.ScreenshotsDir,
+		ScreenshotsMax:    w.cfg.ScreenshotsMax,
+		RawJSONDir:        w.cfg.SaveRawJSON,
+		RawJSONMax:        w.cfg.RawJSONMax,
+		EnrichWebsite:     w.cfg.EnrichWebsite,
+		MaxInputJobs:      w.cfg.MaxInputJobs,
+		Shuffle:           w.cfg.ShuffleSeeds,
+		Settings:          w.jobSettings(),
+	})
 	if err != nil {
-		err2 := w.svc.Update(ctx, job)
-		if err2 != nil {
-			log.Printf("failed to update job status: %v", err2)
-		}
-
 		return err
 	}
 
-	if len(seedJobs) > 0 {
-		exitMonitor.SetSeedCount(len(seedJobs))
+	if len(seedJobs) == 0 {
+		return nil
+	}
 
-		allowedSeconds := max(60, len(seedJobs)*10*job.Data.Depth/50+120)
+	exitMonitor.SetSeedCount(len(seedJobs))
 
-		if job.Data.MaxTime > 0 {
-			if job.Data.MaxTime.Seconds() < 180 {
-				allowedSeconds = 180
-			} else {
-				allowedSeconds = int(job.Data.MaxTime.Seconds())
-			}
-		}
+	allowedSeconds := max(60, len(seedJobs)*10*job.Data.Depth/50+120)
 
-		log.Printf("running job %s with %d seed jobs and %d allowed seconds", job.ID, len(seedJobs), allowedSeconds)
+	if job.Data.MaxTime > 0 {
+		allowedSeconds = int(job.Data.RunTime().Seconds())
+	}
 
-		mateCtx, cancel := context.WithTimeout(ctx, time.Duration(allowedSeconds)*time.Second)
-		defer cancel()
+	log.Printf("running job %s with %d seed jobs and %d allowed seconds", job.ID, len(seedJobs), allowedSeconds)
 
-		exitMonitor.SetCancelFunc(cancel)
+	mateCtx, cancel := context.WithTimeout(ctx, time.Duration(allowedSeconds)*time.Second)
+	defer cancel()
 
-		go exitMonitor.Run(mateCtx)
+	exitMonitor.SetCancelFunc(cancel)
 
-		err = mate.Start(mateCtx, seedJobs...)
-		if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
-			cancel()
+	go exitMonitor.Run(mateCtx)
 
-			err2 := w.svc.Update(ctx, job)
-			if err2 != nil {
-				log.Printf("failed to update job status: %v", err2)
-			}
+	if trackProgress {
+		w.svc.StartProgress(job.ID, exitMonitor)
+	}
 
-			return err
-		}
+	err = mate.Start(mateCtx, seedJobs...)
 
-		cancel()
+	if trackProgress {
+		job.Progress = w.svc.StopProgress(job.ID)
 	}
 
-	mate.Close()
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return err
+	}
 
-	job.Status = web.StatusOK
+	return nil
+}
 
-	return w.svc.Update(ctx, job)
+// jobSettings returns the settings of the jobs of a web job. Every web job
+// runs with its own scrapemate app, so it has its own throttle for the
+// -c navigations of the app, and its own limits of debug files.
+func (w *webrunner) jobSettings() *gmaps.Settings {
+	settings := *w.settings
+	settings.Throttle = gmaps.NewThrottle(w.cfg.Concurrency, w.cfg.MinConcurrency)
+	settings.Artifacts = &gmaps.ArtifactCounter{}
+
+	return &settings
 }
 
-func (w *webrunner) setupMate(_ context.Context, writer io.Writer, job *web.Job) (*scrapemateapp.ScrapemateApp, error) {
+func (w *webrunner) setupMate(_ context.Context, writer scrapemate.ResultWriter, job *web.Job) (runner.App, error) {
 	opts := []func(*scrapemateapp.Config) error{
 		scrapemateapp.WithConcurrency(w.cfg.Concurrency),
 		scrapemateapp.WithExitOnInactivity(time.Minute * 3),
 	}
 
-	if !job.Data.FastMode {
-		opts = append(opts,
-			scrapemateapp.WithJS(scrapemateapp.DisableImages()),
-		)
-	} else {
+	if job.Data.Email && w.cfg.EmailConcurrency > 0 {
 		opts = append(opts,
-			scrapemateapp.WithStealth("firefox"),
+			scrapemateapp.WithProvider(runner.LimitEmailJobs(runner.NewMemoryProvider(), w.cfg.EmailConcurrency)),
 		)
 	}
 
+	opts = append(opts, runner.BrowserOptions(w.cfg, job.Data.FastMode)...)
+
 	hasProxy := false
 
 	if len(w.cfg.Proxies) > 0 {
@@ -277,18 +378,11 @@ func (w *webrunner) setupMate(_ context.Context, writer io.Writer, job *web.Job)
 		hasProxy = true
 	}
 
-	if !w.cfg.DisablePageReuse {
-		opts = append(opts,
-			scrapemateapp.WithPageReuseLimit(2),
-			scrapemateapp.WithPageReuseLimit(200),
-		)
-	}
+	opts = append(opts, runner.ReuseOptions(w.cfg)...)
 
 	log.Printf("job %s has proxy: %v", job.ID, hasProxy)
 
-	csvWriter := csvwriter.NewCsvWriter(csv.NewWriter(writer))
-
-	writers := []scrapemate.ResultWriter{csvWriter}
+	writers := []scrapemate.ResultWriter{writer}
 
 	matecfg, err := scrapemateapp.NewConfig(
 		writers,
@@ -298,5 +392,5 @@ func (w *webrunner) setupMate(_ context.Context, writer io.Writer, job *web.Job)
 		return nil, err
 	}
 
-	return scrapemateapp.NewScrapeMateApp(matecfg)
+	return runner.NewApp(w.cfg, matecfg)
 }
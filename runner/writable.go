@@ -0,0 +1,55 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CheckWritable returns an error when a file cannot be written at path.
+// It creates and removes a temporary file next to path, and opens path for
+// writing without truncating it if the file already exists.
+func CheckWritable(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", path, err)
+	}
+
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+
+	f, err = os.OpenFile(path, os.O_WRONLY, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s is not writable: %w", path, err)
+	}
+
+	return f.Close()
+}
+
+// CheckDirWritable returns an error when files cannot be written in dir.
+// A missing dir is checked against its closest existing parent, in which
+// it would be created.
+func CheckDirWritable(dir string) error {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+
+			return CheckWritable(filepath.Join(dir, "check"))
+		}
+
+		parent := filepath.Dir(dir)
+		if !errors.Is(err, os.ErrNotExist) || parent == dir {
+			return fmt.Errorf("%s is not writable: %w", dir, err)
+		}
+
+		dir = parent
+	}
+}
@@ -0,0 +1,58 @@
+package runner_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/runner"
+)
+
+func Test_CheckWritable(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, runner.CheckWritable(filepath.Join(dir, "results.csv")))
+
+	existing := filepath.Join(dir, "existing.csv")
+	require.NoError(t, os.WriteFile(existing, []byte("data"), 0o600))
+	require.NoError(t, runner.CheckWritable(existing))
+
+	// the existing file is not truncated and no file is left behind
+	data, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	require.Equal(t, "data", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	err = runner.CheckWritable(filepath.Join(dir, "missing", "results.csv"))
+	require.ErrorContains(t, err, filepath.Join(dir, "missing", "results.csv"))
+
+	require.NoError(t, runner.CheckDirWritable(filepath.Join(dir, "a", "b")))
+	require.Error(t, runner.CheckDirWritable(filepath.Join(existing, "a")))
+}
+
+func Test_CheckWritable_ReadOnlyDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.Chmod(dir, 0o500))
+
+	t.Cleanup(func() {
+		_ = os.Chmod(dir, 0o700)
+	})
+
+	path := filepath.Join(dir, "results.csv")
+
+	err := runner.CheckWritable(path)
+	require.ErrorContains(t, err, path)
+	require.ErrorIs(t, err, os.ErrPermission)
+
+	err = runner.CheckDirWritable(dir)
+	require.ErrorIs(t, err, os.ErrPermission)
+}
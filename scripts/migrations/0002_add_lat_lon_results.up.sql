@@ -1,5 +0,0 @@
-BEGIN;
-    ALTER TABLE results 
-        ADD COLUMN latitude DOUBLE PRECISION NOT NULL DEFAULT 0,
-        ADD COLUMN longitude DOUBLE PRECISION NOT NULL DEFAULT 0;
-COMMIT;
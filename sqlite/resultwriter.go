@@ -0,0 +1,194 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gosom/scrapemate"
+	_ "modernc.org/sqlite" // sqlite driver
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+type ResultWriterOption func(*resultWriter)
+
+// WithReviewsTable stores the reviews of every entry in the reviews table,
+// one row per review linked to the row of the entry in results, instead
+// of inside the entry.
+func WithReviewsTable() ResultWriterOption {
+	return func(r *resultWriter) {
+		r.reviewsTable = true
+	}
+}
+
+// NewResultWriter opens (or creates) the sqlite database at path and returns
+// a writer that stores every entry as JSON in the results table.
+// The writer implements io.Closer and must be closed to release the database.
+func NewResultWriter(path string, opts ...ResultWriterOption) (scrapemate.ResultWriter, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+
+	w := resultWriter{db: db}
+
+	for _, opt := range opts {
+		opt(&w)
+	}
+
+	schema := []string{
+		`CREATE TABLE IF NOT EXISTS results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		data TEXT NOT NULL
+	)`,
+	}
+
+	if w.reviewsTable {
+		schema = append(schema,
+			`PRAGMA foreign_keys = ON`,
+			`CREATE TABLE IF NOT EXISTS reviews (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		result_id INTEGER NOT NULL REFERENCES results(id) ON DELETE CASCADE,
+		place_id TEXT NOT NULL,
+		data TEXT NOT NULL
+	)`,
+			`CREATE INDEX IF NOT EXISTS reviews_place_id_idx ON reviews(place_id)`,
+		)
+	}
+
+	for _, q := range schema {
+		if _, err := db.Exec(q); err != nil {
+			_ = db.Close()
+
+			return nil, err
+		}
+	}
+
+	return &w, nil
+}
+
+type resultWriter struct {
+	db           *sql.DB
+	reviewsTable bool
+}
+
+func (r *resultWriter) Close() error {
+	return r.db.Close()
+}
+
+func (r *resultWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	const maxBatchSize = 50
+
+	buff := make([]*gmaps.Entry, 0, maxBatchSize)
+	lastSave := time.Now().UTC()
+
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			buff = append(buff, data)
+		case []*gmaps.Entry:
+			buff = append(buff, data...)
+		default:
+			return errors.New("invalid data type")
+		}
+
+		if len(buff) >= maxBatchSize || time.Now().UTC().Sub(lastSave) >= time.Minute {
+			if err := r.batchSave(ctx, buff); err != nil {
+				return err
+			}
+
+			buff = buff[:0]
+			lastSave = time.Now().UTC()
+		}
+	}
+
+	return r.batchSave(ctx, buff)
+}
+
+func (r *resultWriter) batchSave(ctx context.Context, entries []*gmaps.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if r.reviewsTable {
+		return r.saveWithReviews(ctx, entries)
+	}
+
+	elements := make([]string, 0, len(entries))
+	args := make([]any, 0, len(entries))
+
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+
+		elements = append(elements, "(?)")
+		args = append(args, string(data))
+	}
+
+	q := `INSERT INTO results (data) VALUES ` + strings.Join(elements, ", ")
+
+	_, err := r.db.ExecContext(ctx, q, args...)
+
+	return err
+}
+
+// saveWithReviews inserts the entries one by one, since the reviews need
+// the id of their entry, in a single transaction.
+func (r *resultWriter) saveWithReviews(ctx context.Context, entries []*gmaps.Entry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, entry := range entries {
+		reviews := entry.ReviewRows()
+
+		place := *entry
+		place.UserReviews = []gmaps.Review{}
+		place.UserReviewsExtended = []gmaps.Review{}
+
+		data, err := json.Marshal(&place)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `INSERT INTO results (data) VALUES (?)`, string(data))
+		if err != nil {
+			return err
+		}
+
+		resultID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		for _, review := range reviews {
+			data, err := json.Marshal(review)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO reviews (result_id, place_id, data) VALUES (?, ?, ?)`,
+				resultID, review.PlaceID, string(data),
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
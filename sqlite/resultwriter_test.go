@@ -0,0 +1,98 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/sqlite"
+)
+
+func Test_ResultWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+
+	w, err := sqlite.NewResultWriter(path)
+	require.NoError(t, err)
+
+	in := make(chan scrapemate.Result, 2)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "one"}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{{Title: "two"}, {Title: "three"}}}
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+
+	closer, ok := w.(io.Closer)
+	require.True(t, ok)
+	require.NoError(t, closer.Close())
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	var count int
+
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM results`).Scan(&count))
+	require.Equal(t, 3, count)
+
+	var title string
+
+	require.NoError(t, db.QueryRow(`SELECT json_extract(data, '$.title') FROM results ORDER BY id LIMIT 1`).Scan(&title))
+	require.Equal(t, "one", title)
+}
+
+func Test_ResultWriterReviewsTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+
+	w, err := sqlite.NewResultWriter(path, sqlite.WithReviewsTable())
+	require.NoError(t, err)
+
+	in := make(chan scrapemate.Result, 2)
+	in <- scrapemate.Result{Data: &gmaps.Entry{
+		PlaceID:     "1",
+		Title:       "one",
+		UserReviews: []gmaps.Review{{Name: "ann", Rating: 5}, {Name: "bob", Rating: 2}},
+	}}
+	in <- scrapemate.Result{Data: &gmaps.Entry{PlaceID: "2", Title: "two"}}
+	close(in)
+
+	require.NoError(t, w.Run(context.Background(), in))
+	require.NoError(t, w.(io.Closer).Close())
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	var reviews string
+
+	require.NoError(t, db.QueryRow(`SELECT json_extract(data, '$.user_reviews') FROM results WHERE id = 1`).Scan(&reviews))
+	require.Equal(t, "[]", reviews)
+
+	rows, err := db.Query(`SELECT r.place_id, json_extract(r.data, '$.author')
+		FROM reviews r JOIN results p ON p.id = r.result_id
+		WHERE json_extract(p.data, '$.title') = 'one' ORDER BY r.id`)
+	require.NoError(t, err)
+
+	defer rows.Close()
+
+	var authors []string
+
+	for rows.Next() {
+		var placeID, author string
+
+		require.NoError(t, rows.Scan(&placeID, &author))
+		require.Equal(t, "1", placeID)
+
+		authors = append(authors, author)
+	}
+
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"ann", "bob"}, authors)
+}
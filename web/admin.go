@@ -0,0 +1,92 @@
+package web
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+type healthResponse struct {
+	Status string `json:"status"`
+	Paused bool   `json:"paused"`
+}
+
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		ans := apiError{
+			Code:    http.StatusMethodNotAllowed,
+			Message: "Method not allowed",
+		}
+
+		renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+		return
+	}
+
+	renderJSON(w, http.StatusOK, healthResponse{Status: "ok", Paused: s.svc.Paused()})
+}
+
+// admin wraps the handler of an /admin endpoint. It only accepts POST
+// requests carrying the admin token.
+func (s *Server) admin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			ans := apiError{
+				Code:    http.StatusMethodNotAllowed,
+				Message: "Method not allowed",
+			}
+
+			renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+			return
+		}
+
+		if s.adminToken == "" {
+			ans := apiError{
+				Code:    http.StatusForbidden,
+				Message: "admin endpoints are disabled, start the server with -admin-token",
+			}
+
+			renderJSON(w, http.StatusForbidden, ans)
+
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			ans := apiError{
+				Code:    http.StatusUnauthorized,
+				Message: http.StatusText(http.StatusUnauthorized),
+			}
+
+			renderJSON(w, http.StatusUnauthorized, ans)
+
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func (s *Server) pause(w http.ResponseWriter, _ *http.Request) {
+	s.setPaused(w, s.svc.Pause)
+}
+
+func (s *Server) resume(w http.ResponseWriter, _ *http.Request) {
+	s.setPaused(w, s.svc.Resume)
+}
+
+func (s *Server) setPaused(w http.ResponseWriter, fn func() error) {
+	if err := fn(); err != nil {
+		ans := apiError{
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		}
+
+		renderJSON(w, http.StatusInternalServerError, ans)
+
+		return
+	}
+
+	renderJSON(w, http.StatusOK, healthResponse{Status: "ok", Paused: s.svc.Paused()})
+}
@@ -0,0 +1,105 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_ServicePause(t *testing.T) {
+	dir := t.TempDir()
+
+	svc := web.NewService(&memRepo{jobs: map[string]web.Job{}}, dir)
+	require.False(t, svc.Paused())
+
+	require.NoError(t, svc.Pause())
+	require.True(t, svc.Paused())
+
+	// the paused state survives a restart
+	svc = web.NewService(&memRepo{jobs: map[string]web.Job{}}, dir)
+	require.True(t, svc.Paused())
+
+	require.NoError(t, svc.Resume())
+	require.False(t, svc.Paused())
+
+	svc = web.NewService(&memRepo{jobs: map[string]web.Job{}}, dir)
+	require.False(t, svc.Paused())
+
+	// resuming twice is fine
+	require.NoError(t, svc.Resume())
+}
+
+func Test_AdminEndpoints(t *testing.T) {
+	newHandler := func(t *testing.T, opts ...web.ServerOption) (http.Handler, *web.Service) {
+		t.Helper()
+
+		svc := web.NewService(&memRepo{jobs: map[string]web.Job{}}, t.TempDir())
+
+		srv, err := web.New(svc, ":0", opts...)
+		require.NoError(t, err)
+
+		return srv.Handler(), svc
+	}
+
+	do := func(h http.Handler, method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, http.NoBody)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	paused := func(t *testing.T, rec *httptest.ResponseRecorder) bool {
+		t.Helper()
+
+		var body struct {
+			Paused bool `json:"paused"`
+		}
+
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+
+		return body.Paused
+	}
+
+	t.Run("disabled without token", func(t *testing.T) {
+		h, svc := newHandler(t)
+
+		rec := do(h, http.MethodPost, "/admin/pause", "secret")
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.False(t, svc.Paused())
+	})
+
+	t.Run("pause and resume", func(t *testing.T) {
+		h, svc := newHandler(t, web.WithAdminToken("secret"))
+
+		require.Equal(t, http.StatusUnauthorized, do(h, http.MethodPost, "/admin/pause", "").Code)
+		require.Equal(t, http.StatusUnauthorized, do(h, http.MethodPost, "/admin/pause", "wrong").Code)
+		require.Equal(t, http.StatusMethodNotAllowed, do(h, http.MethodGet, "/admin/pause", "secret").Code)
+		require.False(t, svc.Paused())
+
+		rec := do(h, http.MethodPost, "/admin/pause", "secret")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, paused(t, rec))
+		require.True(t, svc.Paused())
+
+		rec = do(h, http.MethodGet, "/healthz", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.True(t, paused(t, rec))
+
+		rec = do(h, http.MethodPost, "/admin/resume", "secret")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.False(t, paused(t, rec))
+
+		rec = do(h, http.MethodGet, "/healthz", "")
+		require.False(t, paused(t, rec))
+	})
+}
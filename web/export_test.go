@@ -0,0 +1,14 @@
+package web
+
+import (
+	"net/http"
+	"time"
+)
+
+func (s *Server) Handler() http.Handler {
+	return s.srv.Handler
+}
+
+func StreamWriteTimeout(job *Job) time.Duration {
+	return streamWriteTimeout(job)
+}
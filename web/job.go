@@ -34,6 +34,8 @@ type Job struct {
 	Date   time.Time
 	Status string
 	Data   JobData
+	// Progress is set once the job has started.
+	Progress *JobProgress `json:",omitempty"`
 }
 
 func (j *Job) Validate() error {
@@ -60,6 +62,10 @@ func (j *Job) Validate() error {
 	return nil
 }
 
+// MinRunTime is the shortest time a job with a max time runs: a shorter
+// max time is raised to it, as the browser alone takes a while to start.
+const MinRunTime = 180 * time.Second
+
 type JobData struct {
 	Keywords []string      `json:"keywords"`
 	Lang     string        `json:"lang"`
@@ -74,6 +80,11 @@ type JobData struct {
 	Proxies  []string      `json:"proxies"`
 }
 
+// RunTime returns how long the job runs, its max time raised to MinRunTime.
+func (d *JobData) RunTime() time.Duration {
+	return max(d.MaxTime, MinRunTime)
+}
+
 func (d *JobData) Validate() error {
 	if len(d.Keywords) == 0 {
 		return errors.New("missing keywords")
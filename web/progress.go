@@ -0,0 +1,53 @@
+package web
+
+import (
+	"time"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+)
+
+// JobProgress tells how far a job is. Work is counted in seed jobs
+// (searches) and places, so the percentage can go down when a search
+// finds new places.
+type JobProgress struct {
+	SeedCount       int     `json:"seed_count"`
+	SeedCompleted   int     `json:"seed_completed"`
+	PlacesFound     int     `json:"places_found"`
+	PlacesCompleted int     `json:"places_completed"`
+	Percent         float64 `json:"percent"`
+	ElapsedSeconds  float64 `json:"elapsed_seconds"`
+	// RemainingSeconds is a rough estimate, extrapolated from the work done
+	// so far. It is omitted when nothing is done yet or the job has finished.
+	RemainingSeconds float64 `json:"remaining_seconds,omitempty"`
+}
+
+// NewJobProgress computes the progress of a job that has been running for
+// elapsed from the counters of its exit monitor.
+func NewJobProgress(p exiter.Progress, elapsed time.Duration, finished bool) *JobProgress {
+	ans := JobProgress{
+		SeedCount:       p.SeedCount,
+		SeedCompleted:   p.SeedCompleted,
+		PlacesFound:     p.PlacesFound,
+		PlacesCompleted: p.PlacesCompleted,
+		ElapsedSeconds:  elapsed.Round(time.Second).Seconds(),
+	}
+
+	total := p.SeedCount + p.PlacesFound
+	done := min(p.SeedCompleted+p.PlacesCompleted, total)
+
+	if total > 0 {
+		ans.Percent = float64(done*10000/total) / 100
+	}
+
+	if !finished && done > 0 && done < total {
+		remaining := time.Duration(float64(elapsed) * float64(total-done) / float64(done))
+		ans.RemainingSeconds = remaining.Round(time.Second).Seconds()
+	}
+
+	return &ans
+}
+
+type activeJob struct {
+	monitor exiter.Exiter
+	started time.Time
+}
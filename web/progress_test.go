@@ -0,0 +1,107 @@
+package web_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/exiter"
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_NewJobProgress(t *testing.T) {
+	p := exiter.Progress{SeedCount: 2, SeedCompleted: 2, PlacesFound: 8, PlacesCompleted: 3}
+
+	progress := web.NewJobProgress(p, time.Minute, false)
+	require.InDelta(t, 50, progress.Percent, 0.001)
+	require.InDelta(t, 60, progress.ElapsedSeconds, 0.001)
+	require.InDelta(t, 60, progress.RemainingSeconds, 0.001)
+
+	// finished jobs have no estimate
+	progress = web.NewJobProgress(p, time.Minute, true)
+	require.Zero(t, progress.RemainingSeconds)
+
+	// nothing done yet
+	progress = web.NewJobProgress(exiter.Progress{SeedCount: 3}, time.Second, false)
+	require.Zero(t, progress.Percent)
+	require.Zero(t, progress.RemainingSeconds)
+
+	progress = web.NewJobProgress(exiter.Progress{}, time.Second, false)
+	require.Zero(t, progress.Percent)
+}
+
+type memRepo struct {
+	jobs map[string]web.Job
+}
+
+func (r *memRepo) Get(_ context.Context, id string) (web.Job, error) {
+	return r.jobs[id], nil
+}
+
+func (r *memRepo) Create(_ context.Context, job *web.Job) error {
+	r.jobs[job.ID] = *job
+
+	return nil
+}
+
+func (r *memRepo) Delete(_ context.Context, id string) error {
+	delete(r.jobs, id)
+
+	return nil
+}
+
+func (r *memRepo) Select(context.Context, web.SelectParams) ([]web.Job, error) {
+	ans := make([]web.Job, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		ans = append(ans, job)
+	}
+
+	return ans, nil
+}
+
+func (r *memRepo) Update(_ context.Context, job *web.Job) error {
+	r.jobs[job.ID] = *job
+
+	return nil
+}
+
+func Test_ServiceProgress(t *testing.T) {
+	ctx := context.Background()
+	svc := web.NewService(&memRepo{jobs: map[string]web.Job{}}, t.TempDir())
+
+	job := web.Job{ID: "job-1", Status: web.StatusWorking}
+	require.NoError(t, svc.Create(ctx, &job))
+
+	got, err := svc.Get(ctx, job.ID)
+	require.NoError(t, err)
+	require.Nil(t, got.Progress)
+
+	monitor := exiter.New()
+	monitor.SetSeedCount(4)
+	monitor.IncrSeedCompleted(1)
+
+	svc.StartProgress(job.ID, monitor)
+
+	got, err = svc.Get(ctx, job.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.Progress)
+	require.Equal(t, 4, got.Progress.SeedCount)
+	require.Equal(t, 1, got.Progress.SeedCompleted)
+
+	jobs, err := svc.All(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, jobs[0].Progress)
+
+	monitor.IncrSeedCompleted(3)
+
+	job.Status = web.StatusOK
+	job.Progress = svc.StopProgress(job.ID)
+	require.NoError(t, svc.Update(ctx, &job))
+
+	got, err = svc.Get(ctx, job.ID)
+	require.NoError(t, err)
+	require.InDelta(t, 100, got.Progress.Percent, 0.001)
+	require.Nil(t, svc.StopProgress(job.ID))
+}
@@ -6,18 +6,74 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gosom/google-maps-scraper/exiter"
 )
 
 type Service struct {
 	repo       JobRepository
 	dataFolder string
+
+	mu     sync.RWMutex
+	active map[string]activeJob
+
+	paused atomic.Bool
 }
 
+// pausedFile is the file in the data folder whose presence means that
+// pending jobs must not be started. It keeps the service paused across
+// restarts.
+const pausedFile = "paused"
+
 func NewService(repo JobRepository, dataFolder string) *Service {
-	return &Service{
+	ans := Service{
 		repo:       repo,
 		dataFolder: dataFolder,
+		active:     make(map[string]activeJob),
+	}
+
+	if _, err := os.Stat(filepath.Join(dataFolder, pausedFile)); err == nil {
+		ans.paused.Store(true)
+	}
+
+	return &ans
+}
+
+// Paused reports whether starting pending jobs is paused.
+func (s *Service) Paused() bool {
+	return s.paused.Load()
+}
+
+// Pause stops pending jobs from being started until Resume is called.
+// The running jobs are not affected.
+func (s *Service) Pause() error {
+	f, err := os.Create(filepath.Join(s.dataFolder, pausedFile))
+	if err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	s.paused.Store(true)
+
+	return nil
+}
+
+// Resume lets pending jobs be started again.
+func (s *Service) Resume() error {
+	err := os.Remove(filepath.Join(s.dataFolder, pausedFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
 	}
+
+	s.paused.Store(false)
+
+	return nil
 }
 
 func (s *Service) Create(ctx context.Context, job *Job) error {
@@ -25,11 +81,70 @@ func (s *Service) Create(ctx context.Context, job *Job) error {
 }
 
 func (s *Service) All(ctx context.Context) ([]Job, error) {
-	return s.repo.Select(ctx, SelectParams{})
+	jobs, err := s.repo.Select(ctx, SelectParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range jobs {
+		if p := s.liveProgress(jobs[i].ID); p != nil {
+			jobs[i].Progress = p
+		}
+	}
+
+	return jobs, nil
 }
 
+// Get returns the job with its progress: live while the job runs,
+// the final counts once it has finished.
 func (s *Service) Get(ctx context.Context, id string) (Job, error) {
-	return s.repo.Get(ctx, id)
+	job, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return job, err
+	}
+
+	if p := s.liveProgress(id); p != nil {
+		job.Progress = p
+	}
+
+	return job, nil
+}
+
+// StartProgress makes the progress of the running job id available
+// through Get, based on the counters of its exit monitor.
+func (s *Service) StartProgress(id string, monitor exiter.Exiter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.active[id] = activeJob{monitor: monitor, started: time.Now()}
+}
+
+// StopProgress stops tracking the job id and returns its final progress,
+// or nil if it was not tracked.
+func (s *Service) StopProgress(id string) *JobProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.active[id]
+	if !ok {
+		return nil
+	}
+
+	delete(s.active, id)
+
+	return NewJobProgress(a.monitor.Progress(), time.Since(a.started), true)
+}
+
+func (s *Service) liveProgress(id string) *JobProgress {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a, ok := s.active[id]
+	if !ok {
+		return nil
+	}
+
+	return NewJobProgress(a.monitor.Progress(), time.Since(a.started), false)
 }
 
 func (s *Service) Delete(ctx context.Context, id string) error {
@@ -54,8 +169,9 @@ func (s *Service) Update(ctx context.Context, job *Job) error {
 	return s.repo.Update(ctx, job)
 }
 
-func (s *Service) SelectPending(ctx context.Context) ([]Job, error) {
-	return s.repo.Select(ctx, SelectParams{Status: StatusPending, Limit: 1})
+// SelectPending returns up to limit pending jobs.
+func (s *Service) SelectPending(ctx context.Context, limit int) ([]Job, error) {
+	return s.repo.Select(ctx, SelectParams{Status: StatusPending, Limit: limit})
 }
 
 func (s *Service) GetCSV(_ context.Context, id string) (string, error) {
@@ -25,7 +25,7 @@ func New(path string) (web.JobRepository, error) {
 }
 
 func (repo *repo) Get(ctx context.Context, id string) (web.Job, error) {
-	const q = `SELECT * from jobs WHERE id = ?`
+	const q = `SELECT ` + jobColumns + ` from jobs WHERE id = ?`
 
 	row := repo.db.QueryRowContext(ctx, q, id)
 
@@ -38,9 +38,9 @@ func (repo *repo) Create(ctx context.Context, job *web.Job) error {
 		return err
 	}
 
-	const q = `INSERT INTO jobs (id, name, status, data, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
+	const q = `INSERT INTO jobs (id, name, status, data, created_at, updated_at, progress) VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	_, err = repo.db.ExecContext(ctx, q, item.ID, item.Name, item.Status, item.Data, item.CreatedAt, item.UpdatedAt)
+	_, err = repo.db.ExecContext(ctx, q, item.ID, item.Name, item.Status, item.Data, item.CreatedAt, item.UpdatedAt, item.Progress)
 	if err != nil {
 		return err
 	}
@@ -57,7 +57,7 @@ func (repo *repo) Delete(ctx context.Context, id string) error {
 }
 
 func (repo *repo) Select(ctx context.Context, params web.SelectParams) ([]web.Job, error) {
-	q := `SELECT * from jobs`
+	q := `SELECT ` + jobColumns + ` from jobs`
 
 	var args []any
 
@@ -106,13 +106,15 @@ func (repo *repo) Update(ctx context.Context, job *web.Job) error {
 		return err
 	}
 
-	const q = `UPDATE jobs SET name = ?, status = ?, data = ?, updated_at = ? WHERE id = ?`
+	const q = `UPDATE jobs SET name = ?, status = ?, data = ?, updated_at = ?, progress = ? WHERE id = ?`
 
-	_, err = repo.db.ExecContext(ctx, q, item.Name, item.Status, item.Data, item.UpdatedAt, item.ID)
+	_, err = repo.db.ExecContext(ctx, q, item.Name, item.Status, item.Data, item.UpdatedAt, item.Progress, item.ID)
 
 	return err
 }
 
+const jobColumns = `id, name, status, data, created_at, updated_at, progress`
+
 type scannable interface {
 	Scan(dest ...any) error
 }
@@ -120,7 +122,7 @@ type scannable interface {
 func rowToJob(row scannable) (web.Job, error) {
 	var j job
 
-	err := row.Scan(&j.ID, &j.Name, &j.Status, &j.Data, &j.CreatedAt, &j.UpdatedAt)
+	err := row.Scan(&j.ID, &j.Name, &j.Status, &j.Data, &j.CreatedAt, &j.UpdatedAt, &j.Progress)
 	if err != nil {
 		return web.Job{}, err
 	}
@@ -137,6 +139,14 @@ func rowToJob(row scannable) (web.Job, error) {
 		return web.Job{}, err
 	}
 
+	if j.Progress != "" {
+		ans.Progress = &web.JobProgress{}
+
+		if err := json.Unmarshal([]byte(j.Progress), ans.Progress); err != nil {
+			return web.Job{}, err
+		}
+	}
+
 	return ans, nil
 }
 
@@ -146,6 +156,15 @@ func jobToRow(item *web.Job) (job, error) {
 		return job{}, err
 	}
 
+	var progress []byte
+
+	if item.Progress != nil {
+		progress, err = json.Marshal(item.Progress)
+		if err != nil {
+			return job{}, err
+		}
+	}
+
 	return job{
 		ID:        item.ID,
 		Name:      item.Name,
@@ -153,6 +172,7 @@ func jobToRow(item *web.Job) (job, error) {
 		Data:      string(data),
 		CreatedAt: item.Date.Unix(),
 		UpdatedAt: time.Now().UTC().Unix(),
+		Progress:  string(progress),
 	}, nil
 }
 
@@ -163,6 +183,7 @@ type job struct {
 	Data      string
 	CreatedAt int64
 	UpdatedAt int64
+	Progress  string
 }
 
 func initDatabase(path string) (*sql.DB, error) {
@@ -211,9 +232,23 @@ func createSchema(db *sql.DB) error {
 			status TEXT NOT NULL,
 			data TEXT NOT NULL,
 			created_at INT NOT NULL,
-			updated_at INT NOT NULL
+			updated_at INT NOT NULL,
+			progress TEXT NOT NULL DEFAULT ''
 		)
 	`)
+	if err != nil {
+		return err
+	}
+
+	// databases created before the progress column was added
+	var hasProgress bool
+
+	err = db.QueryRow(`SELECT COUNT(*) > 0 FROM pragma_table_info('jobs') WHERE name = 'progress'`).Scan(&hasProgress)
+	if err != nil || hasProgress {
+		return err
+	}
+
+	_, err = db.Exec(`ALTER TABLE jobs ADD COLUMN progress TEXT NOT NULL DEFAULT ''`)
 
 	return err
 }
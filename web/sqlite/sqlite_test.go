@@ -0,0 +1,139 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web"
+	"github.com/gosom/google-maps-scraper/web/sqlite"
+)
+
+func newJob() web.Job {
+	return web.Job{
+		ID:     "job-1",
+		Name:   "cafes",
+		Date:   time.Now().UTC().Truncate(time.Second),
+		Status: web.StatusPending,
+		Data: web.JobData{
+			Keywords: []string{"cafe in athens"},
+			Lang:     "en",
+			Depth:    1,
+			MaxTime:  time.Minute,
+		},
+	}
+}
+
+func Test_RepoProgress(t *testing.T) {
+	ctx := context.Background()
+
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "jobs.db"))
+	require.NoError(t, err)
+
+	job := newJob()
+	require.NoError(t, repo.Create(ctx, &job))
+
+	got, err := repo.Get(ctx, job.ID)
+	require.NoError(t, err)
+	require.Nil(t, got.Progress)
+
+	job.Status = web.StatusOK
+	job.Progress = &web.JobProgress{SeedCount: 1, SeedCompleted: 1, PlacesFound: 5, PlacesCompleted: 5, Percent: 100}
+	require.NoError(t, repo.Update(ctx, &job))
+
+	got, err = repo.Get(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, job.Progress, got.Progress)
+}
+
+func Test_RepoAddsProgressColumn(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "jobs.db")
+
+	// the schema before the progress column existed
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`CREATE TABLE jobs (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		status TEXT NOT NULL,
+		data TEXT NOT NULL,
+		created_at INT NOT NULL,
+		updated_at INT NOT NULL
+	)`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO jobs VALUES ('old', 'old', 'ok', '{"keywords":["a"],"lang":"en"}', 0, 0)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	repo, err := sqlite.New(path)
+	require.NoError(t, err)
+
+	got, err := repo.Get(ctx, "old")
+	require.NoError(t, err)
+	require.Equal(t, "old", got.Name)
+	require.Nil(t, got.Progress)
+
+	// opening it again does not try to add the column twice
+	_, err = sqlite.New(path)
+	require.NoError(t, err)
+}
+
+func Test_ServiceSelectPending(t *testing.T) {
+	ctx := context.Background()
+
+	repo, err := sqlite.New(filepath.Join(t.TempDir(), "jobs.db"))
+	require.NoError(t, err)
+
+	svc := web.NewService(repo, t.TempDir())
+
+	for i, status := range []string{web.StatusPending, web.StatusWorking, web.StatusPending, web.StatusPending} {
+		job := newJob()
+		job.ID = fmt.Sprintf("job-%d", i)
+		job.Status = status
+		require.NoError(t, svc.Create(ctx, &job))
+	}
+
+	jobs, err := svc.SelectPending(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+
+	// jobs running at the same time update the database concurrently
+	var wg sync.WaitGroup
+
+	errs := make([]error, len(jobs))
+
+	for i := range jobs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			jobs[i].Status = web.StatusWorking
+
+			for range 20 {
+				if errs[i] = svc.Update(ctx, &jobs[i]); errs[i] != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	jobs, err = svc.SelectPending(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+}
@@ -0,0 +1,186 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Limits of the jobs run by /scrape/stream. The request is held open until
+// the job finishes, so they are kept much smaller than queued jobs.
+const (
+	maxStreamKeywords = 5
+	maxStreamDepth    = 10
+	maxStreamTime     = 5 * time.Minute
+)
+
+// StreamFunc runs job synchronously and writes its results to w as
+// newline delimited JSON. It must return when ctx is cancelled.
+type StreamFunc func(ctx context.Context, job *Job, w io.Writer) error
+
+// WithStreamFunc enables the /scrape/stream endpoint. It answers 501
+// without it.
+func WithStreamFunc(fn StreamFunc) ServerOption {
+	return func(s *Server) {
+		s.stream = fn
+	}
+}
+
+func (s *Server) scrapeStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		ans := apiError{
+			Code:    http.StatusMethodNotAllowed,
+			Message: "Method not allowed",
+		}
+
+		renderJSON(w, http.StatusMethodNotAllowed, ans)
+
+		return
+	}
+
+	if s.stream == nil {
+		ans := apiError{
+			Code:    http.StatusNotImplemented,
+			Message: "streaming is not supported by this server",
+		}
+
+		renderJSON(w, http.StatusNotImplemented, ans)
+
+		return
+	}
+
+	if s.svc.Paused() {
+		ans := apiError{
+			Code:    http.StatusServiceUnavailable,
+			Message: "the server is paused",
+		}
+
+		renderJSON(w, http.StatusServiceUnavailable, ans)
+
+		return
+	}
+
+	var req apiScrapeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		ans := apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+
+		renderJSON(w, http.StatusUnprocessableEntity, ans)
+
+		return
+	}
+
+	job := Job{
+		ID:     uuid.New().String(),
+		Name:   req.Name,
+		Date:   time.Now().UTC(),
+		Status: StatusWorking,
+		Data:   req.JobData,
+	}
+
+	if job.Name == "" {
+		job.Name = "stream"
+	}
+
+	// convert to seconds
+	job.Data.MaxTime *= time.Second
+
+	if job.Data.MaxTime == 0 {
+		job.Data.MaxTime = maxStreamTime
+	}
+
+	if err := validateStreamJob(&job); err != nil {
+		ans := apiError{
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+
+		renderJSON(w, http.StatusUnprocessableEntity, ans)
+
+		return
+	}
+
+	// One job at a time: each one holds a browser for minutes.
+	select {
+	case s.streamSem <- struct{}{}:
+		defer func() { <-s.streamSem }()
+	default:
+		ans := apiError{
+			Code:    http.StatusTooManyRequests,
+			Message: "another streaming job is running",
+		}
+
+		renderJSON(w, http.StatusTooManyRequests, ans)
+
+		return
+	}
+
+	// The server write timeout is much shorter than a job.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout(&job)))
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Job-Id", job.ID)
+	w.WriteHeader(http.StatusOK)
+
+	_ = rc.Flush()
+
+	// The request context is cancelled when the client goes away, which
+	// stops the job.
+	err := s.stream(r.Context(), &job, &flushWriter{w: w, rc: rc})
+	if err != nil {
+		log.Printf("streaming job %s failed: %v", job.ID, err)
+	}
+}
+
+// streamWriteTimeout returns how long the results of job can be written:
+// as long as it runs, a short max time raised to MinRunTime included, and
+// a minute to spare.
+func streamWriteTimeout(job *Job) time.Duration {
+	return job.Data.RunTime() + time.Minute
+}
+
+func validateStreamJob(job *Job) error {
+	if err := job.Validate(); err != nil {
+		return err
+	}
+
+	if len(job.Data.Keywords) > maxStreamKeywords {
+		return fmt.Errorf("at most %d keywords are allowed when streaming", maxStreamKeywords)
+	}
+
+	if job.Data.Depth > maxStreamDepth {
+		return fmt.Errorf("depth must be at most %d when streaming", maxStreamDepth)
+	}
+
+	if job.Data.MaxTime > maxStreamTime {
+		return fmt.Errorf("max time must be at most %d seconds when streaming", int(maxStreamTime.Seconds()))
+	}
+
+	return nil
+}
+
+// flushWriter sends every write to the client right away, so each result
+// line arrives as soon as its place is scraped.
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (f *flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	return n, f.rc.Flush()
+}
@@ -0,0 +1,165 @@
+package web_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/web"
+)
+
+func Test_ScrapeStream(t *testing.T) {
+	newHandler := func(t *testing.T, opts ...web.ServerOption) http.Handler {
+		t.Helper()
+
+		svc := web.NewService(&memRepo{jobs: map[string]web.Job{}}, t.TempDir())
+
+		srv, err := web.New(svc, ":0", opts...)
+		require.NoError(t, err)
+
+		return srv.Handler()
+	}
+
+	post := func(h http.Handler, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/scrape/stream", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("disabled without stream func", func(t *testing.T) {
+		h := newHandler(t)
+
+		rec := post(h, `{"keywords": ["cafe"], "lang": "en", "depth": 1}`)
+		require.Equal(t, http.StatusNotImplemented, rec.Code)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		h := newHandler(t, web.WithStreamFunc(func(context.Context, *web.Job, io.Writer) error {
+			t.Fatal("the job must not run")
+
+			return nil
+		}))
+
+		for _, body := range []string{
+			`{"keywords": ["a", "b", "c", "d", "e", "f"], "lang": "en", "depth": 1}`,
+			`{"keywords": ["cafe"], "lang": "en", "depth": 11}`,
+			`{"keywords": ["cafe"], "lang": "en", "depth": 1, "max_time": 3600}`,
+			`{"keywords": [], "lang": "en", "depth": 1}`,
+		} {
+			rec := post(h, body)
+			require.Equal(t, http.StatusUnprocessableEntity, rec.Code, body)
+		}
+	})
+
+	t.Run("streams results", func(t *testing.T) {
+		var got *web.Job
+
+		h := newHandler(t, web.WithStreamFunc(func(_ context.Context, job *web.Job, w io.Writer) error {
+			got = job
+
+			for i := range 2 {
+				if _, err := fmt.Fprintf(w, "{\"title\":\"place %d\"}\n", i); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}))
+
+		rec := post(h, `{"keywords": ["cafe"], "lang": "en", "depth": 1}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		require.True(t, rec.Flushed)
+
+		require.NotNil(t, got)
+		require.Equal(t, got.ID, rec.Header().Get("X-Job-Id"))
+		require.Equal(t, []string{"cafe"}, got.Data.Keywords)
+		require.Equal(t, 5*time.Minute, got.Data.MaxTime)
+
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		require.Len(t, lines, 2)
+
+		var place struct {
+			Title string `json:"title"`
+		}
+
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &place))
+		require.Equal(t, "place 1", place.Title)
+	})
+
+	t.Run("short max time", func(t *testing.T) {
+		var got *web.Job
+
+		h := newHandler(t, web.WithStreamFunc(func(_ context.Context, job *web.Job, _ io.Writer) error {
+			got = job
+
+			return nil
+		}))
+
+		rec := post(h, `{"keywords": ["cafe"], "lang": "en", "depth": 1, "max_time": 30}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		// the job runs for MinRunTime, so its results can be written as long
+		require.NotNil(t, got)
+		require.Equal(t, 30*time.Second, got.Data.MaxTime)
+		require.Equal(t, web.MinRunTime, got.Data.RunTime())
+		require.Equal(t, web.MinRunTime+time.Minute, web.StreamWriteTimeout(got))
+	})
+
+	t.Run("client disconnect cancels the job", func(t *testing.T) {
+		cancelled := make(chan struct{})
+
+		srv := httptest.NewServer(newHandler(t, web.WithStreamFunc(func(ctx context.Context, _ *web.Job, w io.Writer) error {
+			_, _ = io.WriteString(w, "{\"title\":\"first\"}\n")
+
+			<-ctx.Done()
+			close(cancelled)
+
+			return ctx.Err()
+		})))
+		defer srv.Close()
+
+		body := `{"keywords": ["cafe"], "lang": "en", "depth": 1}`
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/scrape/stream", strings.NewReader(body))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+
+		defer resp.Body.Close()
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "{\"title\":\"first\"}\n", line)
+
+		// only one streaming job runs at a time
+		busy, err := http.Post(srv.URL+"/scrape/stream", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+
+		_ = busy.Body.Close()
+		require.Equal(t, http.StatusTooManyRequests, busy.StatusCode)
+
+		cancel()
+
+		select {
+		case <-cancelled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the job was not cancelled")
+		}
+	})
+}
@@ -23,15 +23,29 @@ import (
 var static embed.FS
 
 type Server struct {
-	tmpl map[string]*template.Template
-	srv  *http.Server
-	svc  *Service
+	tmpl       map[string]*template.Template
+	srv        *http.Server
+	svc        *Service
+	adminToken string
+	stream     StreamFunc
+	streamSem  chan struct{}
 }
 
-func New(svc *Service, addr string) (*Server, error) {
+type ServerOption func(*Server)
+
+// WithAdminToken enables the /admin endpoints for requests with the
+// header "Authorization: Bearer <token>". They are disabled without it.
+func WithAdminToken(token string) ServerOption {
+	return func(s *Server) {
+		s.adminToken = token
+	}
+}
+
+func New(svc *Service, addr string, opts ...ServerOption) (*Server, error) {
 	ans := Server{
-		svc:  svc,
-		tmpl: make(map[string]*template.Template),
+		svc:       svc,
+		tmpl:      make(map[string]*template.Template),
+		streamSem: make(chan struct{}, 1),
 		srv: &http.Server{
 			Addr:              addr,
 			ReadHeaderTimeout: 10 * time.Second,
@@ -42,6 +56,10 @@ func New(svc *Service, addr string) (*Server, error) {
 		},
 	}
 
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
 	staticFS, err := fs.Sub(static, "static")
 	if err != nil {
 		return nil, err
@@ -52,6 +70,7 @@ func New(svc *Service, addr string) (*Server, error) {
 
 	mux.Handle("/static/", http.StripPrefix("/static/", fileServer))
 	mux.HandleFunc("/scrape", ans.scrape)
+	mux.HandleFunc("/scrape/stream", ans.scrapeStream)
 	mux.HandleFunc("/download", func(w http.ResponseWriter, r *http.Request) {
 		r = requestWithID(r)
 
@@ -118,6 +137,10 @@ func New(svc *Service, addr string) (*Server, error) {
 		ans.download(w, r)
 	})
 
+	mux.HandleFunc("/healthz", ans.healthz)
+	mux.HandleFunc("/admin/pause", ans.admin(ans.pause))
+	mux.HandleFunc("/admin/resume", ans.admin(ans.resume))
+
 	handler := securityHeaders(mux)
 	ans.srv.Handler = handler
 
@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"fmt"
+	"net/textproto"
+	"strings"
+)
+
+// RunIDPlaceholder is replaced by the ID of the run in the values of the
+// headers, e.g. to route the results of several runs.
+const RunIDPlaceholder = "{run_id}"
+
+// forbiddenHeaders are set by the HTTP client or by the writer itself.
+var forbiddenHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Content-Type":      true,
+	"Host":              true,
+	"Keep-Alive":        true,
+	"Proxy-Connection":  true,
+	"Te":                true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+}
+
+// ParseHeader parses a "Key: Value" header. It returns the canonical key
+// and the value without the surrounding spaces, and fails on a malformed
+// header or one the writer cannot set.
+func ParseHeader(s string) (key, value string, err error) {
+	key, value, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", "", fmt.Errorf("header %q is not of the form Key: Value", s)
+	}
+
+	key = strings.TrimSpace(key)
+	value = strings.TrimSpace(value)
+
+	if key == "" || strings.IndexFunc(key, func(r rune) bool { return !isTokenChar(r) }) != -1 {
+		return "", "", fmt.Errorf("header %q has an invalid name", s)
+	}
+
+	if strings.IndexFunc(value, func(r rune) bool { return r != '\t' && (r < ' ' || r == 0x7f) }) != -1 {
+		return "", "", fmt.Errorf("header %q has an invalid value", s)
+	}
+
+	key = textproto.CanonicalMIMEHeaderKey(key)
+
+	if forbiddenHeaders[key] {
+		return "", "", fmt.Errorf("header %s cannot be set", key)
+	}
+
+	return key, value, nil
+}
+
+// isTokenChar reports whether r can be in a header name, see RFC 9110.
+func isTokenChar(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	default:
+		return strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+	}
+}
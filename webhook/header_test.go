@@ -0,0 +1,46 @@
+package webhook_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/webhook"
+)
+
+func Test_ParseHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		key    string
+		value  string
+	}{
+		{header: "Authorization: Bearer abc", key: "Authorization", value: "Bearer abc"},
+		{header: "x-signature:sha256=ff", key: "X-Signature", value: "sha256=ff"},
+		{header: " X-Run : {run_id} ", key: "X-Run", value: "{run_id}"},
+		{header: "X-Empty:", key: "X-Empty", value: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.header, func(t *testing.T) {
+			key, value, err := webhook.ParseHeader(tc.header)
+			require.NoError(t, err)
+			require.Equal(t, tc.key, key)
+			require.Equal(t, tc.value, value)
+		})
+	}
+}
+
+func Test_ParseHeaderInvalid(t *testing.T) {
+	for _, header := range []string{
+		"Authorization",
+		": value",
+		"X Tenant: acme",
+		"X-Tenant: acme\r\nHost: evil",
+		"Host: example.com",
+		"content-type: text/plain",
+		"Content-Length: 10",
+	} {
+		_, _, err := webhook.ParseHeader(header)
+		require.Error(t, err, header)
+	}
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gosom/scrapemate"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+)
+
+// ResultWriterOption configures the writer of NewResultWriter.
+type ResultWriterOption func(*resultWriter)
+
+// WithHeaders adds headers to the requests of the writer, e.g. for
+// authentication or routing. See ParseHeader.
+func WithHeaders(headers http.Header) ResultWriterOption {
+	return func(r *resultWriter) {
+		r.headers = headers
+	}
+}
+
+// NewResultWriter returns a writer that POSTs the entries as a JSON array
+// to url, in batches of up to 50 entries.
+func NewResultWriter(url string, opts ...ResultWriterOption) scrapemate.ResultWriter {
+	ans := resultWriter{
+		url: url,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&ans)
+	}
+
+	return &ans
+}
+
+type resultWriter struct {
+	url     string
+	client  *http.Client
+	headers http.Header
+}
+
+func (r *resultWriter) Run(ctx context.Context, in <-chan scrapemate.Result) error {
+	const maxBatchSize = 50
+
+	buff := make([]*gmaps.Entry, 0, maxBatchSize)
+	lastSend := time.Now().UTC()
+
+	for result := range in {
+		switch data := result.Data.(type) {
+		case *gmaps.Entry:
+			buff = append(buff, data)
+		case []*gmaps.Entry:
+			buff = append(buff, data...)
+		default:
+			return errors.New("invalid data type")
+		}
+
+		if len(buff) >= maxBatchSize || time.Now().UTC().Sub(lastSend) >= time.Minute {
+			if err := r.send(ctx, buff); err != nil {
+				return err
+			}
+
+			buff = buff[:0]
+			lastSend = time.Now().UTC()
+		}
+	}
+
+	return r.send(ctx, buff)
+}
+
+func (r *resultWriter) send(ctx context.Context, entries []*gmaps.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for key, values := range r.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gosom/scrapemate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gosom/google-maps-scraper/gmaps"
+	"github.com/gosom/google-maps-scraper/webhook"
+)
+
+func Test_ResultWriter(t *testing.T) {
+	var received []gmaps.Entry
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var batch []gmaps.Entry
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+
+		received = append(received, batch...)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	in := make(chan scrapemate.Result, 2)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "one"}}
+	in <- scrapemate.Result{Data: []*gmaps.Entry{{Title: "two"}}}
+	close(in)
+
+	require.NoError(t, webhook.NewResultWriter(srv.URL).Run(context.Background(), in))
+
+	require.Len(t, received, 2)
+	require.Equal(t, "one", received[0].Title)
+	require.Equal(t, "two", received[1].Title)
+}
+
+func Test_ResultWriterErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	in := make(chan scrapemate.Result, 1)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "one"}}
+	close(in)
+
+	require.Error(t, webhook.NewResultWriter(srv.URL).Run(context.Background(), in))
+}
+
+func Test_ResultWriterHeaders(t *testing.T) {
+	var got http.Header
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	headers := http.Header{}
+	headers.Add("Authorization", "Bearer secret")
+	headers.Add("X-Tenant", "acme")
+	headers.Add("X-Tenant", "eu")
+
+	in := make(chan scrapemate.Result, 1)
+	in <- scrapemate.Result{Data: &gmaps.Entry{Title: "one"}}
+	close(in)
+
+	require.NoError(t, webhook.NewResultWriter(srv.URL, webhook.WithHeaders(headers)).Run(context.Background(), in))
+
+	require.Equal(t, "Bearer secret", got.Get("Authorization"))
+	require.Equal(t, []string{"acme", "eu"}, got.Values("X-Tenant"))
+	require.Equal(t, "application/json", got.Get("Content-Type"))
+}